@@ -0,0 +1,265 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package blsync implements a beacon light client, following the head of the
+// beacon chain through the light client endpoints of a beacon node REST API and
+// announcing the verified execution payloads.
+package blsync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/light"
+	"github.com/ethereum/go-ethereum/beacon/light/api"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// pollInterval is the time between two consecutive head update requests,
+	// a third of the beacon chain slot time.
+	pollInterval = 4 * time.Second
+
+	// maxUpdateRequest is the maximum number of committee updates requested at
+	// once.
+	maxUpdateRequest = 128
+)
+
+var (
+	errNoCheckpoint     = errors.New("no checkpoint specified")
+	errTooFewSigners    = errors.New("not enough sync committee signers")
+	errInvalidSignature = errors.New("invalid sync committee signature")
+)
+
+// ChainHeadEvent is announced when a new head of the beacon chain is verified,
+// along with the beacon block containing the belonging execution payload.
+type ChainHeadEvent struct {
+	BeaconHead types.Header
+	Block      *api.BeaconBlock // Beacon block carrying the verified execution payload
+	Finalized  common.Hash      // Hash of the latest finalized execution block, zero if unknown
+}
+
+// Client is the beacon light sync client. It initializes the sync committee chain
+// from a trusted checkpoint, keeps it synced with the committee updates served
+// by the beacon API, and verifies the signed heads against it.
+type Client struct {
+	config   *Config
+	api      *api.BeaconLightApi
+	chain    *light.CommitteeChain
+	interval time.Duration
+
+	headFeed event.Feed
+	scope    event.SubscriptionScope
+	closeCh  chan struct{}
+	wg       sync.WaitGroup
+
+	// Last announced head, only accessed by the sync loop
+	headSlot  uint64
+	headRoot  common.Hash
+	finalized common.Hash
+}
+
+// NewClient creates a new beacon light sync client. The sync committee chain is
+// persisted in the given database.
+func NewClient(config *Config, db ethdb.KeyValueStore) *Client {
+	return newClient(config, light.NewCommitteeChain(db, &config.ChainConfig, config.Threshold, true))
+}
+
+// newClient creates a new beacon light sync client with the given committee chain.
+func newClient(config *Config, chain *light.CommitteeChain) *Client {
+	return &Client{
+		config:   config,
+		api:      api.NewBeaconLightApi(config.Api, config.CustomHeader),
+		chain:    chain,
+		interval: pollInterval,
+		closeCh:  make(chan struct{}),
+	}
+}
+
+// SubscribeChainHeadEvent subscribes to the verified beacon chain heads.
+func (c *Client) SubscribeChainHeadEvent(ch chan<- ChainHeadEvent) event.Subscription {
+	return c.scope.Track(c.headFeed.Subscribe(ch))
+}
+
+// Start launches the background sync process.
+func (c *Client) Start() {
+	c.wg.Add(1)
+	go c.loop()
+}
+
+// Stop terminates the background sync process.
+func (c *Client) Stop() {
+	close(c.closeCh)
+	c.wg.Wait()
+	c.scope.Close()
+}
+
+// loop periodically polls the beacon API for new committee and head updates.
+func (c *Client) loop() {
+	defer c.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := c.syncCommittees(); err != nil {
+				log.Warn("Failed to sync beacon committees", "err", err)
+			} else if err := c.syncHead(); err != nil {
+				log.Warn("Failed to sync beacon head", "err", err)
+			}
+			timer.Reset(c.interval)
+
+		case <-c.closeCh:
+			return
+		}
+	}
+}
+
+// currentPeriod returns the sync period of the current slot according to the
+// local clock.
+func (c *Client) currentPeriod() uint64 {
+	now := uint64(time.Now().Unix())
+	if now < c.config.GenesisTime {
+		return 0
+	}
+	return types.SyncPeriod((now - c.config.GenesisTime) / c.config.SlotTime())
+}
+
+// syncCommittees initializes the committee chain from the checkpoint if it is
+// empty, and inserts the committee updates up to the current sync period.
+func (c *Client) syncCommittees() error {
+	if _, ok := c.chain.NextSyncPeriod(); !ok {
+		if c.config.Checkpoint == (common.Hash{}) {
+			return errNoCheckpoint
+		}
+		bootstrap, err := c.api.GetCheckpointData(c.config.Checkpoint)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve checkpoint: %w", err)
+		}
+		if err := c.chain.CheckpointInit(bootstrap); err != nil {
+			return fmt.Errorf("failed to initialize from checkpoint: %w", err)
+		}
+		log.Info("Initialized beacon committee chain", "checkpoint", c.config.Checkpoint, "period", bootstrap.Header.SyncPeriod())
+	}
+	current := c.currentPeriod()
+	for {
+		next, _ := c.chain.NextSyncPeriod()
+		if next > current {
+			return nil
+		}
+		count := current - next + 1
+		if count > maxUpdateRequest {
+			count = maxUpdateRequest
+		}
+		updates, committees, err := c.api.GetBestUpdatesAndCommittees(next, count)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve committee updates: %w", err)
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		for i, update := range updates {
+			period := next + uint64(i)
+			if err := c.chain.InsertUpdate(update, committees[i]); err != nil {
+				// The update of the current period might not be strong
+				// enough yet, it is retried later.
+				if period == current {
+					log.Debug("Postponed current committee update", "period", period, "err", err)
+					return nil
+				}
+				return fmt.Errorf("failed to insert committee update of period %d: %w", period, err)
+			}
+		}
+	}
+}
+
+// verifyHeader checks the sync committee signature of a signed header.
+func (c *Client) verifyHeader(head types.SignedHeader) error {
+	if head.Signature.SignerCount() < c.config.Threshold {
+		return errTooFewSigners
+	}
+	ok, age, err := c.chain.VerifySignedHeader(head)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errInvalidSignature
+	}
+	if age < 0 {
+		log.Warn("Future signed head received", "age", age)
+	}
+	return nil
+}
+
+// syncHead retrieves and verifies the latest optimistic and finality updates,
+// and announces the new head along with the belonging execution payload if
+// anything changed.
+func (c *Client) syncHead() error {
+	optimistic, err := c.api.GetOptimisticUpdate()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve optimistic update: %w", err)
+	}
+	if err := c.verifyHeader(optimistic.SignedHeader()); err != nil {
+		return fmt.Errorf("invalid optimistic update: %w", err)
+	}
+	finalized := c.finalized
+	finality, err := c.api.GetFinalityUpdate()
+	switch {
+	case err == nil:
+		if err := c.verifyHeader(finality.SignedHeader()); err != nil {
+			return fmt.Errorf("invalid finality update: %w", err)
+		}
+		finalized = finality.Finalized.PayloadHeader.BlockHash
+	case !errors.Is(err, api.ErrNotFound):
+		return fmt.Errorf("failed to retrieve finality update: %w", err)
+	}
+	head := optimistic.Attested
+	if head.Slot < c.headSlot || (head.Hash() == c.headRoot && finalized == c.finalized) {
+		return nil
+	}
+	// Retrieve the full execution payload of the head, which is verified
+	// against the execution block hash proven by the signed header.
+	block, err := c.api.GetBeaconBlock(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve head block: %w", err)
+	}
+	if block.Slot != head.Slot || block.ParentRoot != head.ParentRoot {
+		return errors.New("head block mismatch")
+	}
+	if block.Payload.BlockHash != head.PayloadHeader.BlockHash {
+		return errors.New("execution payload mismatch")
+	}
+	if _, err := block.ExecutionBlock(); err != nil {
+		return fmt.Errorf("invalid execution payload: %w", err)
+	}
+	c.headSlot, c.headRoot, c.finalized = head.Slot, head.Hash(), finalized
+
+	log.Debug("New beacon head", "slot", head.Slot, "root", c.headRoot, "number", block.Payload.Number, "hash", block.Payload.BlockHash, "finalized", finalized)
+	c.headFeed.Send(ChainHeadEvent{
+		BeaconHead: head.Header,
+		Block:      block,
+		Finalized:  finalized,
+	})
+	return nil
+}
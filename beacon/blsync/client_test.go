@@ -0,0 +1,245 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package blsync
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/light"
+	"github.com/ethereum/go-ethereum/beacon/light/api"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// makeTestProof creates a merkle proof of the given value at the given generalized
+// tree index, returning the root and the proof branch. The branch starts with the
+// given siblings, the rest of it is random.
+func makeTestProof(index uint64, value merkle.Value, siblings ...merkle.Value) (common.Hash, merkle.Values) {
+	var (
+		branch merkle.Values
+		hasher = sha256.New()
+	)
+	for ; index > 1; index >>= 1 {
+		var sibling merkle.Value
+		if len(branch) < len(siblings) {
+			sibling = siblings[len(branch)]
+		} else {
+			rand.Read(sibling[:])
+		}
+		hasher.Reset()
+		if index&1 == 0 {
+			hasher.Write(value[:])
+			hasher.Write(sibling[:])
+		} else {
+			hasher.Write(sibling[:])
+			hasher.Write(value[:])
+		}
+		hasher.Sum(value[:0])
+		branch = append(branch, sibling)
+	}
+	return common.Hash(value), branch
+}
+
+// makeTestBlock creates a beacon block with an execution payload carrying no
+// transactions but a withdrawal request, along with the proof of the execution
+// payload header.
+func makeTestBlock(slot uint64, number uint64) (*api.BeaconBlock, types.HeaderWithExecProof) {
+	var parentRoot common.Hash
+	rand.Read(parentRoot[:])
+
+	request := make([]byte, 1+20+48+8)
+	request[0] = ctypes.WithdrawalRequestType
+	rand.Read(request[1:])
+	var (
+		requests     = [][]byte{request}
+		requestsHash = ctypes.CalcRequestsHash(requests)
+		zero         = uint64(0)
+	)
+	header := &ctypes.Header{
+		ParentHash:       common.Hash{byte(number)},
+		UncleHash:        ctypes.EmptyUncleHash,
+		Root:             common.Hash{1},
+		Number:           new(big.Int).SetUint64(number),
+		GasLimit:         30_000_000,
+		Time:             number * 12,
+		Difficulty:       new(big.Int),
+		BaseFee:          big.NewInt(7),
+		BlobGasUsed:      &zero,
+		ExcessBlobGas:    &zero,
+		ParentBeaconRoot: &parentRoot,
+		RequestsHash:     &requestsHash,
+	}
+	ed := engine.BlockToExecutableData(ctypes.NewBlockWithWithdrawals(header, nil, nil, nil, []*ctypes.Withdrawal{}, trie.NewStackTrie(nil)), nil, nil, requests).ExecutionPayload
+
+	exec := &types.ExecutionHeader{
+		ParentHash:    ed.ParentHash,
+		FeeRecipient:  ed.FeeRecipient,
+		StateRoot:     ed.StateRoot,
+		ReceiptsRoot:  ed.ReceiptsRoot,
+		PrevRandao:    ed.Random,
+		BlockNumber:   ed.Number,
+		GasLimit:      ed.GasLimit,
+		GasUsed:       ed.GasUsed,
+		Timestamp:     ed.Timestamp,
+		ExtraData:     ed.ExtraData,
+		BaseFeePerGas: ed.BaseFeePerGas,
+		BlockHash:     ed.BlockHash,
+		BlobGasUsed:   ed.BlobGasUsed,
+		ExcessBlobGas: ed.ExcessBlobGas,
+	}
+	copy(exec.LogsBloom[:], ed.LogsBloom)
+	bodyRoot, branch := makeTestProof(params.BodyIndexExecPayload, merkle.Value(exec.Root()))
+
+	block := &api.BeaconBlock{
+		Slot:            slot,
+		ParentRoot:      parentRoot,
+		Payload:         ed,
+		BlobCommitments: []kzg4844.Commitment{},
+		Requests:        requests,
+	}
+	return block, types.HeaderWithExecProof{
+		Header:        types.Header{Slot: slot, ParentRoot: parentRoot, BodyRoot: bodyRoot},
+		PayloadHeader: exec,
+		PayloadBranch: branch,
+	}
+}
+
+func TestClientSync(t *testing.T) {
+	// Create a beacon chain being in the middle of the third sync period.
+	config := &Config{
+		ChainConfig: types.ChainConfig{
+			GenesisTime: uint64(time.Now().Unix()) - params.SyncPeriodLength*12*5/2,
+		},
+		Threshold: 300,
+	}
+	config.ChainConfig.AddFork("GENESIS", 0, []byte{0, 0, 0, 0})
+
+	server := api.NewTestServer()
+	defer server.Close()
+
+	committees := make([]*types.SerializedSyncCommittee, 4)
+	for i := range committees {
+		committees[i] = light.GenerateTestCommittee()
+	}
+	// The checkpoint also proves the root of the next committee, being the
+	// sibling of the current one in the state tree.
+	checkpoint := &types.BootstrapData{
+		Header:        types.Header{Slot: 200},
+		Committee:     committees[0],
+		CommitteeRoot: committees[0].Root(),
+	}
+	checkpoint.Header.StateRoot, checkpoint.CommitteeBranch = makeTestProof(params.StateIndexSyncCommittee, merkle.Value(committees[0].Root()), merkle.Value(committees[1].Root()))
+	server.AddBootstrap(checkpoint)
+	for period := uint64(0); period < 3; period++ {
+		server.AddUpdate(light.GenerateTestUpdate(&config.ChainConfig, period, committees[period], committees[period+1], 400, false), committees[period+1])
+	}
+	config.Checkpoint = checkpoint.Header.Hash()
+	config.Api = server.URL
+
+	// Create the signed head and finalized headers, both proving the belonging
+	// execution payload headers.
+	headSlot := types.SyncPeriodStart(2) + 4000
+	headBlock, head := makeTestBlock(headSlot, 200)
+	_, finalized := makeTestBlock(headSlot-64, 190)
+
+	var finalityBranch merkle.Values
+	head.StateRoot, finalityBranch = makeTestProof(params.StateIndexFinalBlock, merkle.Value(finalized.Hash()))
+	signed := light.GenerateTestSignedHeader(head.Header, &config.ChainConfig, committees[2], headSlot+1, 400)
+
+	server.SetOptimisticUpdate(types.OptimisticUpdate{
+		Attested:      head,
+		Signature:     signed.Signature,
+		SignatureSlot: signed.SignatureSlot,
+	})
+	server.SetFinalityUpdate(types.FinalityUpdate{
+		Attested:       head,
+		Finalized:      finalized,
+		FinalityBranch: finalityBranch,
+		Signature:      signed.Signature,
+		SignatureSlot:  signed.SignatureSlot,
+	})
+	// Serve a tampered execution payload first, which should be rejected.
+	tampered := *headBlock
+	tampered.Payload = new(engine.ExecutableData)
+	*tampered.Payload = *headBlock.Payload
+	tampered.Payload.GasLimit++
+	server.AddBlock(head.Hash(), &tampered)
+
+	client := newClient(config, light.NewTestCommitteeChain(memorydb.New(), &config.ChainConfig, config.Threshold, true))
+	client.interval = 10 * time.Millisecond
+
+	headCh := make(chan ChainHeadEvent, 1)
+	sub := client.SubscribeChainHeadEvent(headCh)
+	defer sub.Unsubscribe()
+
+	client.Start()
+	defer client.Stop()
+
+	select {
+	case ev := <-headCh:
+		t.Fatalf("Tampered execution payload announced, hash: %x", ev.Block.Payload.BlockHash)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if next, ok := client.chain.NextSyncPeriod(); !ok || next != 3 {
+		t.Fatalf("Committee chain not synced, next period: %d (%t)", next, ok)
+	}
+	server.AddBlock(head.Hash(), headBlock)
+
+	select {
+	case ev := <-headCh:
+		if ev.BeaconHead != head.Header {
+			t.Fatalf("Unexpected beacon head, want: %x, got: %x", head.Hash(), ev.BeaconHead.Hash())
+		}
+		if ev.Block.Payload.BlockHash != head.PayloadHeader.BlockHash {
+			t.Fatalf("Unexpected execution head, want: %x, got: %x", head.PayloadHeader.BlockHash, ev.Block.Payload.BlockHash)
+		}
+		if !reflect.DeepEqual(ev.Block.Requests, headBlock.Requests) {
+			t.Fatalf("Unexpected execution requests, want: %x, got: %x", headBlock.Requests, ev.Block.Requests)
+		}
+		if ev.Finalized != finalized.PayloadHeader.BlockHash {
+			t.Fatalf("Unexpected finalized execution block, want: %x, got: %x", finalized.PayloadHeader.BlockHash, ev.Finalized)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Verified head not announced")
+	}
+}
+
+func TestClientCurrentPeriod(t *testing.T) {
+	for _, slotTime := range []uint64{0, 6, 12} {
+		config := &Config{
+			ChainConfig: types.ChainConfig{SecondsPerSlot: slotTime},
+		}
+		// Place the clock in the middle of the third sync period.
+		config.GenesisTime = uint64(time.Now().Unix()) - params.SyncPeriodLength*config.SlotTime()*5/2
+		client := &Client{config: config}
+		if period := client.currentPeriod(); period != 2 {
+			t.Errorf("slot time %d: wrong current period %d, want 2", slotTime, period)
+		}
+	}
+}
@@ -15,15 +15,17 @@ var _ = (*executionPayloadEnvelopeMarshaling)(nil)
 // MarshalJSON marshals as JSON.
 func (e ExecutionPayloadEnvelope) MarshalJSON() ([]byte, error) {
 	type ExecutionPayloadEnvelope struct {
-		ExecutionPayload *ExecutableData `json:"executionPayload"  gencodec:"required"`
-		BlockValue       *hexutil.Big    `json:"blockValue"  gencodec:"required"`
-		BlobsBundle      *BlobsBundleV1  `json:"blobsBundle"`
-		Override         bool            `json:"shouldOverrideBuilder"`
+		ExecutionPayload *ExecutableData  `json:"executionPayload"  gencodec:"required"`
+		BlockValue       *hexutil.Big     `json:"blockValue"  gencodec:"required"`
+		BlobsBundle      *BlobsBundleV1   `json:"blobsBundle"`
+		Requests         *[]hexutil.Bytes `json:"executionRequests,omitempty"`
+		Override         bool             `json:"shouldOverrideBuilder"`
 	}
 	var enc ExecutionPayloadEnvelope
 	enc.ExecutionPayload = e.ExecutionPayload
 	enc.BlockValue = (*hexutil.Big)(e.BlockValue)
 	enc.BlobsBundle = e.BlobsBundle
+	enc.Requests = e.Requests
 	enc.Override = e.Override
 	return json.Marshal(&enc)
 }
@@ -31,10 +33,11 @@ func (e ExecutionPayloadEnvelope) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON unmarshals from JSON.
 func (e *ExecutionPayloadEnvelope) UnmarshalJSON(input []byte) error {
 	type ExecutionPayloadEnvelope struct {
-		ExecutionPayload *ExecutableData `json:"executionPayload"  gencodec:"required"`
-		BlockValue       *hexutil.Big    `json:"blockValue"  gencodec:"required"`
-		BlobsBundle      *BlobsBundleV1  `json:"blobsBundle"`
-		Override         *bool           `json:"shouldOverrideBuilder"`
+		ExecutionPayload *ExecutableData  `json:"executionPayload"  gencodec:"required"`
+		BlockValue       *hexutil.Big     `json:"blockValue"  gencodec:"required"`
+		BlobsBundle      *BlobsBundleV1   `json:"blobsBundle"`
+		Requests         *[]hexutil.Bytes `json:"executionRequests,omitempty"`
+		Override         *bool            `json:"shouldOverrideBuilder"`
 	}
 	var dec ExecutionPayloadEnvelope
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -51,6 +54,9 @@ func (e *ExecutionPayloadEnvelope) UnmarshalJSON(input []byte) error {
 	if dec.BlobsBundle != nil {
 		e.BlobsBundle = dec.BlobsBundle
 	}
+	if dec.Requests != nil {
+		e.Requests = dec.Requests
+	}
 	if dec.Override != nil {
 		e.Override = *dec.Override
 	}
@@ -0,0 +1,608 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// This file contains the JSON representations of the light client data structures
+// served by the beacon node REST API. Only the Capella and newer formats are
+// supported, where the light client headers also prove the execution payload
+// headers belonging to the beacon headers.
+
+// jsonVersioned is the envelope of the API responses, tagging the data with the
+// name of the fork it belongs to.
+type jsonVersioned[T any] struct {
+	Version string `json:"version"`
+	Data    T      `json:"data"`
+}
+
+type jsonExecutionHeader struct {
+	ParentHash       common.Hash      `json:"parent_hash"`
+	FeeRecipient     common.Address   `json:"fee_recipient"`
+	StateRoot        common.Hash      `json:"state_root"`
+	ReceiptsRoot     common.Hash      `json:"receipts_root"`
+	LogsBloom        hexutil.Bytes    `json:"logs_bloom"`
+	PrevRandao       common.Hash      `json:"prev_randao"`
+	BlockNumber      common.Decimal   `json:"block_number"`
+	GasLimit         common.Decimal   `json:"gas_limit"`
+	GasUsed          common.Decimal   `json:"gas_used"`
+	Timestamp        common.Decimal   `json:"timestamp"`
+	ExtraData        hexutil.Bytes    `json:"extra_data"`
+	BaseFeePerGas    *math.Decimal256 `json:"base_fee_per_gas"`
+	BlockHash        common.Hash      `json:"block_hash"`
+	TransactionsRoot common.Hash      `json:"transactions_root"`
+	WithdrawalsRoot  common.Hash      `json:"withdrawals_root"`
+	BlobGasUsed      *common.Decimal  `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas    *common.Decimal  `json:"excess_blob_gas,omitempty"`
+}
+
+func encodeExecutionHeader(h *types.ExecutionHeader) *jsonExecutionHeader {
+	enc := &jsonExecutionHeader{
+		ParentHash:       h.ParentHash,
+		FeeRecipient:     h.FeeRecipient,
+		StateRoot:        h.StateRoot,
+		ReceiptsRoot:     h.ReceiptsRoot,
+		LogsBloom:        h.LogsBloom[:],
+		PrevRandao:       h.PrevRandao,
+		BlockNumber:      common.Decimal(h.BlockNumber),
+		GasLimit:         common.Decimal(h.GasLimit),
+		GasUsed:          common.Decimal(h.GasUsed),
+		Timestamp:        common.Decimal(h.Timestamp),
+		ExtraData:        h.ExtraData,
+		BaseFeePerGas:    (*math.Decimal256)(h.BaseFeePerGas),
+		BlockHash:        h.BlockHash,
+		TransactionsRoot: h.TransactionsRoot,
+		WithdrawalsRoot:  h.WithdrawalsRoot,
+	}
+	if h.BlobGasUsed != nil && h.ExcessBlobGas != nil {
+		blobGasUsed, excessBlobGas := common.Decimal(*h.BlobGasUsed), common.Decimal(*h.ExcessBlobGas)
+		enc.BlobGasUsed, enc.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return enc
+}
+
+func (h *jsonExecutionHeader) decode() (*types.ExecutionHeader, error) {
+	dec := &types.ExecutionHeader{
+		ParentHash:       h.ParentHash,
+		FeeRecipient:     h.FeeRecipient,
+		StateRoot:        h.StateRoot,
+		ReceiptsRoot:     h.ReceiptsRoot,
+		PrevRandao:       h.PrevRandao,
+		BlockNumber:      uint64(h.BlockNumber),
+		GasLimit:         uint64(h.GasLimit),
+		GasUsed:          uint64(h.GasUsed),
+		Timestamp:        uint64(h.Timestamp),
+		ExtraData:        h.ExtraData,
+		BaseFeePerGas:    (*big.Int)(h.BaseFeePerGas),
+		BlockHash:        h.BlockHash,
+		TransactionsRoot: h.TransactionsRoot,
+		WithdrawalsRoot:  h.WithdrawalsRoot,
+	}
+	if len(h.LogsBloom) != len(dec.LogsBloom) {
+		return nil, errors.New("invalid logs bloom length")
+	}
+	copy(dec.LogsBloom[:], h.LogsBloom)
+	if len(h.ExtraData) > 32 {
+		return nil, errors.New("extra data too long")
+	}
+	if h.BaseFeePerGas == nil {
+		return nil, errors.New("missing base fee")
+	}
+	if (h.BlobGasUsed == nil) != (h.ExcessBlobGas == nil) {
+		return nil, errors.New("incomplete blob gas fields")
+	}
+	if h.BlobGasUsed != nil {
+		blobGasUsed, excessBlobGas := uint64(*h.BlobGasUsed), uint64(*h.ExcessBlobGas)
+		dec.BlobGasUsed, dec.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return dec, nil
+}
+
+type jsonHeaderWithExecProof struct {
+	Beacon          types.Header         `json:"beacon"`
+	Execution       *jsonExecutionHeader `json:"execution,omitempty"`
+	ExecutionBranch merkle.Values        `json:"execution_branch,omitempty"`
+}
+
+func encodeHeaderWithExecProof(h *types.HeaderWithExecProof) jsonHeaderWithExecProof {
+	enc := jsonHeaderWithExecProof{
+		Beacon:          h.Header,
+		ExecutionBranch: h.PayloadBranch,
+	}
+	if h.PayloadHeader != nil {
+		enc.Execution = encodeExecutionHeader(h.PayloadHeader)
+	}
+	return enc
+}
+
+func (h *jsonHeaderWithExecProof) decode() (types.HeaderWithExecProof, error) {
+	if h.Execution == nil {
+		return types.HeaderWithExecProof{}, errors.New("missing execution payload header")
+	}
+	payload, err := h.Execution.decode()
+	if err != nil {
+		return types.HeaderWithExecProof{}, err
+	}
+	return types.HeaderWithExecProof{
+		Header:        h.Beacon,
+		PayloadHeader: payload,
+		PayloadBranch: h.ExecutionBranch,
+	}, nil
+}
+
+type jsonBootstrapData struct {
+	Header          jsonHeaderWithExecProof        `json:"header"`
+	Committee       *types.SerializedSyncCommittee `json:"current_sync_committee"`
+	CommitteeBranch merkle.Values                  `json:"current_sync_committee_branch"`
+}
+
+func encodeBootstrapData(b *types.BootstrapData) *jsonBootstrapData {
+	return &jsonBootstrapData{
+		Header:          jsonHeaderWithExecProof{Beacon: b.Header},
+		Committee:       b.Committee,
+		CommitteeBranch: b.CommitteeBranch,
+	}
+}
+
+func (b *jsonBootstrapData) decode() (*types.BootstrapData, error) {
+	if b.Committee == nil {
+		return nil, errors.New("missing sync committee")
+	}
+	return &types.BootstrapData{
+		Header:          b.Header.Beacon,
+		CommitteeRoot:   b.Committee.Root(),
+		Committee:       b.Committee,
+		CommitteeBranch: b.CommitteeBranch,
+	}, nil
+}
+
+type jsonLightClientUpdate struct {
+	AttestedHeader          jsonHeaderWithExecProof        `json:"attested_header"`
+	NextSyncCommittee       *types.SerializedSyncCommittee `json:"next_sync_committee"`
+	NextSyncCommitteeBranch merkle.Values                  `json:"next_sync_committee_branch"`
+	FinalizedHeader         *jsonHeaderWithExecProof       `json:"finalized_header,omitempty"`
+	FinalityBranch          merkle.Values                  `json:"finality_branch,omitempty"`
+	SyncAggregate           types.SyncAggregate            `json:"sync_aggregate"`
+	SignatureSlot           common.Decimal                 `json:"signature_slot"`
+}
+
+func encodeLightClientUpdate(u *types.LightClientUpdate, committee *types.SerializedSyncCommittee) *jsonLightClientUpdate {
+	enc := &jsonLightClientUpdate{
+		AttestedHeader:          jsonHeaderWithExecProof{Beacon: u.AttestedHeader.Header},
+		NextSyncCommittee:       committee,
+		NextSyncCommitteeBranch: u.NextSyncCommitteeBranch,
+		FinalityBranch:          u.FinalityBranch,
+		SyncAggregate:           u.AttestedHeader.Signature,
+		SignatureSlot:           common.Decimal(u.AttestedHeader.SignatureSlot),
+	}
+	if u.FinalizedHeader != nil {
+		enc.FinalizedHeader = &jsonHeaderWithExecProof{Beacon: *u.FinalizedHeader}
+	}
+	return enc
+}
+
+func (u *jsonLightClientUpdate) decode() (*types.LightClientUpdate, *types.SerializedSyncCommittee, error) {
+	if u.NextSyncCommittee == nil {
+		return nil, nil, errors.New("missing next sync committee")
+	}
+	update := &types.LightClientUpdate{
+		AttestedHeader: types.SignedHeader{
+			Header:        u.AttestedHeader.Beacon,
+			Signature:     u.SyncAggregate,
+			SignatureSlot: uint64(u.SignatureSlot),
+		},
+		NextSyncCommitteeRoot:   u.NextSyncCommittee.Root(),
+		NextSyncCommitteeBranch: u.NextSyncCommitteeBranch,
+	}
+	// Updates without finality carry an empty finalized header.
+	if u.FinalizedHeader != nil && u.FinalizedHeader.Beacon != (types.Header{}) {
+		update.FinalizedHeader = &u.FinalizedHeader.Beacon
+		update.FinalityBranch = u.FinalityBranch
+	}
+	return update, u.NextSyncCommittee, nil
+}
+
+type jsonOptimisticUpdate struct {
+	Attested      jsonHeaderWithExecProof `json:"attested_header"`
+	SyncAggregate types.SyncAggregate     `json:"sync_aggregate"`
+	SignatureSlot common.Decimal          `json:"signature_slot"`
+}
+
+func encodeOptimisticUpdate(u *types.OptimisticUpdate) *jsonOptimisticUpdate {
+	return &jsonOptimisticUpdate{
+		Attested:      encodeHeaderWithExecProof(&u.Attested),
+		SyncAggregate: u.Signature,
+		SignatureSlot: common.Decimal(u.SignatureSlot),
+	}
+}
+
+func (u *jsonOptimisticUpdate) decode() (types.OptimisticUpdate, error) {
+	attested, err := u.Attested.decode()
+	if err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	return types.OptimisticUpdate{
+		Attested:      attested,
+		Signature:     u.SyncAggregate,
+		SignatureSlot: uint64(u.SignatureSlot),
+	}, nil
+}
+
+type jsonFinalityUpdate struct {
+	Attested       jsonHeaderWithExecProof `json:"attested_header"`
+	Finalized      jsonHeaderWithExecProof `json:"finalized_header"`
+	FinalityBranch merkle.Values           `json:"finality_branch"`
+	SyncAggregate  types.SyncAggregate     `json:"sync_aggregate"`
+	SignatureSlot  common.Decimal          `json:"signature_slot"`
+}
+
+func encodeFinalityUpdate(u *types.FinalityUpdate) *jsonFinalityUpdate {
+	return &jsonFinalityUpdate{
+		Attested:       encodeHeaderWithExecProof(&u.Attested),
+		Finalized:      encodeHeaderWithExecProof(&u.Finalized),
+		FinalityBranch: u.FinalityBranch,
+		SyncAggregate:  u.Signature,
+		SignatureSlot:  common.Decimal(u.SignatureSlot),
+	}
+}
+
+func (u *jsonFinalityUpdate) decode() (types.FinalityUpdate, error) {
+	attested, err := u.Attested.decode()
+	if err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	finalized, err := u.Finalized.decode()
+	if err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	return types.FinalityUpdate{
+		Attested:       attested,
+		Finalized:      finalized,
+		FinalityBranch: u.FinalityBranch,
+		Signature:      u.SyncAggregate,
+		SignatureSlot:  uint64(u.SignatureSlot),
+	}, nil
+}
+
+type jsonWithdrawal struct {
+	Index     common.Decimal `json:"index"`
+	Validator common.Decimal `json:"validator_index"`
+	Address   common.Address `json:"address"`
+	Amount    common.Decimal `json:"amount"`
+}
+
+type jsonExecutionPayload struct {
+	ParentHash    common.Hash      `json:"parent_hash"`
+	FeeRecipient  common.Address   `json:"fee_recipient"`
+	StateRoot     common.Hash      `json:"state_root"`
+	ReceiptsRoot  common.Hash      `json:"receipts_root"`
+	LogsBloom     hexutil.Bytes    `json:"logs_bloom"`
+	PrevRandao    common.Hash      `json:"prev_randao"`
+	BlockNumber   common.Decimal   `json:"block_number"`
+	GasLimit      common.Decimal   `json:"gas_limit"`
+	GasUsed       common.Decimal   `json:"gas_used"`
+	Timestamp     common.Decimal   `json:"timestamp"`
+	ExtraData     hexutil.Bytes    `json:"extra_data"`
+	BaseFeePerGas *math.Decimal256 `json:"base_fee_per_gas"`
+	BlockHash     common.Hash      `json:"block_hash"`
+	Transactions  []hexutil.Bytes  `json:"transactions"`
+	Withdrawals   []jsonWithdrawal `json:"withdrawals"`
+	BlobGasUsed   *common.Decimal  `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas *common.Decimal  `json:"excess_blob_gas,omitempty"`
+}
+
+func encodeExecutionPayload(p *engine.ExecutableData) *jsonExecutionPayload {
+	enc := &jsonExecutionPayload{
+		ParentHash:    p.ParentHash,
+		FeeRecipient:  p.FeeRecipient,
+		StateRoot:     p.StateRoot,
+		ReceiptsRoot:  p.ReceiptsRoot,
+		LogsBloom:     p.LogsBloom,
+		PrevRandao:    p.Random,
+		BlockNumber:   common.Decimal(p.Number),
+		GasLimit:      common.Decimal(p.GasLimit),
+		GasUsed:       common.Decimal(p.GasUsed),
+		Timestamp:     common.Decimal(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BaseFeePerGas: (*math.Decimal256)(p.BaseFeePerGas),
+		BlockHash:     p.BlockHash,
+		Transactions:  make([]hexutil.Bytes, len(p.Transactions)),
+		Withdrawals:   make([]jsonWithdrawal, len(p.Withdrawals)),
+	}
+	for i, tx := range p.Transactions {
+		enc.Transactions[i] = tx
+	}
+	for i, w := range p.Withdrawals {
+		enc.Withdrawals[i] = jsonWithdrawal{
+			Index:     common.Decimal(w.Index),
+			Validator: common.Decimal(w.Validator),
+			Address:   w.Address,
+			Amount:    common.Decimal(w.Amount),
+		}
+	}
+	if p.BlobGasUsed != nil && p.ExcessBlobGas != nil {
+		blobGasUsed, excessBlobGas := common.Decimal(*p.BlobGasUsed), common.Decimal(*p.ExcessBlobGas)
+		enc.BlobGasUsed, enc.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return enc
+}
+
+func (p *jsonExecutionPayload) decode() (*engine.ExecutableData, error) {
+	if p.BaseFeePerGas == nil {
+		return nil, errors.New("missing base fee")
+	}
+	if (p.BlobGasUsed == nil) != (p.ExcessBlobGas == nil) {
+		return nil, errors.New("incomplete blob gas fields")
+	}
+	dec := &engine.ExecutableData{
+		ParentHash:    p.ParentHash,
+		FeeRecipient:  p.FeeRecipient,
+		StateRoot:     p.StateRoot,
+		ReceiptsRoot:  p.ReceiptsRoot,
+		LogsBloom:     p.LogsBloom,
+		Random:        p.PrevRandao,
+		Number:        uint64(p.BlockNumber),
+		GasLimit:      uint64(p.GasLimit),
+		GasUsed:       uint64(p.GasUsed),
+		Timestamp:     uint64(p.Timestamp),
+		ExtraData:     p.ExtraData,
+		BaseFeePerGas: (*big.Int)(p.BaseFeePerGas),
+		BlockHash:     p.BlockHash,
+		Transactions:  make([][]byte, len(p.Transactions)),
+		Withdrawals:   make([]*ctypes.Withdrawal, len(p.Withdrawals)),
+	}
+	for i, tx := range p.Transactions {
+		dec.Transactions[i] = tx
+	}
+	for i, w := range p.Withdrawals {
+		dec.Withdrawals[i] = &ctypes.Withdrawal{
+			Index:     uint64(w.Index),
+			Validator: uint64(w.Validator),
+			Address:   w.Address,
+			Amount:    uint64(w.Amount),
+		}
+	}
+	if p.BlobGasUsed != nil {
+		blobGasUsed, excessBlobGas := uint64(*p.BlobGasUsed), uint64(*p.ExcessBlobGas)
+		dec.BlobGasUsed, dec.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return dec, nil
+}
+
+type jsonDepositRequest struct {
+	Pubkey                hexutil.Bytes  `json:"pubkey"`
+	WithdrawalCredentials common.Hash    `json:"withdrawal_credentials"`
+	Amount                common.Decimal `json:"amount"`
+	Signature             hexutil.Bytes  `json:"signature"`
+	Index                 common.Decimal `json:"index"`
+}
+
+type jsonWithdrawalRequest struct {
+	SourceAddress   common.Address `json:"source_address"`
+	ValidatorPubkey hexutil.Bytes  `json:"validator_pubkey"`
+	Amount          common.Decimal `json:"amount"`
+}
+
+type jsonConsolidationRequest struct {
+	SourceAddress common.Address `json:"source_address"`
+	SourcePubkey  hexutil.Bytes  `json:"source_pubkey"`
+	TargetPubkey  hexutil.Bytes  `json:"target_pubkey"`
+}
+
+type jsonExecutionRequests struct {
+	Deposits       []jsonDepositRequest       `json:"deposits"`
+	Withdrawals    []jsonWithdrawalRequest    `json:"withdrawals"`
+	Consolidations []jsonConsolidationRequest `json:"consolidations"`
+}
+
+// Sizes of the SSZ encoding of the execution layer requests.
+const (
+	depositRequestSize       = params.BLSPubkeySize + 32 + 8 + params.BLSSignatureSize + 8
+	withdrawalRequestSize    = common.AddressLength + params.BLSPubkeySize + 8
+	consolidationRequestSize = common.AddressLength + 2*params.BLSPubkeySize
+)
+
+func encodeExecutionRequests(requests [][]byte) (*jsonExecutionRequests, error) {
+	enc := &jsonExecutionRequests{
+		Deposits:       []jsonDepositRequest{},
+		Withdrawals:    []jsonWithdrawalRequest{},
+		Consolidations: []jsonConsolidationRequest{},
+	}
+	for _, item := range requests {
+		if len(item) == 0 {
+			return nil, errors.New("empty request")
+		}
+		data := item[1:]
+		switch item[0] {
+		case ctypes.DepositRequestType:
+			if len(data)%depositRequestSize != 0 {
+				return nil, errors.New("invalid deposit requests length")
+			}
+			for ; len(data) > 0; data = data[depositRequestSize:] {
+				var (
+					r = data[:depositRequestSize]
+					d jsonDepositRequest
+				)
+				d.Pubkey, r = r[:params.BLSPubkeySize], r[params.BLSPubkeySize:]
+				d.WithdrawalCredentials, r = common.BytesToHash(r[:32]), r[32:]
+				d.Amount, r = common.Decimal(binary.LittleEndian.Uint64(r)), r[8:]
+				d.Signature, r = r[:params.BLSSignatureSize], r[params.BLSSignatureSize:]
+				d.Index = common.Decimal(binary.LittleEndian.Uint64(r))
+				enc.Deposits = append(enc.Deposits, d)
+			}
+		case ctypes.WithdrawalRequestType:
+			if len(data)%withdrawalRequestSize != 0 {
+				return nil, errors.New("invalid withdrawal requests length")
+			}
+			for ; len(data) > 0; data = data[withdrawalRequestSize:] {
+				r := data[:withdrawalRequestSize]
+				enc.Withdrawals = append(enc.Withdrawals, jsonWithdrawalRequest{
+					SourceAddress:   common.BytesToAddress(r[:common.AddressLength]),
+					ValidatorPubkey: r[common.AddressLength : common.AddressLength+params.BLSPubkeySize],
+					Amount:          common.Decimal(binary.LittleEndian.Uint64(r[common.AddressLength+params.BLSPubkeySize:])),
+				})
+			}
+		case ctypes.ConsolidationRequestType:
+			if len(data)%consolidationRequestSize != 0 {
+				return nil, errors.New("invalid consolidation requests length")
+			}
+			for ; len(data) > 0; data = data[consolidationRequestSize:] {
+				r := data[:consolidationRequestSize]
+				enc.Consolidations = append(enc.Consolidations, jsonConsolidationRequest{
+					SourceAddress: common.BytesToAddress(r[:common.AddressLength]),
+					SourcePubkey:  r[common.AddressLength : common.AddressLength+params.BLSPubkeySize],
+					TargetPubkey:  r[common.AddressLength+params.BLSPubkeySize:],
+				})
+			}
+		default:
+			return nil, fmt.Errorf("unknown request type %d", item[0])
+		}
+	}
+	return enc, nil
+}
+
+// decode converts the requests into the EIP-7685 encoding used by the engine
+// API: for every request type having any requests, the type byte followed by
+// the concatenated SSZ encodings of the requests.
+func (r *jsonExecutionRequests) decode() ([][]byte, error) {
+	requests := [][]byte{}
+	if len(r.Deposits) > 0 {
+		item := []byte{ctypes.DepositRequestType}
+		for _, d := range r.Deposits {
+			if len(d.Pubkey) != params.BLSPubkeySize || len(d.Signature) != params.BLSSignatureSize {
+				return nil, errors.New("invalid deposit request")
+			}
+			item = append(item, d.Pubkey...)
+			item = append(item, d.WithdrawalCredentials[:]...)
+			item = binary.LittleEndian.AppendUint64(item, uint64(d.Amount))
+			item = append(item, d.Signature...)
+			item = binary.LittleEndian.AppendUint64(item, uint64(d.Index))
+		}
+		requests = append(requests, item)
+	}
+	if len(r.Withdrawals) > 0 {
+		item := []byte{ctypes.WithdrawalRequestType}
+		for _, w := range r.Withdrawals {
+			if len(w.ValidatorPubkey) != params.BLSPubkeySize {
+				return nil, errors.New("invalid withdrawal request")
+			}
+			item = append(item, w.SourceAddress[:]...)
+			item = append(item, w.ValidatorPubkey...)
+			item = binary.LittleEndian.AppendUint64(item, uint64(w.Amount))
+		}
+		requests = append(requests, item)
+	}
+	if len(r.Consolidations) > 0 {
+		item := []byte{ctypes.ConsolidationRequestType}
+		for _, c := range r.Consolidations {
+			if len(c.SourcePubkey) != params.BLSPubkeySize || len(c.TargetPubkey) != params.BLSPubkeySize {
+				return nil, errors.New("invalid consolidation request")
+			}
+			item = append(item, c.SourceAddress[:]...)
+			item = append(item, c.SourcePubkey...)
+			item = append(item, c.TargetPubkey...)
+		}
+		requests = append(requests, item)
+	}
+	return requests, nil
+}
+
+type jsonBeaconBlockBody struct {
+	ExecutionPayload   *jsonExecutionPayload  `json:"execution_payload"`
+	BlobKzgCommitments []kzg4844.Commitment   `json:"blob_kzg_commitments,omitempty"`
+	ExecutionRequests  *jsonExecutionRequests `json:"execution_requests,omitempty"`
+}
+
+type jsonBeaconBlock struct {
+	Slot          common.Decimal      `json:"slot"`
+	ProposerIndex common.Decimal      `json:"proposer_index"`
+	ParentRoot    common.Hash         `json:"parent_root"`
+	StateRoot     common.Hash         `json:"state_root"`
+	Body          jsonBeaconBlockBody `json:"body"`
+}
+
+type jsonSignedBeaconBlock struct {
+	Message   jsonBeaconBlock `json:"message"`
+	Signature hexutil.Bytes   `json:"signature"`
+}
+
+func encodeBeaconBlock(b *BeaconBlock) (*jsonSignedBeaconBlock, error) {
+	var requests *jsonExecutionRequests
+	if b.Requests != nil {
+		var err error
+		if requests, err = encodeExecutionRequests(b.Requests); err != nil {
+			return nil, err
+		}
+	}
+	return &jsonSignedBeaconBlock{
+		Message: jsonBeaconBlock{
+			Slot:          common.Decimal(b.Slot),
+			ProposerIndex: common.Decimal(b.ProposerIndex),
+			ParentRoot:    b.ParentRoot,
+			StateRoot:     b.StateRoot,
+			Body: jsonBeaconBlockBody{
+				ExecutionPayload:   encodeExecutionPayload(b.Payload),
+				BlobKzgCommitments: b.BlobCommitments,
+				ExecutionRequests:  requests,
+			},
+		},
+		Signature: make(hexutil.Bytes, 96),
+	}, nil
+}
+
+func (b *jsonBeaconBlock) decode() (*BeaconBlock, error) {
+	if b.Body.ExecutionPayload == nil {
+		return nil, errors.New("missing execution payload")
+	}
+	payload, err := b.Body.ExecutionPayload.decode()
+	if err != nil {
+		return nil, err
+	}
+	block := &BeaconBlock{
+		Slot:            uint64(b.Slot),
+		ProposerIndex:   uint64(b.ProposerIndex),
+		ParentRoot:      b.ParentRoot,
+		StateRoot:       b.StateRoot,
+		Payload:         payload,
+		BlobCommitments: b.Body.BlobKzgCommitments,
+	}
+	if b.Body.ExecutionRequests != nil {
+		if block.Requests, err = b.Body.ExecutionRequests.decode(); err != nil {
+			return nil, err
+		}
+	}
+	// Blocks starting from Deneb are committing to blobs even if there's none.
+	if payload.BlobGasUsed != nil && block.BlobCommitments == nil {
+		block.BlobCommitments = []kzg4844.Commitment{}
+	}
+	return block, nil
+}
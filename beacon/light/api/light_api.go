@@ -0,0 +1,278 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package api implements a client of the light client endpoints of the beacon
+// node REST API.
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+var (
+	ErrNotFound = errors.New("404 Not Found")
+	ErrInternal = errors.New("500 Internal Server Error")
+)
+
+// requestTimeout is the time limit of a single API request.
+const requestTimeout = 10 * time.Second
+
+// BeaconBlock contains the fields of a beacon block which are required for
+// importing the execution payload of the block.
+type BeaconBlock struct {
+	Slot            uint64
+	ProposerIndex   uint64
+	ParentRoot      common.Hash
+	StateRoot       common.Hash
+	Payload         *engine.ExecutableData
+	BlobCommitments []kzg4844.Commitment // Nil before Deneb
+	Requests        [][]byte             // EIP-7685 execution requests, nil before Electra
+}
+
+// BlobHashes returns the versioned hashes of the blobs committed to by the block,
+// or nil for blocks before Deneb.
+func (b *BeaconBlock) BlobHashes() []common.Hash {
+	if b.BlobCommitments == nil {
+		return nil
+	}
+	var (
+		hasher = sha256.New()
+		hashes = make([]common.Hash, len(b.BlobCommitments))
+	)
+	for i := range b.BlobCommitments {
+		hashes[i] = kzg4844.CalcBlobHashV1(hasher, &b.BlobCommitments[i])
+	}
+	return hashes
+}
+
+// ExecutionBlock converts the execution payload of the beacon block into an
+// execution block, verifying the block hash of the payload.
+func (b *BeaconBlock) ExecutionBlock() (*ctypes.Block, error) {
+	var beaconRoot *common.Hash
+	if b.BlobCommitments != nil {
+		beaconRoot = &b.ParentRoot
+	}
+	return engine.ExecutableDataToBlock(*b.Payload, b.BlobHashes(), beaconRoot, b.Requests)
+}
+
+// BeaconLightApi requests light client information from a beacon node REST API.
+type BeaconLightApi struct {
+	url           string
+	client        *http.Client
+	customHeaders map[string]string
+}
+
+// NewBeaconLightApi creates a new REST API client for the given beacon node url.
+func NewBeaconLightApi(url string, customHeaders map[string]string) *BeaconLightApi {
+	return &BeaconLightApi{
+		url: url,
+		client: &http.Client{
+			Timeout: requestTimeout,
+		},
+		customHeaders: customHeaders,
+	}
+}
+
+func (api *BeaconLightApi) httpGet(path string) ([]byte, error) {
+	return api.httpGetf(path, nil)
+}
+
+func (api *BeaconLightApi) httpGetf(path string, query url.Values) ([]byte, error) {
+	uri, err := url.JoinPath(api.url, path)
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		uri += "?" + query.Encode()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range api.customHeaders {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := api.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case 200:
+		return io.ReadAll(resp.Body)
+	case 404:
+		return nil, ErrNotFound
+	case 500:
+		return nil, ErrInternal
+	default:
+		return nil, fmt.Errorf("unexpected error from API endpoint \"%s\": status code %d", path, resp.StatusCode)
+	}
+}
+
+// GetBestUpdatesAndCommittees fetches and validates LightClientUpdate for given
+// period and full serialized committee for the next period (committee root hash
+// equals update.NextSyncCommitteeRoot).
+// Note that the results are validated but the update signature should be verified
+// by the caller as its validity depends on the update chain.
+func (api *BeaconLightApi) GetBestUpdatesAndCommittees(firstPeriod, count uint64) ([]*types.LightClientUpdate, []*types.SerializedSyncCommittee, error) {
+	resp, err := api.httpGetf("/eth/v1/beacon/light_client/updates", url.Values{
+		"start_period": {strconv.FormatUint(firstPeriod, 10)},
+		"count":        {strconv.FormatUint(count, 10)},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var data []jsonVersioned[jsonLightClientUpdate]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, nil, err
+	}
+	if len(data) > int(count) {
+		return nil, nil, errors.New("invalid number of light client updates")
+	}
+	var (
+		updates    = make([]*types.LightClientUpdate, len(data))
+		committees = make([]*types.SerializedSyncCommittee, len(data))
+	)
+	for i, d := range data {
+		update, committee, err := d.Data.decode()
+		if err != nil {
+			return nil, nil, err
+		}
+		if update.AttestedHeader.Header.SyncPeriod() != firstPeriod+uint64(i) {
+			return nil, nil, errors.New("wrong committee update header period")
+		}
+		if err := update.Validate(); err != nil {
+			return nil, nil, err
+		}
+		if committee.Root() != update.NextSyncCommitteeRoot {
+			return nil, nil, errors.New("wrong sync committee root")
+		}
+		updates[i], committees[i] = update, committee
+	}
+	return updates, committees, nil
+}
+
+// GetOptimisticUpdate fetches the latest available optimistic update.
+// Note that the signature should be verified by the caller as its validity
+// depends on the update chain.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientoptimisticupdate
+func (api *BeaconLightApi) GetOptimisticUpdate() (types.OptimisticUpdate, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/optimistic_update")
+	if err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	var data jsonVersioned[jsonOptimisticUpdate]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	update, err := data.Data.decode()
+	if err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	if err := update.Validate(); err != nil {
+		return types.OptimisticUpdate{}, err
+	}
+	return update, nil
+}
+
+// GetFinalityUpdate fetches the latest available finality update.
+// Note that the signature should be verified by the caller as its validity
+// depends on the update chain.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientfinalityupdate
+func (api *BeaconLightApi) GetFinalityUpdate() (types.FinalityUpdate, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/finality_update")
+	if err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	var data jsonVersioned[jsonFinalityUpdate]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	update, err := data.Data.decode()
+	if err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	if err := update.Validate(); err != nil {
+		return types.FinalityUpdate{}, err
+	}
+	return update, nil
+}
+
+// GetCheckpointData fetches and validates bootstrap data belonging to the given
+// checkpoint.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/light-client/sync-protocol.md#lightclientbootstrap
+func (api *BeaconLightApi) GetCheckpointData(checkpointHash common.Hash) (*types.BootstrapData, error) {
+	resp, err := api.httpGet("/eth/v1/beacon/light_client/bootstrap/" + checkpointHash.String())
+	if err != nil {
+		return nil, err
+	}
+	var data jsonVersioned[jsonBootstrapData]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	bootstrap, err := data.Data.decode()
+	if err != nil {
+		return nil, err
+	}
+	if bootstrap.Header.Hash() != checkpointHash {
+		return nil, errors.New("invalid checkpoint block header")
+	}
+	if err := bootstrap.Validate(); err != nil {
+		return nil, err
+	}
+	return bootstrap, nil
+}
+
+// GetBeaconBlock fetches the beacon block with the given block root, including
+// the execution payload. Note that the block is not validated, the block hash of
+// the payload should be checked against a verified execution header.
+func (api *BeaconLightApi) GetBeaconBlock(blockRoot common.Hash) (*BeaconBlock, error) {
+	resp, err := api.httpGet("/eth/v2/beacon/blocks/" + blockRoot.String())
+	if err != nil {
+		return nil, err
+	}
+	var data jsonVersioned[jsonSignedBeaconBlock]
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, err
+	}
+	return data.Data.Message.decode()
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TestServer is a mock beacon node REST API server, serving the light client
+// endpoints from the data supplied by the test.
+type TestServer struct {
+	*httptest.Server
+
+	lock       sync.Mutex
+	bootstraps map[common.Hash]*types.BootstrapData
+	updates    map[uint64]*jsonLightClientUpdate
+	optimistic *types.OptimisticUpdate
+	finality   *types.FinalityUpdate
+	blocks     map[common.Hash]*BeaconBlock
+}
+
+// NewTestServer starts a new mock beacon API server. The server should be
+// closed by the caller after use.
+func NewTestServer() *TestServer {
+	s := &TestServer{
+		bootstraps: make(map[common.Hash]*types.BootstrapData),
+		updates:    make(map[uint64]*jsonLightClientUpdate),
+		blocks:     make(map[common.Hash]*BeaconBlock),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// AddBootstrap adds the bootstrap data served for the checkpoint of its header.
+func (s *TestServer) AddBootstrap(bootstrap *types.BootstrapData) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.bootstraps[bootstrap.Header.Hash()] = bootstrap
+}
+
+// AddUpdate adds the best update served for the period of its attested header,
+// along with the sync committee of the next period.
+func (s *TestServer) AddUpdate(update *types.LightClientUpdate, committee *types.SerializedSyncCommittee) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.updates[update.AttestedHeader.Header.SyncPeriod()] = encodeLightClientUpdate(update, committee)
+}
+
+// SetOptimisticUpdate sets the latest optimistic update.
+func (s *TestServer) SetOptimisticUpdate(update types.OptimisticUpdate) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.optimistic = &update
+}
+
+// SetFinalityUpdate sets the latest finality update.
+func (s *TestServer) SetFinalityUpdate(update types.FinalityUpdate) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.finality = &update
+}
+
+// AddBlock adds a beacon block served for the given block root.
+func (s *TestServer) AddBlock(root common.Hash, block *BeaconBlock) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.blocks[root] = block
+}
+
+// serve handles the API requests.
+func (s *TestServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var (
+		path = r.URL.Path
+		resp any
+	)
+	switch {
+	case path == "/eth/v1/beacon/light_client/updates":
+		start, err1 := strconv.ParseUint(r.URL.Query().Get("start_period"), 10, 64)
+		count, err2 := strconv.ParseUint(r.URL.Query().Get("count"), 10, 64)
+		if err1 != nil || err2 != nil {
+			http.Error(w, "invalid period range", http.StatusBadRequest)
+			return
+		}
+		updates := []jsonVersioned[*jsonLightClientUpdate]{}
+		for period := start; period < start+count; period++ {
+			update, ok := s.updates[period]
+			if !ok {
+				break
+			}
+			updates = append(updates, jsonVersioned[*jsonLightClientUpdate]{Version: "deneb", Data: update})
+		}
+		resp = updates
+
+	case path == "/eth/v1/beacon/light_client/optimistic_update":
+		if s.optimistic == nil {
+			http.NotFound(w, r)
+			return
+		}
+		resp = jsonVersioned[*jsonOptimisticUpdate]{Version: "deneb", Data: encodeOptimisticUpdate(s.optimistic)}
+
+	case path == "/eth/v1/beacon/light_client/finality_update":
+		if s.finality == nil {
+			http.NotFound(w, r)
+			return
+		}
+		resp = jsonVersioned[*jsonFinalityUpdate]{Version: "deneb", Data: encodeFinalityUpdate(s.finality)}
+
+	case strings.HasPrefix(path, "/eth/v1/beacon/light_client/bootstrap/"):
+		bootstrap, ok := s.bootstraps[common.HexToHash(strings.TrimPrefix(path, "/eth/v1/beacon/light_client/bootstrap/"))]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		resp = jsonVersioned[*jsonBootstrapData]{Version: "deneb", Data: encodeBootstrapData(bootstrap)}
+
+	case strings.HasPrefix(path, "/eth/v2/beacon/blocks/"):
+		block, ok := s.blocks[common.HexToHash(strings.TrimPrefix(path, "/eth/v2/beacon/blocks/"))]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		enc, err := encodeBeaconBlock(block)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		version := "deneb"
+		if block.Requests != nil {
+			version = "electra"
+		}
+		resp = jsonVersioned[*jsonSignedBeaconBlock]{Version: version, Data: enc}
+
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
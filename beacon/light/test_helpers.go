@@ -20,13 +20,22 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	mrand "math/rand"
+	"time"
 
 	"github.com/ethereum/go-ethereum/beacon/merkle"
 	"github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/beacon/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/ethdb"
 )
 
+// NewTestCommitteeChain creates a committee chain verifying the fake signatures
+// generated by the test helpers instead of BLS signatures.
+func NewTestCommitteeChain(db ethdb.KeyValueStore, config *types.ChainConfig, signerThreshold int, enforceTime bool) *CommitteeChain {
+	return newCommitteeChain(db, config, signerThreshold, enforceTime, dummyVerifier{}, &mclock.System{}, func() int64 { return time.Now().UnixNano() })
+}
+
 func GenerateTestCommittee() *types.SerializedSyncCommittee {
 	s := new(types.SerializedSyncCommittee)
 	rand.Read(s[:32])
@@ -19,6 +19,7 @@ package merkle
 
 import (
 	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"reflect"
 
@@ -39,6 +40,11 @@ func (m *Value) UnmarshalJSON(input []byte) error {
 	return hexutil.UnmarshalFixedJSON(valueT, input, m[:])
 }
 
+// MarshalJSON encodes a merkle value in hex syntax.
+func (m Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hexutil.Bytes(m[:]))
+}
+
 // VerifyProof verifies a Merkle proof branch for a single value in a
 // binary Merkle tree (index is a generalized tree index).
 func VerifyProof(root common.Hash, index uint64, branch Values, value Value) error {
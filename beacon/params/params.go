@@ -20,6 +20,8 @@ const (
 	EpochLength      = 32
 	SyncPeriodLength = 8192
 
+	SecondsPerSlot = 12 // Slot time of the mainnet and the public testnets
+
 	BLSSignatureSize = 96
 	BLSPubkeySize    = 48
 
@@ -41,4 +43,6 @@ const (
 	StateIndexNextSyncCommittee = 55
 	StateIndexExecPayload       = 56
 	StateIndexExecHead          = 908
+
+	BodyIndexExecPayload = 25
 )
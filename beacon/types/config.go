@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"gopkg.in/yaml.v3"
@@ -107,9 +108,18 @@ func (f Forks) Less(i, j int) bool { return f[i].Epoch < f[j].Epoch }
 type ChainConfig struct {
 	GenesisTime           uint64      // Unix timestamp of slot 0
 	GenesisValidatorsRoot common.Hash // Root hash of the genesis validator set, used for signature domain calculation
+	SecondsPerSlot        uint64      // Slot time in seconds, params.SecondsPerSlot if zero
 	Forks                 Forks
 }
 
+// SlotTime returns the slot time of the chain in seconds.
+func (c *ChainConfig) SlotTime() uint64 {
+	if c.SecondsPerSlot == 0 {
+		return params.SecondsPerSlot
+	}
+	return c.SecondsPerSlot
+}
+
 // AddFork adds a new item to the list of forks.
 func (c *ChainConfig) AddFork(name string, epoch uint64, version []byte) *ChainConfig {
 	fork := &Fork{
@@ -126,7 +136,7 @@ func (c *ChainConfig) AddFork(name string, epoch uint64, version []byte) *ChainC
 }
 
 // LoadForks parses the beacon chain configuration file (config.yaml) and extracts
-// the list of forks.
+// the list of forks and the slot time.
 func (c *ChainConfig) LoadForks(path string) error {
 	file, err := os.ReadFile(path)
 	if err != nil {
@@ -142,6 +152,13 @@ func (c *ChainConfig) LoadForks(path string) error {
 	)
 	epochs["GENESIS"] = 0
 
+	if value, ok := config["SECONDS_PER_SLOT"]; ok {
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || v == 0 {
+			return fmt.Errorf("invalid slot time %q in beacon chain config file", value)
+		}
+		c.SecondsPerSlot = v
+	}
 	for key, value := range config {
 		if strings.HasSuffix(key, "_FORK_VERSION") {
 			name := key[:len(key)-len("_FORK_VERSION")]
@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ExecutionHeader defines an execution payload header, the summary of the
+// execution block included in a beacon block.
+//
+// See data structure definition here:
+// https://github.com/ethereum/consensus-specs/blob/dev/specs/deneb/beacon-chain.md#executionpayloadheader
+type ExecutionHeader struct {
+	ParentHash       common.Hash
+	FeeRecipient     common.Address
+	StateRoot        common.Hash
+	ReceiptsRoot     common.Hash
+	LogsBloom        [256]byte
+	PrevRandao       common.Hash
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte
+	BaseFeePerGas    *big.Int
+	BlockHash        common.Hash
+	TransactionsRoot common.Hash
+	WithdrawalsRoot  common.Hash
+
+	// Fields introduced by Deneb, nil for Capella headers
+	BlobGasUsed   *uint64
+	ExcessBlobGas *uint64
+}
+
+// Root calculates the SSZ hash tree root of the header. The fields introduced by
+// Deneb are included in the tree only if they are present.
+//
+// TODO(zsfelfoldi): Remove this when an SSZ encoder lands.
+func (h *ExecutionHeader) Root() common.Hash {
+	var (
+		leaves []merkle.Value
+		value  merkle.Value
+	)
+	addUint64 := func(v uint64) {
+		var leaf merkle.Value
+		binary.LittleEndian.PutUint64(leaf[:8], v)
+		leaves = append(leaves, leaf)
+	}
+	leaves = append(leaves, merkle.Value(h.ParentHash))
+	copy(value[:], h.FeeRecipient[:])
+	leaves = append(leaves, value)
+	leaves = append(leaves, merkle.Value(h.StateRoot), merkle.Value(h.ReceiptsRoot))
+
+	// The logs bloom is a fixed size byte vector spanning 8 chunks.
+	bloom := make([]merkle.Value, len(h.LogsBloom)/32)
+	for i := range bloom {
+		copy(bloom[i][:], h.LogsBloom[i*32:])
+	}
+	leaves = append(leaves, merkleRoot(bloom), merkle.Value(h.PrevRandao))
+	addUint64(h.BlockNumber)
+	addUint64(h.GasLimit)
+	addUint64(h.GasUsed)
+	addUint64(h.Timestamp)
+
+	// The extra data is a byte list limited to a single chunk, its length is
+	// mixed into the root of the chunk.
+	var extra, length merkle.Value
+	copy(extra[:], h.ExtraData)
+	binary.LittleEndian.PutUint64(length[:8], uint64(len(h.ExtraData)))
+	leaves = append(leaves, merkleRoot([]merkle.Value{extra, length}))
+
+	// The base fee is a little endian uint256.
+	var baseFee merkle.Value
+	if h.BaseFeePerGas != nil {
+		h.BaseFeePerGas.FillBytes(baseFee[:])
+		for i := 0; i < 16; i++ {
+			baseFee[i], baseFee[31-i] = baseFee[31-i], baseFee[i]
+		}
+	}
+	leaves = append(leaves, baseFee)
+	leaves = append(leaves, merkle.Value(h.BlockHash), merkle.Value(h.TransactionsRoot), merkle.Value(h.WithdrawalsRoot))
+	if h.BlobGasUsed != nil && h.ExcessBlobGas != nil {
+		addUint64(*h.BlobGasUsed)
+		addUint64(*h.ExcessBlobGas)
+	}
+	return common.Hash(merkleRoot(leaves))
+}
+
+// merkleRoot calculates the root of a binary merkle tree with the given leaves,
+// padded with zero values to the next power of two.
+func merkleRoot(leaves []merkle.Value) merkle.Value {
+	size := 1
+	for size < len(leaves) {
+		size *= 2
+	}
+	nodes := make([]merkle.Value, size)
+	copy(nodes, leaves)
+
+	hasher := sha256.New()
+	for ; size > 1; size /= 2 {
+		for i := 0; i < size/2; i++ {
+			hasher.Reset()
+			hasher.Write(nodes[i*2][:])
+			hasher.Write(nodes[i*2+1][:])
+			hasher.Sum(nodes[i][:0])
+		}
+	}
+	return nodes[0]
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/urfave/cli/v2"
 )
 
@@ -38,6 +39,9 @@ var (
 			discv5CrawlCommand,
 			discv5TestCommand,
 			discv5ListenCommand,
+			discv5TopicRegisterCommand,
+			discv5TopicQueryCommand,
+			discv5TopicSearchCommand,
 		},
 	}
 	discv5PingCommand = &cli.Command{
@@ -77,6 +81,29 @@ var (
 		Action: discv5Listen,
 		Flags:  discoveryNodeFlags,
 	}
+	discv5TopicRegisterCommand = &cli.Command{
+		Name:      "topic-register",
+		Usage:     "Runs a node which advertises itself under a topic",
+		ArgsUsage: "<topic>",
+		Action:    discv5TopicRegister,
+		Flags:     discoveryNodeFlags,
+	}
+	discv5TopicQueryCommand = &cli.Command{
+		Name:      "topic-query",
+		Usage:     "Asks a node for the nodes advertising a topic",
+		ArgsUsage: "<node> <topic>",
+		Action:    discv5TopicQuery,
+		Flags:     discoveryNodeFlags,
+	}
+	discv5TopicSearchCommand = &cli.Command{
+		Name:      "topic-search",
+		Usage:     "Searches the DHT for nodes advertising a topic",
+		ArgsUsage: "<topic>",
+		Action:    discv5TopicSearch,
+		Flags: flags.Merge(discoveryNodeFlags, []cli.Flag{
+			crawlTimeoutFlag,
+		}),
+	}
 )
 
 func discv5Ping(ctx *cli.Context) error {
@@ -138,6 +165,58 @@ func discv5Listen(ctx *cli.Context) error {
 	select {}
 }
 
+func discv5TopicRegister(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need topic as argument")
+	}
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	disc.RegisterTopic(discover.NewTopic(ctx.Args().First()))
+	fmt.Println(disc.Self())
+	select {}
+}
+
+func discv5TopicQuery(ctx *cli.Context) error {
+	if ctx.NArg() < 2 {
+		return errors.New("need node and topic as arguments")
+	}
+	n := getNodeArg(ctx)
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	nodes, err := disc.TopicQuery(n, discover.NewTopic(ctx.Args().Get(1)))
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		fmt.Println(n)
+	}
+	return nil
+}
+
+// discv5TopicSearch prints the nodes advertising a topic as they are found.
+func discv5TopicSearch(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return errors.New("need topic as argument")
+	}
+	disc, _ := startV5(ctx)
+	defer disc.Close()
+
+	it := disc.TopicSearch(discover.NewTopic(ctx.Args().First()))
+	timer := time.AfterFunc(ctx.Duration(crawlTimeoutFlag.Name), it.Close)
+	defer timer.Stop()
+
+	seen := make(map[enode.ID]bool)
+	for it.Next() {
+		if n := it.Node(); !seen[n.ID()] {
+			seen[n.ID()] = true
+			fmt.Println(n)
+		}
+	}
+	return nil
+}
+
 // startV5 starts an ephemeral discovery v5 node.
 func startV5(ctx *cli.Context) (*discover.UDPv5, discover.Config) {
 	ln, config := makeDiscoveryConfig(ctx)
@@ -18,6 +18,7 @@ package ethtest
 
 import (
 	"crypto/rand"
+	"fmt"
 	"math/big"
 	"reflect"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/utesting"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/holiman/uint256"
 )
 
@@ -65,6 +67,7 @@ func (s *Suite) EthTests() []utesting.Test {
 	return []utesting.Test{
 		// status
 		{Name: "Status", Fn: s.TestStatus},
+		{Name: "Status69", Fn: s.TestStatus69},
 		// get block headers
 		{Name: "GetBlockHeaders", Fn: s.TestGetBlockHeaders},
 		{Name: "SimultaneousRequests", Fn: s.TestSimultaneousRequests},
@@ -72,6 +75,12 @@ func (s *Suite) EthTests() []utesting.Test {
 		{Name: "ZeroRequestID", Fn: s.TestZeroRequestID},
 		// get block bodies
 		{Name: "GetBlockBodies", Fn: s.TestGetBlockBodies},
+		// get receipts
+		{Name: "GetReceipts", Fn: s.TestGetReceipts},
+		{Name: "GetReceipts69", Fn: s.TestGetReceipts69},
+		// block range announcements
+		{Name: "BlockRangeUpdate", Fn: s.TestBlockRangeUpdate},
+		{Name: "InvalidBlockRangeUpdate", Fn: s.TestInvalidBlockRangeUpdate},
 		// // malicious handshakes + status
 		{Name: "MaliciousHandshake", Fn: s.TestMaliciousHandshake},
 		{Name: "MaliciousStatus", Fn: s.TestMaliciousStatus},
@@ -107,6 +116,20 @@ func (s *Suite) TestStatus(t *utesting.T) {
 	}
 }
 
+func (s *Suite) TestStatus69(t *utesting.T) {
+	t.Log(`This test performs an eth/69 protocol handshake, checking the block range
+advertised by the node.`)
+
+	conn, err := s.dialEth69()
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+}
+
 // headersMatch returns whether the received headers match the given request
 func headersMatch(expected []*types.Header, headers []*types.Header) bool {
 	return reflect.DeepEqual(expected, headers)
@@ -371,6 +394,165 @@ func (s *Suite) TestGetBlockBodies(t *utesting.T) {
 	}
 }
 
+func (s *Suite) TestGetReceipts(t *utesting.T) {
+	t.Log(`This test sends GetReceipts requests to the node for known blocks in the test chain,
+and checks the returned receipts against the receipt roots of the blocks.`)
+
+	conn, err := s.dial()
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	req, blocks := s.receiptsRequest(66)
+	if err := conn.Write(ethProto, eth.GetReceiptsMsg, req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	resp := new(eth.ReceiptsPacket)
+	if err := conn.ReadMsg(ethProto, eth.ReceiptsMsg, &resp); err != nil {
+		t.Fatalf("error reading receipts msg: %v", err)
+	}
+	if got, want := resp.RequestId, req.RequestId; got != want {
+		t.Fatalf("unexpected request id in response: got %d, want %d", got, want)
+	}
+	if err := checkReceipts(blocks, resp.ReceiptsResponse); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (s *Suite) TestGetReceipts69(t *utesting.T) {
+	t.Log(`This test sends GetReceipts requests to the node over eth/69, and checks that the
+bloomless receipts returned match the receipt roots of the blocks.`)
+
+	conn, err := s.dialEth69()
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	req, blocks := s.receiptsRequest(69)
+	if err := conn.Write(ethProto, eth.GetReceiptsMsg, req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	resp := new(eth.ReceiptsPacket69)
+	if err := conn.ReadMsg(ethProto, eth.ReceiptsMsg, &resp); err != nil {
+		t.Fatalf("error reading receipts msg: %v", err)
+	}
+	if got, want := resp.RequestId, req.RequestId; got != want {
+		t.Fatalf("unexpected request id in response: got %d, want %d", got, want)
+	}
+	receipts, err := resp.ReceiptsResponse69.Unpack()
+	if err != nil {
+		t.Fatalf("invalid receipts in response: %v", err)
+	}
+	if err := checkReceipts(blocks, receipts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receiptsRequest creates a receipts query for a few blocks of the test chain,
+// returning the requested blocks along with it.
+func (s *Suite) receiptsRequest(id uint64) (*eth.GetReceiptsPacket, []*types.Block) {
+	blocks := []*types.Block{s.chain.blocks[54], s.chain.blocks[75], s.chain.Head()}
+
+	req := &eth.GetReceiptsPacket{RequestId: id}
+	for _, block := range blocks {
+		req.GetReceiptsRequest = append(req.GetReceiptsRequest, block.Hash())
+	}
+	return req, blocks
+}
+
+// checkReceipts verifies the receipts returned for a query against the receipt
+// roots of the requested blocks.
+func checkReceipts(blocks []*types.Block, receipts eth.ReceiptsResponse) error {
+	if len(receipts) != len(blocks) {
+		return fmt.Errorf("wrong receipts in response: expected %d blocks, got %d", len(blocks), len(receipts))
+	}
+	for i, block := range blocks {
+		if root := types.DeriveSha(types.Receipts(receipts[i]), trie.NewStackTrie(nil)); root != block.ReceiptHash() {
+			return fmt.Errorf("receipts mismatch for block %d: root %x, want %x", block.NumberU64(), root, block.ReceiptHash())
+		}
+	}
+	return nil
+}
+
+func (s *Suite) TestBlockRangeUpdate(t *utesting.T) {
+	t.Log(`This test announces a block range update to the node over eth/69, and checks that
+the node keeps serving requests afterwards.`)
+
+	conn, err := s.dialEth69()
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	head := s.chain.Head()
+	update := &eth.BlockRangeUpdatePacket{
+		EarliestBlock:   1,
+		LatestBlock:     head.NumberU64(),
+		LatestBlockHash: head.Hash(),
+	}
+	if err := conn.Write(ethProto, eth.BlockRangeUpdateMsg, update); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	req := &eth.GetBlockHeadersPacket{
+		RequestId: 77,
+		GetBlockHeadersRequest: &eth.GetBlockHeadersRequest{
+			Origin: eth.HashOrNumber{Hash: head.Hash()},
+			Amount: 1,
+		},
+	}
+	if err := conn.Write(ethProto, eth.GetBlockHeadersMsg, req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	headers := new(eth.BlockHeadersPacket)
+	if err := conn.ReadMsg(ethProto, eth.BlockHeadersMsg, &headers); err != nil {
+		t.Fatalf("error reading msg: %v", err)
+	}
+	if got, want := headers.RequestId, req.RequestId; got != want {
+		t.Fatalf("unexpected request id")
+	}
+}
+
+func (s *Suite) TestInvalidBlockRangeUpdate(t *utesting.T) {
+	t.Log(`This test announces a malformed block range update to the node over eth/69 and
+expects a disconnect.`)
+
+	conn, err := s.dialEth69()
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	head := s.chain.Head()
+	update := &eth.BlockRangeUpdatePacket{
+		EarliestBlock:   head.NumberU64() + 1,
+		LatestBlock:     head.NumberU64(),
+		LatestBlockHash: head.Hash(),
+	}
+	if err := conn.Write(ethProto, eth.BlockRangeUpdateMsg, update); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	// Wait for disconnect, skipping any messages sent before it.
+	for {
+		code, _, err := conn.Read()
+		if err != nil {
+			t.Fatalf("error reading from connection: %v", err)
+		}
+		if code == discMsg {
+			return
+		}
+	}
+}
+
 // randBuf makes a random buffer size kilobytes large.
 func randBuf(size int) []byte {
 	buf := make([]byte, size*1024)
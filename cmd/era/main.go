@@ -21,13 +21,20 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"os/signal"
 	"path"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
@@ -58,6 +65,41 @@ var (
 		Name:  "txs",
 		Usage: "print full transaction values",
 	}
+	datadirFlag = &cli.StringFlag{
+		Name:     "datadir",
+		Usage:    "data directory of the node importing the history",
+		Required: true,
+	}
+	ancientFlag = &cli.StringFlag{
+		Name:  "datadir.ancient",
+		Usage: "root directory for ancient data (default = inside chaindata)",
+	}
+	dbEngineFlag = &cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "backing database implementation to use ('pebble' or 'leveldb')",
+	}
+	workersFlag = &cli.IntFlag{
+		Name:  "workers",
+		Usage: "number of era1 files read and verified concurrently",
+		Value: runtime.NumCPU(),
+	}
+	refFlag = &cli.StringFlag{
+		Name:     "ref",
+		Usage:    "data directory of the reference node to compare the history with",
+		Required: true,
+	}
+	refAncientFlag = &cli.StringFlag{
+		Name:  "ref.ancient",
+		Usage: "root directory for ancient data of the reference node (default = inside chaindata)",
+	}
+	fromFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "first block to compare",
+	}
+	toFlag = &cli.Uint64Flag{
+		Name:  "to",
+		Usage: "last block to compare (default = last frozen block)",
+	}
 )
 
 var (
@@ -82,6 +124,37 @@ var (
 		Usage:     "verifies each era1 against expected accumulator root",
 		Action:    verify,
 	}
+	importCommand = &cli.Command{
+		Name:      "import",
+		ArgsUsage: "[<expected>]",
+		Usage:     "imports the era1 files straight into the ancient store of a database",
+		Description: `
+The import command writes the headers, bodies and receipts of the era1 files into
+the freezer of the database, verifying the accumulator root of each file without
+processing the blocks. If a file of expected accumulator roots is given, each era1
+file is also checked against it. An interrupted import is resumed from the last
+frozen block.`,
+		Action: importHistory,
+		Flags: []cli.Flag{
+			datadirFlag,
+			ancientFlag,
+			dbEngineFlag,
+			workersFlag,
+		},
+	}
+	compareCommand = &cli.Command{
+		Name:   "compare",
+		Usage:  "compares the imported history against a reference database",
+		Action: compareHistory,
+		Flags: []cli.Flag{
+			datadirFlag,
+			ancientFlag,
+			refFlag,
+			refAncientFlag,
+			fromFlag,
+			toFlag,
+		},
+	}
 )
 
 func init() {
@@ -89,6 +162,8 @@ func init() {
 		blockCommand,
 		infoCommand,
 		verifyCommand,
+		importCommand,
+		compareCommand,
 	}
 	app.Flags = []cli.Flag{
 		dirFlag,
@@ -322,3 +397,101 @@ func readHashes(f string) ([]common.Hash, error) {
 	}
 	return r, nil
 }
+
+// openDatabase opens the chain database in the given data directory, along with
+// its ancient store.
+func openDatabase(datadir, ancient, engine string, readonly bool) (ethdb.Database, error) {
+	chaindata := filepath.Join(datadir, "geth", "chaindata")
+	if ancient == "" {
+		ancient = filepath.Join(chaindata, "ancient")
+	} else if !filepath.IsAbs(ancient) {
+		ancient = filepath.Join(chaindata, ancient)
+	}
+	return rawdb.Open(rawdb.OpenOptions{
+		Type:              engine,
+		Directory:         chaindata,
+		AncientsDirectory: ancient,
+		Cache:             512,
+		Handles:           512,
+		ReadOnly:          readonly,
+	})
+}
+
+// importHistory imports the era1 files into the ancient store of a database.
+func importHistory(ctx *cli.Context) error {
+	var roots []common.Hash
+	if ctx.Args().Len() > 0 {
+		var err error
+		if roots, err = readHashes(ctx.Args().First()); err != nil {
+			return fmt.Errorf("unable to read expected roots file: %w", err)
+		}
+	}
+	db, err := openDatabase(ctx.String(datadirFlag.Name), ctx.String(ancientFlag.Name), ctx.String(dbEngineFlag.Name), false)
+	if err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer db.Close()
+
+	// Interrupt the import on a signal, it can be resumed later.
+	var (
+		stop   = make(chan struct{})
+		sigc   = make(chan os.Signal, 1)
+		start  = time.Now()
+		before uint64
+	)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		if _, ok := <-sigc; ok {
+			fmt.Println("Interrupted, stopping the import")
+			close(stop)
+		}
+	}()
+	if before, err = db.Ancients(); err != nil {
+		return err
+	}
+	if err := utils.ImportHistoryFreezer(db, ctx.String(dirFlag.Name), ctx.String(networkFlag.Name), ctx.Int(workersFlag.Name), roots, stop); err != nil {
+		return err
+	}
+	after, _ := db.Ancients()
+	fmt.Printf("Import done, imported=%d,\t frozen=%d,\t elapsed=%s\n", after-before, after, common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// compareHistory cross-checks the history stored in a database against the one
+// of a reference database.
+func compareHistory(ctx *cli.Context) error {
+	db, err := openDatabase(ctx.String(datadirFlag.Name), ctx.String(ancientFlag.Name), "", true)
+	if err != nil {
+		return fmt.Errorf("error opening database: %w", err)
+	}
+	defer db.Close()
+	ref, err := openDatabase(ctx.String(refFlag.Name), ctx.String(refAncientFlag.Name), "", true)
+	if err != nil {
+		return fmt.Errorf("error opening reference database: %w", err)
+	}
+	defer ref.Close()
+
+	var (
+		from = ctx.Uint64(fromFlag.Name)
+		to   = ctx.Uint64(toFlag.Name)
+	)
+	if !ctx.IsSet(toFlag.Name) {
+		frozen, err := db.Ancients()
+		if err != nil {
+			return err
+		}
+		if frozen == 0 {
+			return fmt.Errorf("no history in the database")
+		}
+		to = frozen - 1
+	}
+	if from > to {
+		return fmt.Errorf("invalid range: from %d, to %d", from, to)
+	}
+	if err := utils.CompareHistory(db, ref, from, to); err != nil {
+		return err
+	}
+	fmt.Printf("History matches, from=%d,\t to=%d\n", from, to)
+	return nil
+}
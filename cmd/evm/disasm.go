@@ -17,12 +17,14 @@
 package main
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/urfave/cli/v2"
 )
 
@@ -51,5 +53,33 @@ func disasmCmd(ctx *cli.Context) error {
 
 	code := strings.TrimSpace(in)
 	fmt.Printf("%v\n", code)
+	if bin, err := hex.DecodeString(code); err == nil && vm.HasEOFByte(bin) {
+		return disasmEOF(bin)
+	}
 	return asm.PrintDisassembled(code)
 }
+
+// disasmEOF prints the layout of the given EOF container, followed by the
+// disassembly of each of its code sections.
+func disasmEOF(code []byte) error {
+	var container vm.Container
+	if err := container.UnmarshalBinary(code); err != nil {
+		return err
+	}
+	fmt.Println(container.String())
+	for i, section := range container.CodeSections() {
+		fmt.Printf("\nCode section %d\n", i)
+		it := asm.NewEOFInstructionIterator(section)
+		for it.Next() {
+			if len(it.Arg()) > 0 {
+				fmt.Printf("%05x: %v %#x\n", it.PC(), it.Op(), it.Arg())
+			} else {
+				fmt.Printf("%05x: %v\n", it.PC(), it.Op())
+			}
+		}
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var engineTestCommand = &cli.Command{
+	Action:    engineTestCmd,
+	Name:      "enginetest",
+	Usage:     "Executes the given engine API blockchain tests through an in-memory node",
+	ArgsUsage: "<file or directory>",
+	Flags:     []cli.Flag{RunFlag, WorkersFlag},
+}
+
+func engineTestCmd(ctx *cli.Context) error {
+	if len(ctx.Args().First()) == 0 {
+		return errors.New("path-to-test argument required")
+	}
+	re, err := regexp.Compile(ctx.String(RunFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid regex -%s: %v", RunFlag.Name, err)
+	}
+	return runTestFiles(ctx.Args().First(), ctx.Int(WorkersFlag.Name), func(file string) ([]FixtureTestResult, error) {
+		return runEngineTest(file, re)
+	})
+}
+
+// runEngineTest loads the engine tests given by fname, and replays each of them
+// against a fresh in-memory node.
+func runEngineTest(fname string, re *regexp.Regexp) ([]FixtureTestResult, error) {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var testsByName map[string]*tests.EngineTest
+	if err := json.Unmarshal(src, &testsByName); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(testsByName))
+	for name := range testsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []FixtureTestResult
+	for _, name := range names {
+		if !re.MatchString(name) {
+			continue
+		}
+		test := testsByName[name]
+		result := FixtureTestResult{File: fname, Name: name, Fork: test.Network(), Pass: true}
+		if err := test.Run(newEngineClient); err != nil {
+			result.Pass, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// engineClient drives an in-memory node through the engine API, calling the
+// consensus API directly instead of over RPC.
+type engineClient struct {
+	stack *node.Node
+	eth   *eth.Ethereum
+	api   *catalyst.ConsensusAPI
+}
+
+// newEngineClient starts an in-memory node without networking on the given
+// genesis, and attaches an engine API client to it.
+func newEngineClient(genesis *core.Genesis) (tests.EngineClient, error) {
+	stack, err := node.New(&node.Config{
+		P2P: p2p.Config{
+			NoDiscovery: true,
+			MaxPeers:    0,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Engine API tests are only defined for merged networks, which the node
+	// only supports if the merge is marked as having happened.
+	if genesis.Config.TerminalTotalDifficulty == nil {
+		stack.Close()
+		return nil, errors.New("engine tests require a merged network")
+	}
+	chainConfig := *genesis.Config
+	chainConfig.TerminalTotalDifficultyPassed = true
+
+	gspec := *genesis
+	gspec.Config = &chainConfig
+
+	config := ethconfig.Defaults
+	config.Genesis = &gspec
+	config.SyncMode = downloader.FullSync
+	config.StateScheme = rawdb.HashScheme
+	config.NoPruning = true
+
+	backend, err := eth.New(stack, &config)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return nil, err
+	}
+	backend.SetSynced()
+	return &engineClient{
+		stack: stack,
+		eth:   backend,
+		api:   catalyst.NewConsensusAPI(backend),
+	}, nil
+}
+
+// NewPayload implements tests.EngineClient, delivering the payload using the
+// requested version of engine_newPayload.
+func (c *engineClient) NewPayload(version int, params []json.RawMessage) (string, error) {
+	var (
+		data       engine.ExecutableData
+		hashes     []common.Hash
+		beaconRoot *common.Hash
+		requests   []hexutil.Bytes
+	)
+	args := []interface{}{&data, &hashes, &beaconRoot, &requests}
+	if len(params) > len(args) {
+		return "", engine.InvalidParams.With(fmt.Errorf("too many params: %d", len(params)))
+	}
+	for i, param := range params {
+		if err := json.Unmarshal(param, args[i]); err != nil {
+			return "", engine.InvalidParams.With(fmt.Errorf("invalid param %d: %v", i, err))
+		}
+	}
+	var (
+		status engine.PayloadStatusV1
+		err    error
+		ctx    = context.Background()
+	)
+	switch version {
+	case 1:
+		status, err = c.api.NewPayloadV1(ctx, data)
+	case 2:
+		status, err = c.api.NewPayloadV2(ctx, data)
+	case 3:
+		status, err = c.api.NewPayloadV3(ctx, data, hashes, beaconRoot)
+	case 4:
+		status, err = c.api.NewPayloadV4(ctx, data, hashes, beaconRoot, requests)
+	default:
+		return "", fmt.Errorf("unsupported engine_newPayload version %d", version)
+	}
+	return status.Status, err
+}
+
+// ForkchoiceUpdated implements tests.EngineClient, setting the head, safe and
+// finalized blocks to the given one.
+func (c *engineClient) ForkchoiceUpdated(version int, head common.Hash) (string, error) {
+	var (
+		update = engine.ForkchoiceStateV1{HeadBlockHash: head, SafeBlockHash: head, FinalizedBlockHash: head}
+		res    engine.ForkChoiceResponse
+		err    error
+		ctx    = context.Background()
+	)
+	switch version {
+	case 1:
+		res, err = c.api.ForkchoiceUpdatedV1(ctx, update, nil)
+	case 2:
+		res, err = c.api.ForkchoiceUpdatedV2(ctx, update, nil)
+	case 3:
+		res, err = c.api.ForkchoiceUpdatedV3(ctx, update, nil)
+	default:
+		return "", fmt.Errorf("unsupported engine_forkchoiceUpdated version %d", version)
+	}
+	return res.PayloadStatus.Status, err
+}
+
+// State implements tests.EngineClient, returning the current head block and
+// its state.
+func (c *engineClient) State() (common.Hash, *state.StateDB, error) {
+	chain := c.eth.BlockChain()
+	head := chain.CurrentBlock()
+	statedb, err := chain.StateAt(head.Root)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	return head.Hash(), statedb, nil
+}
+
+// Close implements tests.EngineClient, terminating the node.
+func (c *engineClient) Close() error {
+	return c.stack.Close()
+}
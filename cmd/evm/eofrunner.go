@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/urfave/cli/v2"
+)
+
+var eofTestCommand = &cli.Command{
+	Action:    eofTestCmd,
+	Name:      "eoftest",
+	Usage:     "Executes the given EOF validation tests. Filenames can be fed via standard input (batch mode) or as an argument (one-off execution).",
+	ArgsUsage: "<file>",
+}
+
+// eofTest is a single EOF validation test file entry.
+type eofTest struct {
+	Vectors map[string]eofTestVector `json:"vectors"`
+}
+
+// eofTestVector is a container to be validated, and the expected validation
+// result per fork.
+type eofTestVector struct {
+	Code          hexutil.Bytes            `json:"code"`
+	ContainerKind string                   `json:"containerKind"`
+	Results       map[string]eofTestResult `json:"results"`
+}
+
+// eofTestResult is the expected validation result of a test vector.
+type eofTestResult struct {
+	Result    bool   `json:"result"`
+	Exception string `json:"exception,omitempty"`
+}
+
+// EOFTestResult contains the validation outcome of a single test vector.
+type EOFTestResult struct {
+	Name  string `json:"name"`
+	Pass  bool   `json:"pass"`
+	Fork  string `json:"fork"`
+	Error string `json:"error,omitempty"`
+}
+
+func eofTestCmd(ctx *cli.Context) error {
+	// Load the test content from the input file
+	if len(ctx.Args().First()) != 0 {
+		return runEOFTest(ctx.Args().First())
+	}
+	// Read filenames from stdin and execute back-to-back
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fname := scanner.Text()
+		if len(fname) == 0 {
+			return nil
+		}
+		if err := runEOFTest(fname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runEOFTest loads the EOF tests given by fname, and validates all the test
+// vectors for the forks EOF is supported in.
+func runEOFTest(fname string) error {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	var testsByName map[string]eofTest
+	if err := json.Unmarshal(src, &testsByName); err != nil {
+		return err
+	}
+	// Iterate over all the tests in a stable order, validate the containers
+	// and aggregate the results
+	var (
+		jt      = vm.LookupEOFInstructionSet()
+		results []EOFTestResult
+		names   = make([]string, 0, len(testsByName))
+	)
+	for name := range testsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		test := testsByName[name]
+		ids := make([]string, 0, len(test.Vectors))
+		for id := range test.Vectors {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			vector := test.Vectors[id]
+			expect, ok := vector.Results["Prague"]
+			if !ok {
+				continue
+			}
+			result := EOFTestResult{Name: fmt.Sprintf("%s/%s", name, id), Fork: "Prague", Pass: true}
+			err := validateEOFContainer(vector.Code, &jt, vector.ContainerKind == "INITCODE")
+			switch {
+			case err == nil && !expect.Result:
+				result.Pass, result.Error = false, fmt.Sprintf("validation succeeded, want %s", expect.Exception)
+			case err != nil && expect.Result:
+				result.Pass, result.Error = false, err.Error()
+			case err != nil:
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+	}
+	out, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
+
+// validateEOFContainer parses and validates the given top-level EOF container.
+func validateEOFContainer(code []byte, jt *vm.JumpTable, isInitCode bool) error {
+	if !vm.HasEOFByte(code) {
+		return errors.New("missing EOF magic")
+	}
+	var container vm.Container
+	if err := container.UnmarshalBinary(code); err != nil {
+		return err
+	}
+	return container.ValidateCode(jt, isInitCode)
+}
@@ -230,7 +230,10 @@ func init() {
 		disasmCommand,
 		runCommand,
 		blockTestCommand,
+		engineTestCommand,
 		stateTestCommand,
+		eofTestCommand,
+		txTestCommand,
 		stateTransitionCommand,
 		transactionCommand,
 		blockBuilderCommand,
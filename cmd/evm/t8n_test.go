@@ -560,6 +560,90 @@ func TestB11r(t *testing.T) {
 	}
 }
 
+func TestEOFTest(t *testing.T) {
+	t.Parallel()
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	args := []string{"eoftest", "./testdata/31/eof_tests.json"}
+	tt.Run("evm-test", args...)
+	tt.Logf("args:\n go run . %v\n", strings.Join(args, " "))
+
+	want, err := os.ReadFile("./testdata/31/exp.json")
+	if err != nil {
+		t.Fatalf("could not read expected output: %v", err)
+	}
+	have := tt.Output()
+	ok, err := cmpJson(have, want)
+	switch {
+	case err != nil:
+		t.Logf(string(have))
+		t.Fatalf("json parsing failed: %v", err)
+	case !ok:
+		t.Fatalf("output wrong, have \n%v\nwant\n%v\n", string(have), string(want))
+	}
+	tt.WaitExit()
+	if have := tt.ExitStatus(); have != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", have)
+	}
+}
+
+func TestEngineTest(t *testing.T) {
+	t.Parallel()
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	args := []string{"enginetest", "./testdata/32/engine_tests.json"}
+	tt.Run("evm-test", args...)
+	tt.Logf("args:\n go run . %v\n", strings.Join(args, " "))
+
+	want, err := os.ReadFile("./testdata/32/exp.json")
+	if err != nil {
+		t.Fatalf("could not read expected output: %v", err)
+	}
+	have := tt.Output()
+	ok, err := cmpJson(have, want)
+	switch {
+	case err != nil:
+		t.Logf(string(have))
+		t.Fatalf("json parsing failed: %v", err)
+	case !ok:
+		t.Fatalf("output wrong, have \n%v\nwant\n%v\n", string(have), string(want))
+	}
+	tt.WaitExit()
+	if have := tt.ExitStatus(); have != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", have)
+	}
+}
+
+func TestTxTest(t *testing.T) {
+	t.Parallel()
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+
+	args := []string{"txtest", "./testdata/33/tx_tests.json"}
+	tt.Run("evm-test", args...)
+	tt.Logf("args:\n go run . %v\n", strings.Join(args, " "))
+
+	want, err := os.ReadFile("./testdata/33/exp.json")
+	if err != nil {
+		t.Fatalf("could not read expected output: %v", err)
+	}
+	have := tt.Output()
+	ok, err := cmpJson(have, want)
+	switch {
+	case err != nil:
+		t.Logf(string(have))
+		t.Fatalf("json parsing failed: %v", err)
+	case !ok:
+		t.Fatalf("output wrong, have \n%v\nwant\n%v\n", string(have), string(want))
+	}
+	tt.WaitExit()
+	if have := tt.ExitStatus(); have != 0 {
+		t.Fatalf("wrong exit code, have %d, want 0", have)
+	}
+}
+
 // cmpJson compares the JSON in two byte slices.
 func cmpJson(a, b []byte) (bool, error) {
 	var j, j2 interface{}
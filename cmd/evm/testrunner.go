@@ -0,0 +1,125 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v2"
+)
+
+var WorkersFlag = &cli.IntFlag{
+	Name:  "workers",
+	Value: runtime.NumCPU(),
+	Usage: "Number of test files to run in parallel when given a directory.",
+}
+
+// FixtureTestResult contains the outcome of a single test case of a fixture
+// file, as reported by the fixture runners.
+type FixtureTestResult struct {
+	File  string `json:"file"`
+	Name  string `json:"name"`
+	Pass  bool   `json:"pass"`
+	Fork  string `json:"fork,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// collectTestFiles returns the fixture file at the given path, or all the JSON
+// files found recursively if the path is a directory.
+func collectTestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// runTestFiles runs the fixture files found at the given path on a number of
+// parallel workers, and prints the aggregated results as JSON. Files which fail
+// to load are reported as a failing result.
+func runTestFiles(path string, workers int, run func(file string) ([]FixtureTestResult, error)) error {
+	files, err := collectTestFiles(path)
+	if err != nil {
+		return err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		tasks   = make(chan string)
+		results []FixtureTestResult
+		lock    sync.Mutex
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range tasks {
+				res, err := run(file)
+				if err != nil {
+					res = append(res, FixtureTestResult{File: file, Pass: false, Error: err.Error()})
+				}
+				lock.Lock()
+				results = append(results, res...)
+				lock.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		tasks <- file
+	}
+	close(tasks)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].File != results[j].File {
+			return results[i].File < results[j].File
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Fork < results[j].Fork
+	})
+	if results == nil {
+		results = []FixtureTestResult{}
+	}
+	out, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(out))
+	return nil
+}
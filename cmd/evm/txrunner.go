@@ -0,0 +1,89 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var txTestCommand = &cli.Command{
+	Action:    txTestCmd,
+	Name:      "txtest",
+	Usage:     "Validates the given transaction tests against every fork they define results for",
+	ArgsUsage: "<file or directory>",
+	Flags:     []cli.Flag{RunFlag, WorkersFlag},
+}
+
+func txTestCmd(ctx *cli.Context) error {
+	if len(ctx.Args().First()) == 0 {
+		return errors.New("path-to-test argument required")
+	}
+	re, err := regexp.Compile(ctx.String(RunFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid regex -%s: %v", RunFlag.Name, err)
+	}
+	return runTestFiles(ctx.Args().First(), ctx.Int(WorkersFlag.Name), func(file string) ([]FixtureTestResult, error) {
+		return runTxTest(file, re)
+	})
+}
+
+// runTxTest loads the transaction tests given by fname, and validates each of
+// them in all the forks with an expected result.
+func runTxTest(fname string, re *regexp.Regexp) ([]FixtureTestResult, error) {
+	src, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var testsByName map[string]*tests.TxTest
+	if err := json.Unmarshal(src, &testsByName); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(testsByName))
+	for name := range testsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []FixtureTestResult
+	for _, name := range names {
+		if !re.MatchString(name) {
+			continue
+		}
+		test := testsByName[name]
+		forks := make([]string, 0, len(test.Result))
+		for fork := range test.Result {
+			forks = append(forks, fork)
+		}
+		sort.Strings(forks)
+		for _, fork := range forks {
+			result := FixtureTestResult{File: fname, Name: name, Fork: fork, Pass: true}
+			if err := test.Run(fork); err != nil {
+				result.Pass, result.Error = false, err.Error()
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
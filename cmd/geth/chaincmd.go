@@ -98,6 +98,11 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.MetricsInfluxDBTokenFlag,
 			utils.MetricsInfluxDBBucketFlag,
 			utils.MetricsInfluxDBOrganizationFlag,
+			utils.MetricsEnableOTLPFlag,
+			utils.MetricsOTLPEndpointFlag,
+			utils.MetricsOTLPIntervalFlag,
+			utils.MetricsOTLPHeadersFlag,
+			utils.MetricsOTLPAttributesFlag,
 			utils.TxLookupLimitFlag,
 			utils.TransactionHistoryFlag,
 			utils.StateHistoryFlag,
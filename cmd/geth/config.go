@@ -178,6 +178,7 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		cfg.Eth.OverrideVerkle = &v
 	}
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
+	utils.SetupTelemetry(ctx, stack)
 
 	// Create gauge with geth system and build information
 	if eth != nil { // The 'eth' backend may be nil in light mode
@@ -221,6 +222,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		}
 		catalyst.RegisterSimulatedBeaconAPIs(stack, simBeacon)
 		stack.RegisterLifecycle(simBeacon)
+	} else if ctx.IsSet(utils.BeaconApiFlag.Name) {
+		// Follow the chain by the beacon light client instead of an
+		// external consensus client.
+		utils.RegisterLightSyncer(stack, eth, utils.MakeBeaconLightConfig(ctx))
 	} else {
 		err := catalyst.Register(stack, eth)
 		if err != nil {
@@ -302,6 +307,21 @@ func applyMetricConfig(ctx *cli.Context, cfg *gethConfig) {
 	if ctx.IsSet(utils.MetricsInfluxDBOrganizationFlag.Name) {
 		cfg.Metrics.InfluxDBOrganization = ctx.String(utils.MetricsInfluxDBOrganizationFlag.Name)
 	}
+	if ctx.IsSet(utils.MetricsEnableOTLPFlag.Name) {
+		cfg.Metrics.EnableOTLP = ctx.Bool(utils.MetricsEnableOTLPFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPEndpointFlag.Name) {
+		cfg.Metrics.OTLPEndpoint = ctx.String(utils.MetricsOTLPEndpointFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPIntervalFlag.Name) {
+		cfg.Metrics.OTLPInterval = ctx.Duration(utils.MetricsOTLPIntervalFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPHeadersFlag.Name) {
+		cfg.Metrics.OTLPHeaders = ctx.String(utils.MetricsOTLPHeadersFlag.Name)
+	}
+	if ctx.IsSet(utils.MetricsOTLPAttributesFlag.Name) {
+		cfg.Metrics.OTLPAttributes = ctx.String(utils.MetricsOTLPAttributesFlag.Name)
+	}
 }
 
 func deprecated(field string) bool {
@@ -18,7 +18,10 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -31,12 +34,16 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/remotedb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -52,6 +59,16 @@ var (
 		Usage: "If set, selects the state data for removal",
 	}
 
+	dbServeAddrFlag = &cli.StringFlag{
+		Name:  "addr",
+		Usage: "Listening address of the HTTP and WebSocket endpoint",
+		Value: "localhost:8555",
+	}
+	dbServeIPCFlag = &cli.StringFlag{
+		Name:  "ipc",
+		Usage: "Filename of the IPC endpoint (disabled if empty)",
+	}
+
 	removedbCommand = &cli.Command{
 		Action:    removeDB,
 		Name:      "removedb",
@@ -79,6 +96,9 @@ Remove blockchain and state databases`,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
+			dbBuildLogIndexCmd,
+			dbCheckLogIndexCmd,
+			dbServeCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -100,6 +120,26 @@ Remove blockchain and state databases`,
 		Description: `This command iterates the entire database for 32-byte keys, looking for rlp-encoded trie nodes.
 For each trie node encountered, it checks that the key corresponds to the keccak256(value). If this is not true, this indicates
 a data corruption.`,
+	}
+	dbBuildLogIndexCmd = &cli.Command{
+		Action: buildLogIndex,
+		Name:   "build-logindex",
+		Usage:  "Build the log index up to the current chain head",
+		Flags:  flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command indexes the logs of the canonical chain which are not yet indexed,
+reverting the indexed blocks which are not canonical anymore. The log index is otherwise
+maintained in the background while the node is running. The command can be interrupted
+and resumed later on.`,
+	}
+	dbCheckLogIndexCmd = &cli.Command{
+		Action:    checkLogIndex,
+		Name:      "check-logindex",
+		ArgsUsage: "<start (optional)> <end (optional)>",
+		Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+		Usage:     "Verify that the log index is consistent with the canonical chain",
+		Description: `This command iterates the canonical blocks in the given range (the entire indexed
+chain by default), checking that the log index references every log of the blocks at the
+correct position.`,
 	}
 	dbStatCmd = &cli.Command{
 		Action: dbStats,
@@ -194,6 +234,25 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Exports the specified chain data to an RLP encoded stream, optionally gzip-compressed.",
 	}
+	dbServeCmd = &cli.Command{
+		Action: dbServe,
+		Name:   "serve",
+		Usage:  "Serve the database to remote clients",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			dbServeAddrFlag,
+			dbServeIPCFlag,
+			utils.WSAllowedOriginsFlag,
+			utils.JWTSecretFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command serves the key-value store and the ancient store of the
+database over HTTP, WebSocket and optionally IPC, allowing other processes to read
+and write it through the --remotedb flag. The served database is writable, so the
+HTTP and WebSocket endpoint requires JWT authentication like the engine API, using
+the secret given by --authrpc.jwtsecret. Browser origins are rejected unless allowed
+by --ws.origins. WebSocket or IPC is recommended, as iterators are streamed over
+multiple requests.`,
+	}
 	dbMetadataCmd = &cli.Command{
 		Action: showMetaData,
 		Name:   "metadata",
@@ -375,6 +434,99 @@ func checkStateContent(ctx *cli.Context) error {
 	return nil
 }
 
+func buildLogIndex(ctx *cli.Context) error {
+	var (
+		stack, _  = makeConfigNode(ctx)
+		interrupt = make(chan os.Signal, 1)
+		stop      = make(chan struct{})
+	)
+	defer stack.Close()
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	defer close(interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Info("Interrupted during log indexing, stopping at next block")
+		}
+		close(stop)
+	}()
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	head := rawdb.ReadHeadBlock(db)
+	if head == nil {
+		return errors.New("head block is not available")
+	}
+	start := time.Now()
+	if err := core.UpdateLogIndex(db, head.NumberU64(), stop); err != nil {
+		return err
+	}
+	number, _ := rawdb.ReadLogIndexHead(db)
+	log.Info("Updated log index", "head", number, "chain", head.NumberU64(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+func checkLogIndex(ctx *cli.Context) error {
+	if ctx.NArg() > 2 {
+		return fmt.Errorf("max 2 arguments: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	head, ok := core.LogIndexHead(db)
+	if !ok {
+		return errors.New("log index is not available or not canonical")
+	}
+	var (
+		start = uint64(0)
+		end   = head
+		err   error
+	)
+	if ctx.NArg() > 0 {
+		if start, err = strconv.ParseUint(ctx.Args().Get(0), 10, 64); err != nil {
+			return fmt.Errorf("failed to parse 'start': %v", err)
+		}
+	}
+	if ctx.NArg() > 1 {
+		if end, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			return fmt.Errorf("failed to parse 'end': %v", err)
+		}
+		if end > head {
+			return fmt.Errorf("blocks above the log index head %d are not indexed", head)
+		}
+	}
+	if start > end {
+		return fmt.Errorf("invalid range [%d, %d]", start, end)
+	}
+	var (
+		errs      int
+		startTime = time.Now()
+		lastLog   = time.Now()
+	)
+	for number := start; number <= end; number++ {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+		if header == nil {
+			return fmt.Errorf("missing canonical header #%d", number)
+		}
+		if err := core.VerifyLogIndex(db, header); err != nil {
+			errs++
+			fmt.Printf("Error at block %d: %v\n", number, err)
+		}
+		if time.Since(lastLog) > 8*time.Second {
+			log.Info("Checking the log index", "at", number, "end", end, "elapsed", common.PrettyDuration(time.Since(startTime)))
+			lastLog = time.Now()
+		}
+	}
+	log.Info("Checked the log index", "errors", errs, "blocks", end-start+1, "elapsed", common.PrettyDuration(time.Since(startTime)))
+	if errs > 0 {
+		return fmt.Errorf("log index is corrupted at %d blocks", errs)
+	}
+	return nil
+}
+
 func showLeveldbStats(db ethdb.KeyValueStater) {
 	if stats, err := db.Stat("leveldb.stats"); err != nil {
 		log.Warn("Failed to read database stats", "error", err)
@@ -419,6 +571,61 @@ func dbCompact(ctx *cli.Context) error {
 	return nil
 }
 
+// dbServe serves the database to remote clients until interrupted.
+func dbServe(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	server, service, err := remotedb.NewServer(db)
+	if err != nil {
+		return err
+	}
+	defer service.Close()
+	defer server.Stop()
+
+	secret, err := stack.ObtainJWTSecret(stack.Config().JWTSecret)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", ctx.String(dbServeAddrFlag.Name))
+	if err != nil {
+		return err
+	}
+	var (
+		ws      = server.WebsocketHandler(stack.Config().WSOrigins)
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				ws.ServeHTTP(w, r)
+				return
+			}
+			server.ServeHTTP(w, r)
+		})
+		httpServer = &http.Server{Handler: node.NewJWTHandler(secret, handler)}
+	)
+	go httpServer.Serve(listener)
+	defer httpServer.Close()
+	log.Info("Serving database", "url", fmt.Sprintf("http://%s", listener.Addr()))
+
+	if path := ctx.String(dbServeIPCFlag.Name); path != "" {
+		ipc, ipcServer, err := rpc.StartIPCEndpoint(path, []rpc.API{{Namespace: remotedb.Namespace, Service: service}})
+		if err != nil {
+			return err
+		}
+		defer ipcServer.Stop()
+		defer ipc.Close()
+		log.Info("Serving database", "ipc", path)
+	}
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	<-sigc
+	log.Info("Stopping database server")
+	return nil
+}
+
 // dbGet shows the value of a given database key
 func dbGet(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -36,10 +37,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/beacon/blsync"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -57,10 +61,12 @@ import (
 	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
+	"github.com/ethereum/go-ethereum/metrics/otlp"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -94,7 +100,7 @@ var (
 	}
 	RemoteDBFlag = &cli.StringFlag{
 		Name:     "remotedb",
-		Usage:    "URL for remote database",
+		Usage:    "URL for remote database served by 'geth db serve' (authenticated with --authrpc.jwtsecret), or read-only via the debug API of a node",
 		Category: flags.LoggingCategory,
 	}
 	DBEngineFlag = &cli.StringFlag{
@@ -260,7 +266,7 @@ var (
 	}
 	GCModeFlag = &cli.StringFlag{
 		Name:     "gcmode",
-		Usage:    `Blockchain garbage collection mode, only relevant in state.scheme=hash ("full", "archive")`,
+		Usage:    `Blockchain garbage collection mode ("full", "archive"), archive mode in state.scheme=path indexes the state histories for historical state access`,
 		Value:    "full",
 		Category: flags.StateCategory,
 	}
@@ -281,6 +287,11 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	AccessListHistoryFlag = &cli.BoolFlag{
+		Name:     "history.accesslists",
+		Usage:    "Record and store the accounts and storage slots accessed by the transactions of each imported block",
+		Category: flags.StateCategory,
+	}
 	// Transaction pool settings
 	TxPoolLocalsFlag = &cli.StringFlag{
 		Name:     "txpool.locals",
@@ -346,6 +357,23 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPersistFlag = &cli.StringFlag{
+		Name:     "txpool.persist",
+		Usage:    "Journal file persisting all pending and queued transactions across node restarts (disabled if empty)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPersistSizeFlag = &cli.Uint64Flag{
+		Name:     "txpool.persist.maxsize",
+		Usage:    "Maximum accumulated size of the persisted transactions in megabytes",
+		Value:    ethconfig.Defaults.TxPoolJournal.MaxSize / 1024 / 1024,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPersistAgeFlag = &cli.DurationFlag{
+		Name:     "txpool.persist.maxage",
+		Usage:    "Maximum age of the persisted transactions to be reinjected on startup",
+		Value:    ethconfig.Defaults.TxPoolJournal.MaxAge,
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -401,6 +429,11 @@ var (
 		Usage:    "Disable heuristic state prefetch during block import (less CPU and disk IO, more time waiting for data)",
 		Category: flags.PerfCategory,
 	}
+	ParallelExecutionFlag = &cli.BoolFlag{
+		Name:     "execution.parallel",
+		Usage:    "Execute the transactions of imported blocks optimistically in parallel",
+		Category: flags.PerfCategory,
+	}
 	CachePreimagesFlag = &cli.BoolFlag{
 		Name:     "cache.preimages",
 		Usage:    "Enable recording the SHA3/keccak preimages of trie keys",
@@ -496,6 +529,16 @@ var (
 		Usage:    "Record information useful for VM and contract debugging",
 		Category: flags.VMCategory,
 	}
+	VMTraceFlag = &cli.StringFlag{
+		Name:     "vmtrace",
+		Usage:    "Name of tracer which should record internal VM operations (costly)",
+		Category: flags.VMCategory,
+	}
+	VMTraceJsonConfigFlag = &cli.StringFlag{
+		Name:     "vmtrace.jsonconfig",
+		Usage:    "Tracer configuration (JSON)",
+		Category: flags.VMCategory,
+	}
 
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
@@ -561,6 +604,44 @@ var (
 		Category:  flags.MiscCategory,
 	}
 
+	// Beacon light client settings
+	BeaconApiFlag = &cli.StringFlag{
+		Name:     "beacon.api",
+		Usage:    "Beacon node light client API URL. This flag enables the beacon light sync, following the chain without an attached consensus client",
+		Category: flags.BeaconCategory,
+	}
+	BeaconApiHeaderFlag = &cli.StringSliceFlag{
+		Name:     "beacon.api.header",
+		Usage:    "Pass custom HTTP header fields to the beacon node API in \"key:value\" format. This flag can be given multiple times.",
+		Category: flags.BeaconCategory,
+	}
+	BeaconCheckpointFlag = &cli.StringFlag{
+		Name:     "beacon.checkpoint",
+		Usage:    "Beacon chain weak subjectivity checkpoint block hash to start the light sync from",
+		Category: flags.BeaconCategory,
+	}
+	BeaconThresholdFlag = &cli.IntFlag{
+		Name:     "beacon.threshold",
+		Usage:    "Beacon sync committee participation threshold",
+		Value:    blsync.DefaultThreshold,
+		Category: flags.BeaconCategory,
+	}
+	BeaconConfigFlag = &cli.StringFlag{
+		Name:     "beacon.config",
+		Usage:    "Beacon chain config YAML file",
+		Category: flags.BeaconCategory,
+	}
+	BeaconGenesisRootFlag = &cli.StringFlag{
+		Name:     "beacon.genesis.gvroot",
+		Usage:    "Beacon chain genesis validators root",
+		Category: flags.BeaconCategory,
+	}
+	BeaconGenesisTimeFlag = &cli.Uint64Flag{
+		Name:     "beacon.genesis.time",
+		Usage:    "Beacon chain genesis time",
+		Category: flags.BeaconCategory,
+	}
+
 	// RPC settings
 	IPCDisabledFlag = &cli.BoolFlag{
 		Name:     "ipcdisable",
@@ -697,6 +778,28 @@ var (
 		Usage:    "Enables the (deprecated) personal namespace",
 		Category: flags.APICategory,
 	}
+	RPCTelemetryFlag = &cli.BoolFlag{
+		Name:     "rpc.telemetry",
+		Usage:    "Enable tracing of RPC and engine API calls, exported to an OpenTelemetry collector (OTLP/HTTP)",
+		Category: flags.APICategory,
+	}
+	RPCTelemetryEndpointFlag = &cli.StringFlag{
+		Name:     "rpc.telemetry.endpoint",
+		Usage:    "OTLP/HTTP traces endpoint to report spans to",
+		Value:    telemetry.DefaultConfig.Endpoint,
+		Category: flags.APICategory,
+	}
+	RPCTelemetrySampleRatioFlag = &cli.Float64Flag{
+		Name:     "rpc.telemetry.sample-ratio",
+		Usage:    "Ratio of the traces started locally to record, between 0 and 1 (traces propagated by callers follow their sampling decision)",
+		Value:    telemetry.DefaultConfig.SampleRatio,
+		Category: flags.APICategory,
+	}
+	RPCTelemetryHeadersFlag = &cli.StringFlag{
+		Name:     "rpc.telemetry.headers",
+		Usage:    "Comma-separated HTTP headers (key=value) sent with each OTLP push (e.g. authorization)",
+		Category: flags.APICategory,
+	}
 
 	// Network Settings
 	MaxPeersFlag = &cli.IntFlag{
@@ -757,6 +860,11 @@ var (
 		Usage:    "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 		Category: flags.NetworkingCategory,
 	}
+	DiscoveryV5TopicsFlag = &cli.BoolFlag{
+		Name:     "discovery.v5.topics",
+		Usage:    "Advertise and search the protocol topics via V5 discovery (experimental)",
+		Category: flags.NetworkingCategory,
+	}
 	NetrestrictFlag = &cli.StringFlag{
 		Name:     "netrestrict",
 		Usage:    "Restricts network communication to the given IP networks (CIDR masks)",
@@ -773,6 +881,16 @@ var (
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	DiscoveryAddr6Flag = &cli.StringFlag{
+		Name:     "discovery.addr6",
+		Usage:    "Run P2P discovery on a separate IPv6 socket at this address (e.g. [::]:30303), in addition to IPv4",
+		Category: flags.NetworkingCategory,
+	}
+	QUICPortFlag = &cli.IntFlag{
+		Name:     "quic.port",
+		Usage:    "Accept P2P connections over QUIC on this UDP port, and prefer QUIC when dialing peers which support it (disabled if unset)",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -908,6 +1026,36 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Value:    metrics.DefaultConfig.InfluxDBOrganization,
 		Category: flags.MetricsCategory,
 	}
+
+	MetricsEnableOTLPFlag = &cli.BoolFlag{
+		Name:     "metrics.otlp",
+		Usage:    "Enable metrics export/push to an OpenTelemetry collector (OTLP/HTTP)",
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPEndpointFlag = &cli.StringFlag{
+		Name:     "metrics.otlp.endpoint",
+		Usage:    "OTLP/HTTP metrics endpoint to report metrics to",
+		Value:    metrics.DefaultConfig.OTLPEndpoint,
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPIntervalFlag = &cli.DurationFlag{
+		Name:     "metrics.otlp.interval",
+		Usage:    "Time interval between two consecutive OTLP metrics pushes",
+		Value:    metrics.DefaultConfig.OTLPInterval,
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPHeadersFlag = &cli.StringFlag{
+		Name:     "metrics.otlp.headers",
+		Usage:    "Comma-separated HTTP headers (key=value) sent with each OTLP push (e.g. authorization)",
+		Value:    metrics.DefaultConfig.OTLPHeaders,
+		Category: flags.MetricsCategory,
+	}
+	MetricsOTLPAttributesFlag = &cli.StringFlag{
+		Name:     "metrics.otlp.attributes",
+		Usage:    "Comma-separated OTLP resource attributes (key=value) describing the node",
+		Value:    metrics.DefaultConfig.OTLPAttributes,
+		Category: flags.MetricsCategory,
+	}
 )
 
 var (
@@ -1060,6 +1208,12 @@ func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
 	if ctx.IsSet(DiscoveryPortFlag.Name) {
 		cfg.DiscAddr = fmt.Sprintf(":%d", ctx.Int(DiscoveryPortFlag.Name))
 	}
+	if ctx.IsSet(DiscoveryAddr6Flag.Name) {
+		cfg.DiscAddr6 = ctx.String(DiscoveryAddr6Flag.Name)
+	}
+	if ctx.IsSet(QUICPortFlag.Name) {
+		cfg.QUICAddr = fmt.Sprintf(":%d", ctx.Int(QUICPortFlag.Name))
+	}
 }
 
 // setNAT creates a port mapper from command line flags.
@@ -1325,6 +1479,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	CheckExclusive(ctx, DiscoveryV5Flag, NoDiscoverFlag)
 	cfg.DiscoveryV4 = ctx.Bool(DiscoveryV4Flag.Name)
 	cfg.DiscoveryV5 = ctx.Bool(DiscoveryV5Flag.Name)
+	cfg.DiscoveryV5Topics = ctx.Bool(DiscoveryV5TopicsFlag.Name)
 
 	if netrestrict := ctx.String(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
@@ -1495,6 +1650,18 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	}
 }
 
+func setTxPoolJournal(ctx *cli.Context, cfg *txpool.JournalConfig) {
+	if ctx.IsSet(TxPoolPersistFlag.Name) {
+		cfg.Path = ctx.String(TxPoolPersistFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPersistSizeFlag.Name) {
+		cfg.MaxSize = ctx.Uint64(TxPoolPersistSizeFlag.Name) * 1024 * 1024
+	}
+	if ctx.IsSet(TxPoolPersistAgeFlag.Name) {
+		cfg.MaxAge = ctx.Duration(TxPoolPersistAgeFlag.Name)
+	}
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerExtraDataFlag.Name) {
 		cfg.ExtraData = []byte(ctx.String(MinerExtraDataFlag.Name))
@@ -1592,6 +1759,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setTxPoolJournal(ctx, &cfg.TxPoolJournal)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1641,6 +1809,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = ctx.Bool(CacheNoPrefetchFlag.Name)
 	}
+	if ctx.IsSet(ParallelExecutionFlag.Name) {
+		cfg.ParallelExecution = ctx.Bool(ParallelExecutionFlag.Name)
+	}
 	// Read the value from the flag no matter if it's set or not.
 	cfg.Preimages = ctx.Bool(CachePreimagesFlag.Name)
 	if cfg.NoPruning && !cfg.Preimages {
@@ -1653,6 +1824,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
+	if ctx.IsSet(AccessListHistoryFlag.Name) {
+		cfg.BlockAccessLists = ctx.Bool(AccessListHistoryFlag.Name)
+	}
 	// Parse transaction history flag, if user is still using legacy config
 	// file with 'TxLookupLimit' configured, copy the value to 'TransactionHistory'.
 	if cfg.TransactionHistory == ethconfig.Defaults.TransactionHistory && cfg.TxLookupLimit != ethconfig.Defaults.TxLookupLimit {
@@ -1703,6 +1877,13 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	// VM tracing config.
+	if ctx.IsSet(VMTraceFlag.Name) {
+		if name := ctx.String(VMTraceFlag.Name); name != "" {
+			cfg.VMTrace = name
+			cfg.VMTraceJsonConfig = ctx.String(VMTraceJsonConfigFlag.Name)
+		}
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1901,6 +2082,61 @@ func RegisterFullSyncTester(stack *node.Node, eth *eth.Ethereum, target common.H
 	log.Info("Registered full-sync tester", "hash", target)
 }
 
+// MakeBeaconLightConfig constructs the beacon light sync configuration based on
+// the command line flags.
+func MakeBeaconLightConfig(ctx *cli.Context) *blsync.Config {
+	config := &blsync.Config{
+		Api:          ctx.String(BeaconApiFlag.Name),
+		CustomHeader: make(map[string]string),
+		Threshold:    ctx.Int(BeaconThresholdFlag.Name),
+	}
+	switch {
+	case ctx.IsSet(BeaconConfigFlag.Name):
+		if !ctx.IsSet(BeaconGenesisRootFlag.Name) || !ctx.IsSet(BeaconGenesisTimeFlag.Name) {
+			Fatalf("Custom beacon chain config requires genesis validators root and genesis time to be specified")
+		}
+		root, err := hexutil.Decode(ctx.String(BeaconGenesisRootFlag.Name))
+		if err != nil || len(root) != common.HashLength {
+			Fatalf("Invalid beacon genesis validators root: %s", ctx.String(BeaconGenesisRootFlag.Name))
+		}
+		config.GenesisValidatorsRoot = common.BytesToHash(root)
+		config.GenesisTime = ctx.Uint64(BeaconGenesisTimeFlag.Name)
+		if err := config.ChainConfig.LoadForks(ctx.String(BeaconConfigFlag.Name)); err != nil {
+			Fatalf("Could not load beacon chain config: %v", err)
+		}
+	case ctx.Bool(SepoliaFlag.Name):
+		config.ChainConfig = *blsync.SepoliaConfig
+	case ctx.Bool(HoleskyFlag.Name):
+		config.ChainConfig = *blsync.HoleskyConfig
+	case ctx.Bool(GoerliFlag.Name):
+		Fatalf("Beacon light sync is not supported on the Goerli network")
+	default:
+		config.ChainConfig = *blsync.MainnetConfig
+	}
+	if ctx.IsSet(BeaconCheckpointFlag.Name) {
+		hash, err := hexutil.Decode(ctx.String(BeaconCheckpointFlag.Name))
+		if err != nil || len(hash) != common.HashLength {
+			Fatalf("Invalid beacon checkpoint: %s", ctx.String(BeaconCheckpointFlag.Name))
+		}
+		config.Checkpoint = common.BytesToHash(hash)
+	}
+	for _, header := range ctx.StringSlice(BeaconApiHeaderFlag.Name) {
+		key, value, ok := strings.Cut(header, ":")
+		if !ok {
+			Fatalf("Invalid custom API header entry: %s", header)
+		}
+		config.CustomHeader[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return config
+}
+
+// RegisterLightSyncer adds the beacon light sync service into node, driving the
+// chain by the heads verified by the light client.
+func RegisterLightSyncer(stack *node.Node, eth *eth.Ethereum, config *blsync.Config) {
+	catalyst.RegisterLightSyncer(stack, eth, blsync.NewClient(config, eth.ChainDb()))
+	log.Info("Registered beacon light syncer", "api", config.Api, "checkpoint", config.Checkpoint)
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1952,6 +2188,22 @@ func SetupMetrics(ctx *cli.Context) {
 			go influxdb.InfluxDBV2WithTags(metrics.DefaultRegistry, 10*time.Second, endpoint, token, bucket, organization, "geth.", tagsMap)
 		}
 
+		if ctx.Bool(MetricsEnableOTLPFlag.Name) {
+			config := otlp.Config{
+				Endpoint:   ctx.String(MetricsOTLPEndpointFlag.Name),
+				Interval:   ctx.Duration(MetricsOTLPIntervalFlag.Name),
+				Namespace:  "geth.",
+				Headers:    splitHeadersFlag(ctx.String(MetricsOTLPHeadersFlag.Name)),
+				Attributes: SplitTagsFlag(ctx.String(MetricsOTLPAttributesFlag.Name)),
+			}
+			if _, ok := config.Attributes["service.version"]; !ok {
+				config.Attributes["service.version"] = params.VersionWithMeta
+			}
+			log.Info("Enabling metrics export to OpenTelemetry", "endpoint", config.Endpoint, "interval", config.Interval)
+
+			otlp.NewExporter(metrics.DefaultRegistry, config).Start()
+		}
+
 		if ctx.IsSet(MetricsHTTPFlag.Name) {
 			address := net.JoinHostPort(ctx.String(MetricsHTTPFlag.Name), fmt.Sprintf("%d", ctx.Int(MetricsPortFlag.Name)))
 			log.Info("Enabling stand-alone metrics HTTP endpoint", "address", address)
@@ -1962,6 +2214,48 @@ func SetupMetrics(ctx *cli.Context) {
 	}
 }
 
+// splitHeadersFlag parses a comma-separated list of key=value HTTP headers. As
+// header values (e.g. base64 credentials) may contain '=', only the first one
+// is considered the separator.
+func splitHeadersFlag(headersFlag string) map[string]string {
+	headers := make(map[string]string)
+	for _, header := range strings.Split(headersFlag, ",") {
+		if key, value, ok := strings.Cut(header, "="); ok {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+// SetupTelemetry enables the tracing of the RPC and engine API calls if requested,
+// exporting the spans until the node is stopped.
+func SetupTelemetry(ctx *cli.Context, stack *node.Node) {
+	if !ctx.Bool(RPCTelemetryFlag.Name) {
+		return
+	}
+	ratio := ctx.Float64(RPCTelemetrySampleRatioFlag.Name)
+	if ratio < 0 || ratio > 1 {
+		Fatalf("Invalid --%s value %v, must be between 0 and 1", RPCTelemetrySampleRatioFlag.Name, ratio)
+	}
+	config := telemetry.Config{
+		Endpoint:    ctx.String(RPCTelemetryEndpointFlag.Name),
+		SampleRatio: ratio,
+		Interval:    telemetry.DefaultConfig.Interval,
+		Headers:     splitHeadersFlag(ctx.String(RPCTelemetryHeadersFlag.Name)),
+		Attributes:  map[string]string{"service.version": params.VersionWithMeta},
+	}
+	log.Info("Enabling RPC tracing", "endpoint", config.Endpoint, "ratio", config.SampleRatio)
+	stack.RegisterLifecycle(&telemetryService{stop: telemetry.Enable(config)})
+}
+
+// telemetryService flushes the pending spans when the node is stopped.
+type telemetryService struct {
+	stop func()
+}
+
+func (s *telemetryService) Start() error { return nil }
+func (s *telemetryService) Stop() error  { s.stop(); return nil }
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
@@ -1990,7 +2284,8 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node, readonly bool) ethdb.
 	switch {
 	case ctx.IsSet(RemoteDBFlag.Name):
 		log.Info("Using remote db", "url", ctx.String(RemoteDBFlag.Name), "headers", len(ctx.StringSlice(HttpHeaderFlag.Name)))
-		client, err := DialRPCWithHeaders(ctx.String(RemoteDBFlag.Name), ctx.StringSlice(HttpHeaderFlag.Name))
+		var client *rpc.Client
+		client, err = dialRemoteDB(ctx)
 		if err != nil {
 			break
 		}
@@ -2028,7 +2323,7 @@ func IsNetworkPreset(ctx *cli.Context) bool {
 	return false
 }
 
-func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error) {
+func DialRPCWithHeaders(endpoint string, headers []string, opts ...rpc.ClientOption) (*rpc.Client, error) {
 	if endpoint == "" {
 		return nil, errors.New("endpoint must be specified")
 	}
@@ -2037,7 +2332,6 @@ func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error)
 		// these prefixes.
 		endpoint = endpoint[4:]
 	}
-	var opts []rpc.ClientOption
 	if len(headers) > 0 {
 		customHeaders := make(http.Header)
 		for _, h := range headers {
@@ -2052,6 +2346,24 @@ func DialRPCWithHeaders(endpoint string, headers []string) (*rpc.Client, error)
 	return rpc.DialOptions(context.Background(), endpoint, opts...)
 }
 
+// dialRemoteDB connects to the database given by the remotedb flag, authenticating
+// with the JWT secret if one is configured.
+func dialRemoteDB(ctx *cli.Context) (*rpc.Client, error) {
+	var opts []rpc.ClientOption
+	if ctx.IsSet(JWTSecretFlag.Name) {
+		data, err := os.ReadFile(ctx.String(JWTSecretFlag.Name))
+		if err != nil {
+			return nil, err
+		}
+		secret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(secret) != 32 {
+			return nil, errors.New("invalid JWT secret")
+		}
+		opts = append(opts, rpc.WithHTTPAuth(node.NewJWTAuth([32]byte(secret))))
+	}
+	return DialRPCWithHeaders(ctx.String(RemoteDBFlag.Name), ctx.StringSlice(HttpHeaderFlag.Name), opts...)
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -2100,6 +2412,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		ParallelExecution:   ctx.Bool(ParallelExecutionFlag.Name),
+		BlockAccessLists:    ctx.Bool(AccessListHistoryFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -2120,6 +2434,19 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		cache.TrieDirtyLimit = ctx.Int(CacheFlag.Name) * ctx.Int(CacheGCFlag.Name) / 100
 	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.Bool(VMEnableDebugFlag.Name)}
+	if ctx.IsSet(VMTraceFlag.Name) {
+		if name := ctx.String(VMTraceFlag.Name); name != "" {
+			var config json.RawMessage
+			if ctx.IsSet(VMTraceJsonConfigFlag.Name) {
+				config = json.RawMessage(ctx.String(VMTraceJsonConfigFlag.Name))
+			}
+			t, err := tracers.LiveDirectory.New(name, config)
+			if err != nil {
+				Fatalf("Failed to create tracer %q: %v", name, err)
+			}
+			vmcfg.Tracer = t
+		}
+	}
 
 	// Disable transaction indexing/unindexing by default.
 	chain, err := core.NewBlockChain(chainDb, cache, gspec, nil, engine, vmcfg, nil, nil)
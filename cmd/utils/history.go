@@ -0,0 +1,427 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// errHistoryImportInterrupted is returned if the freezer import is aborted.
+var errHistoryImportInterrupted = errors.New("history import interrupted")
+
+// eraBlock is a block read from an era1 file, converted into the format stored
+// in the freezer.
+type eraBlock struct {
+	number   uint64
+	hash     common.Hash
+	parent   common.Hash
+	header   []byte // RLP encoded header
+	body     []byte // RLP encoded body
+	receipts []byte // RLP encoded receipts in storage format
+	td       *big.Int
+}
+
+// eraResult is the outcome of reading and verifying an era1 file.
+type eraResult struct {
+	blocks []*eraBlock
+	err    error
+	done   chan struct{} // Closed when the result is available
+}
+
+// readEraBlocks reads all blocks of an era1 file starting from the given block
+// number, verifying the transaction, uncle and receipt roots of each block, and
+// the accumulator root of the whole file against the expected one if it's given.
+func readEraBlocks(filename string, from uint64, root *common.Hash) ([]*eraBlock, error) {
+	e, err := era.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening era1 file: %w", err)
+	}
+	defer e.Close()
+
+	acc, err := e.Accumulator()
+	if err != nil {
+		return nil, fmt.Errorf("error reading accumulator: %w", err)
+	}
+	if root != nil && acc != *root {
+		return nil, fmt.Errorf("unexpected accumulator root: have %s, want %s", acc, *root)
+	}
+	it, err := era.NewRawIterator(e)
+	if err != nil {
+		return nil, fmt.Errorf("error making era1 iterator: %w", err)
+	}
+	var (
+		blocks []*eraBlock
+		hashes = make([]common.Hash, 0, e.Count())
+		tds    = make([]*big.Int, 0, e.Count())
+	)
+	for it.Next() {
+		if err := it.Error(); err != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), err)
+		}
+		block, err := readEraBlock(it)
+		if err != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), err)
+		}
+		if n := len(hashes); n > 0 && block.parent != hashes[n-1] {
+			return nil, fmt.Errorf("block %d is not linked to its parent", block.number)
+		}
+		hashes = append(hashes, block.hash)
+		tds = append(tds, block.td)
+		if block.number >= from {
+			blocks = append(blocks, block)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	// The accumulator is recomputed over the entire file, verifying the
+	// headers through their hashes.
+	if have, err := era.ComputeAccumulator(hashes, tds); err != nil {
+		return nil, fmt.Errorf("error computing accumulator: %w", err)
+	} else if have != acc {
+		return nil, fmt.Errorf("accumulator mismatch: have %s, want %s", have, acc)
+	}
+	return blocks, nil
+}
+
+// readEraBlock reads the current block of the era1 iterator and converts it into
+// the freezer format, verifying the body and the receipts against the header.
+func readEraBlock(it *era.RawIterator) (*eraBlock, error) {
+	rawHeader, err := io.ReadAll(it.Header)
+	if err != nil {
+		return nil, err
+	}
+	rawBody, err := io.ReadAll(it.Body)
+	if err != nil {
+		return nil, err
+	}
+	rawReceipts, err := io.ReadAll(it.Receipts)
+	if err != nil {
+		return nil, err
+	}
+	rawTd, err := io.ReadAll(it.TotalDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		header   types.Header
+		body     types.Body
+		receipts types.Receipts
+	)
+	if err := rlp.DecodeBytes(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if header.Number.Uint64() != it.Number() {
+		return nil, fmt.Errorf("unexpected header number %d", header.Number)
+	}
+	if err := rlp.DecodeBytes(rawBody, &body); err != nil {
+		return nil, fmt.Errorf("invalid body: %w", err)
+	}
+	if hash := types.DeriveSha(types.Transactions(body.Transactions), trie.NewStackTrie(nil)); hash != header.TxHash {
+		return nil, fmt.Errorf("transaction root mismatch: have %s, want %s", hash, header.TxHash)
+	}
+	if hash := types.CalcUncleHash(body.Uncles); hash != header.UncleHash {
+		return nil, fmt.Errorf("uncle root mismatch: have %s, want %s", hash, header.UncleHash)
+	}
+	if err := rlp.DecodeBytes(rawReceipts, &receipts); err != nil {
+		return nil, fmt.Errorf("invalid receipts: %w", err)
+	}
+	if hash := types.DeriveSha(receipts, trie.NewStackTrie(nil)); hash != header.ReceiptHash {
+		return nil, fmt.Errorf("receipt root mismatch: have %s, want %s", hash, header.ReceiptHash)
+	}
+	storage := make([]*types.ReceiptForStorage, len(receipts))
+	for i, receipt := range receipts {
+		storage[i] = (*types.ReceiptForStorage)(receipt)
+	}
+	stReceipts, err := rlp.EncodeToBytes(storage)
+	if err != nil {
+		return nil, err
+	}
+	// The total difficulty is stored as a little endian integer.
+	td := make([]byte, len(rawTd))
+	for i := range rawTd {
+		td[i] = rawTd[len(rawTd)-1-i]
+	}
+	return &eraBlock{
+		number:   it.Number(),
+		hash:     crypto.Keccak256Hash(rawHeader),
+		parent:   header.ParentHash,
+		header:   rawHeader,
+		body:     rawBody,
+		receipts: stReceipts,
+		td:       new(big.Int).SetBytes(td),
+	}, nil
+}
+
+// writeEraBlocks appends the given blocks to the freezer, along with the hash
+// to number mappings and the head markers in the key-value store.
+func writeEraBlocks(db ethdb.Database, blocks []*eraBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	// The number mappings are written first, so that they are never missing
+	// for a frozen block. They are simply rewritten if the import is resumed.
+	batch := db.NewBatch()
+	for _, block := range blocks {
+		rawdb.WriteHeaderNumber(batch, block.hash, block.number)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	_, err := db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for _, block := range blocks {
+			td, err := rlp.EncodeToBytes(block.td)
+			if err != nil {
+				return err
+			}
+			if err := op.AppendRaw(rawdb.ChainFreezerHashTable, block.number, block.hash.Bytes()); err != nil {
+				return fmt.Errorf("can't add block %d hash: %v", block.number, err)
+			}
+			if err := op.AppendRaw(rawdb.ChainFreezerHeaderTable, block.number, block.header); err != nil {
+				return fmt.Errorf("can't append block header %d: %v", block.number, err)
+			}
+			if err := op.AppendRaw(rawdb.ChainFreezerBodiesTable, block.number, block.body); err != nil {
+				return fmt.Errorf("can't append block body %d: %v", block.number, err)
+			}
+			if err := op.AppendRaw(rawdb.ChainFreezerReceiptTable, block.number, block.receipts); err != nil {
+				return fmt.Errorf("can't append block %d receipts: %v", block.number, err)
+			}
+			if err := op.AppendRaw(rawdb.ChainFreezerDifficultyTable, block.number, td); err != nil {
+				return fmt.Errorf("can't append block %d total difficulty: %v", block.number, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.Sync(); err != nil {
+		return err
+	}
+	head := blocks[len(blocks)-1].hash
+	rawdb.WriteHeadHeaderHash(db, head)
+	rawdb.WriteHeadFastBlockHash(db, head)
+	return nil
+}
+
+// ImportHistoryFreezer imports the blockchain history from the era1 files in the
+// specified directory straight into the freezer, skipping the block processing.
+// The era1 files are read and verified concurrently by the given number of
+// workers, checking the accumulator root of each file against the expected one
+// if the roots are provided. The import continues from the last block already
+// stored in the freezer, and can be interrupted by closing the stop channel.
+func ImportHistoryFreezer(db ethdb.Database, dir string, network string, workers int, roots []common.Hash, stop chan struct{}) error {
+	entries, err := era.ReadDir(dir, network)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	if roots != nil && len(roots) != len(entries) {
+		return fmt.Errorf("expected equal number of accumulator roots and entries, have: %d roots, %d entries", len(roots), len(entries))
+	}
+	frozen, err := db.Ancients()
+	if err != nil {
+		return err
+	}
+	// The history is only appended to the freezer, refuse to import into a
+	// database which already has chain data beyond the genesis in the key-value
+	// store.
+	if head := rawdb.ReadHeaderNumber(db, rawdb.ReadHeadHeaderHash(db)); head != nil && *head > 0 && *head >= frozen {
+		return fmt.Errorf("database already contains blocks beyond the freezer, head %d, frozen %d", *head, frozen)
+	}
+	var parent common.Hash
+	if frozen > 0 {
+		if parent = rawdb.ReadCanonicalHash(db, frozen-1); parent == (common.Hash{}) {
+			return fmt.Errorf("missing frozen block %d", frozen-1)
+		}
+	}
+	// Skip the era1 files which are fully imported already.
+	var first int
+	for ; first < len(entries); first++ {
+		e, err := era.Open(path.Join(dir, entries[first]))
+		if err != nil {
+			return fmt.Errorf("error opening era1 file %s: %w", entries[first], err)
+		}
+		end := e.Start() + e.Count()
+		e.Close()
+		if end > frozen {
+			break
+		}
+	}
+	if first == len(entries) {
+		log.Info("History already imported", "blocks", frozen)
+		return nil
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	// Read the era1 files concurrently, limiting the number of files held in
+	// memory to the number of workers.
+	var (
+		results = make([]*eraResult, len(entries))
+		tasks   = make(chan int)
+		slots   = make(chan struct{}, workers)
+		quit    = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+	for i := range results {
+		results[i] = &eraResult{done: make(chan struct{})}
+	}
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range tasks {
+				var root *common.Hash
+				if roots != nil {
+					root = &roots[index]
+				}
+				result := results[index]
+				result.blocks, result.err = readEraBlocks(path.Join(dir, entries[index]), frozen, root)
+				close(result.done)
+			}
+		}()
+	}
+	go func() {
+		defer close(tasks)
+		for index := first; index < len(entries); index++ {
+			select {
+			case slots <- struct{}{}:
+			case <-quit:
+				return
+			}
+			select {
+			case tasks <- index:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	var (
+		start    = time.Now()
+		reported = time.Now()
+		imported int
+		next     = frozen
+	)
+	for index := first; index < len(entries); index++ {
+		result := results[index]
+		select {
+		case <-result.done:
+		case <-stop:
+			return errHistoryImportInterrupted
+		}
+		if result.err != nil {
+			return fmt.Errorf("error reading era1 file %s: %w", entries[index], result.err)
+		}
+		blocks := result.blocks
+		if len(blocks) > 0 {
+			if blocks[0].number != next {
+				return fmt.Errorf("gap in era1 history: have block %d, want %d", blocks[0].number, next)
+			}
+			if next > 0 && blocks[0].parent != parent {
+				return fmt.Errorf("block %d is not linked to the imported history", next)
+			}
+			if err := writeEraBlocks(db, blocks); err != nil {
+				return fmt.Errorf("error writing era1 file %s: %w", entries[index], err)
+			}
+			next += uint64(len(blocks))
+			parent = blocks[len(blocks)-1].hash
+			imported += len(blocks)
+		}
+		results[index] = nil
+		<-slots
+
+		// Give the user some feedback that something is happening.
+		if time.Since(reported) >= 8*time.Second || index == len(entries)-1 {
+			var (
+				elapsed = time.Since(start)
+				eta     time.Duration
+			)
+			if done := index - first + 1; done < len(entries)-first {
+				eta = elapsed / time.Duration(done) * time.Duration(len(entries)-first-done)
+			}
+			log.Info("Importing era1 files", "head", next-1, "files", fmt.Sprintf("%d/%d", index+1, len(entries)), "imported", imported,
+				"elapsed", common.PrettyDuration(elapsed), "eta", common.PrettyDuration(eta))
+			reported = time.Now()
+		}
+	}
+	return nil
+}
+
+// CompareHistory cross-checks the blockchain history within the given block
+// range against a reference database, comparing the canonical hashes, headers,
+// bodies, receipts and total difficulties.
+func CompareHistory(db ethdb.Reader, ref ethdb.Reader, first, last uint64) error {
+	var (
+		start    = time.Now()
+		reported = time.Now()
+	)
+	for number := first; number <= last; number++ {
+		hash := rawdb.ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("missing canonical hash %d", number)
+		}
+		if want := rawdb.ReadCanonicalHash(ref, number); hash != want {
+			return fmt.Errorf("canonical hash %d mismatch: have %s, want %s", number, hash, want)
+		}
+		for _, item := range []struct {
+			name string
+			read func(ethdb.Reader) []byte
+		}{
+			{"header", func(db ethdb.Reader) []byte { return rawdb.ReadHeaderRLP(db, hash, number) }},
+			{"body", func(db ethdb.Reader) []byte { return rawdb.ReadBodyRLP(db, hash, number) }},
+			{"receipts", func(db ethdb.Reader) []byte { return rawdb.ReadReceiptsRLP(db, hash, number) }},
+			{"total difficulty", func(db ethdb.Reader) []byte { return rawdb.ReadTdRLP(db, hash, number) }},
+		} {
+			have, want := item.read(db), item.read(ref)
+			if have == nil {
+				return fmt.Errorf("missing %s %d", item.name, number)
+			}
+			if !bytes.Equal(have, want) {
+				return fmt.Errorf("%s %d mismatch: have %x, want %x", item.name, number, have, want)
+			}
+		}
+		if number := rawdb.ReadHeaderNumber(db, hash); number == nil {
+			return fmt.Errorf("missing number of block %s", hash)
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Comparing history", "number", number, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+	}
+	return nil
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/era"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
@@ -183,3 +184,105 @@ func TestHistoryImportAndExport(t *testing.T) {
 		t.Fatalf("imported chain does not match expected, have (%d, %s) want (%d, %s)", have.Number, have.Hash(), want.Number, want.Hash())
 	}
 }
+
+func TestHistoryImportFreezer(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  types.GenesisAlloc{address: {Balance: big.NewInt(1000000000000000000)}},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	db, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), int(count), func(i int, g *core.BlockGen) {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   genesis.Config.ChainID,
+			Nonce:     uint64(i),
+			GasTipCap: common.Big0,
+			GasFeeCap: g.PrevBlock(-1).BaseFee(),
+			Gas:       50000,
+			To:        &common.Address{0xaa},
+			Value:     big.NewInt(int64(i)),
+		})
+		if err != nil {
+			t.Fatalf("error creating tx: %v", err)
+		}
+		g.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("error insterting chain: %v", err)
+	}
+	dir := t.TempDir()
+	if err := ExportHistory(chain, dir, 0, count, step); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	entries, _ := era.ReadDir(dir, "mainnet")
+
+	// Import the first half of the era files only, the rest of them are imported
+	// after reopening the database.
+	partial := t.TempDir()
+	for _, name := range entries[:len(entries)/2] {
+		b, err := os.ReadFile(path.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read era file: %v", err)
+		}
+		if err := os.WriteFile(path.Join(partial, name), b, 0644); err != nil {
+			t.Fatalf("failed to copy era file: %v", err)
+		}
+	}
+	var (
+		datadir = t.TempDir()
+		ancient = t.TempDir()
+	)
+	open := func() ethdb.Database {
+		db, err := rawdb.Open(rawdb.OpenOptions{
+			Type:              "leveldb",
+			Directory:         datadir,
+			AncientsDirectory: ancient,
+		})
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		return db
+	}
+	db2 := open()
+	if err := ImportHistoryFreezer(db2, partial, "mainnet", 2, nil, nil); err != nil {
+		t.Fatalf("failed to import partial history: %v", err)
+	}
+	if frozen, _ := db2.Ancients(); frozen != uint64(len(entries)/2)*step {
+		t.Fatalf("unexpected number of frozen blocks: have %d, want %d", frozen, uint64(len(entries)/2)*step)
+	}
+	db2.Close()
+
+	// Resume the import and cross-check the result with the original chain.
+	db2 = open()
+	defer db2.Close()
+	if err := ImportHistoryFreezer(db2, dir, "mainnet", 3, nil, nil); err != nil {
+		t.Fatalf("failed to resume history import: %v", err)
+	}
+	if err := CompareHistory(db2, db, 0, count); err != nil {
+		t.Fatalf("imported history mismatch: %v", err)
+	}
+	if have, want := rawdb.ReadHeadHeaderHash(db2), chain.CurrentHeader().Hash(); have != want {
+		t.Fatalf("unexpected head header: have %s, want %s", have, want)
+	}
+	// Importing the same history again is a no-op.
+	if err := ImportHistoryFreezer(db2, dir, "mainnet", 1, nil, nil); err != nil {
+		t.Fatalf("failed to reimport history: %v", err)
+	}
+	// Importing a different history is rejected by the accumulator check.
+	db3, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db3.Close()
+	roots := make([]common.Hash, len(entries))
+	if err := ImportHistoryFreezer(db3, dir, "mainnet", 1, roots, nil); err == nil {
+		t.Fatal("history with mismatching accumulator roots imported")
+	}
+}
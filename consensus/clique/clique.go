@@ -317,6 +317,8 @@ func (c *Clique) verifyHeader(chain consensus.ChainHeaderReader, header *types.H
 		return fmt.Errorf("invalid blobGasUsed: have %d, expected nil", header.BlobGasUsed)
 	case header.ParentBeaconRoot != nil:
 		return fmt.Errorf("invalid parentBeaconRoot, have %#x, expected nil", header.ParentBeaconRoot)
+	case header.RequestsHash != nil:
+		return fmt.Errorf("invalid requestsHash, have %#x, expected nil", header.RequestsHash)
 	}
 	// All basic checks passed, verify cascading fields
 	return c.verifyCascadingFields(chain, header, parents)
@@ -775,6 +777,9 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 	if header.ParentBeaconRoot != nil {
 		panic("unexpected parent beacon root value in clique")
 	}
+	if header.RequestsHash != nil {
+		panic("unexpected requests hash value in clique")
+	}
 	if err := rlp.Encode(w, enc); err != nil {
 		panic("can't encode: " + err.Error())
 	}
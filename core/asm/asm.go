@@ -32,6 +32,7 @@ type instructionIterator struct {
 	op      vm.OpCode
 	error   error
 	started bool
+	eof     bool
 }
 
 // NewInstructionIterator creates a new instruction iterator.
@@ -41,6 +42,14 @@ func NewInstructionIterator(code []byte) *instructionIterator {
 	return it
 }
 
+// NewEOFInstructionIterator creates a new instruction iterator for the code of
+// an EOF code section, which takes the EOF immediate arguments into account.
+func NewEOFInstructionIterator(code []byte) *instructionIterator {
+	it := NewInstructionIterator(code)
+	it.eof = true
+	return it
+}
+
 // Next returns true if there is a next instruction and moves on.
 func (it *instructionIterator) Next() bool {
 	if it.error != nil || uint64(len(it.code)) <= it.pc {
@@ -65,7 +74,21 @@ func (it *instructionIterator) Next() bool {
 	}
 
 	it.op = vm.OpCode(it.code[it.pc])
-	if it.op.IsPush() {
+	if it.eof && !it.op.IsPush() {
+		size := uint64(vm.EOFImmediateSize(it.op))
+		if it.op == vm.RJUMPV && it.pc+1 < uint64(len(it.code)) {
+			size += 2 * (uint64(it.code[it.pc+1]) + 1)
+		}
+		u := it.pc + 1 + size
+		if uint64(len(it.code)) < u {
+			it.error = fmt.Errorf("incomplete %v instruction at %v", it.op, it.pc)
+			return false
+		}
+		it.arg = nil
+		if size > 0 {
+			it.arg = it.code[it.pc+1 : u]
+		}
+	} else if it.op.IsPush() {
 		a := uint64(it.op) - uint64(vm.PUSH1) + 1
 		u := it.pc + 1 + a
 		if uint64(len(it.code)) <= it.pc || uint64(len(it.code)) < u {
@@ -56,3 +56,38 @@ func TestInstructionIterator(t *testing.T) {
 		}
 	}
 }
+
+// Tests disassembling EOF code sections
+func TestEOFInstructionIterator(t *testing.T) {
+	for i, tc := range []struct {
+		want    int
+		code    string
+		wantErr string
+	}{
+		{3, "e0000000e4", ""},                                 // rjump, stop, retf
+		{3, "5fe2010003000400", ""},                           // push0, rjumpv with two targets, stop
+		{2, "e30001e4", ""},                                   // callf, retf
+		{0, "e2010003", "incomplete RJUMPV instruction at 0"}, // truncated jump table
+		{0, "e6", "incomplete DUPN instruction at 0"},         // truncated immediate
+	} {
+		var (
+			have    int
+			code, _ = hex.DecodeString(tc.code)
+			it      = NewEOFInstructionIterator(code)
+		)
+		for it.Next() {
+			have++
+		}
+		var haveErr = ""
+		if it.Error() != nil {
+			haveErr = it.Error().Error()
+		}
+		if haveErr != tc.wantErr {
+			t.Errorf("test %d: encountered error: %q want %q", i, haveErr, tc.wantErr)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("test %d: wrong instruction count, have %d want %d", i, have, tc.want)
+		}
+	}
+}
@@ -68,6 +68,18 @@ func BenchmarkInsertChain_ring1000_memdb(b *testing.B) {
 func BenchmarkInsertChain_ring1000_diskdb(b *testing.B) {
 	benchInsertChain(b, true, genTxRing(1000))
 }
+func BenchmarkInsertChain_independent1000_memdb(b *testing.B) {
+	benchInsertChainIndependent(b, false, false)
+}
+func BenchmarkInsertChain_independent1000_parallel_memdb(b *testing.B) {
+	benchInsertChainIndependent(b, false, true)
+}
+func BenchmarkInsertChain_independent1000_diskdb(b *testing.B) {
+	benchInsertChainIndependent(b, true, false)
+}
+func BenchmarkInsertChain_independent1000_parallel_diskdb(b *testing.B) {
+	benchInsertChainIndependent(b, true, true)
+}
 
 var (
 	// This is the content of the genesis block used by the benchmarks.
@@ -83,7 +95,7 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 	return func(i int, gen *BlockGen) {
 		toaddr := common.Address{}
 		data := make([]byte, nbytes)
-		gas, _ := IntrinsicGas(data, nil, false, false, false, false)
+		gas, _ := IntrinsicGas(data, nil, nil, false, false, false, false)
 		signer := gen.Signer()
 		gasPrice := big.NewInt(0)
 		if gen.header.BaseFee != nil {
@@ -158,6 +170,41 @@ func genTxRing(naccounts int) func(int, *BlockGen) {
 	}
 }
 
+// genTxIndependent returns a block generator that fills the blocks with
+// value transfers from distinct accounts, none of them depending on another
+// transaction in the same block.
+func genTxIndependent(naccounts int) func(int, *BlockGen) {
+	from := 0
+	return func(i int, gen *BlockGen) {
+		block := gen.PrevBlock(i - 1)
+		gas := block.GasLimit()
+		gasPrice := big.NewInt(0)
+		if gen.header.BaseFee != nil {
+			gasPrice = gen.header.BaseFee
+		}
+		signer := gen.Signer()
+		for {
+			gas -= params.TxGas
+			if gas < params.TxGas {
+				break
+			}
+			tx, err := types.SignNewTx(ringKeys[from], signer,
+				&types.LegacyTx{
+					Nonce:    gen.TxNonce(ringAddrs[from]),
+					To:       &ringAddrs[from],
+					Value:    big.NewInt(1),
+					Gas:      params.TxGas,
+					GasPrice: gasPrice,
+				})
+			if err != nil {
+				panic(err)
+			}
+			gen.AddTx(tx)
+			from = (from + 1) % naccounts
+		}
+	}
+}
+
 // genUncles generates blocks with two uncle headers.
 func genUncles(i int, gen *BlockGen) {
 	if i >= 7 {
@@ -171,6 +218,23 @@ func genUncles(i int, gen *BlockGen) {
 }
 
 func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
+	alloc := types.GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}}
+	benchInsertChainWithConfig(b, disk, alloc, nil, gen)
+}
+
+// benchInsertChainIndependent benchmarks the import of blocks full of
+// independent transactions, with sequential or parallel execution.
+func benchInsertChainIndependent(b *testing.B, disk bool, parallel bool) {
+	alloc := make(types.GenesisAlloc)
+	for _, addr := range ringAddrs {
+		alloc[addr] = types.Account{Balance: benchRootFunds}
+	}
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.ParallelExecution = parallel
+	benchInsertChainWithConfig(b, disk, alloc, cacheConfig, genTxIndependent(len(ringKeys)))
+}
+
+func benchInsertChainWithConfig(b *testing.B, disk bool, alloc types.GenesisAlloc, cacheConfig *CacheConfig, gen func(int, *BlockGen)) {
 	// Create the database in memory or in a temporary directory.
 	var db ethdb.Database
 	var err error
@@ -189,13 +253,13 @@ func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
 	// generator function.
 	gspec := &Genesis{
 		Config: params.TestChainConfig,
-		Alloc:  types.GenesisAlloc{benchRootAddr: {Balance: benchRootFunds}},
+		Alloc:  alloc,
 	}
 	_, chain, _ := GenerateChainWithGenesis(gspec, ethash.NewFaker(), b.N, gen)
 
 	// Time the insertion of the new chain.
 	// State and blocks are stored in the same DB.
-	chainman, _ := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	chainman, _ := NewBlockChain(db, cacheConfig, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
 	defer chainman.Stop()
 	b.ReportAllocs()
 	b.ResetTimer()
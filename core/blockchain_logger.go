@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BlockEvent is emitted upon tracing an incoming block.
+// It contains the block as well as consensus related information.
+type BlockEvent struct {
+	Block     *types.Block
+	TD        *big.Int
+	Finalized *types.Header
+	Safe      *types.Header
+}
+
+// BlockchainLogger is used to collect traces during chain processing.
+// Take note that EVM and state hooks are only invoked for blocks imported
+// into the chain, not for RPC calls or block building.
+//
+// A BlockchainLogger is attached to the chain by setting it as the tracer
+// of the vm.Config the chain is created with.
+type BlockchainLogger interface {
+	vm.EVMLogger
+	tracing.StateLogger
+
+	// OnBlockchainInit is called once when the blockchain is initialized.
+	OnBlockchainInit(chainConfig *params.ChainConfig)
+	// OnBlockStart is called before executing a block.
+	OnBlockStart(event BlockEvent)
+	// OnBlockEnd is called after executing a block, err is nil if processing,
+	// validation and commitment of the block succeeded.
+	OnBlockEnd(err error)
+	// OnSkippedBlock indicates a block was skipped during processing
+	// due to it being known previously. This can happen e.g. when recovering
+	// from a crash.
+	OnSkippedBlock(event BlockEvent)
+	// OnGenesisBlock is called when the genesis block is processed, along
+	// with the allocation which makes up the genesis state.
+	OnGenesisBlock(genesis *types.Block, alloc types.GenesisAlloc)
+	// OnBeaconBlockRootStart is called before the EIP-4788 system call which
+	// stores the parent beacon block root in the state.
+	OnBeaconBlockRootStart(root common.Hash)
+	// OnBeaconBlockRootEnd is called after the EIP-4788 system call.
+	OnBeaconBlockRootEnd()
+	// OnTxStart is called before the execution of a transaction starts.
+	OnTxStart(env *vm.EVM, tx *types.Transaction, from common.Address)
+	// OnTxEnd is called after the execution of a transaction ends, with either
+	// the receipt or the error which made the transaction invalid.
+	OnTxEnd(receipt *types.Receipt, err error)
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// recordingLogger is a BlockchainLogger which records the chain level events
+// and balance changes it receives.
+type recordingLogger struct {
+	inits    int
+	genesis  types.GenesisAlloc
+	blocks   []uint64
+	blockErr []error
+	txs      []common.Hash
+	receipts []*types.Receipt
+	balances map[tracing.BalanceChangeReason]int
+}
+
+func (l *recordingLogger) CaptureTxStart(gasLimit uint64) {}
+func (l *recordingLogger) CaptureTxEnd(restGas uint64)    {}
+func (l *recordingLogger) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+func (l *recordingLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+func (l *recordingLogger) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+func (l *recordingLogger) CaptureExit(output []byte, gasUsed uint64, err error) {}
+func (l *recordingLogger) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (l *recordingLogger) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (l *recordingLogger) OnBlockchainInit(chainConfig *params.ChainConfig) { l.inits++ }
+func (l *recordingLogger) OnBlockStart(ev BlockEvent) {
+	l.blocks = append(l.blocks, ev.Block.NumberU64())
+}
+func (l *recordingLogger) OnBlockEnd(err error)                    { l.blockErr = append(l.blockErr, err) }
+func (l *recordingLogger) OnSkippedBlock(ev BlockEvent)            {}
+func (l *recordingLogger) OnBeaconBlockRootStart(root common.Hash) {}
+func (l *recordingLogger) OnBeaconBlockRootEnd()                   {}
+func (l *recordingLogger) OnGenesisBlock(b *types.Block, alloc types.GenesisAlloc) {
+	l.genesis = alloc
+}
+func (l *recordingLogger) OnTxStart(env *vm.EVM, tx *types.Transaction, from common.Address) {
+	l.txs = append(l.txs, tx.Hash())
+}
+func (l *recordingLogger) OnTxEnd(receipt *types.Receipt, err error) {
+	l.receipts = append(l.receipts, receipt)
+}
+func (l *recordingLogger) OnBalanceChange(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+	l.balances[reason]++
+}
+func (l *recordingLogger) OnNonceChange(addr common.Address, prev, new uint64) {}
+func (l *recordingLogger) OnCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+}
+func (l *recordingLogger) OnStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {
+}
+func (l *recordingLogger) OnLog(log *types.Log) {}
+
+// Tests that a live tracer attached to the chain receives the chain level
+// events of block import, and the state changes made while processing them.
+func TestBlockchainLogger(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		to       = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		funds    = big.NewInt(params.Ether)
+		gspec    = &Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{addr: {Balance: funds}}}
+		signer   = types.LatestSigner(gspec.Config)
+		engine   = ethash.NewFaker()
+		tracer   = &recordingLogger{balances: make(map[tracing.BalanceChangeReason]int)}
+		txHashes []common.Hash
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		// Leave some gas unused and pay a tip, so that both gas refunds
+		// and fee rewards are present.
+		price := new(big.Int).Mul(b.header.BaseFee, common.Big2)
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), to, big.NewInt(1), params.TxGas+1000, price, nil), signer, key)
+		b.AddTx(tx)
+		txHashes = append(txHashes, tx.Hash())
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{Tracer: tracer}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if tracer.inits != 1 {
+		t.Fatalf("init hook invocations mismatch: have %d, want 1", tracer.inits)
+	}
+	if tracer.genesis == nil || tracer.genesis[addr].Balance.Cmp(funds) != 0 {
+		t.Fatalf("genesis allocation mismatch: have %v", tracer.genesis)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if len(tracer.blocks) != 2 || tracer.blocks[0] != 1 || tracer.blocks[1] != 2 {
+		t.Fatalf("block start mismatch: have %v", tracer.blocks)
+	}
+	for i, err := range tracer.blockErr {
+		if err != nil {
+			t.Fatalf("block %d: unexpected block end error: %v", i, err)
+		}
+	}
+	if len(tracer.blockErr) != 2 {
+		t.Fatalf("block end mismatch: have %d, want 2", len(tracer.blockErr))
+	}
+	if len(tracer.txs) != len(txHashes) {
+		t.Fatalf("tx start mismatch: have %d, want %d", len(tracer.txs), len(txHashes))
+	}
+	for i, hash := range txHashes {
+		if tracer.txs[i] != hash {
+			t.Errorf("tx %d: hash mismatch: have %x, want %x", i, tracer.txs[i], hash)
+		}
+		if tracer.receipts[i] == nil || tracer.receipts[i].TxHash != hash {
+			t.Errorf("tx %d: receipt mismatch", i)
+		}
+	}
+	for _, reason := range []tracing.BalanceChangeReason{
+		tracing.BalanceDecreaseGasBuy,
+		tracing.BalanceIncreaseGasReturn,
+		tracing.BalanceChangeTransfer,
+		tracing.BalanceIncreaseRewardTransactionFee,
+		tracing.BalanceIncreaseRewardMineBlock,
+	} {
+		if tracer.balances[reason] == 0 {
+			t.Errorf("missing balance change with reason %v", reason)
+		}
+	}
+}
+
+// Tests that the genesis allocation is only reported when the genesis is
+// committed, not when reopening a chain which is still at the genesis block.
+func TestBlockchainLoggerGenesisOnce(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &Genesis{Config: params.TestChainConfig, Alloc: types.GenesisAlloc{common.Address{0x01}: {Balance: common.Big1}}}
+	)
+	for i, want := range []bool{true, false} {
+		tracer := &recordingLogger{balances: make(map[tracing.BalanceChangeReason]int)}
+		chain, err := NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{Tracer: tracer}, nil, nil)
+		if err != nil {
+			t.Fatalf("run %d: failed to create chain: %v", i, err)
+		}
+		chain.Stop()
+
+		if tracer.inits != 1 {
+			t.Fatalf("run %d: init hook invocations mismatch: have %d, want 1", i, tracer.inits)
+		}
+		if have := tracer.genesis != nil; have != want {
+			t.Fatalf("run %d: genesis hook invoked mismatch: have %t, want %t", i, have, want)
+		}
+	}
+}
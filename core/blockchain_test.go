@@ -17,6 +17,8 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -163,14 +165,14 @@ func testBlockChainImport(chain types.Blocks, blockchain *BlockChain) error {
 		if err != nil {
 			return err
 		}
-		receipts, _, usedGas, err := blockchain.processor.Process(block, statedb, vm.Config{})
+		res, err := blockchain.processor.Process(block, statedb, vm.Config{})
 		if err != nil {
-			blockchain.reportBlock(block, receipts, err)
+			blockchain.reportBlock(block, nil, err)
 			return err
 		}
-		err = blockchain.validator.ValidateState(block, statedb, receipts, usedGas)
+		err = blockchain.validator.ValidateState(block, statedb, res)
 		if err != nil {
-			blockchain.reportBlock(block, receipts, err)
+			blockchain.reportBlock(block, res.Receipts, err)
 			return err
 		}
 
@@ -3773,7 +3775,7 @@ func testSetCanonical(t *testing.T, scheme string) {
 		gen.AddTx(tx)
 	})
 	for _, block := range side {
-		err := chain.InsertBlockWithoutSetHead(block)
+		err := chain.InsertBlockWithoutSetHead(context.Background(), block)
 		if err != nil {
 			t.Fatalf("Failed to insert into chain: %v", err)
 		}
@@ -4326,3 +4328,358 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// TestEIP7702 deploys two delegation designations and calls them. It writes one
+// value to storage which is verified after.
+func TestEIP7702(t *testing.T) {
+	var (
+		config  = *params.MergedTestChainConfig
+		engine  = beacon.NewFaker()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		aa      = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb      = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+	)
+	config.PragueTime = u64(0)
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			addr1: {Balance: funds},
+			addr2: {Balance: funds},
+			// The address 0xAAAA calls into addr2, then stores 0x42 at slot 0.
+			aa: {
+				Code: append(append([]byte{
+					byte(vm.PUSH1), 0, // out size
+					byte(vm.DUP1), // out offset
+					byte(vm.DUP1), // in size
+					byte(vm.DUP1), // in offset
+					byte(vm.DUP1), // value
+					byte(vm.PUSH20),
+				}, addr2.Bytes()...), []byte{
+					byte(vm.GAS),
+					byte(vm.CALL),
+					byte(vm.POP),
+					byte(vm.PUSH1), 0x42,
+					byte(vm.PUSH1), 0,
+					byte(vm.SSTORE),
+				}...),
+				Nonce:   0,
+				Balance: big.NewInt(0),
+			},
+			// The address 0xBBBB stores 0x42 at slot 0.
+			bb: {
+				Code: []byte{
+					byte(vm.PUSH1), 0x42,
+					byte(vm.PUSH1), 0,
+					byte(vm.SSTORE),
+				},
+				Nonce:   0,
+				Balance: big.NewInt(0),
+			},
+		},
+	}
+	// Sign authorizations delegating addr1 to 0xAAAA and addr2 to 0xBBBB. The
+	// sender's nonce is bumped before the authorizations are applied.
+	auth1, _ := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(gspec.Config.ChainID),
+		Address: aa,
+		Nonce:   1,
+	})
+	auth2, _ := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: bb,
+		Nonce:   0,
+	})
+	signer := types.LatestSigner(gspec.Config)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(aa)
+		txdata := &types.SetCodeTx{
+			ChainID:   uint256.MustFromBig(gspec.Config.ChainID),
+			Nonce:     0,
+			To:        addr1,
+			Gas:       500000,
+			GasFeeCap: uint256.MustFromBig(newGwei(5)),
+			GasTipCap: uint256.NewInt(2),
+			AuthList:  []types.SetCodeAuthorization{auth1, auth2},
+		}
+		tx := types.MustSignNewTx(key1, signer, txdata)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// Verify delegation designations were deployed.
+	state, _ := chain.State()
+	code, want := state.GetCode(addr1), types.AddressToDelegation(aa)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	code, want = state.GetCode(addr2), types.AddressToDelegation(bb)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	// Verify the nonces account for both the transaction and the authorizations.
+	if nonce := state.GetNonce(addr1); nonce != 2 {
+		t.Fatalf("addr1 nonce incorrect: got %d, want 2", nonce)
+	}
+	if nonce := state.GetNonce(addr2); nonce != 1 {
+		t.Fatalf("addr2 nonce incorrect: got %d, want 1", nonce)
+	}
+	// Verify delegation executed the correct code.
+	var (
+		fortyTwo = common.BytesToHash([]byte{0x42})
+		actual   = state.GetState(addr2, common.Hash{})
+	)
+	if actual.Cmp(fortyTwo) != 0 {
+		t.Fatalf("addr2 storage wrong: expected %d, got %d", fortyTwo, actual)
+	}
+	if actual = state.GetState(addr1, common.Hash{}); actual.Cmp(fortyTwo) != 0 {
+		t.Fatalf("addr1 storage wrong: expected %d, got %d", fortyTwo, actual)
+	}
+}
+
+// TestEIP2935 checks that the parent block hashes are recorded in the history
+// storage contract from Prague onward, and served by its getter.
+func TestEIP2935(t *testing.T) {
+	var (
+		config = *params.MergedTestChainConfig
+		engine = beacon.NewFaker()
+		caller = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+	)
+	config.PragueTime = u64(20)
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			params.HistoryStorageAddress: {Nonce: 1, Code: params.HistoryStorageCode, Balance: common.Big0},
+		},
+	}
+	// Blocks are 10 seconds apart, so Prague activates at block 2.
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 5, func(i int, b *BlockGen) {})
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	state, _ := chain.State()
+	head := chain.CurrentBlock()
+
+	for number := uint64(0); number <= head.Number.Uint64(); number++ {
+		var (
+			key  = common.BigToHash(new(big.Int).SetUint64(number % params.HistoryServeWindow))
+			want common.Hash
+		)
+		// Only the parents of Prague blocks are recorded.
+		if number >= 1 && number < head.Number.Uint64() {
+			want = chain.GetHeaderByNumber(number).Hash()
+		}
+		if have := state.GetState(params.HistoryStorageAddress, key); have != want {
+			t.Errorf("block %d: stored hash mismatch: have %x, want %x", number, have, want)
+		}
+	}
+	// Query the contract as if executing in the next block.
+	header := &types.Header{
+		ParentHash: head.Hash(),
+		Number:     new(big.Int).Add(head.Number, common.Big1),
+		Time:       head.Time + 10,
+		Difficulty: common.Big0,
+		GasLimit:   head.GasLimit,
+	}
+	evm := vm.NewEVM(NewEVMBlockContext(header, chain, &caller), vm.TxContext{}, state, &config, vm.Config{})
+	ProcessParentBlockHash(head.Hash(), evm, state)
+
+	for number := uint64(1); number <= head.Number.Uint64(); number++ {
+		input := common.BigToHash(new(big.Int).SetUint64(number))
+		ret, _, err := evm.Call(vm.AccountRef(caller), params.HistoryStorageAddress, input[:], 100000, new(uint256.Int))
+		if err != nil {
+			t.Fatalf("block %d: getter failed: %v", number, err)
+		}
+		if want := chain.GetHeaderByNumber(number).Hash(); common.BytesToHash(ret) != want {
+			t.Errorf("block %d: served hash mismatch: have %x, want %x", number, ret, want)
+		}
+	}
+	// Requests for the current block or later must revert.
+	input := common.BigToHash(header.Number)
+	if _, _, err := evm.Call(vm.AccountRef(caller), params.HistoryStorageAddress, input[:], 100000, new(uint256.Int)); err == nil {
+		t.Fatal("getter served hash for the current block")
+	}
+}
+
+// TestEIP7685 tests that the execution layer requests are collected from the
+// deposit logs and the system contracts, and committed to in the header.
+func TestEIP7685(t *testing.T) {
+	var (
+		config  = *params.MergedTestChainConfig
+		engine  = beacon.NewFaker()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		deposit = common.HexToAddress("0x000000000000000000000000000000000000dddd")
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+	)
+	config.PragueTime = u64(0)
+	config.DepositContractAddress = deposit
+
+	// The mock deposit contract emits its calldata as a DepositEvent.
+	depositCode := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CALLDATACOPY),
+		byte(vm.PUSH32),
+	}
+	depositCode = append(depositCode, types.DepositEventSignature.Bytes()...)
+	depositCode = append(depositCode, byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0, byte(vm.LOG1), byte(vm.STOP))
+
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			addr:    {Balance: funds},
+			deposit: {Code: depositCode, Balance: common.Big0},
+			// The mock withdrawal queue returns a single zero request.
+			params.WithdrawalQueueAddress: {
+				Code:    []byte{byte(vm.PUSH1), 76, byte(vm.PUSH1), 0, byte(vm.RETURN)},
+				Nonce:   1,
+				Balance: common.Big0,
+			},
+		},
+	}
+	var (
+		signer = types.LatestSigner(&config)
+		data   = make([]byte, 576)
+	)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		if i != 0 {
+			return
+		}
+		tx, _ := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     0,
+			To:        &deposit,
+			Gas:       100000,
+			GasFeeCap: newGwei(5),
+			GasTipCap: big.NewInt(2),
+			Data:      data,
+		})
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	depositRequest, err := types.DepositLogToRequest(data)
+	if err != nil {
+		t.Fatalf("failed to convert deposit log: %v", err)
+	}
+	withdrawalRequest := append([]byte{types.WithdrawalRequestType}, make([]byte, 76)...)
+
+	for i, want := range []common.Hash{
+		types.CalcRequestsHash([][]byte{append([]byte{types.DepositRequestType}, depositRequest...), withdrawalRequest}),
+		types.CalcRequestsHash([][]byte{withdrawalRequest}),
+	} {
+		header := chain.GetHeaderByNumber(uint64(i + 1))
+		if header.RequestsHash == nil {
+			t.Fatalf("block %d: missing requests hash", i+1)
+		}
+		if *header.RequestsHash != want {
+			t.Errorf("block %d: requests hash mismatch: have %x, want %x", i+1, *header.RequestsHash, want)
+		}
+	}
+	// Blocks committing to different requests must be rejected.
+	_, bad, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {})
+	header := bad[0].Header()
+	header.RequestsHash = &types.EmptyRequestsHash
+	chain2, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	defer chain2.Stop()
+	if _, err := chain2.InsertChain(types.Blocks{bad[0].WithSeal(header)}); err == nil {
+		t.Fatal("block with invalid requests hash inserted")
+	}
+}
+
+// Tests that the block access lists are recorded and stored during import if
+// enabled, and that they are removed when the chain is rewound or reorged.
+func TestBlockAccessListStorage(t *testing.T) {
+	var (
+		engine = beacon.NewFaker()
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		gspec  = &Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc:  types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		for j := 0; j < 2; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(tx)
+		}
+	})
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.BlockAccessLists = true
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	for i, block := range blocks {
+		list := rawdb.ReadAccessList(db, block.Hash(), block.NumberU64())
+		if len(list) != len(block.Transactions()) {
+			t.Fatalf("block %d: access list length mismatch: have %d, want %d", block.NumberU64(), len(list), len(block.Transactions()))
+		}
+		for j, entry := range list {
+			if entry.Index != uint64(j) {
+				t.Errorf("block %d: tx %d: index mismatch: have %d", block.NumberU64(), j, entry.Index)
+			}
+			var sender *types.AccountAccess
+			for _, acc := range entry.Accounts {
+				if acc.Address == addr {
+					sender = acc
+				}
+			}
+			if want := uint64(2*i + j + 1); sender == nil || sender.Post == nil || sender.Post.Nonce != want {
+				t.Errorf("block %d: tx %d: sender post-state missing or invalid, want nonce %d", block.NumberU64(), j, want)
+			}
+		}
+	}
+	if err := chain.SetHead(1); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if list := rawdb.ReadAccessList(db, blocks[1].Hash(), blocks[1].NumberU64()); list != nil {
+		t.Fatalf("access list not removed on rewind")
+	}
+	// Reorg the remaining block out with a sidechain, its access list should be
+	// dropped together with the transaction lookups.
+	_, forks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0xff})
+	})
+	if n, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("fork %d: failed to insert into chain: %v", n, err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != forks[1].Hash() {
+		t.Fatalf("chain not reorged: head %x, want %x", head, forks[1].Hash())
+	}
+	if list := rawdb.ReadAccessList(db, blocks[0].Hash(), blocks[0].NumberU64()); list != nil {
+		t.Fatalf("access list not removed on reorg")
+	}
+}
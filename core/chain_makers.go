@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
 	"github.com/holiman/uint256"
 )
 
@@ -340,11 +341,33 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		if config.IsPrague(b.header.Number, b.header.Time) {
+			// EIP-2935
+			blockContext := NewEVMBlockContext(b.header, cm, &b.header.Coinbase)
+			vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, cm.config, vm.Config{})
+			ProcessParentBlockHash(b.header.ParentHash, vmenv, statedb)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
 		}
 
+		if config.IsPrague(b.header.Number, b.header.Time) {
+			// Collect the EIP-7685 requests of the block
+			var logs []*types.Log
+			for _, r := range b.receipts {
+				logs = append(logs, r.Logs...)
+			}
+			blockContext := NewEVMBlockContext(b.header, cm, &b.header.Coinbase)
+			vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, cm.config, vm.Config{})
+			requests, err := ProcessRequests(logs, vmenv, statedb)
+			if err != nil {
+				panic(fmt.Sprintf("failed to process requests: %v", err))
+			}
+			reqHash := types.CalcRequestsHash(requests)
+			b.header.RequestsHash = &reqHash
+		}
+
 		block, err := b.engine.FinalizeAndAssemble(cm, b.header, statedb, b.txs, b.uncles, b.receipts, b.withdrawals)
 		if err != nil {
 			panic(err)
@@ -361,8 +384,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		return block, b.receipts
 	}
 
-	// Forcibly use hash-based state scheme for retaining all nodes in disk.
-	triedb := triedb.NewDatabase(db, triedb.HashDefaults)
+	// Forcibly use hash-based state scheme for retaining all nodes in disk,
+	// unless the chain is running on verkle.
+	triedb := triedb.NewDatabase(db, chainMakerTrieConfig(config.IsVerkle(parent.Number(), parent.Time())))
 	defer triedb.Close()
 
 	for i := 0; i < n; i++ {
@@ -407,7 +431,7 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 // then generate chain on top.
 func GenerateChainWithGenesis(genesis *Genesis, engine consensus.Engine, n int, gen func(int, *BlockGen)) (ethdb.Database, []*types.Block, []types.Receipts) {
 	db := rawdb.NewMemoryDatabase()
-	triedb := triedb.NewDatabase(db, triedb.HashDefaults)
+	triedb := triedb.NewDatabase(db, chainMakerTrieConfig(genesis.IsVerkle()))
 	defer triedb.Close()
 	_, err := genesis.Commit(db, triedb)
 	if err != nil {
@@ -417,6 +441,16 @@ func GenerateChainWithGenesis(genesis *Genesis, engine consensus.Engine, n int,
 	return db, blocks, receipts
 }
 
+// chainMakerTrieConfig returns the trie database config used for generating
+// chains. The verkle tree is only supported by the path-based scheme, which
+// flushes all the nodes into disk on commit as well.
+func chainMakerTrieConfig(isVerkle bool) *triedb.Config {
+	if isVerkle {
+		return &triedb.Config{IsVerkle: true, PathDB: pathdb.Defaults}
+	}
+	return triedb.HashDefaults
+}
+
 func (cm *chainMaker) makeHeader(parent *types.Block, state *state.StateDB, engine consensus.Engine) *types.Header {
 	time := parent.Time() + 10 // block time is fixed at 10 seconds
 	header := &types.Header{
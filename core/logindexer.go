@@ -0,0 +1,339 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/exp/slices"
+)
+
+// The log index maps every address and every topic (along with its position
+// in the topic list) emitted by the canonical chain to the positions of the
+// referencing logs, grouped per block. A log position is the index of the log
+// within the block. The logs matching a filter can thus be located by iterating
+// the index entries of the requested values within the block range, without
+// inspecting the blocks which don't contain any relevant log.
+//
+// The index is maintained incrementally: the indexed blocks are always a
+// connected segment of a chain from genesis up to the index head. Once the head
+// is reorged out, the non-canonical blocks are unindexed before the new ones
+// are indexed.
+
+// errLogIndexReorged is returned if the log index update was aborted because
+// the canonical chain was reorged concurrently.
+var errLogIndexReorged = errors.New("canonical chain reorged during log indexing")
+
+// logIndexEntries returns the log index entries of the block with the given
+// receipts, mapping the index prefixes to the referenced log positions.
+func logIndexEntries(receipts types.Receipts) map[string][]uint32 {
+	var (
+		entries = make(map[string][]uint32)
+		pos     uint32
+	)
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			key := string(rawdb.AddressLogIndexPrefix(l.Address))
+			entries[key] = append(entries[key], pos)
+
+			for i, topic := range l.Topics {
+				key := string(rawdb.TopicLogIndexPrefix(i, topic))
+				entries[key] = append(entries[key], pos)
+			}
+			pos++
+		}
+	}
+	return entries
+}
+
+// readLogIndexReceipts retrieves the receipts of the given block for maintaining
+// the log index.
+func readLogIndexReceipts(db ethdb.Reader, header *types.Header) (types.Receipts, error) {
+	receipts := rawdb.ReadRawReceipts(db, header.Hash(), header.Number.Uint64())
+	if receipts == nil && header.ReceiptHash != types.EmptyReceiptsHash {
+		return nil, fmt.Errorf("missing receipts of block #%d [%x]", header.Number, header.Hash())
+	}
+	return receipts, nil
+}
+
+// indexLogs writes the log index entries of the given block.
+func indexLogs(db ethdb.Reader, batch ethdb.KeyValueWriter, header *types.Header) error {
+	receipts, err := readLogIndexReceipts(db, header)
+	if err != nil {
+		return err
+	}
+	for key, positions := range logIndexEntries(receipts) {
+		rawdb.WriteLogIndex(batch, []byte(key), header.Number.Uint64(), positions)
+	}
+	return nil
+}
+
+// unindexLogs removes the log index entries of the given block. If the receipts
+// are not available anymore the entries are left in place, which is harmless as
+// the logs located through the index are always checked against the filter.
+func unindexLogs(db ethdb.Reader, batch ethdb.KeyValueWriter, header *types.Header) {
+	receipts, err := readLogIndexReceipts(db, header)
+	if err != nil {
+		log.Debug("Leaving stale log index entries", "number", header.Number, "hash", header.Hash(), "err", err)
+		return
+	}
+	for key := range logIndexEntries(receipts) {
+		rawdb.DeleteLogIndex(batch, []byte(key), header.Number.Uint64())
+	}
+}
+
+// LogIndexHead returns the number of the last block whose logs are indexed. The
+// flag is false if nothing is indexed yet, or the indexed chain segment is not
+// canonical anymore, in which case the index must not be used until the indexer
+// catches up with the reorg.
+func LogIndexHead(db ethdb.Reader) (uint64, bool) {
+	number, hash := rawdb.ReadLogIndexHead(db)
+	if hash == (common.Hash{}) || rawdb.ReadCanonicalHash(db, number) != hash {
+		return 0, false
+	}
+	return number, true
+}
+
+// UpdateLogIndex synchronizes the log index with the canonical chain up to the
+// given block number. The indexed blocks which are not canonical anymore are
+// unindexed first. The process can be interrupted by closing the stop channel,
+// the progress made so far is persisted. If the canonical chain is reorged in
+// the meantime the update is aborted with errLogIndexReorged, and needs to be
+// retried to revert the index to the common ancestor.
+func UpdateLogIndex(db ethdb.Database, head uint64, stop chan struct{}) error {
+	var (
+		number, hash = rawdb.ReadLogIndexHead(db)
+		batch        = db.NewBatch()
+		start        = time.Now()
+		logged       = start.Add(-7 * time.Second)
+		reverted     int
+		indexed      int
+		reorged      bool
+	)
+	flush := func(force bool) error {
+		if !force && batch.ValueSize() < ethdb.IdealBatchSize {
+			return nil
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+	interrupted := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+	// Revert the indexed blocks which are not canonical anymore, either reorged
+	// out or above the requested head.
+	for hash != (common.Hash{}) && (number > head || rawdb.ReadCanonicalHash(db, number) != hash) {
+		if interrupted() {
+			return flush(true)
+		}
+		if number == 0 {
+			// The genesis is mismatched, nothing is left in the index.
+			rawdb.DeleteLogIndexHead(batch)
+			hash = common.Hash{}
+			break
+		}
+		// The block data is deleted if the chain was rewound, in which case
+		// the block was canonical and so are its ancestors. Its entries are
+		// left in place as the receipts are not available anymore.
+		parent := rawdb.ReadCanonicalHash(db, number-1)
+		if header := rawdb.ReadHeader(db, hash, number); header != nil {
+			unindexLogs(db, batch, header)
+			parent = header.ParentHash
+		}
+		reverted++
+
+		number, hash = number-1, parent
+		rawdb.WriteLogIndexHead(batch, number, hash)
+		if err := flush(false); err != nil {
+			return err
+		}
+	}
+	// Index the canonical blocks on top of the index head.
+	next := uint64(0)
+	if hash != (common.Hash{}) {
+		next = number + 1
+	}
+	for ; next <= head; next++ {
+		if interrupted() {
+			break
+		}
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, next), next)
+		if header == nil {
+			return fmt.Errorf("missing canonical header #%d", next)
+		}
+		// Abort if the canonical chain is being reorged concurrently, the
+		// next run will revert the index to the common ancestor.
+		if next > 0 && header.ParentHash != hash {
+			reorged = true
+			break
+		}
+		if err := indexLogs(db, batch, header); err != nil {
+			return err
+		}
+		hash = header.Hash()
+		rawdb.WriteLogIndexHead(batch, next, hash)
+		indexed++
+
+		if err := flush(false); err != nil {
+			return err
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing logs", "number", next, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := flush(true); err != nil {
+		return err
+	}
+	if reverted > 0 || indexed > 1 {
+		log.Debug("Updated log index", "reverted", reverted, "indexed", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	if reorged {
+		return errLogIndexReorged
+	}
+	return nil
+}
+
+// VerifyLogIndex checks that the log index entries of the given block are all
+// present and reference the correct log positions.
+func VerifyLogIndex(db ethdb.Database, header *types.Header) error {
+	receipts, err := readLogIndexReceipts(db, header)
+	if err != nil {
+		return err
+	}
+	for key, want := range logIndexEntries(receipts) {
+		have := rawdb.ReadLogIndex(db, []byte(key), header.Number.Uint64())
+		if !slices.Equal(have, want) {
+			return fmt.Errorf("log index mismatch, number: %d, key: %x, want: %v, have: %v", header.Number, key, want, have)
+		}
+	}
+	return nil
+}
+
+// LogIndexer is the module responsible for maintaining the log index in the
+// background, following the head of the canonical chain.
+type LogIndexer struct {
+	db     ethdb.Database
+	term   chan chan struct{}
+	closed chan struct{}
+}
+
+// NewLogIndexer initializes the log indexer and starts following the chain.
+func NewLogIndexer(chain *BlockChain) *LogIndexer {
+	indexer := &LogIndexer{
+		db:     chain.db,
+		term:   make(chan chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go indexer.loop(chain)
+	return indexer
+}
+
+// run executes the log index update in a separate thread. If the stop channel
+// is closed, the task should be terminated as soon as possible. Once the task is
+// finished, the done channel receives whether it was aborted by a reorg and thus
+// needs to be rescheduled.
+func (indexer *LogIndexer) run(head uint64, stop chan struct{}, done chan bool) {
+	err := UpdateLogIndex(indexer.db, head, stop)
+	switch {
+	case errors.Is(err, errLogIndexReorged):
+		log.Debug("Log index update aborted by reorg", "head", head)
+	case err != nil:
+		log.Error("Failed to update log index", "head", head, "err", err)
+	}
+	done <- errors.Is(err, errLogIndexReorged)
+}
+
+// loop is the scheduler of the indexer, starting a new update whenever the
+// chain head changes.
+func (indexer *LogIndexer) loop(chain *BlockChain) {
+	defer close(indexer.closed)
+
+	var (
+		stop    chan struct{} // Non-nil if background routine is active.
+		done    chan bool     // Non-nil if background routine is active.
+		head    uint64        // The latest announced chain head
+		pending bool          // Flag whether the head changed since the last update started
+
+		headCh = make(chan ChainHeadEvent)
+		sub    = chain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	launch := func() {
+		stop = make(chan struct{})
+		done = make(chan bool, 1)
+		pending = false
+		go indexer.run(head, stop, done)
+	}
+	if current := chain.CurrentBlock(); current != nil {
+		head = current.Number.Uint64()
+		launch()
+	}
+	for {
+		select {
+		case ev := <-headCh:
+			head = ev.Block.NumberU64()
+			if done == nil {
+				launch()
+			} else {
+				pending = true
+			}
+		case reorged := <-done:
+			stop = nil
+			done = nil
+			if pending || reorged {
+				launch()
+			}
+		case ch := <-indexer.term:
+			if stop != nil {
+				close(stop)
+			}
+			if done != nil {
+				log.Info("Waiting background log indexer to exit")
+				<-done
+			}
+			close(ch)
+			return
+		}
+	}
+}
+
+// Close shuts down the indexer. Safe to be called for multiple times.
+func (indexer *LogIndexer) Close() {
+	ch := make(chan struct{})
+	select {
+	case indexer.term <- ch:
+		<-ch
+	case <-indexer.closed:
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestLogIndexer tests that the log index follows the canonical chain across
+// reorgs and rewinds.
+func TestLogIndexer(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xaaaa")
+		forked   = common.HexToAddress("0xbbbb")
+
+		// CALLER PUSH1 0 PUSH1 0 LOG1 STOP, emitting a log with the caller as topic
+		code  = []byte{byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1), byte(vm.STOP)}
+		gspec = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender:   {Balance: big.NewInt(1000000000000000000)},
+				contract: {Balance: common.Big0, Code: code},
+				forked:   {Balance: common.Big0, Code: code},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+	)
+	call := func(to common.Address) func(int, *BlockGen) {
+		return func(i int, gen *BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(sender), to, common.Big0, 50000, gen.header.BaseFee, nil), signer, key)
+			gen.AddTx(tx)
+		}
+	}
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, engine, 32, call(contract))
+	forks, _ := GenerateChain(gspec.Config, blocks[15], engine, genDb, 20, call(forked))
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	// checkIndex verifies the index head, and the index entries of the blocks
+	// against the address expected to have emitted the log. The entries above
+	// the head are checked to be absent unless they are stale ones left by a
+	// chain rewind.
+	checkIndex := func(head uint64, rewound bool, emitter func(number uint64) common.Address) {
+		t.Helper()
+
+		if err := UpdateLogIndex(db, head, nil); err != nil {
+			t.Fatalf("Failed to update log index: %v", err)
+		}
+		if number, ok := LogIndexHead(db); !ok || number != head {
+			t.Fatalf("Unexpected log index head, want: %d, got: %d (%t)", head, number, ok)
+		}
+		topic := common.BytesToHash(sender.Bytes())
+		for number := uint64(1); number <= 40; number++ {
+			if rewound && number > head {
+				break
+			}
+			for _, addr := range []common.Address{contract, forked} {
+				var want []uint32
+				if number <= head && emitter(number) == addr {
+					want = []uint32{0}
+				}
+				if have := rawdb.ReadLogIndex(db, rawdb.AddressLogIndexPrefix(addr), number); len(have) != len(want) {
+					t.Fatalf("Unexpected log index of %x at block %d, want: %v, got: %v", addr, number, want, have)
+				}
+			}
+			var want []uint32
+			if number <= head {
+				want = []uint32{0}
+			}
+			if have := rawdb.ReadLogIndex(db, rawdb.TopicLogIndexPrefix(0, topic), number); len(have) != len(want) {
+				t.Fatalf("Unexpected topic index at block %d, want: %v, got: %v", number, want, have)
+			}
+			if number <= head {
+				if err := VerifyLogIndex(db, chain.GetHeaderByNumber(number)); err != nil {
+					t.Fatalf("Invalid log index: %v", err)
+				}
+			}
+		}
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("Failed to insert chain: %v", err)
+	}
+	checkIndex(32, false, func(uint64) common.Address { return contract })
+
+	// Reorg to the longer fork, the index is unusable until updated.
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("Failed to insert fork: %v", err)
+	}
+	if _, ok := LogIndexHead(db); ok {
+		t.Fatal("Reorged log index is reported as usable")
+	}
+	onFork := func(number uint64) common.Address {
+		if number <= 16 {
+			return contract
+		}
+		return forked
+	}
+	// Simulate the canonical markers being rewritten by a concurrent reorg, the
+	// update should bail out at the inconsistency and report it for retrying.
+	rawdb.WriteCanonicalHash(db, blocks[17].Hash(), 18)
+	if err := UpdateLogIndex(db, 36, nil); !errors.Is(err, errLogIndexReorged) {
+		t.Fatalf("Unexpected error, want: %v, got: %v", errLogIndexReorged, err)
+	}
+	if number, ok := LogIndexHead(db); !ok || number != 18 {
+		t.Fatalf("Unexpected log index head, want: 18, got: %d (%t)", number, ok)
+	}
+	rawdb.WriteCanonicalHash(db, forks[1].Hash(), 18)
+	checkIndex(36, false, onFork)
+
+	// Rewind the chain, the index head follows it back.
+	if err := chain.SetHead(20); err != nil {
+		t.Fatalf("Failed to rewind chain: %v", err)
+	}
+	checkIndex(20, true, onFork)
+}
@@ -0,0 +1,449 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// accountField is a bit set of the account fields accessed by a transaction.
+type accountField uint8
+
+const (
+	fieldBalance accountField = 1 << iota
+	fieldNonce
+	fieldCode
+	fieldExist // Existence of the account, also covering the loss of its storage
+)
+
+// accountOrigin is the value of an account before a transaction mutated it.
+type accountOrigin struct {
+	exist    bool
+	balance  *uint256.Int
+	nonce    uint64
+	codeHash common.Hash
+}
+
+// trackedState is a state database view which records the accounts and storage
+// slots read and written by a transaction, used to detect the conflicts between
+// transactions executed in parallel.
+//
+// Balance changes are tracked as deltas instead of reads: crediting an account
+// (such as the coinbase being paid the fees) does not depend on its balance, so
+// transactions doing so don't conflict with each other.
+type trackedState struct {
+	*state.StateDB
+
+	reads     map[common.Address]accountField
+	slotReads map[common.Address]map[common.Hash]struct{}
+
+	origins    map[common.Address]*accountOrigin
+	slotWrites map[common.Address]map[common.Hash]struct{}
+	destructs  map[common.Address]struct{} // Accounts self-destructed or resurrected
+}
+
+// newTrackedState wraps a state database into an access tracking view.
+func newTrackedState(db *state.StateDB) *trackedState {
+	return &trackedState{
+		StateDB:    db,
+		reads:      make(map[common.Address]accountField),
+		slotReads:  make(map[common.Address]map[common.Hash]struct{}),
+		origins:    make(map[common.Address]*accountOrigin),
+		slotWrites: make(map[common.Address]map[common.Hash]struct{}),
+		destructs:  make(map[common.Address]struct{}),
+	}
+}
+
+// read marks the given fields of an account as read.
+func (s *trackedState) read(addr common.Address, fields accountField) {
+	s.reads[addr] |= fields
+}
+
+// readSlot marks a storage slot as read.
+func (s *trackedState) readSlot(addr common.Address, key common.Hash) {
+	if _, ok := s.slotReads[addr]; !ok {
+		s.slotReads[addr] = make(map[common.Hash]struct{})
+	}
+	s.slotReads[addr][key] = struct{}{}
+}
+
+// touch records the original value of an account before its first mutation.
+func (s *trackedState) touch(addr common.Address) {
+	if _, ok := s.origins[addr]; ok {
+		return
+	}
+	s.origins[addr] = &accountOrigin{
+		exist:    s.StateDB.Exist(addr),
+		balance:  s.StateDB.GetBalance(addr).Clone(),
+		nonce:    s.StateDB.GetNonce(addr),
+		codeHash: s.StateDB.GetCodeHash(addr),
+	}
+}
+
+func (s *trackedState) GetBalance(addr common.Address) *uint256.Int {
+	s.read(addr, fieldBalance)
+	return s.StateDB.GetBalance(addr)
+}
+
+func (s *trackedState) GetNonce(addr common.Address) uint64 {
+	s.read(addr, fieldNonce)
+	return s.StateDB.GetNonce(addr)
+}
+
+func (s *trackedState) GetCodeHash(addr common.Address) common.Hash {
+	s.read(addr, fieldCode|fieldExist)
+	return s.StateDB.GetCodeHash(addr)
+}
+
+func (s *trackedState) GetCode(addr common.Address) []byte {
+	s.read(addr, fieldCode)
+	return s.StateDB.GetCode(addr)
+}
+
+func (s *trackedState) GetCodeSize(addr common.Address) int {
+	s.read(addr, fieldCode)
+	return s.StateDB.GetCodeSize(addr)
+}
+
+func (s *trackedState) Exist(addr common.Address) bool {
+	s.read(addr, fieldExist)
+	return s.StateDB.Exist(addr)
+}
+
+func (s *trackedState) Empty(addr common.Address) bool {
+	s.read(addr, fieldBalance|fieldNonce|fieldCode|fieldExist)
+	return s.StateDB.Empty(addr)
+}
+
+func (s *trackedState) GetState(addr common.Address, key common.Hash) common.Hash {
+	s.readSlot(addr, key)
+	return s.StateDB.GetState(addr, key)
+}
+
+func (s *trackedState) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	s.readSlot(addr, key)
+	return s.StateDB.GetCommittedState(addr, key)
+}
+
+func (s *trackedState) CreateAccount(addr common.Address) {
+	// Creating an account over an existing one carries the balance over
+	// and drops the storage.
+	if s.StateDB.Exist(addr) {
+		s.read(addr, fieldBalance)
+		s.destructs[addr] = struct{}{}
+	}
+	s.touch(addr)
+	s.StateDB.CreateAccount(addr)
+}
+
+func (s *trackedState) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	s.touch(addr)
+	s.StateDB.AddBalance(addr, amount, reason)
+}
+
+func (s *trackedState) SubBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
+	s.touch(addr)
+	s.StateDB.SubBalance(addr, amount, reason)
+}
+
+func (s *trackedState) SetNonce(addr common.Address, nonce uint64) {
+	s.touch(addr)
+	s.StateDB.SetNonce(addr, nonce)
+}
+
+func (s *trackedState) SetCode(addr common.Address, code []byte) {
+	s.touch(addr)
+	s.StateDB.SetCode(addr, code)
+}
+
+func (s *trackedState) SetState(addr common.Address, key, value common.Hash) {
+	s.touch(addr)
+	if _, ok := s.slotWrites[addr]; !ok {
+		s.slotWrites[addr] = make(map[common.Hash]struct{})
+	}
+	s.slotWrites[addr][key] = struct{}{}
+	s.StateDB.SetState(addr, key, value)
+}
+
+func (s *trackedState) SelfDestruct(addr common.Address) {
+	s.touch(addr)
+	s.destructs[addr] = struct{}{}
+	s.StateDB.SelfDestruct(addr)
+}
+
+func (s *trackedState) Selfdestruct6780(addr common.Address) {
+	s.touch(addr)
+	s.StateDB.Selfdestruct6780(addr)
+	if s.StateDB.HasSelfDestructed(addr) {
+		s.destructs[addr] = struct{}{}
+	}
+}
+
+// changes returns the fields of a mutated account which differ from their
+// original values. It must be called after the state is finalised.
+func (s *trackedState) changes(addr common.Address) accountField {
+	var (
+		origin  = s.origins[addr]
+		changed accountField
+	)
+	if s.StateDB.Exist(addr) != origin.exist {
+		changed |= fieldExist
+	}
+	if _, ok := s.destructs[addr]; ok {
+		changed |= fieldExist
+	}
+	if !s.StateDB.GetBalance(addr).Eq(origin.balance) {
+		changed |= fieldBalance
+	}
+	if s.StateDB.GetNonce(addr) != origin.nonce {
+		changed |= fieldNonce
+	}
+	if s.StateDB.GetCodeHash(addr) != origin.codeHash {
+		changed |= fieldCode
+	}
+	return changed
+}
+
+// mergeable reports whether the changes of the transaction can be replayed on
+// top of a different state. Destructing accounts, either explicitly or by the
+// removal of touched empty accounts, is not supported.
+func (s *trackedState) mergeable() bool {
+	if len(s.destructs) > 0 {
+		return false
+	}
+	for addr, origin := range s.origins {
+		if origin.exist && !s.StateDB.Exist(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply replays the changes of the transaction on the given state database.
+// Nonces, codes and storage slots are copied over, whereas the balances are
+// adjusted by the difference the transaction made.
+func (s *trackedState) apply(db *state.StateDB) {
+	for addr, origin := range s.origins {
+		// Skip the accounts which were never created or got removed as empty
+		if !s.StateDB.Exist(addr) {
+			continue
+		}
+		changed := s.changes(addr)
+		if changed&fieldBalance != 0 {
+			balance := s.StateDB.GetBalance(addr)
+			if balance.Gt(origin.balance) {
+				db.AddBalance(addr, new(uint256.Int).Sub(balance, origin.balance), tracing.BalanceChangeUnspecified)
+			} else {
+				db.SubBalance(addr, new(uint256.Int).Sub(origin.balance, balance), tracing.BalanceChangeUnspecified)
+			}
+		}
+		if changed&fieldNonce != 0 {
+			db.SetNonce(addr, s.StateDB.GetNonce(addr))
+		}
+		if changed&fieldCode != 0 {
+			db.SetCode(addr, s.StateDB.GetCode(addr))
+		}
+	}
+	for addr, keys := range s.slotWrites {
+		if !s.StateDB.Exist(addr) {
+			continue
+		}
+		for key := range keys {
+			db.SetState(addr, key, s.StateDB.GetState(addr, key))
+		}
+	}
+}
+
+// blockWrites accumulates the state changes made by the transactions of a
+// block which have already been merged.
+type blockWrites struct {
+	accounts map[common.Address]accountField
+	slots    map[common.Address]map[common.Hash]struct{}
+}
+
+func newBlockWrites() *blockWrites {
+	return &blockWrites{
+		accounts: make(map[common.Address]accountField),
+		slots:    make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+// conflicts reports whether the transaction read any state changed by the
+// transactions merged before it.
+func (w *blockWrites) conflicts(s *trackedState) bool {
+	for addr, fields := range s.reads {
+		if w.accounts[addr]&fields != 0 {
+			return true
+		}
+	}
+	for addr, keys := range s.slotReads {
+		if w.accounts[addr]&fieldExist != 0 {
+			return true
+		}
+		slots := w.slots[addr]
+		for key := range keys {
+			if _, ok := slots[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// record adds the changes made by the transaction to the set.
+func (w *blockWrites) record(s *trackedState) {
+	for addr := range s.origins {
+		w.accounts[addr] |= s.changes(addr)
+	}
+	for addr, keys := range s.slotWrites {
+		if _, ok := w.slots[addr]; !ok {
+			w.slots[addr] = make(map[common.Hash]struct{})
+		}
+		for key := range keys {
+			w.slots[addr][key] = struct{}{}
+		}
+	}
+}
+
+// speculativeTx is a transaction executed against the pre-state of the block.
+type speculativeTx struct {
+	msg    *Message
+	state  *trackedState
+	evm    *vm.EVM
+	result *ExecutionResult
+	err    error
+}
+
+// parallelizable reports whether the transactions of the block can be executed
+// in parallel. Tracing, witness collection, access list recording and the verkle
+// access events all depend on sequential execution, as do the pre-Byzantium
+// receipts carrying the intermediate state roots.
+func (p *StateProcessor) parallelizable(block *types.Block, statedb *state.StateDB, cfg vm.Config) bool {
+	return len(block.Transactions()) > 1 && cfg.Tracer == nil && statedb.Witness() == nil && statedb.BlockAccessList() == nil &&
+		p.config.IsByzantium(block.Number()) && !p.config.IsVerkle(block.Number(), block.Time())
+}
+
+// processParallel executes the transactions of the block optimistically in
+// parallel. Every transaction is first run speculatively against its own copy
+// of the pre-state, tracking the state it reads and writes. The results are
+// then merged in order: if a transaction read any state written by a preceding
+// one in the block, or made changes which can't be replayed, it's re-executed
+// on top of the merged state. The outcome is identical to sequential execution.
+func (p *StateProcessor) processParallel(block *types.Block, statedb *state.StateDB, signer types.Signer, cfg vm.Config, gp *GasPool, usedGas *uint64) (types.Receipts, []*types.Log, error) {
+	var (
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		txs         = block.Transactions()
+		specs       = make([]*speculativeTx, len(txs))
+	)
+	for i, tx := range txs {
+		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		specs[i] = &speculativeTx{msg: msg, state: newTrackedState(statedb.Copy())}
+	}
+	// Execute all transactions speculatively on the pre-state
+	var (
+		wg      sync.WaitGroup
+		tasks   = make(chan int)
+		workers = runtime.NumCPU()
+	)
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				spec := specs[i]
+				spec.state.SetTxContext(txs[i].Hash(), i)
+
+				context := NewEVMBlockContext(header, p.chain, nil)
+				spec.evm = vm.NewEVM(context, NewEVMTxContext(spec.msg), spec.state, p.config, cfg)
+				spec.result, spec.err = ApplyMessage(spec.evm, spec.msg, new(GasPool).AddGas(header.GasLimit))
+				if spec.err == nil {
+					spec.state.Finalise(true)
+				}
+			}
+		}()
+	}
+	for i := range txs {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+
+	// Merge the results in order, re-executing the conflicting transactions
+	var (
+		receipts = make(types.Receipts, 0, len(txs))
+		allLogs  []*types.Log
+		writes   = newBlockWrites()
+		vmenv    = vm.NewEVM(NewEVMBlockContext(header, p.chain, nil), vm.TxContext{}, statedb, p.config, cfg)
+	)
+	for i, tx := range txs {
+		var (
+			spec   = specs[i]
+			evm    = spec.evm
+			result = spec.result
+		)
+		statedb.SetTxContext(tx.Hash(), i)
+
+		if spec.err != nil || gp.Gas() < spec.msg.GasLimit || !spec.state.mergeable() || writes.conflicts(spec.state) {
+			tracked := newTrackedState(statedb)
+			vmenv.Reset(NewEVMTxContext(spec.msg), tracked)
+
+			var err error
+			if result, err = ApplyMessage(vmenv, spec.msg, gp); err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			statedb.Finalise(true)
+			writes.record(tracked)
+			evm = vmenv
+		} else {
+			spec.state.apply(statedb)
+			for _, log := range spec.state.GetLogs(tx.Hash(), blockNumber.Uint64(), blockHash) {
+				statedb.AddLog(log)
+			}
+			for hash, preimage := range spec.state.Preimages() {
+				statedb.AddPreimage(hash, preimage)
+			}
+			if err := gp.SubGas(result.UsedGas); err != nil {
+				return nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			statedb.Finalise(true)
+			writes.record(spec.state)
+		}
+		*usedGas += result.UsedGas
+
+		receipt := MakeReceipt(evm, result, statedb, blockNumber, blockHash, tx, *usedGas, nil)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that executing the transactions of a block in parallel yields the same
+// state and receipts as the sequential execution, both for independent and for
+// conflicting transactions.
+func TestParallelProcessing(t *testing.T) {
+	var (
+		engine   = beacon.NewFaker()
+		coinbase = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+		target   = common.HexToAddress("0x000000000000000000000000000000000000dead")
+		counter  = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		prober   = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		reverter = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+
+		keys  = make([]*ecdsa.PrivateKey, 8)
+		addrs = make([]common.Address, len(keys))
+		alloc = types.GenesisAlloc{
+			// The counter increments slot 0 and logs the new value.
+			counter: {
+				Code: []byte{
+					byte(vm.PUSH1), 0, byte(vm.SLOAD),
+					byte(vm.PUSH1), 1, byte(vm.ADD),
+					byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+					byte(vm.PUSH1), 0, byte(vm.MSTORE),
+					byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.LOG0),
+				},
+				Balance: big.NewInt(0),
+			},
+			// The prober stores the balance of the coinbase at slot 0 and the
+			// balance of the address in the calldata at slot 1.
+			prober: {
+				Code: []byte{
+					byte(vm.COINBASE), byte(vm.BALANCE), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+					byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.BALANCE), byte(vm.PUSH1), 1, byte(vm.SSTORE),
+				},
+				Balance: big.NewInt(0),
+			},
+			reverter: {
+				Code:    []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.SSTORE), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT)},
+				Balance: big.NewInt(0),
+			},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = types.Account{Balance: big.NewInt(params.Ether)}
+	}
+	gspec := &Genesis{Config: params.MergedTestChainConfig, Alloc: alloc}
+	signer := types.LatestSigner(gspec.Config)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 3, func(i int, b *BlockGen) {
+		b.SetCoinbase(coinbase)
+		gasPrice := new(big.Int).Add(b.BaseFee(), big.NewInt(params.GWei))
+
+		send := func(key *ecdsa.PrivateKey, to *common.Address, value int64, gas uint64, data []byte) {
+			tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    b.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+				To:       to,
+				Value:    big.NewInt(value),
+				Gas:      gas,
+				GasPrice: gasPrice,
+				Data:     data,
+			})
+			if err != nil {
+				t.Fatalf("failed to sign tx: %v", err)
+			}
+			b.AddTx(tx)
+		}
+		// Storage contention on the counter
+		send(keys[0], &counter, 0, 100000, nil)
+		send(keys[1], &counter, 0, 100000, nil)
+
+		// Balance dependency between a transfer and a later read
+		send(keys[2], &target, 1000, params.TxGas, nil)
+		send(keys[3], &prober, 0, 100000, common.LeftPadBytes(target.Bytes(), 32))
+
+		// Reverted storage write
+		send(keys[4], &reverter, 0, 100000, nil)
+
+		// Nonce dependency on the same sender
+		send(keys[0], &counter, 0, 100000, nil)
+
+		// Contract creation self-destructing in the same transaction
+		send(keys[5], nil, 1, 100000, []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)})
+
+		// Independent transfers
+		send(keys[6], &addrs[6], 1, params.TxGas, nil)
+		send(keys[7], &common.Address{byte(i + 1)}, 1, params.TxGas, nil)
+	})
+	// Import the chain with parallel execution, validating the state roots and
+	// the receipts against the ones of the sequentially generated blocks.
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.TrieDirtyDisabled = true
+	cacheConfig.ParallelExecution = true
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// Compare the receipts including the derived fields, which aren't covered
+	// by the block validation.
+	var (
+		sequential = NewStateProcessor(gspec.Config, chain.hc, engine)
+		parallel   = NewStateProcessor(gspec.Config, chain.hc, engine)
+	)
+	parallel.parallel = true
+
+	for _, block := range blocks {
+		parent := chain.GetHeaderByHash(block.ParentHash())
+
+		want, err := processAt(chain, sequential, parent.Root, block)
+		if err != nil {
+			t.Fatalf("block %d: sequential processing failed: %v", block.NumberU64(), err)
+		}
+		have, err := processAt(chain, parallel, parent.Root, block)
+		if err != nil {
+			t.Fatalf("block %d: parallel processing failed: %v", block.NumberU64(), err)
+		}
+		if have.GasUsed != want.GasUsed {
+			t.Errorf("block %d: gas used mismatch: have %d, want %d", block.NumberU64(), have.GasUsed, want.GasUsed)
+		}
+		if !reflect.DeepEqual(have.Receipts, want.Receipts) {
+			t.Errorf("block %d: receipts mismatch", block.NumberU64())
+		}
+	}
+}
+
+// processAt runs the processor on the block on top of the given state root.
+func processAt(chain *BlockChain, processor *StateProcessor, root common.Hash, block *types.Block) (*ProcessResult, error) {
+	statedb, err := chain.StateAt(root)
+	if err != nil {
+		return nil, err
+	}
+	return processor.Process(block, statedb, vm.Config{})
+}
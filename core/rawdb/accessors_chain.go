@@ -669,6 +669,40 @@ func DeleteReceipts(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadAccessList retrieves the access list recorded for a block. Access lists
+// are only kept in the key-value store, and are deleted once the block is moved
+// to the freezer.
+func ReadAccessList(db ethdb.KeyValueReader, hash common.Hash, number uint64) types.BlockAccessList {
+	data, _ := db.Get(blockAccessListKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var list types.BlockAccessList
+	if err := rlp.DecodeBytes(data, &list); err != nil {
+		log.Error("Invalid block access list RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return list
+}
+
+// WriteAccessList stores the access list recorded for a block.
+func WriteAccessList(db ethdb.KeyValueWriter, hash common.Hash, number uint64, list types.BlockAccessList) {
+	data, err := rlp.EncodeToBytes(list)
+	if err != nil {
+		log.Crit("Failed to encode block access list", "err", err)
+	}
+	if err := db.Put(blockAccessListKey(number, hash), data); err != nil {
+		log.Crit("Failed to store block access list", "err", err)
+	}
+}
+
+// DeleteAccessList removes the access list recorded for a block.
+func DeleteAccessList(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockAccessListKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block access list", "err", err)
+	}
+}
+
 // storedReceiptRLP is the storage encoding of a receipt.
 // Re-definition in core/types/receipt.go.
 // TODO: Re-use the existing definition.
@@ -810,6 +844,7 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteAccessList(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -819,6 +854,7 @@ func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // the hash to number mapping.
 func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteAccessList(db, hash, number)
 	deleteHeaderWithoutNumber(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -18,7 +18,9 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -179,3 +181,141 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// DeleteBloomBitsDb removes all the compressed bloom bits vectors along with
+// the progress markers of the legacy bloombits chain indexer.
+func DeleteBloomBitsDb(db ethdb.Database) error {
+	var (
+		start   = time.Now()
+		batch   = db.NewBatch()
+		deleted int
+	)
+	for _, prefix := range [][]byte{bloomBitsPrefix, BloomBitsIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if bytes.Equal(prefix, bloomBitsPrefix) && len(it.Key()) != len(bloomBitsPrefix)+2+8+common.HashLength {
+				continue
+			}
+			if err := batch.Delete(it.Key()); err != nil {
+				it.Release()
+				return err
+			}
+			deleted++
+
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Info("Deleted legacy bloombits", "items", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// ReadLogIndexHead retrieves the number and hash of the last block whose logs
+// are indexed. The returned hash is empty if nothing is indexed yet.
+func ReadLogIndexHead(db ethdb.KeyValueReader) (uint64, common.Hash) {
+	data, _ := db.Get(logIndexHeadKey)
+	if len(data) != 8+common.HashLength {
+		return 0, common.Hash{}
+	}
+	return binary.BigEndian.Uint64(data[:8]), common.BytesToHash(data[8:])
+}
+
+// WriteLogIndexHead stores the number and hash of the last block whose logs
+// are indexed.
+func WriteLogIndexHead(db ethdb.KeyValueWriter, number uint64, hash common.Hash) {
+	if err := db.Put(logIndexHeadKey, append(encodeBlockNumber(number), hash.Bytes()...)); err != nil {
+		log.Crit("Failed to store log index head", "err", err)
+	}
+}
+
+// DeleteLogIndexHead removes the log index head marker.
+func DeleteLogIndexHead(db ethdb.KeyValueWriter) {
+	if err := db.Delete(logIndexHeadKey); err != nil {
+		log.Crit("Failed to delete log index head", "err", err)
+	}
+}
+
+// encodeLogPositions packs the log positions into a byte stream.
+func encodeLogPositions(positions []uint32) []byte {
+	buf := make([]byte, 4*len(positions))
+	for i, pos := range positions {
+		binary.BigEndian.PutUint32(buf[4*i:], pos)
+	}
+	return buf
+}
+
+// decodeLogPositions unpacks the log positions from the given byte stream.
+func decodeLogPositions(blob []byte) []uint32 {
+	if len(blob)%4 != 0 {
+		log.Error("Invalid log index entry", "len", len(blob))
+		return nil
+	}
+	positions := make([]uint32, len(blob)/4)
+	for i := range positions {
+		positions[i] = binary.BigEndian.Uint32(blob[4*i:])
+	}
+	return positions
+}
+
+// ReadLogIndex retrieves the positions of the logs within the specified block
+// which are referenced by the log index with the given prefix.
+func ReadLogIndex(db ethdb.KeyValueReader, prefix []byte, number uint64) []uint32 {
+	data, _ := db.Get(logIndexKey(prefix, number))
+	if len(data) == 0 {
+		return nil
+	}
+	return decodeLogPositions(data)
+}
+
+// WriteLogIndex stores the positions of the logs within the specified block
+// which are referenced by the log index with the given prefix.
+func WriteLogIndex(db ethdb.KeyValueWriter, prefix []byte, number uint64, positions []uint32) {
+	if err := db.Put(logIndexKey(prefix, number), encodeLogPositions(positions)); err != nil {
+		log.Crit("Failed to store log index", "err", err)
+	}
+}
+
+// DeleteLogIndex removes the log positions of the specified block from the log
+// index with the given prefix.
+func DeleteLogIndex(db ethdb.KeyValueWriter, prefix []byte, number uint64) {
+	if err := db.Delete(logIndexKey(prefix, number)); err != nil {
+		log.Crit("Failed to delete log index", "err", err)
+	}
+}
+
+// IterateLogIndex iterates the entries of the log index with the given prefix
+// within the block range [from, to] in ascending order. The iteration stops if
+// the callback returns false.
+func IterateLogIndex(db ethdb.Iteratee, prefix []byte, from, to uint64, fn func(number uint64, positions []uint32) bool) error {
+	it := db.NewIterator(prefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		if !fn(number, decodeLogPositions(it.Value())) {
+			break
+		}
+	}
+	return it.Error()
+}
@@ -154,3 +154,33 @@ func TestDeleteBloomBits(t *testing.T) {
 	check(1, 1, params.MainnetGenesisHash, true)
 	check(1, 1, params.SepoliaGenesisHash, true)
 }
+
+func TestDeleteBloomBitsDb(t *testing.T) {
+	db := NewMemoryDatabase()
+	for i := uint(0); i < 2; i++ {
+		for s := uint64(0); s < 2; s++ {
+			WriteBloomBits(db, i, s, params.MainnetGenesisHash, []byte{0x01, 0x02})
+		}
+	}
+	db.Put(append(common.CopyBytes(BloomBitsIndexPrefix), []byte("count")...), []byte{0x01})
+
+	// Unrelated key sharing the bloombits prefix, must be left untouched.
+	other := append(common.CopyBytes(bloomBitsPrefix), []byte("other")...)
+	db.Put(other, []byte{0x01})
+
+	if err := DeleteBloomBitsDb(db); err != nil {
+		t.Fatalf("Failed to delete bloombits: %v", err)
+	}
+	for _, prefix := range [][]byte{bloomBitsPrefix, BloomBitsIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if !bytes.Equal(it.Key(), other) {
+				t.Fatalf("Bloombits item not removed: %x", it.Key())
+			}
+		}
+		it.Release()
+	}
+	if ok, _ := db.Has(other); !ok {
+		t.Fatal("Unrelated item removed")
+	}
+}
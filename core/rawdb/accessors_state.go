@@ -160,6 +160,49 @@ func DeleteTrieJournal(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadStateHistoryIndexMetadata retrieves the metadata of state history indexing.
+func ReadStateHistoryIndexMetadata(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(stateHistoryIndexKey)
+	return data
+}
+
+// WriteStateHistoryIndexMetadata stores the metadata of state history indexing.
+func WriteStateHistoryIndexMetadata(db ethdb.KeyValueWriter, blob []byte) {
+	if err := db.Put(stateHistoryIndexKey, blob); err != nil {
+		log.Crit("Failed to store the state history index metadata", "err", err)
+	}
+}
+
+// DeleteStateHistoryIndexMetadata removes the metadata of state history indexing.
+func DeleteStateHistoryIndexMetadata(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateHistoryIndexKey); err != nil {
+		log.Crit("Failed to delete the state history index metadata", "err", err)
+	}
+}
+
+// ReadStateHistoryIndexBlock retrieves the state history index block with the
+// given index prefix and the id of the last history contained in it.
+func ReadStateHistoryIndexBlock(db ethdb.KeyValueReader, prefix []byte, last uint64) []byte {
+	data, _ := db.Get(stateHistoryIndexBlockKey(prefix, last))
+	return data
+}
+
+// WriteStateHistoryIndexBlock stores the state history index block with the
+// given index prefix and the id of the last history contained in it.
+func WriteStateHistoryIndexBlock(db ethdb.KeyValueWriter, prefix []byte, last uint64, blob []byte) {
+	if err := db.Put(stateHistoryIndexBlockKey(prefix, last), blob); err != nil {
+		log.Crit("Failed to store state history index block", "err", err)
+	}
+}
+
+// DeleteStateHistoryIndexBlock removes the state history index block with the
+// given index prefix and the id of the last history contained in it.
+func DeleteStateHistoryIndexBlock(db ethdb.KeyValueWriter, prefix []byte, last uint64) {
+	if err := db.Delete(stateHistoryIndexBlockKey(prefix, last)); err != nil {
+		log.Crit("Failed to delete state history index block", "err", err)
+	}
+}
+
 // ReadStateHistoryMeta retrieves the metadata corresponding to the specified
 // state history. Compute the position of state history in freezer by minus
 // one since the id of first state history starts from one(zero for initial
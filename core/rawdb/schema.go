@@ -76,6 +76,12 @@ var (
 	// trieJournalKey tracks the in-memory trie node layers across restarts.
 	trieJournalKey = []byte("TrieJournal")
 
+	// stateHistoryIndexKey tracks the indexing progress of state histories.
+	stateHistoryIndexKey = []byte("StateHistoryIndex")
+
+	// logIndexHeadKey tracks the last block whose logs are indexed.
+	logIndexHeadKey = []byte("LogIndexHead")
+
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
@@ -105,6 +111,8 @@ var (
 	blockBodyPrefix     = []byte("b") // blockBodyPrefix + num (uint64 big endian) + hash -> block body
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
+	blockAccessListPrefix = []byte("x") // blockAccessListPrefix + num (uint64 big endian) + hash -> block access list
+
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
@@ -117,6 +125,23 @@ var (
 	trieNodeStoragePrefix = []byte("O") // trieNodeStoragePrefix + accountHash + hexPath -> trie node
 	stateIDPrefix         = []byte("L") // stateIDPrefix + state root -> state id
 
+	// State history indexing within path-based storage scheme.
+	StateHistoryIndexPrefix           = []byte("m")  // StateHistoryIndexPrefix + ... -> history indexes
+	StateHistoryAccountIndexPrefix    = []byte("ma") // StateHistoryAccountIndexPrefix + address + last id -> account history index block
+	StateHistoryStorageIndexPrefix    = []byte("ms") // StateHistoryStorageIndexPrefix + address + slot hash + last id -> storage history index block
+	StateHistoryIncompleteIndexPrefix = []byte("mi") // StateHistoryIncompleteIndexPrefix + address + last id -> incomplete storage history index block
+
+	LogIndexPrefix        = []byte("g")  // LogIndexPrefix + ... -> log indexes
+	LogIndexAddressPrefix = []byte("ga") // LogIndexAddressPrefix + address + num (uint64 big endian) -> log positions
+	LogIndexTopicPrefix   = []byte("gt") // LogIndexTopicPrefix + topic position + topic + num (uint64 big endian) -> log positions
+
+	// VerklePrefix is the database prefix for Verkle trie data, which includes:
+	// (a) Trie nodes
+	// (b) In-memory trie node journal
+	// (c) Persistent state ID
+	// (d) Snap sync status
+	VerklePrefix = []byte("v")
+
 	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
 	genesisPrefix  = []byte("ethereum-genesis-") // genesis state prefix for the db
@@ -192,6 +217,11 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// blockAccessListKey = blockAccessListPrefix + num (uint64 big endian) + hash
+func blockAccessListKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockAccessListPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -265,6 +295,43 @@ func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
 }
 
+// AccountHistoryIndexPrefix = StateHistoryAccountIndexPrefix + address
+func AccountHistoryIndexPrefix(address common.Address) []byte {
+	return append(common.CopyBytes(StateHistoryAccountIndexPrefix), address.Bytes()...)
+}
+
+// StorageHistoryIndexPrefix = StateHistoryStorageIndexPrefix + address + slot hash
+func StorageHistoryIndexPrefix(address common.Address, slotHash common.Hash) []byte {
+	key := append(common.CopyBytes(StateHistoryStorageIndexPrefix), address.Bytes()...)
+	return append(key, slotHash.Bytes()...)
+}
+
+// IncompleteHistoryIndexPrefix = StateHistoryIncompleteIndexPrefix + address
+func IncompleteHistoryIndexPrefix(address common.Address) []byte {
+	return append(common.CopyBytes(StateHistoryIncompleteIndexPrefix), address.Bytes()...)
+}
+
+// stateHistoryIndexBlockKey = index prefix + last id (uint64 big endian)
+func stateHistoryIndexBlockKey(prefix []byte, last uint64) []byte {
+	return append(common.CopyBytes(prefix), encodeBlockNumber(last)...)
+}
+
+// AddressLogIndexPrefix = LogIndexAddressPrefix + address
+func AddressLogIndexPrefix(address common.Address) []byte {
+	return append(common.CopyBytes(LogIndexAddressPrefix), address.Bytes()...)
+}
+
+// TopicLogIndexPrefix = LogIndexTopicPrefix + topic position + topic
+func TopicLogIndexPrefix(position int, topic common.Hash) []byte {
+	key := append(common.CopyBytes(LogIndexTopicPrefix), byte(position))
+	return append(key, topic.Bytes()...)
+}
+
+// logIndexKey = log index prefix + num (uint64 big endian)
+func logIndexKey(prefix []byte, number uint64) []byte {
+	return append(common.CopyBytes(prefix), encodeBlockNumber(number)...)
+}
+
 // accountTrieNodeKey = trieNodeAccountPrefix + nodePath.
 func accountTrieNodeKey(path []byte) []byte {
 	return append(trieNodeAccountPrefix, path...)
@@ -0,0 +1,319 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie/utils"
+	"github.com/holiman/uint256"
+)
+
+// mode specifies how a tree location has been accessed
+// for the byte value:
+// * the first bit is set if the branch has been read
+// * the second bit is set if the branch has been edited
+type mode byte
+
+const (
+	AccessWitnessReadFlag  = mode(1)
+	AccessWitnessWriteFlag = mode(2)
+)
+
+var zeroTreeIndex uint256.Int
+
+// leafReader retrieves the values of verkle tree leaves, returning nil for the
+// leaves which are empty.
+type leafReader interface {
+	GetLeaf(key []byte) ([]byte, error)
+}
+
+// AccessEvents lists the locations of the state that are being accessed
+// during the production of a block, as defined by EIP-4762. The first
+// access of each branch (stem) and chunk (leaf) is charged.
+type AccessEvents struct {
+	branches map[branchAccessKey]mode
+	chunks   map[chunkAccessKey]mode
+
+	pointCache *utils.PointCache
+	reader     leafReader // Pre-state for charging chunk fills, nil to skip them
+}
+
+// NewAccessEvents creates an empty set of access events, the point cache
+// is used for deriving the tree keys of the accessed locations.
+func NewAccessEvents(pointCache *utils.PointCache) *AccessEvents {
+	return &AccessEvents{
+		branches:   make(map[branchAccessKey]mode),
+		chunks:     make(map[chunkAccessKey]mode),
+		pointCache: pointCache,
+	}
+}
+
+// Merge is used to merge the access events that were generated during the
+// execution of a tx, with the accumulation of all access events that were
+// generated during the execution of all txs preceding this one in a block.
+func (ae *AccessEvents) Merge(other *AccessEvents) {
+	for k := range other.branches {
+		ae.branches[k] |= other.branches[k]
+	}
+	for k, chunk := range other.chunks {
+		ae.chunks[k] |= chunk
+	}
+}
+
+// Keys returns, predictably, the list of keys that were touched during the
+// buildup of the access witness.
+func (ae *AccessEvents) Keys() [][]byte {
+	// TODO: consider if parallelizing this is worth it, probably depending on len(ae.chunks).
+	keys := make([][]byte, 0, len(ae.chunks))
+	for chunk := range ae.chunks {
+		basePoint := ae.pointCache.Get(chunk.addr[:])
+		key := utils.GetTreeKeyWithEvaluatedAddress(basePoint, &chunk.treeIndex, chunk.leafKey)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Copy returns a deep copy of the access events.
+func (ae *AccessEvents) Copy() *AccessEvents {
+	cpy := NewAccessEvents(ae.pointCache)
+	cpy.reader = ae.reader
+	cpy.Merge(ae)
+	return cpy
+}
+
+// AddAccount returns the gas to be charged for each of the currently cold
+// member fields of an account.
+func (ae *AccessEvents) AddAccount(addr common.Address, isWrite bool) uint64 {
+	var gas uint64
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.VersionLeafKey, isWrite)
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.BalanceLeafKey, isWrite)
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.NonceLeafKey, isWrite)
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.CodeKeccakLeafKey, isWrite)
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.CodeSizeLeafKey, isWrite)
+	return gas
+}
+
+// MessageCallGas returns the gas to be charged for each of the currently
+// cold member fields of an account, that need to be touched when making a message
+// call to that account.
+func (ae *AccessEvents) MessageCallGas(destination common.Address) uint64 {
+	var gas uint64
+	gas += ae.touchAddressAndChargeGas(destination, zeroTreeIndex, utils.VersionLeafKey, false)
+	gas += ae.touchAddressAndChargeGas(destination, zeroTreeIndex, utils.CodeSizeLeafKey, false)
+	return gas
+}
+
+// ValueTransferGas returns the gas to be charged for each of the currently
+// cold balance member fields of the caller and the callee accounts.
+func (ae *AccessEvents) ValueTransferGas(callerAddr, targetAddr common.Address) uint64 {
+	var gas uint64
+	gas += ae.touchAddressAndChargeGas(callerAddr, zeroTreeIndex, utils.BalanceLeafKey, true)
+	gas += ae.touchAddressAndChargeGas(targetAddr, zeroTreeIndex, utils.BalanceLeafKey, true)
+	return gas
+}
+
+// ContractCreateInitGas returns the access gas costs for the initialization of
+// a contract creation.
+func (ae *AccessEvents) ContractCreateInitGas(addr common.Address, createSendsValue bool) uint64 {
+	var gas uint64
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.VersionLeafKey, true)
+	gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.NonceLeafKey, true)
+	if createSendsValue {
+		gas += ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.BalanceLeafKey, true)
+	}
+	return gas
+}
+
+// AddTxOrigin adds the member fields of the sender account to the access event list,
+// so that cold accesses are not charged, since they are covered by the 21000 gas.
+func (ae *AccessEvents) AddTxOrigin(originAddr common.Address) {
+	for _, leafKey := range []byte{utils.VersionLeafKey, utils.BalanceLeafKey, utils.NonceLeafKey, utils.CodeKeccakLeafKey, utils.CodeSizeLeafKey} {
+		ae.touchAddressAndChargeGas(originAddr, zeroTreeIndex, leafKey, leafKey == utils.BalanceLeafKey || leafKey == utils.NonceLeafKey)
+	}
+}
+
+// AddTxDestination adds the member fields of the destination account to the access event list,
+// so that cold accesses are not charged, since they are covered by the 21000 gas.
+func (ae *AccessEvents) AddTxDestination(addr common.Address, sendsValue bool) {
+	for _, leafKey := range []byte{utils.VersionLeafKey, utils.BalanceLeafKey, utils.NonceLeafKey, utils.CodeKeccakLeafKey, utils.CodeSizeLeafKey} {
+		ae.touchAddressAndChargeGas(addr, zeroTreeIndex, leafKey, sendsValue && leafKey == utils.BalanceLeafKey)
+	}
+}
+
+// SlotGas returns the amount of gas to be charged for a cold storage access.
+func (ae *AccessEvents) SlotGas(addr common.Address, slot common.Hash, isWrite bool) uint64 {
+	treeIndex, subIndex := utils.StorageIndex(slot.Bytes())
+	return ae.touchAddressAndChargeGas(addr, *treeIndex, subIndex, isWrite)
+}
+
+// touchAddressAndChargeGas adds any missing access event to the access event list, and returns the cold
+// access cost to be charged, if need be.
+func (ae *AccessEvents) touchAddressAndChargeGas(addr common.Address, treeIndex uint256.Int, subIndex byte, isWrite bool) uint64 {
+	stemRead, selectorRead, stemWrite, selectorWrite, selectorFill := ae.touchAddress(addr, treeIndex, subIndex, isWrite)
+
+	var gas uint64
+	if stemRead {
+		gas += params.WitnessBranchReadCost
+	}
+	if selectorRead {
+		gas += params.WitnessChunkReadCost
+	}
+	if stemWrite {
+		gas += params.WitnessBranchWriteCost
+	}
+	if selectorWrite {
+		gas += params.WitnessChunkWriteCost
+	}
+	if selectorFill {
+		gas += params.WitnessChunkFillCost
+	}
+	return gas
+}
+
+// touchAddress adds any missing access event to the access event list.
+func (ae *AccessEvents) touchAddress(addr common.Address, treeIndex uint256.Int, subIndex byte, isWrite bool) (bool, bool, bool, bool, bool) {
+	branchKey := newBranchAccessKey(addr, treeIndex)
+	chunkKey := newChunkAccessKey(branchKey, subIndex)
+
+	// Read access.
+	var branchRead, chunkRead bool
+	if _, hasStem := ae.branches[branchKey]; !hasStem {
+		branchRead = true
+		ae.branches[branchKey] = AccessWitnessReadFlag
+	}
+	if _, hasSelector := ae.chunks[chunkKey]; !hasSelector {
+		chunkRead = true
+		ae.chunks[chunkKey] = AccessWitnessReadFlag
+	}
+
+	// Write access.
+	var branchWrite, chunkWrite, chunkFill bool
+	if isWrite {
+		if (ae.branches[branchKey] & AccessWitnessWriteFlag) == 0 {
+			branchWrite = true
+			ae.branches[branchKey] |= AccessWitnessWriteFlag
+		}
+		chunkValue := ae.chunks[chunkKey]
+		if (chunkValue & AccessWitnessWriteFlag) == 0 {
+			chunkWrite = true
+			chunkFill = ae.isEmptyLeaf(chunkKey)
+			ae.chunks[chunkKey] |= AccessWitnessWriteFlag
+		}
+	}
+	return branchRead, chunkRead, branchWrite, chunkWrite, chunkFill
+}
+
+// isEmptyLeaf reports whether the leaf is empty in the pre-state, in which case
+// the first write to it is charged the chunk filling cost. Leaves which can't be
+// resolved are reported as filled, the failed read surfaces in the state
+// database when the leaf is accessed through it.
+func (ae *AccessEvents) isEmptyLeaf(chunk chunkAccessKey) bool {
+	if ae.reader == nil {
+		return false
+	}
+	basePoint := ae.pointCache.Get(chunk.addr[:])
+	value, err := ae.reader.GetLeaf(utils.GetTreeKeyWithEvaluatedAddress(basePoint, &chunk.treeIndex, chunk.leafKey))
+	return err == nil && value == nil
+}
+
+type branchAccessKey struct {
+	addr      common.Address
+	treeIndex uint256.Int
+}
+
+func newBranchAccessKey(addr common.Address, treeIndex uint256.Int) branchAccessKey {
+	var sk branchAccessKey
+	sk.addr = addr
+	sk.treeIndex = treeIndex
+	return sk
+}
+
+type chunkAccessKey struct {
+	branchAccessKey
+	leafKey byte
+}
+
+func newChunkAccessKey(branchKey branchAccessKey, leafKey byte) chunkAccessKey {
+	var lk chunkAccessKey
+	lk.branchAccessKey = branchKey
+	lk.leafKey = leafKey
+	return lk
+}
+
+// CodeChunksRangeGas is a helper function to touch every chunk in a code range and charge witness gas costs
+func (ae *AccessEvents) CodeChunksRangeGas(contractAddr common.Address, startPC, size uint64, codeLen uint64, isWrite bool) uint64 {
+	// note that in the case where the copied code is outside the range of the
+	// contract code but touches the last leaf with contract code in it,
+	// we don't include the last leaf of code in the AccessWitness. The
+	// reason that we do not need the last leaf is the account's code size
+	// is already in the AccessWitness so a stateless verifier can see that
+	// the code from the last leaf is not needed.
+	if size == 0 || startPC >= codeLen {
+		return 0
+	}
+	endPC := startPC + size
+	if endPC > codeLen {
+		endPC = codeLen
+	}
+	if endPC > 0 {
+		endPC -= 1 // endPC is the last bytecode that will be touched.
+	}
+	var statelessGasCharged uint64
+	for chunkNumber := startPC / 31; chunkNumber <= endPC/31; chunkNumber++ {
+		treeIndex := *uint256.NewInt((chunkNumber + 128) / 256)
+		subIndex := byte((chunkNumber + 128) % 256)
+		gas := ae.touchAddressAndChargeGas(contractAddr, treeIndex, subIndex, isWrite)
+		var overflow bool
+		statelessGasCharged, overflow = math.SafeAdd(statelessGasCharged, gas)
+		if overflow {
+			panic("overflow when adding gas")
+		}
+	}
+	return statelessGasCharged
+}
+
+// VersionGas adds the account's version to the accessed data, and returns the
+// amount of gas that it costs.
+func (ae *AccessEvents) VersionGas(addr common.Address, isWrite bool) uint64 {
+	return ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.VersionLeafKey, isWrite)
+}
+
+// BalanceGas adds the account's balance to the accessed data, and returns the
+// amount of gas that it costs.
+func (ae *AccessEvents) BalanceGas(addr common.Address, isWrite bool) uint64 {
+	return ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.BalanceLeafKey, isWrite)
+}
+
+// NonceGas adds the account's nonce to the accessed data, and returns the
+// amount of gas that it costs.
+func (ae *AccessEvents) NonceGas(addr common.Address, isWrite bool) uint64 {
+	return ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.NonceLeafKey, isWrite)
+}
+
+// CodeSizeGas adds the account's code size to the accessed data, and returns the
+// amount of gas that it costs.
+func (ae *AccessEvents) CodeSizeGas(addr common.Address, isWrite bool) uint64 {
+	return ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.CodeSizeLeafKey, isWrite)
+}
+
+// CodeHashGas adds the account's code hash to the accessed data, and returns the
+// amount of gas that it costs.
+func (ae *AccessEvents) CodeHashGas(addr common.Address, isWrite bool) uint64 {
+	return ae.touchAddressAndChargeGas(addr, zeroTreeIndex, utils.CodeKeccakLeafKey, isWrite)
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the first write to a leaf which is empty in the state is charged
+// the chunk filling cost, while writes to existing leaves are not.
+func TestAccessEventsChunkFill(t *testing.T) {
+	var (
+		addr   = common.HexToAddress("0x01")
+		filled = common.BigToHash(common.Big1)
+		empty  = common.BigToHash(common.Big2)
+		db     = NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &triedb.Config{IsVerkle: true, PathDB: pathdb.Defaults})
+	)
+	state, _ := New(types.EmptyVerkleHash, db, nil)
+	state.SetBalance(addr, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	state.SetState(addr, filled, common.Hash{0x2a})
+	root, err := state.Commit(0, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, err = New(root, db, nil)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	var (
+		ae       = state.NewAccessEvents()
+		coldSlot = params.WitnessBranchReadCost + params.WitnessChunkReadCost + params.WitnessBranchWriteCost + params.WitnessChunkWriteCost
+	)
+	if gas := ae.SlotGas(addr, filled, true); gas != coldSlot {
+		t.Errorf("write to existing slot: gas %d, want %d", gas, coldSlot)
+	}
+	// The slots share the same branch, only the chunk costs are charged.
+	want := params.WitnessChunkReadCost + params.WitnessChunkWriteCost + params.WitnessChunkFillCost
+	if gas := ae.SlotGas(addr, empty, true); gas != want {
+		t.Errorf("write to empty slot: gas %d, want %d", gas, want)
+	}
+	if gas := ae.SlotGas(addr, empty, true); gas != 0 {
+		t.Errorf("repeated write to empty slot: gas %d, want 0", gas)
+	}
+	// Reads of empty leaves aren't charged the filling cost.
+	if gas := ae.SlotGas(addr, common.BigToHash(common.Big3), false); gas != params.WitnessChunkReadCost {
+		t.Errorf("read of empty slot: gas %d, want %d", gas, params.WitnessChunkReadCost)
+	}
+	// The filling cost is not charged again by copies of the events.
+	if gas := ae.Copy().SlotGas(addr, empty, true); gas != 0 {
+		t.Errorf("write to empty slot in copy: gas %d, want 0", gas)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// accountAccess is the state of an account accessed by the running transaction.
+type accountAccess struct {
+	reads  map[common.Hash]struct{}
+	writes map[common.Hash]common.Hash
+	post   *types.AccountPost
+}
+
+// accessRecorder collects the accounts and storage slots accessed by each
+// transaction of a block, along with the values they were modified to.
+//
+// Accesses are only recorded between the start of a transaction, marked by
+// setting the transaction context, and its finalisation. Everything accessed
+// outside of transactions (system calls, block rewards) is ignored.
+type accessRecorder struct {
+	tx       int // Index of the running transaction, -1 if none
+	accounts map[common.Address]*accountAccess
+	list     types.BlockAccessList
+}
+
+// newAccessRecorder creates an idle access recorder.
+func newAccessRecorder() *accessRecorder {
+	return &accessRecorder{tx: -1, list: make(types.BlockAccessList, 0)}
+}
+
+// begin starts recording the accesses of a transaction, discarding anything
+// recorded for a previous, unfinished one.
+func (r *accessRecorder) begin(tx int) {
+	r.tx = tx
+	r.accounts = make(map[common.Address]*accountAccess)
+}
+
+// account returns the access record of an account, or nil if no transaction
+// is running.
+func (r *accessRecorder) account(addr common.Address) *accountAccess {
+	if r.tx < 0 {
+		return nil
+	}
+	acc, ok := r.accounts[addr]
+	if !ok {
+		acc = &accountAccess{
+			reads:  make(map[common.Hash]struct{}),
+			writes: make(map[common.Hash]common.Hash),
+		}
+		r.accounts[addr] = acc
+	}
+	return acc
+}
+
+// readAccount records the access of an account.
+func (r *accessRecorder) readAccount(addr common.Address) {
+	r.account(addr)
+}
+
+// readSlot records the read of a storage slot.
+func (r *accessRecorder) readSlot(addr common.Address, slot common.Hash) {
+	if acc := r.account(addr); acc != nil {
+		acc.reads[slot] = struct{}{}
+	}
+}
+
+// writeAccount records the post-transaction value of a modified account, along
+// with the slots written. A nil object denotes a deleted account.
+func (r *accessRecorder) writeAccount(addr common.Address, obj *stateObject) {
+	acc := r.account(addr)
+	if acc == nil {
+		return
+	}
+	if obj == nil {
+		acc.post = &types.AccountPost{Balance: new(big.Int)}
+		acc.writes = make(map[common.Hash]common.Hash)
+		return
+	}
+	acc.post = &types.AccountPost{
+		Balance:  obj.Balance().ToBig(),
+		Nonce:    obj.Nonce(),
+		CodeHash: common.BytesToHash(obj.CodeHash()),
+	}
+	for slot, value := range obj.dirtyStorage {
+		acc.writes[slot] = value
+	}
+}
+
+// end finishes recording the running transaction, adding its accesses to the
+// block access list.
+func (r *accessRecorder) end() {
+	if r.tx < 0 {
+		return
+	}
+	entry := &types.TxAccessList{
+		Index:    uint64(r.tx),
+		Accounts: make([]*types.AccountAccess, 0, len(r.accounts)),
+	}
+	for addr, acc := range r.accounts {
+		access := &types.AccountAccess{
+			Address:       addr,
+			StorageReads:  make([]common.Hash, 0, len(acc.reads)),
+			StorageWrites: make([]types.StorageWrite, 0, len(acc.writes)),
+			Post:          acc.post,
+		}
+		for slot := range acc.reads {
+			access.StorageReads = append(access.StorageReads, slot)
+		}
+		sort.Slice(access.StorageReads, func(i, j int) bool {
+			return bytes.Compare(access.StorageReads[i][:], access.StorageReads[j][:]) < 0
+		})
+		for slot, value := range acc.writes {
+			access.StorageWrites = append(access.StorageWrites, types.StorageWrite{Slot: slot, Value: value})
+		}
+		sort.Slice(access.StorageWrites, func(i, j int) bool {
+			return bytes.Compare(access.StorageWrites[i].Slot[:], access.StorageWrites[j].Slot[:]) < 0
+		})
+		entry.Accounts = append(entry.Accounts, access)
+	}
+	sort.Slice(entry.Accounts, func(i, j int) bool {
+		return bytes.Compare(entry.Accounts[i].Address[:], entry.Accounts[j].Address[:]) < 0
+	})
+	r.list = append(r.list, entry)
+	r.tx, r.accounts = -1, nil
+}
+
+// copy returns a deep copy of the recorder.
+func (r *accessRecorder) copy() *accessRecorder {
+	cpy := &accessRecorder{
+		tx:   r.tx,
+		list: make(types.BlockAccessList, len(r.list)),
+	}
+	copy(cpy.list, r.list) // Finished entries are never modified
+	if r.accounts != nil {
+		cpy.accounts = make(map[common.Address]*accountAccess, len(r.accounts))
+		for addr, acc := range r.accounts {
+			entry := &accountAccess{
+				reads:  make(map[common.Hash]struct{}, len(acc.reads)),
+				writes: make(map[common.Hash]common.Hash, len(acc.writes)),
+				post:   acc.post,
+			}
+			for slot := range acc.reads {
+				entry.reads[slot] = struct{}{}
+			}
+			for slot, value := range acc.writes {
+				entry.writes[slot] = value
+			}
+			cpy.accounts[addr] = entry
+		}
+	}
+	return cpy
+}
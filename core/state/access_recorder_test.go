@@ -0,0 +1,129 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// Tests that the state accessed by each transaction is recorded along with the
+// post-values of the modifications, ignoring accesses outside of transactions.
+func TestAccessRecording(t *testing.T) {
+	var (
+		sender   = common.HexToAddress("0x01")
+		contract = common.HexToAddress("0x02")
+		system   = common.HexToAddress("0x03")
+		receiver = common.HexToAddress("0x04")
+
+		slotA = common.HexToHash("0xa")
+		slotB = common.HexToHash("0xb")
+	)
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(types.EmptyRootHash, db, nil)
+	state.SetBalance(sender, uint256.NewInt(100), tracing.BalanceChangeUnspecified)
+	state.SetBalance(contract, uint256.NewInt(1), tracing.BalanceChangeUnspecified)
+	state.SetState(contract, slotA, common.HexToHash("0x1"))
+	root, _ := state.Commit(0, false)
+
+	state, _ = New(root, db, nil)
+	state.StartAccessRecording()
+
+	// Accesses before the first transaction are not recorded
+	state.GetBalance(system)
+	state.SetNonce(system, 1)
+	state.Finalise(true)
+
+	state.SetTxContext(common.Hash{0x1}, 0)
+	state.GetBalance(sender)
+	state.SubBalance(sender, uint256.NewInt(10), tracing.BalanceChangeUnspecified)
+	state.AddBalance(receiver, uint256.NewInt(10), tracing.BalanceChangeUnspecified)
+	state.GetState(contract, slotA)
+	state.SetState(contract, slotB, common.HexToHash("0x2"))
+	state.Finalise(true)
+
+	// Accesses between transactions are not recorded either
+	state.GetNonce(system)
+
+	state.SetTxContext(common.Hash{0x2}, 1)
+	state.GetCommittedState(contract, slotB)
+	state.SelfDestruct(contract)
+	state.Finalise(true)
+
+	want := types.BlockAccessList{
+		{
+			Index: 0,
+			Accounts: []*types.AccountAccess{
+				{
+					Address:       sender,
+					StorageReads:  []common.Hash{},
+					StorageWrites: []types.StorageWrite{},
+					Post:          &types.AccountPost{Balance: big.NewInt(90), CodeHash: types.EmptyCodeHash},
+				},
+				{
+					Address:       contract,
+					StorageReads:  []common.Hash{slotA},
+					StorageWrites: []types.StorageWrite{{Slot: slotB, Value: common.HexToHash("0x2")}},
+					Post:          &types.AccountPost{Balance: big.NewInt(1), CodeHash: types.EmptyCodeHash},
+				},
+				{
+					Address:       receiver,
+					StorageReads:  []common.Hash{},
+					StorageWrites: []types.StorageWrite{},
+					Post:          &types.AccountPost{Balance: big.NewInt(10), CodeHash: types.EmptyCodeHash},
+				},
+			},
+		},
+		{
+			Index: 1,
+			Accounts: []*types.AccountAccess{
+				{
+					Address:       contract,
+					StorageReads:  []common.Hash{slotB},
+					StorageWrites: []types.StorageWrite{},
+					Post:          &types.AccountPost{Balance: new(big.Int)},
+				},
+			},
+		},
+	}
+	have, _ := json.Marshal(state.BlockAccessList())
+	exp, _ := json.Marshal(want)
+	if !bytes.Equal(have, exp) {
+		t.Fatalf("access list mismatch:\nhave %s\nwant %s", have, exp)
+	}
+	// Ensure the access list survives a storage round trip
+	blob, err := rlp.EncodeToBytes(state.BlockAccessList())
+	if err != nil {
+		t.Fatalf("failed to encode access list: %v", err)
+	}
+	var dec types.BlockAccessList
+	if err := rlp.DecodeBytes(blob, &dec); err != nil {
+		t.Fatalf("failed to decode access list: %v", err)
+	}
+	if enc, _ := json.Marshal(dec); !bytes.Equal(enc, exp) {
+		t.Fatalf("decoded access list mismatch:\nhave %s\nwant %s", enc, exp)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+// errHistoricReadOnly is returned if a mutation is attempted on the historic
+// state, which is only available for reading.
+var errHistoricReadOnly = errors.New("historic state is read-only")
+
+// historicDB is a state database for accessing the historical states which are
+// no longer available in the trie database. The states are resolved from the
+// indexed state histories, which is only supported in path-based scheme.
+type historicDB struct {
+	Database
+}
+
+// NewHistoricDatabase creates a state database for accessing historical states
+// on top of the given one. Contract codes are resolved by the wrapped database.
+func NewHistoricDatabase(db Database) Database {
+	return &historicDB{Database: db}
+}
+
+// OpenTrie opens the main account trie at a specific historical root hash.
+func (db *historicDB) OpenTrie(root common.Hash) (Trie, error) {
+	reader, err := db.TrieDB().HistoricReader(root)
+	if err != nil {
+		return nil, err
+	}
+	return &historicTrie{db: db, reader: reader, root: root}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account at a specific historical
+// state root.
+func (db *historicDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	owner, ok := self.(*historicTrie)
+	if !ok {
+		return nil, fmt.Errorf("unexpected account trie %T", self)
+	}
+	return &historicTrie{db: db, reader: owner.reader, root: root, owner: &address}, nil
+}
+
+// CopyTrie returns an independent copy of the given trie.
+func (db *historicDB) CopyTrie(t Trie) Trie {
+	switch t := t.(type) {
+	case *historicTrie:
+		return t.copy()
+	default:
+		panic(fmt.Errorf("unknown trie type %T", t))
+	}
+}
+
+// historicTrie is a read-only trie for accessing the account or storage data
+// at a historical state. The items modified since the requested state are
+// resolved from the state histories, the others are read from the state of
+// the persistent disk layer.
+type historicTrie struct {
+	db     *historicDB
+	reader *pathdb.HistoricalStateReader
+	root   common.Hash     // The root hash of the trie at the historical state
+	owner  *common.Address // The address of the owner, nil for account trie
+
+	// The trie opened at the disk state for resolving the unmodified items,
+	// it's lazily initialized.
+	disk Trie
+}
+
+// copy returns an independent copy of the trie.
+func (t *historicTrie) copy() *historicTrie {
+	return &historicTrie{
+		db:     t.db,
+		reader: t.reader,
+		root:   t.root,
+		owner:  t.owner,
+	}
+}
+
+// diskTrie returns the trie at the disk state, which is either the account
+// trie or the storage trie of the owner.
+func (t *historicTrie) diskTrie() (Trie, error) {
+	if t.disk != nil {
+		return t.disk, nil
+	}
+	diskRoot := t.reader.DiskRoot()
+	tr, err := trie.NewStateTrie(trie.StateTrieID(diskRoot), t.db.TrieDB())
+	if err != nil {
+		return nil, err
+	}
+	if t.owner != nil {
+		acct, err := tr.GetAccount(*t.owner)
+		if err != nil {
+			return nil, err
+		}
+		root := types.EmptyRootHash
+		if acct != nil {
+			root = acct.Root
+		}
+		id := trie.StorageTrieID(diskRoot, crypto.Keccak256Hash(t.owner.Bytes()), root)
+		if tr, err = trie.NewStateTrie(id, t.db.TrieDB()); err != nil {
+			return nil, err
+		}
+	}
+	t.disk = tr
+	return t.disk, nil
+}
+
+// GetKey returns the sha3 preimage of a hashed key, which is not supported.
+func (t *historicTrie) GetKey([]byte) []byte {
+	return nil
+}
+
+// GetAccount retrieves the account with provided address at the historical
+// state. If the specified account is not existent, nil will be returned.
+func (t *historicTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	blob, found, err := t.reader.Account(address)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		tr, err := t.diskTrie()
+		if err != nil {
+			return nil, err
+		}
+		return tr.GetAccount(address)
+	}
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	return types.FullAccount(blob)
+}
+
+// GetStorage retrieves the storage slot with provided key at the historical
+// state. If the specified slot is not existent, nil will be returned.
+func (t *historicTrie) GetStorage(addr common.Address, key []byte) ([]byte, error) {
+	blob, found, err := t.reader.Storage(addr, crypto.Keccak256Hash(key))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		tr, err := t.diskTrie()
+		if err != nil {
+			return nil, err
+		}
+		return tr.GetStorage(addr, key)
+	}
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	_, content, _, err := rlp.Split(blob)
+	return content, err
+}
+
+// UpdateAccount implements Trie, historic state can't be mutated.
+func (t *historicTrie) UpdateAccount(address common.Address, account *types.StateAccount) error {
+	return errHistoricReadOnly
+}
+
+// UpdateStorage implements Trie, historic state can't be mutated.
+func (t *historicTrie) UpdateStorage(addr common.Address, key, value []byte) error {
+	return errHistoricReadOnly
+}
+
+// DeleteAccount implements Trie, historic state can't be mutated.
+func (t *historicTrie) DeleteAccount(address common.Address) error {
+	return errHistoricReadOnly
+}
+
+// DeleteStorage implements Trie, historic state can't be mutated.
+func (t *historicTrie) DeleteStorage(addr common.Address, key []byte) error {
+	return errHistoricReadOnly
+}
+
+// UpdateContractCode implements Trie, historic state can't be mutated.
+func (t *historicTrie) UpdateContractCode(address common.Address, codeHash common.Hash, code []byte) error {
+	return errHistoricReadOnly
+}
+
+// Hash returns the root hash of the trie at the historical state.
+func (t *historicTrie) Hash() common.Hash {
+	return t.root
+}
+
+// Commit implements Trie, historic state can't be mutated.
+func (t *historicTrie) Commit(collectLeaf bool) (common.Hash, *trienode.NodeSet, error) {
+	return common.Hash{}, nil, errHistoricReadOnly
+}
+
+// NodeIterator implements Trie, iteration is not supported on historic state.
+func (t *historicTrie) NodeIterator(startKey []byte) (trie.NodeIterator, error) {
+	return nil, errors.New("node iteration is not supported on historic state")
+}
+
+// Prove implements Trie, proofs are not supported on historic state.
+func (t *historicTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
+	return errors.New("proof is not supported on historic state")
+}
+
+// Witness implements Trie, historic state is served from the state histories
+// rather than trie nodes, so there is nothing to witness.
+func (t *historicTrie) Witness() map[string]struct{} {
+	return nil
+}
@@ -19,12 +19,15 @@ package state
 
 import (
 	"fmt"
+	"math/big"
 	"sort"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
@@ -111,6 +114,14 @@ type StateDB struct {
 	// Transient storage
 	transientStorage transientStorage
 
+	// Witness collector, recording all state and code accessed during the
+	// execution. Nil if no witness is being collected.
+	witness *stateless.Witness
+
+	// Access recorder, collecting the state accessed by each transaction of
+	// the block. Nil if no access list is being recorded.
+	accesses *accessRecorder
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -136,6 +147,9 @@ type StateDB struct {
 	AccountDeleted int
 	StorageDeleted int
 
+	// State change hooks invoked during block processing, nil if disabled
+	logger tracing.StateLogger
+
 	// Testing hooks
 	onCommit func(states *triestate.Set) // Hook invoked when commit is performed
 }
@@ -180,7 +194,7 @@ func (s *StateDB) StartPrefetcher(namespace string) {
 		s.prefetcher.close()
 		s.prefetcher = nil
 	}
-	if s.snap != nil {
+	if s.snap != nil && s.witness == nil {
 		s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, namespace)
 	}
 }
@@ -194,6 +208,35 @@ func (s *StateDB) StopPrefetcher() {
 	}
 }
 
+// StartWitness attaches a witness collector to the state, recording all trie
+// nodes and codes accessed from now on. Snapshot reads are bypassed while the
+// witness is collected, so that every touched trie node gets resolved. Any
+// running prefetcher is terminated as its tries are not witnessed.
+func (s *StateDB) StartWitness(witness *stateless.Witness) {
+	s.StopPrefetcher()
+	s.witness = witness
+}
+
+// Witness retrieves the current state witness being collected.
+func (s *StateDB) Witness() *stateless.Witness {
+	return s.witness
+}
+
+// StartAccessRecording enables recording the accounts and storage slots accessed
+// by each subsequently executed transaction, along with their post-values.
+func (s *StateDB) StartAccessRecording() {
+	s.accesses = newAccessRecorder()
+}
+
+// BlockAccessList retrieves the state accessed by the transactions finalised
+// since the recording started, or nil if no access list is being recorded.
+func (s *StateDB) BlockAccessList() types.BlockAccessList {
+	if s.accesses == nil {
+		return nil
+	}
+	return s.accesses.list
+}
+
 // setError remembers the first non-nil error it is called with.
 func (s *StateDB) setError(err error) {
 	if s.dbErr == nil {
@@ -214,6 +257,10 @@ func (s *StateDB) AddLog(log *types.Log) {
 	log.Index = s.logSize
 	s.logs[s.thash] = append(s.logs[s.thash], log)
 	s.logSize++
+
+	if s.logger != nil {
+		s.logger.OnLog(log)
+	}
 }
 
 // GetLogs returns the logs matching the specified transaction hash, and annotates
@@ -339,6 +386,9 @@ func (s *StateDB) GetCodeHash(addr common.Address) common.Hash {
 
 // GetState retrieves a value from the given account's storage trie.
 func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accesses != nil {
+		s.accesses.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(hash)
@@ -348,6 +398,9 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	if s.accesses != nil {
+		s.accesses.readSlot(addr, hash)
+	}
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(hash)
@@ -373,25 +426,25 @@ func (s *StateDB) HasSelfDestructed(addr common.Address) bool {
  */
 
 // AddBalance adds amount to the account associated with addr.
-func (s *StateDB) AddBalance(addr common.Address, amount *uint256.Int) {
+func (s *StateDB) AddBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
 	stateObject := s.getOrNewStateObject(addr)
 	if stateObject != nil {
-		stateObject.AddBalance(amount)
+		stateObject.AddBalance(amount, reason)
 	}
 }
 
 // SubBalance subtracts amount from the account associated with addr.
-func (s *StateDB) SubBalance(addr common.Address, amount *uint256.Int) {
+func (s *StateDB) SubBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
 	stateObject := s.getOrNewStateObject(addr)
 	if stateObject != nil {
-		stateObject.SubBalance(amount)
+		stateObject.SubBalance(amount, reason)
 	}
 }
 
-func (s *StateDB) SetBalance(addr common.Address, amount *uint256.Int) {
+func (s *StateDB) SetBalance(addr common.Address, amount *uint256.Int, reason tracing.BalanceChangeReason) {
 	stateObject := s.getOrNewStateObject(addr)
 	if stateObject != nil {
-		stateObject.SetBalance(amount)
+		stateObject.SetBalance(amount, reason)
 	}
 }
 
@@ -447,13 +500,20 @@ func (s *StateDB) SelfDestruct(addr common.Address) {
 	if stateObject == nil {
 		return
 	}
+	var (
+		prev = new(uint256.Int).Set(stateObject.Balance())
+		n    = new(uint256.Int)
+	)
 	s.journal.append(selfDestructChange{
 		account:     &addr,
 		prev:        stateObject.selfDestructed,
-		prevbalance: new(uint256.Int).Set(stateObject.Balance()),
+		prevbalance: prev,
 	})
+	if s.logger != nil && !prev.IsZero() {
+		s.logger.OnBalanceChange(addr, prev.ToBig(), n.ToBig(), tracing.BalanceDecreaseSelfdestruct)
+	}
 	stateObject.markSelfdestructed()
-	stateObject.data.Balance = new(uint256.Int)
+	stateObject.data.Balance = n
 }
 
 func (s *StateDB) Selfdestruct6780(addr common.Address) {
@@ -547,6 +607,9 @@ func (s *StateDB) deleteStateObject(obj *stateObject) {
 // the object is not found or was deleted in this execution context. If you need
 // to differentiate between non-existent/just-deleted, use getDeletedStateObject.
 func (s *StateDB) getStateObject(addr common.Address) *stateObject {
+	if s.accesses != nil {
+		s.accesses.readAccount(addr)
+	}
 	if obj := s.getDeletedStateObject(addr); obj != nil && !obj.deleted {
 		return obj
 	}
@@ -562,9 +625,10 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	if obj := s.stateObjects[addr]; obj != nil {
 		return obj
 	}
-	// If no live objects are available, attempt to use snapshots
+	// If no live objects are available, attempt to use snapshots. They are
+	// skipped if a witness is collected, since the trie nodes are needed.
 	var data *types.StateAccount
-	if s.snap != nil {
+	if s.snap != nil && s.witness == nil {
 		start := time.Now()
 		acc, err := s.snap.Account(crypto.HashData(s.hasher, addr.Bytes()))
 		if metrics.EnabledExpensive {
@@ -628,6 +692,12 @@ func (s *StateDB) getOrNewStateObject(addr common.Address) *stateObject {
 func (s *StateDB) createObject(addr common.Address) (newobj, prev *stateObject) {
 	prev = s.getDeletedStateObject(addr) // Note, prev might have been deleted, we need that!
 	newobj = newObject(s, addr, nil)
+
+	// The storage trie of the original account is dropped along with it,
+	// collect the nodes it has resolved so far.
+	if s.witness != nil && prev != nil && prev.trie != nil {
+		s.witness.AddState(prev.trie.Witness())
+	}
 	if prev == nil {
 		s.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -712,6 +782,12 @@ func (s *StateDB) Copy() *StateDB {
 		snaps: s.snaps,
 		snap:  s.snap,
 	}
+	if s.witness != nil {
+		state.witness = s.witness.Copy()
+	}
+	if s.accesses != nil {
+		state.accesses = s.accesses.copy()
+	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range s.journal.dirties {
 		// As documented [here](https://github.com/ethereum/go-ethereum/pull/16485#issuecomment-380438527),
@@ -833,6 +909,14 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		}
 		if obj.selfDestructed || (deleteEmptyObjects && obj.empty()) {
 			obj.deleted = true
+			if s.accesses != nil {
+				s.accesses.writeAccount(addr, nil)
+			}
+
+			// If ether was sent to account post-selfdestruct it is burnt.
+			if bal := obj.Balance(); s.logger != nil && obj.selfDestructed && !bal.IsZero() {
+				s.logger.OnBalanceChange(obj.address, bal.ToBig(), new(big.Int), tracing.BalanceDecreaseSelfdestructBurn)
+			}
 
 			// We need to maintain account deletions explicitly (will remain
 			// set indefinitely). Note only the first occurred self-destruct
@@ -848,6 +932,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 			delete(s.accountsOrigin, obj.address) // Clear out any previously updated account data (may be recreated via a resurrect)
 			delete(s.storagesOrigin, obj.address) // Clear out any previously updated storage data (may be recreated via a resurrect)
 		} else {
+			if s.accesses != nil {
+				s.accesses.writeAccount(addr, obj)
+			}
 			obj.finalise(true) // Prefetch slots in the background
 		}
 		obj.created = false
@@ -862,6 +949,9 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	if s.prefetcher != nil && len(addressesToPrefetch) > 0 {
 		s.prefetcher.prefetch(common.Hash{}, s.originalRoot, common.Address{}, addressesToPrefetch)
 	}
+	if s.accesses != nil {
+		s.accesses.end()
+	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
 }
@@ -922,6 +1012,16 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	if len(s.stateObjectsPending) > 0 {
 		s.stateObjectsPending = make(map[common.Address]struct{})
 	}
+	// All the trie reads and updates of the block are done, gather the nodes
+	// resolved from the account and storage tries into the witness.
+	if s.witness != nil {
+		s.witness.AddState(s.trie.Witness())
+		for _, obj := range s.stateObjects {
+			if obj.trie != nil {
+				s.witness.AddState(obj.trie.Witness())
+			}
+		}
+	}
 	// Track the amount of time wasted on hashing the account trie
 	if metrics.EnabledExpensive {
 		defer func(start time.Time) { s.AccountHashes += time.Since(start) }(time.Now())
@@ -929,12 +1029,21 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	return s.trie.Hash()
 }
 
+// SetLogger sets the logger which is notified of the state changes made
+// while processing a block. A nil logger disables the notifications.
+func (s *StateDB) SetLogger(l tracing.StateLogger) {
+	s.logger = l
+}
+
 // SetTxContext sets the current transaction hash and index which are
 // used when the EVM emits new state logs. It should be invoked before
 // transaction execution.
 func (s *StateDB) SetTxContext(thash common.Hash, ti int) {
 	s.thash = thash
 	s.txIndex = ti
+	if s.accesses != nil {
+		s.accesses.begin(ti)
+	}
 }
 
 func (s *StateDB) clearJournalAndRefund() {
@@ -1120,7 +1229,16 @@ func (s *StateDB) handleDestruction(nodes *trienode.MergedNodeSet) (map[common.A
 		s.accountsOrigin[addr] = types.SlimAccountRLP(*prev) // case (c) or (d)
 
 		// Short circuit if the storage was empty.
-		if prev.Root == types.EmptyRootHash {
+		//
+		// Verkle doesn't track per-account storage roots, so the slots of a
+		// destructed account can't be enumerated. There's nothing to wipe
+		// though: verkle activates after Cancun, where SELFDESTRUCT only
+		// deletes accounts created in the same transaction (EIP-6780), whose
+		// storage never reached the tree. The accounts destructed by being
+		// overwritten with a contract have no code and a zero nonce, which
+		// since EIP-161 also means no storage (except for the few accounts
+		// left from before, see EIP-7610).
+		if prev.Root == types.EmptyRootHash || s.db.TrieDB().IsVerkle() {
 			continue
 		}
 		// Remove storage slots belong to the account.
@@ -1374,6 +1492,17 @@ func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addre
 	return s.accessList.Contains(addr, slot)
 }
 
+// NewAccessEvents creates an empty set of access events for a transaction. The
+// leaves written for the first time are charged the chunk filling cost if they
+// are empty in the state tree.
+func (s *StateDB) NewAccessEvents() *AccessEvents {
+	ae := NewAccessEvents(s.db.PointCache())
+	if reader, ok := s.trie.(leafReader); ok {
+		ae.reader = reader
+	}
+	return ae
+}
+
 // convertAccountSet converts a provided account set from address keyed to hash keyed.
 func (s *StateDB) convertAccountSet(set map[common.Address]*types.StateAccount) map[common.Hash]struct{} {
 	ret := make(map[common.Hash]struct{}, len(set))
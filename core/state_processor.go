@@ -37,15 +37,17 @@ import (
 // StateProcessor implements Processor.
 type StateProcessor struct {
 	config *params.ChainConfig // Chain configuration options
-	bc     *BlockChain         // Canonical block chain
+	chain  *HeaderChain        // Canonical header chain
 	engine consensus.Engine    // Consensus engine used for block rewards
+
+	parallel bool // Whether to execute the transactions optimistically in parallel
 }
 
 // NewStateProcessor initialises a new StateProcessor.
-func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine) *StateProcessor {
+func NewStateProcessor(config *params.ChainConfig, chain *HeaderChain, engine consensus.Engine) *StateProcessor {
 	return &StateProcessor{
 		config: config,
-		bc:     bc,
+		chain:  chain,
 		engine: engine,
 	}
 }
@@ -57,7 +59,7 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 // Process returns the receipts and logs accumulated during the process and
 // returns the amount of gas that was used in the process. If any of the
 // transactions failed to execute due to insufficient gas it will return an error.
-func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*ProcessResult, error) {
 	var (
 		receipts    types.Receipts
 		usedGas     = new(uint64)
@@ -72,43 +74,74 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		misc.ApplyDAOHardFork(statedb)
 	}
 	var (
-		context = NewEVMBlockContext(header, p.bc, nil)
+		context = NewEVMBlockContext(header, p.chain, nil)
 		vmenv   = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
 		signer  = types.MakeSigner(p.config, header.Number, header.Time)
 	)
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
 	}
-	// Iterate over and process the individual transactions
-	for i, tx := range block.Transactions() {
-		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
+	// Iterate over and process the individual transactions, optimistically in
+	// parallel if enabled and supported by the block
+	if p.parallel && p.parallelizable(block, statedb, cfg) {
+		var err error
+		if receipts, allLogs, err = p.processParallel(block, statedb, signer, cfg, gp, usedGas); err != nil {
+			return nil, err
 		}
-		statedb.SetTxContext(tx.Hash(), i)
-		receipt, err := applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+	} else {
+		for i, tx := range block.Transactions() {
+			msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+			if err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			statedb.SetTxContext(tx.Hash(), i)
+			receipt, err := applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+			if err != nil {
+				return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			receipts = append(receipts, receipt)
+			allLogs = append(allLogs, receipt.Logs...)
 		}
-		receipts = append(receipts, receipt)
-		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Fail if Shanghai not enabled and len(withdrawals) is non-zero.
 	withdrawals := block.Withdrawals()
 	if len(withdrawals) > 0 && !p.config.IsShanghai(block.Number(), block.Time()) {
-		return nil, nil, 0, errors.New("withdrawals before shanghai")
+		return nil, errors.New("withdrawals before shanghai")
+	}
+	// Collect the execution layer requests if Prague is enabled.
+	var requests [][]byte
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		var err error
+		if requests, err = ProcessRequests(allLogs, vmenv, statedb); err != nil {
+			return nil, err
+		}
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), withdrawals)
+	p.engine.Finalize(p.chain, header, statedb, block.Transactions(), block.Uncles(), withdrawals)
 
-	return receipts, allLogs, *usedGas, nil
+	return &ProcessResult{
+		Receipts: receipts,
+		Requests: requests,
+		Logs:     allLogs,
+		GasUsed:  *usedGas,
+	}, nil
 }
 
-func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (*types.Receipt, error) {
+func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas *uint64, evm *vm.EVM) (receipt *types.Receipt, err error) {
 	// Create a new context to be used in the EVM environment.
 	txContext := NewEVMTxContext(msg)
 	evm.Reset(txContext, statedb)
 
+	if logger, ok := evm.Config.Tracer.(BlockchainLogger); ok {
+		logger.OnTxStart(evm, tx, msg.From)
+		defer func() {
+			logger.OnTxEnd(receipt, err)
+		}()
+	}
+
 	// Apply the transaction to the current state (included in the env).
 	result, err := ApplyMessage(evm, msg, gp)
 	if err != nil {
@@ -124,9 +157,14 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	}
 	*usedGas += result.UsedGas
 
+	return MakeReceipt(evm, result, statedb, blockNumber, blockHash, tx, *usedGas, root), nil
+}
+
+// MakeReceipt generates the receipt object for a transaction given its execution result.
+func MakeReceipt(evm *vm.EVM, result *ExecutionResult, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas uint64, root []byte) *types.Receipt {
 	// Create a new receipt for the transaction, storing the intermediate root and gas used
 	// by the tx.
-	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: *usedGas}
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: usedGas}
 	if result.Failed() {
 		receipt.Status = types.ReceiptStatusFailed
 	} else {
@@ -141,7 +179,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	}
 
 	// If the transaction created a contract, store the creation address in the receipt.
-	if msg.To == nil {
+	if tx.To() == nil {
 		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
 	}
 
@@ -151,7 +189,7 @@ func applyTransaction(msg *Message, config *params.ChainConfig, gp *GasPool, sta
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, err
+	return receipt
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
@@ -173,6 +211,10 @@ func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *commo
 // ProcessBeaconBlockRoot applies the EIP-4788 system call to the beacon block root
 // contract. This method is exported to be used in tests.
 func ProcessBeaconBlockRoot(beaconRoot common.Hash, vmenv *vm.EVM, statedb *state.StateDB) {
+	if logger, ok := vmenv.Config.Tracer.(BlockchainLogger); ok {
+		logger.OnBeaconBlockRootStart(beaconRoot)
+		defer logger.OnBeaconBlockRootEnd()
+	}
 	// If EIP-4788 is enabled, we need to invoke the beaconroot storage contract with
 	// the new root
 	msg := &Message{
@@ -189,3 +231,97 @@ func ProcessBeaconBlockRoot(beaconRoot common.Hash, vmenv *vm.EVM, statedb *stat
 	_, _, _ = vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
 	statedb.Finalise(true)
 }
+
+// ProcessParentBlockHash stores the parent block hash in the history storage
+// contract as per EIP-2935. This method is exported to be used in tests.
+func ProcessParentBlockHash(prevHash common.Hash, vmenv *vm.EVM, statedb *state.StateDB) {
+	msg := &Message{
+		From:      params.SystemAddress,
+		GasLimit:  30_000_000,
+		GasPrice:  common.Big0,
+		GasFeeCap: common.Big0,
+		GasTipCap: common.Big0,
+		To:        &params.HistoryStorageAddress,
+		Data:      prevHash.Bytes(),
+	}
+	vmenv.Reset(NewEVMTxContext(msg), statedb)
+	statedb.AddAddressToAccessList(params.HistoryStorageAddress)
+	_, _, _ = vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
+	statedb.Finalise(true)
+}
+
+// ProcessRequests collects the EIP-7685 execution layer requests of a block:
+// the deposits emitted by the deposit contract in the given logs, followed by
+// the withdrawal and consolidation requests dequeued from their system
+// contracts. Only request types with at least one request are included.
+func ProcessRequests(logs []*types.Log, vmenv *vm.EVM, statedb *state.StateDB) ([][]byte, error) {
+	requests := [][]byte{}
+
+	// EIP-6110 deposits
+	if err := ParseDepositLogs(&requests, logs, vmenv.ChainConfig()); err != nil {
+		return nil, err
+	}
+	// EIP-7002 withdrawals
+	if err := ProcessWithdrawalQueue(&requests, vmenv, statedb); err != nil {
+		return nil, err
+	}
+	// EIP-7251 consolidations
+	if err := ProcessConsolidationQueue(&requests, vmenv, statedb); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ParseDepositLogs extracts the EIP-6110 deposit requests from the logs emitted
+// by the beacon chain deposit contract, and appends them to the requests list.
+func ParseDepositLogs(requests *[][]byte, logs []*types.Log, config *params.ChainConfig) error {
+	deposits := []byte{types.DepositRequestType}
+	for _, log := range logs {
+		if log.Address != config.DepositContractAddress || len(log.Topics) == 0 || log.Topics[0] != types.DepositEventSignature {
+			continue
+		}
+		request, err := types.DepositLogToRequest(log.Data)
+		if err != nil {
+			return fmt.Errorf("unable to parse deposit data: %v", err)
+		}
+		deposits = append(deposits, request...)
+	}
+	if len(deposits) > 1 {
+		*requests = append(*requests, deposits)
+	}
+	return nil
+}
+
+// ProcessWithdrawalQueue calls the EIP-7002 withdrawal queue contract, and
+// appends the dequeued withdrawal requests to the requests list.
+func ProcessWithdrawalQueue(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB) error {
+	return processRequestsSystemCall(requests, vmenv, statedb, types.WithdrawalRequestType, params.WithdrawalQueueAddress)
+}
+
+// ProcessConsolidationQueue calls the EIP-7251 consolidation queue contract,
+// and appends the dequeued consolidation requests to the requests list.
+func ProcessConsolidationQueue(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB) error {
+	return processRequestsSystemCall(requests, vmenv, statedb, types.ConsolidationRequestType, params.ConsolidationQueueAddress)
+}
+
+func processRequestsSystemCall(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB, requestType byte, addr common.Address) error {
+	msg := &Message{
+		From:      params.SystemAddress,
+		GasLimit:  30_000_000,
+		GasPrice:  common.Big0,
+		GasFeeCap: common.Big0,
+		GasTipCap: common.Big0,
+		To:        &addr,
+	}
+	vmenv.Reset(NewEVMTxContext(msg), statedb)
+	statedb.AddAddressToAccessList(addr)
+	ret, _, err := vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
+	statedb.Finalise(true)
+	if err != nil {
+		return fmt.Errorf("system call to %x failed: %v", addr, err)
+	}
+	if len(ret) > 0 {
+		*requests = append(*requests, append([]byte{requestType}, ret...))
+	}
+	return nil
+}
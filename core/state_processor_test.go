@@ -18,6 +18,7 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -422,3 +423,131 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	}
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// TestProcessVerkle tests that blocks can be generated and imported on top of
+// a verkle genesis, with the state transitions being committed to the verkle
+// tree.
+func TestProcessVerkle(t *testing.T) {
+	var (
+		verkleTime = uint64(0)
+		config     = &params.ChainConfig{
+			ChainID:                       big.NewInt(1),
+			HomesteadBlock:                big.NewInt(0),
+			EIP150Block:                   big.NewInt(0),
+			EIP155Block:                   big.NewInt(0),
+			EIP158Block:                   big.NewInt(0),
+			ByzantiumBlock:                big.NewInt(0),
+			ConstantinopleBlock:           big.NewInt(0),
+			PetersburgBlock:               big.NewInt(0),
+			IstanbulBlock:                 big.NewInt(0),
+			MuirGlacierBlock:              big.NewInt(0),
+			BerlinBlock:                   big.NewInt(0),
+			LondonBlock:                   big.NewInt(0),
+			ArrowGlacierBlock:             big.NewInt(0),
+			GrayGlacierBlock:              big.NewInt(0),
+			ShanghaiTime:                  &verkleTime,
+			CancunTime:                    &verkleTime,
+			PragueTime:                    &verkleTime,
+			VerkleTime:                    &verkleTime,
+			TerminalTotalDifficulty:       big.NewInt(0),
+			TerminalTotalDifficultyPassed: true,
+		}
+		signer    = types.LatestSigner(config)
+		key, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		gspec     = &Genesis{
+			Config: config,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		engine = beacon.NewFaker()
+		// Initcode storing 0x2a at slot 0 and deploying no code.
+		initcode = common.FromHex("602a60005500")
+	)
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 2, func(i int, b *BlockGen) {
+		var tx *types.Transaction
+		switch i {
+		case 0:
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(addr), recipient, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		case 1:
+			tx, _ = types.SignTx(types.NewContractCreation(b.TxNonce(addr), big.NewInt(0), 200000, b.BaseFee(), initcode), signer, key)
+		}
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if !chain.TrieDB().IsVerkle() {
+		t.Fatal("expected verkle trie database")
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to open head state: %v", err)
+	}
+	if have := statedb.GetBalance(recipient); have.Cmp(uint256.NewInt(1000)) != 0 {
+		t.Fatalf("recipient balance mismatch: have %v, want 1000", have)
+	}
+	contract := crypto.CreateAddress(addr, 1)
+	if have := statedb.GetState(contract, common.Hash{}); have != common.BigToHash(big.NewInt(0x2a)) {
+		t.Fatalf("contract storage mismatch: have %x, want 0x2a", have)
+	}
+}
+
+// TestVerkleSetHead tests that rewinding a verkle chain below the persisted
+// state is rejected, as the verkle state can't be rolled back.
+func TestVerkleSetHead(t *testing.T) {
+	var (
+		verkleTime = uint64(0)
+		config     = *params.MergedTestChainConfig
+		key, _     = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr       = crypto.PubkeyToAddress(key.PublicKey)
+		recipient  = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine     = beacon.NewFaker()
+	)
+	config.PragueTime = &verkleTime
+	config.VerkleTime = &verkleTime
+	var (
+		signer = types.LatestSigner(&config)
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+	)
+	// Produce enough blocks for the genesis state to be flattened away.
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 130, func(i int, b *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), recipient, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	if err := chain.SetHead(1); !errors.Is(err, errVerkleRewind) {
+		t.Fatalf("unexpected error: have %v, want %v", err, errVerkleRewind)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 130 {
+		t.Fatalf("chain head changed: have %d, want 130", head)
+	}
+	// Rewinding within the in-memory layers is still possible.
+	if err := chain.SetHead(120); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if head := chain.CurrentBlock().Number.Uint64(); head != 120 {
+		t.Fatalf("chain head mismatch: have %d, want 120", head)
+	}
+}
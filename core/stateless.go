@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// ExecuteStateless runs a stateless execution of a block based on a witness:
+// the pre-state trie nodes, codes and ancestor headers needed to process it.
+// The block is verified against the post-state root, receipt root, gas usage,
+// bloom and requests, but the header itself is not, that remains the duty of
+// the caller.
+func ExecuteStateless(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *stateless.Witness) error {
+	// Sanity check that the witness was built for the block's parent
+	if len(witness.Headers) == 0 {
+		return errors.New("witness has no parent header")
+	}
+	if parent := witness.Headers[0]; parent.Hash() != block.ParentHash() {
+		return fmt.Errorf("witness parent mismatch: have %x, want %x", parent.Hash(), block.ParentHash())
+	}
+	// Create and populate the state database to serve as the stateless backend
+	memdb := witness.MakeHashDB()
+	statedb, err := state.New(witness.Root(), state.NewDatabaseWithConfig(memdb, triedb.HashDefaults), nil)
+	if err != nil {
+		return err
+	}
+	// Create a header chain that is idle, but can be used to access the headers
+	// through BLOCKHASH and the consensus engine.
+	chain := &HeaderChain{
+		config:      config,
+		chainDb:     memdb,
+		headerCache: lru.NewCache[common.Hash, *types.Header](headerCacheLimit),
+		tdCache:     lru.NewCache[common.Hash, *big.Int](tdCacheLimit),
+		numberCache: lru.NewCache[common.Hash, uint64](numberCacheLimit),
+		engine:      engine,
+	}
+	chain.currentHeader.Store(witness.Headers[0])
+
+	// Run the stateless block processing and validate the outcome.
+	res, err := NewStateProcessor(config, chain, engine).Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	if err := NewBlockValidator(config, nil, engine).ValidateState(block, statedb, res); err != nil {
+		return err
+	}
+	// Trie nodes missing from the witness are read as empty, which doesn't always
+	// change the state root. The state database records the failed reads though.
+	return statedb.Error()
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ExtWitness is a witness RLP and JSON encoding for transferring across clients.
+// The codes and trie nodes are sorted to make the encoding deterministic.
+type ExtWitness struct {
+	Headers []*types.Header `json:"headers"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []hexutil.Bytes `json:"state"`
+}
+
+// ToExtWitness converts our internal witness representation to the consensus one.
+func (w *Witness) ToExtWitness() *ExtWitness {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ext := &ExtWitness{
+		Headers: make([]*types.Header, len(w.Headers)),
+		Codes:   sortedBlobs(w.Codes),
+		State:   sortedBlobs(w.State),
+	}
+	copy(ext.Headers, w.Headers)
+	return ext
+}
+
+// FromExtWitness converts the consensus witness format into our internal one.
+func (w *Witness) FromExtWitness(ext *ExtWitness) error {
+	if len(ext.Headers) == 0 {
+		return errors.New("witness without parent header")
+	}
+	w.Headers = ext.Headers
+
+	w.Codes = make(map[string]struct{}, len(ext.Codes))
+	for _, code := range ext.Codes {
+		w.Codes[string(code)] = struct{}{}
+	}
+	w.State = make(map[string]struct{}, len(ext.State))
+	for _, node := range ext.State {
+		w.State[string(node)] = struct{}{}
+	}
+	return nil
+}
+
+// EncodeRLP serializes a witness as RLP.
+func (w *Witness) EncodeRLP(wr io.Writer) error {
+	return rlp.Encode(wr, w.ToExtWitness())
+}
+
+// DecodeRLP decodes a witness from RLP.
+func (w *Witness) DecodeRLP(s *rlp.Stream) error {
+	var ext ExtWitness
+	if err := s.Decode(&ext); err != nil {
+		return err
+	}
+	return w.FromExtWitness(&ext)
+}
+
+// sortedBlobs returns the members of a blob set in lexicographic order.
+func sortedBlobs(set map[string]struct{}) []hexutil.Bytes {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	blobs := make([]hexutil.Bytes, len(keys))
+	for i, key := range keys {
+		blobs[i] = []byte(key)
+	}
+	return blobs
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that blocks can be executed statelessly using the witness collected
+// while processing them on top of the full state, and that an incomplete
+// witness is rejected.
+func TestStatelessExecution(t *testing.T) {
+	var (
+		engine  = beacon.NewFaker()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		counter = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		gspec   = &Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+				// The counter increments slot 0 and stores BLOCKHASH(NUMBER-2) at slot 1.
+				counter: {
+					Code: []byte{
+						byte(vm.PUSH1), 0, byte(vm.SLOAD),
+						byte(vm.PUSH1), 1, byte(vm.ADD),
+						byte(vm.PUSH1), 0, byte(vm.SSTORE),
+						byte(vm.NUMBER), byte(vm.PUSH1), 2, byte(vm.SWAP1), byte(vm.SUB),
+						byte(vm.BLOCKHASH),
+						byte(vm.PUSH1), 1, byte(vm.SSTORE),
+					},
+					Storage: map[common.Hash]common.Hash{{}: common.BigToHash(big.NewInt(1))},
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	// Blocks are generated and imported one by one, as the chain is needed to
+	// resolve the BLOCKHASH queries of the later blocks.
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.TrieDirtyDisabled = true
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var blocks []*types.Block
+	for i := 0; i < 4; i++ {
+		head := chain.GetBlockByHash(chain.CurrentBlock().Hash())
+		generated, _ := GenerateChain(gspec.Config, head, engine, chain.db, 1, func(_ int, b *BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), counter, big.NewInt(0), 100000, b.BaseFee(), nil), signer, key)
+			b.AddTxWithChain(chain, tx)
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTxWithChain(chain, tx)
+		})
+		if _, err := chain.InsertChain(generated); err != nil {
+			t.Fatalf("block %d: failed to insert into chain: %v", i+1, err)
+		}
+		blocks = append(blocks, generated...)
+	}
+	for _, block := range blocks {
+		witness, err := stateless.NewWitness(block.Header(), chain)
+		if err != nil {
+			t.Fatalf("block %d: failed to create witness: %v", block.NumberU64(), err)
+		}
+		parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+		statedb, err := chain.StateAt(parent.Root)
+		if err != nil {
+			t.Fatalf("block %d: failed to open parent state: %v", block.NumberU64(), err)
+		}
+		statedb.StartWitness(witness)
+
+		res, err := chain.Processor().Process(block, statedb, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: failed to process block: %v", block.NumberU64(), err)
+		}
+		if err := chain.Validator().ValidateState(block, statedb, res); err != nil {
+			t.Fatalf("block %d: failed to validate state: %v", block.NumberU64(), err)
+		}
+		if len(witness.Codes) != 1 {
+			t.Errorf("block %d: witness code count mismatch: have %d, want 1", block.NumberU64(), len(witness.Codes))
+		}
+		if block.NumberU64() > 2 && len(witness.Headers) < 2 {
+			t.Errorf("block %d: witness is missing the BLOCKHASH ancestors", block.NumberU64())
+		}
+		// Transfer the witness through its RLP encoding and execute statelessly
+		blob, err := rlp.EncodeToBytes(witness)
+		if err != nil {
+			t.Fatalf("block %d: failed to encode witness: %v", block.NumberU64(), err)
+		}
+		var decoded stateless.Witness
+		if err := rlp.DecodeBytes(blob, &decoded); err != nil {
+			t.Fatalf("block %d: failed to decode witness: %v", block.NumberU64(), err)
+		}
+		if err := ExecuteStateless(gspec.Config, engine, block, &decoded); err != nil {
+			t.Fatalf("block %d: stateless execution failed: %v", block.NumberU64(), err)
+		}
+		// Drop a trie node from the witness and ensure the execution fails
+		for node := range decoded.State {
+			delete(decoded.State, node)
+			break
+		}
+		if err := ExecuteStateless(gspec.Config, engine, block, &decoded); err == nil {
+			t.Fatalf("block %d: stateless execution succeeded with incomplete witness", block.NumberU64())
+		}
+	}
+	// A witness without headers can't be executed
+	if err := ExecuteStateless(gspec.Config, engine, blocks[0], new(stateless.Witness)); err == nil {
+		t.Fatal("stateless execution succeeded without witness headers")
+	}
+}
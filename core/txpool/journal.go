@@ -0,0 +1,252 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/exp/slices"
+)
+
+// journalBatchSize is the number of journaled transactions injected into the
+// subpools at once on startup.
+const journalBatchSize = 1024
+
+// JournalConfig are the configuration parameters of the transaction pool journal,
+// persisting the pending and queued transactions of all the subpools across node
+// restarts.
+type JournalConfig struct {
+	Path    string        // Filesystem path of the journal, journaling is disabled if empty
+	MaxSize uint64        // Maximum accumulated size of the journaled transactions in bytes
+	MaxAge  time.Duration // Maximum time since a transaction was first seen to be reinjected
+}
+
+// DefaultJournalConfig contains the default limits of the transaction pool
+// journal, which is disabled by default.
+var DefaultJournalConfig = JournalConfig{
+	MaxSize: 64 * 1024 * 1024,
+	MaxAge:  3 * time.Hour,
+}
+
+// journalEntry is a transaction stored in the journal along with the metadata
+// needed to reinject it.
+type journalEntry struct {
+	Time  uint64             // Time the transaction was first seen, unix timestamp in milliseconds
+	Local bool               // Whether the transaction was submitted locally
+	Tx    *types.Transaction // Transaction, including the blob sidecar if any
+}
+
+// EnableJournal loads the transactions snapshotted by the previous run of the pool
+// from the journal, revalidating and reinjecting them into the subpools. Once
+// enabled, the pending and queued transactions of all the subpools are written
+// into the journal when the pool is closed. Subpools keeping their own persistent
+// store (e.g. the blob pool) don't report their contents, and are left to restore
+// them by themselves.
+func (p *TxPool) EnableJournal(config JournalConfig) error {
+	if config.Path == "" {
+		return nil
+	}
+	p.journal = &config
+	return p.loadJournal()
+}
+
+// loadJournal injects the transactions stored in the journal into the pool,
+// dropping the ones exceeding the age and the size limits.
+func (p *TxPool) loadJournal() error {
+	input, err := os.Open(p.journal.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var (
+		stream  = rlp.NewStream(bufio.NewReader(input), 0)
+		start   = time.Now()
+		total   int
+		stale   int
+		dropped int
+		size    uint64
+		failure error
+
+		locals  []*types.Transaction
+		remotes []*types.Transaction
+	)
+	add := func(txs []*types.Transaction, local bool) {
+		for _, err := range p.Add(txs, local, false) {
+			if err != nil && !errors.Is(err, ErrAlreadyKnown) {
+				log.Debug("Failed to add journaled transaction", "err", err)
+				dropped++
+			}
+		}
+	}
+	for {
+		var entry journalEntry
+		if err := stream.Decode(&entry); err != nil {
+			if err != io.EOF {
+				failure = err
+			}
+			break
+		}
+		total++
+
+		seen := time.UnixMilli(int64(entry.Time))
+		if p.journal.MaxAge > 0 && time.Since(seen) > p.journal.MaxAge {
+			stale++
+			continue
+		}
+		// The journal is ordered by priority, skip the tail beyond the size
+		// limit (the limit might have been lowered since the last run).
+		if p.journal.MaxSize > 0 && size+entry.Tx.Size() > p.journal.MaxSize {
+			dropped++
+			continue
+		}
+		size += entry.Tx.Size()
+		// Retain the time the transaction was first seen, so the age limit
+		// holds across multiple restarts.
+		entry.Tx.SetTime(seen)
+		if entry.Local {
+			if locals = append(locals, entry.Tx); len(locals) >= journalBatchSize {
+				add(locals, true)
+				locals = locals[:0]
+			}
+		} else {
+			if remotes = append(remotes, entry.Tx); len(remotes) >= journalBatchSize {
+				add(remotes, false)
+				remotes = remotes[:0]
+			}
+		}
+	}
+	if len(locals) > 0 {
+		add(locals, true)
+	}
+	if len(remotes) > 0 {
+		add(remotes, false)
+	}
+	log.Info("Loaded transaction pool journal", "transactions", total, "stale", stale, "dropped", dropped, "elapsed", common.PrettyDuration(time.Since(start)))
+	return failure
+}
+
+// writeJournal snapshots the pending and queued transactions of all the subpools
+// into the journal. The pending transactions are preferred over the queued ones
+// if the size limit is reached, and the local ones over the remote ones.
+func (p *TxPool) writeJournal() error {
+	var (
+		locals  = make(map[common.Address]bool)
+		pending []map[common.Address][]*types.Transaction
+		queued  []map[common.Address][]*types.Transaction
+	)
+	for _, subpool := range p.subpools {
+		run, block := subpool.Content()
+		pending = append(pending, run)
+		queued = append(queued, block)
+	}
+	for _, addr := range p.Locals() {
+		locals[addr] = true
+	}
+	// Order the accounts so that the local ones are written first, the rest
+	// of them are sorted by address for a deterministic output.
+	accounts := func(sets []map[common.Address][]*types.Transaction) []common.Address {
+		var addrs []common.Address
+		for _, set := range sets {
+			for addr := range set {
+				addrs = append(addrs, addr)
+			}
+		}
+		slices.SortFunc(addrs, func(a, b common.Address) int {
+			if locals[a] != locals[b] {
+				if locals[a] {
+					return -1
+				}
+				return 1
+			}
+			return bytes.Compare(a[:], b[:])
+		})
+		return addrs
+	}
+	output, err := os.OpenFile(p.journal.Path+".new", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var (
+		writer    = bufio.NewWriter(output)
+		size      uint64
+		journaled int
+		skipped   int
+	)
+	write := func(sets []map[common.Address][]*types.Transaction) error {
+		for _, addr := range accounts(sets) {
+			var list []*types.Transaction
+			for _, set := range sets {
+				if txs, ok := set[addr]; ok {
+					list = txs
+				}
+			}
+			for i, tx := range list {
+				// Skip the rest of the account's transactions if the size limit
+				// is reached, they would be gapped anyway.
+				if p.journal.MaxSize > 0 && size+tx.Size() > p.journal.MaxSize {
+					skipped += len(list) - i
+					break
+				}
+				entry := &journalEntry{
+					Time:  uint64(tx.Time().UnixMilli()),
+					Local: locals[addr],
+					Tx:    tx,
+				}
+				if err := rlp.Encode(writer, entry); err != nil {
+					return err
+				}
+				size += tx.Size()
+				journaled++
+			}
+		}
+		return nil
+	}
+	if err := write(pending); err != nil {
+		output.Close()
+		return err
+	}
+	if err := write(queued); err != nil {
+		output.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		output.Close()
+		return err
+	}
+	if err := output.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(p.journal.Path+".new", p.journal.Path); err != nil {
+		return err
+	}
+	log.Info("Persisted transaction pool journal", "transactions", journaled, "skipped", skipped, "size", common.StorageSize(size))
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -253,7 +255,7 @@ func (c *testChain) State() (*state.StateDB, error) {
 		c.statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
 		// simulate that the new head block included tx0 and tx1
 		c.statedb.SetNonce(c.address, 2)
-		c.statedb.SetBalance(c.address, new(uint256.Int).SetUint64(params.Ether))
+		c.statedb.SetBalance(c.address, new(uint256.Int).SetUint64(params.Ether), tracing.BalanceChangeUnspecified)
 		*c.trigger = false
 	}
 	return stdb, nil
@@ -273,7 +275,7 @@ func TestStateChangeDuringReset(t *testing.T) {
 	)
 
 	// setup pool with 2 transaction in it
-	statedb.SetBalance(address, new(uint256.Int).SetUint64(params.Ether))
+	statedb.SetBalance(address, new(uint256.Int).SetUint64(params.Ether), tracing.BalanceChangeUnspecified)
 	blockchain := &testChain{newTestBlockChain(params.TestChainConfig, 1000000000, statedb, new(event.Feed)), address, &trigger}
 
 	tx0 := transaction(0, 100000, key)
@@ -307,7 +309,7 @@ func TestStateChangeDuringReset(t *testing.T) {
 
 func testAddBalance(pool *LegacyPool, addr common.Address, amount *big.Int) {
 	pool.mu.Lock()
-	pool.currentState.AddBalance(addr, uint256.MustFromBig(amount))
+	pool.currentState.AddBalance(addr, uint256.MustFromBig(amount), tracing.BalanceChangeUnspecified)
 	pool.mu.Unlock()
 }
 
@@ -468,7 +470,7 @@ func TestChainFork(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
 		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
-		statedb.AddBalance(addr, uint256.NewInt(100000000000000))
+		statedb.AddBalance(addr, uint256.NewInt(100000000000000), tracing.BalanceChangeUnspecified)
 
 		pool.chain = newTestBlockChain(pool.chainconfig, 1000000, statedb, new(event.Feed))
 		<-pool.requestReset(nil, nil)
@@ -497,7 +499,7 @@ func TestDoubleNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	resetState := func() {
 		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
-		statedb.AddBalance(addr, uint256.NewInt(100000000000000))
+		statedb.AddBalance(addr, uint256.NewInt(100000000000000), tracing.BalanceChangeUnspecified)
 
 		pool.chain = newTestBlockChain(pool.chainconfig, 1000000, statedb, new(event.Feed))
 		<-pool.requestReset(nil, nil)
@@ -2457,6 +2459,93 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that the transaction pool journal persists the pending and queued
+// transactions across restarts, within the configured size and age limits.
+func TestTxPoolJournal(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Journal = ""
+
+	journal := txpool.JournalConfig{
+		Path:   filepath.Join(t.TempDir(), "txpool.rlp"),
+		MaxAge: time.Hour,
+	}
+	open := func() (*LegacyPool, *txpool.TxPool) {
+		subpool := New(config, blockchain)
+		pool, err := txpool.New(config.PriceLimit, blockchain, []txpool.SubPool{subpool})
+		if err != nil {
+			t.Fatalf("failed to create transaction pool: %v", err)
+		}
+		if err := pool.EnableJournal(journal); err != nil {
+			t.Fatalf("failed to load journal: %v", err)
+		}
+		<-subpool.requestReset(nil, nil)
+		return subpool, pool
+	}
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+	stale, _ := crypto.GenerateKey()
+
+	subpool, pool := open()
+	for _, key := range []*ecdsa.PrivateKey{local, remote, stale} {
+		testAddBalance(subpool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	// Add a local transaction, a few pending and queued remote ones, and a
+	// remote one first seen long ago.
+	if err := pool.Add([]*types.Transaction{transaction(0, 100000, local)}, true, true)[0]; err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	old := transaction(0, 100000, stale)
+	old.SetTime(time.Now().Add(-2 * time.Hour))
+
+	remotes := []*types.Transaction{
+		transaction(0, 100000, remote),
+		transaction(1, 100000, remote),
+		transaction(3, 100000, remote),
+		old,
+	}
+	for i, err := range pool.Add(remotes, false, true) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("transaction count mismatch: have %d/%d, want 4/1", pending, queued)
+	}
+	pool.Close()
+
+	// Reopen the pool and ensure all but the stale transaction are reinjected,
+	// retaining the local one as local.
+	_, pool = open()
+	if pending, queued := pool.Stats(); pending != 3 || queued != 1 {
+		t.Fatalf("transaction count mismatch after restart: have %d/%d, want 3/1", pending, queued)
+	}
+	if locals := pool.Locals(); len(locals) != 1 || locals[0] != crypto.PubkeyToAddress(local.PublicKey) {
+		t.Fatalf("local accounts mismatch: have %v, want %v", locals, crypto.PubkeyToAddress(local.PublicKey))
+	}
+	if pool.Has(old.Hash()) {
+		t.Fatal("stale transaction reinjected")
+	}
+	// Limit the journal size to two transactions, the local one and the pending
+	// ones should be preferred.
+	pool.Close()
+	journal.MaxSize = 2 * remotes[0].Size()
+
+	_, pool = open()
+	defer pool.Close()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("transaction count mismatch after limited restart: have %d/%d, want 2/0", pending, queued)
+	}
+	if status := pool.Status(remotes[1].Hash()); status != txpool.TxStatusUnknown {
+		t.Fatalf("transaction beyond the size limit reinjected, status %d", status)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -2660,7 +2749,7 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		key, _ := crypto.GenerateKey()
 		account := crypto.PubkeyToAddress(key.PublicKey)
-		pool.currentState.AddBalance(account, uint256.NewInt(1000000))
+		pool.currentState.AddBalance(account, uint256.NewInt(1000000), tracing.BalanceChangeUnspecified)
 		tx := transaction(uint64(0), 100000, key)
 		batches[i] = tx
 	}
@@ -70,6 +70,8 @@ type TxPool struct {
 	reservations map[common.Address]SubPool // Map with the account to pool reservations
 	reserveLock  sync.Mutex                 // Lock protecting the account reservations
 
+	journal *JournalConfig // Journal persisting the transactions across restarts, nil if disabled
+
 	subs event.SubscriptionScope // Subscription scope to unsubscribe all on shutdown
 	quit chan chan error         // Quit channel to tear down the head updater
 	term chan struct{}           // Termination channel to detect a closed pool
@@ -160,6 +162,12 @@ func (p *TxPool) Close() error {
 	if err := <-errc; err != nil {
 		errs = append(errs, err)
 	}
+	// Snapshot the transactions of the subpools before terminating them
+	if p.journal != nil {
+		if err := p.writeJournal(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	// Terminate each subpool
 	for _, subpool := range p.subpools {
 		if err := subpool.Close(); err != nil {
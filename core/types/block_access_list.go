@@ -0,0 +1,71 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+//go:generate go run github.com/fjl/gencodec -type TxAccessList -field-override txAccessListMarshaling -out gen_tx_access_list_json.go
+//go:generate go run github.com/fjl/gencodec -type AccountPost -field-override accountPostMarshaling -out gen_account_post_json.go
+
+// BlockAccessList is the state accessed by the transactions of a block,
+// ordered by transaction index.
+type BlockAccessList []*TxAccessList
+
+// TxAccessList is the state accessed by a single transaction.
+type TxAccessList struct {
+	Index    uint64           `json:"index"`    // position of the transaction in the block
+	Accounts []*AccountAccess `json:"accounts"` // accessed accounts, ordered by address
+}
+
+// field type overrides for gencodec
+type txAccessListMarshaling struct {
+	Index hexutil.Uint64
+}
+
+// AccountAccess is an account accessed by a transaction, along with the storage
+// slots it read and the values of the slots it wrote.
+type AccountAccess struct {
+	Address       common.Address `json:"address"`
+	StorageReads  []common.Hash  `json:"storageReads"`   // ordered by slot
+	StorageWrites []StorageWrite `json:"storageWrites"`  // ordered by slot
+	Post          *AccountPost   `json:"post" rlp:"nil"` // nil if the account was not modified
+}
+
+// StorageWrite is the value of a storage slot after a transaction wrote it.
+type StorageWrite struct {
+	Slot  common.Hash `json:"slot"`
+	Value common.Hash `json:"value"`
+}
+
+// AccountPost is the value of an account after a transaction modified it. The
+// zero value, with an empty code hash, denotes a deleted account.
+type AccountPost struct {
+	Balance  *big.Int    `json:"balance"`
+	Nonce    uint64      `json:"nonce"`
+	CodeHash common.Hash `json:"codeHash"`
+}
+
+// field type overrides for gencodec
+type accountPostMarshaling struct {
+	Balance *hexutil.Big
+	Nonce   hexutil.Uint64
+}
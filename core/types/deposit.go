@@ -0,0 +1,72 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DepositEventSignature is the topic of the DepositEvent emitted by the beacon
+// chain deposit contract.
+var DepositEventSignature = crypto.Keccak256Hash([]byte("DepositEvent(bytes,bytes,bytes,bytes,bytes)"))
+
+const (
+	depositRequestSize = 192 // pubkey ++ withdrawal credentials ++ amount ++ signature ++ index
+	depositEventSize   = 576 // ABI encoding of DepositEvent
+)
+
+// DepositLogToRequest unpacks the data of a DepositEvent log into the EIP-6110
+// deposit request encoding.
+func DepositLogToRequest(data []byte) ([]byte, error) {
+	if len(data) != depositEventSize {
+		return nil, fmt.Errorf("deposit wrong length: want %d, have %d", depositEventSize, len(data))
+	}
+	request := make([]byte, depositRequestSize)
+	const (
+		pubkeyOffset         = 0
+		withdrawalCredOffset = pubkeyOffset + 48
+		amountOffset         = withdrawalCredOffset + 32
+		signatureOffset      = amountOffset + 8
+		indexOffset          = signatureOffset + 96
+	)
+	// The ABI encodes the offsets of the five dynamic fields first, and each
+	// field is prefixed with its length. Skip over the offsets and the length of
+	// the first field.
+	b := 32*5 + 32
+
+	// The public key is 48 bytes, padded to 64.
+	copy(request[pubkeyOffset:], data[b:b+48])
+	b += 64 + 32
+
+	// The withdrawal credentials are 32 bytes.
+	copy(request[withdrawalCredOffset:], data[b:b+32])
+	b += 32 + 32
+
+	// The amount is 8 bytes (little endian), padded to 32.
+	copy(request[amountOffset:], data[b:b+8])
+	b += 32 + 32
+
+	// The signature is 96 bytes.
+	copy(request[signatureOffset:], data[b:b+96])
+	b += 96 + 32
+
+	// The index is 8 bytes (little endian).
+	copy(request[indexOffset:], data[b:b+8])
+	return request, nil
+}
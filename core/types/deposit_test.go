@@ -0,0 +1,104 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const depositABIJSON = `[{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes","name":"pubkey","type":"bytes"},{"indexed":false,"internalType":"bytes","name":"withdrawal_credentials","type":"bytes"},{"indexed":false,"internalType":"bytes","name":"amount","type":"bytes"},{"indexed":false,"internalType":"bytes","name":"signature","type":"bytes"},{"indexed":false,"internalType":"bytes","name":"index","type":"bytes"}],"name":"DepositEvent","type":"event"}]`
+
+func TestDepositLogToRequest(t *testing.T) {
+	depositABI, err := abi.JSON(strings.NewReader(depositABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := DepositEventSignature, depositABI.Events["DepositEvent"].ID; have != want {
+		t.Fatalf("deposit event signature mismatch: have %x, want %x", have, want)
+	}
+	var (
+		pubkey      = bytes.Repeat([]byte{0x01}, 48)
+		credentials = bytes.Repeat([]byte{0x02}, 32)
+		signature   = bytes.Repeat([]byte{0x03}, 96)
+		amount      = binary.LittleEndian.AppendUint64(nil, 32_000_000_000)
+		index       = binary.LittleEndian.AppendUint64(nil, 7)
+	)
+	data, err := depositABI.Events["DepositEvent"].Inputs.Pack(pubkey, credentials, amount, signature, index)
+	if err != nil {
+		t.Fatalf("failed to pack deposit event: %v", err)
+	}
+	request, err := DepositLogToRequest(data)
+	if err != nil {
+		t.Fatalf("failed to unpack deposit log: %v", err)
+	}
+	want := common.CopyBytes(pubkey)
+	for _, field := range [][]byte{credentials, amount, signature, index} {
+		want = append(want, field...)
+	}
+	if !bytes.Equal(request, want) {
+		t.Fatalf("deposit request mismatch:\nhave %x\nwant %x", request, want)
+	}
+	if _, err := DepositLogToRequest(data[:len(data)-1]); err == nil || !strings.Contains(err.Error(), "wrong length") {
+		t.Fatalf("expected length error, got %v", err)
+	}
+}
+
+func TestCalcRequestsHash(t *testing.T) {
+	if have := CalcRequestsHash(nil); have != EmptyRequestsHash {
+		t.Fatalf("empty requests hash mismatch: have %x, want %x", have, EmptyRequestsHash)
+	}
+	// Items without request data must not contribute to the hash.
+	if have := CalcRequestsHash([][]byte{{DepositRequestType}, {WithdrawalRequestType}}); have != EmptyRequestsHash {
+		t.Fatalf("type-only requests hash mismatch: have %x, want %x", have, EmptyRequestsHash)
+	}
+	var (
+		withdrawals = append([]byte{WithdrawalRequestType}, bytes.Repeat([]byte{0xaa}, 76)...)
+		a           = CalcRequestsHash([][]byte{withdrawals})
+		b           = CalcRequestsHash([][]byte{{DepositRequestType}, withdrawals})
+	)
+	if a != b {
+		t.Fatalf("requests hash depends on empty items: %x != %x", a, b)
+	}
+	if a == EmptyRequestsHash {
+		t.Fatal("requests hash ignores request data")
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	tests := []struct {
+		requests [][]byte
+		valid    bool
+	}{
+		{nil, true},
+		{[][]byte{{DepositRequestType, 1}, {ConsolidationRequestType, 2}}, true},
+		{[][]byte{{}}, false},
+		{[][]byte{{DepositRequestType}}, false},
+		{[][]byte{{WithdrawalRequestType, 1}, {DepositRequestType, 2}}, false},
+		{[][]byte{{WithdrawalRequestType, 1}, {WithdrawalRequestType, 2}}, false},
+	}
+	for i, test := range tests {
+		if err := ValidateRequests(test.requests); (err == nil) != test.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid=%v", i, err, test.valid)
+		}
+	}
+}
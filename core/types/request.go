@@ -0,0 +1,77 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Execution layer request types, as per EIP-7685.
+const (
+	DepositRequestType       = 0x00 // EIP-6110
+	WithdrawalRequestType    = 0x01 // EIP-7002
+	ConsolidationRequestType = 0x02 // EIP-7251
+)
+
+var (
+	errEmptyRequest     = errors.New("empty request")
+	errRequestOrder     = errors.New("requests not in strictly ascending type order")
+	errEmptyRequestData = errors.New("request without data")
+)
+
+// CalcRequestsHash computes the EIP-7685 commitment to the given list of
+// requests. Each item of the list is the request type byte followed by the
+// concatenated request payloads of that type:
+//
+//	sha256(sha256(requests_0) ++ sha256(requests_1) ++ ...)
+//
+// Items which only consist of the type byte are skipped.
+func CalcRequestsHash(requests [][]byte) common.Hash {
+	h1, h2 := sha256.New(), sha256.New()
+	var buf common.Hash
+	for _, item := range requests {
+		if len(item) > 1 { // skip items with only the type byte
+			h1.Reset()
+			h1.Write(item)
+			h2.Write(h1.Sum(buf[:0]))
+		}
+	}
+	h2.Sum(buf[:0])
+	return buf
+}
+
+// ValidateRequests checks that a list of requests received from the consensus
+// layer is well formed: every item must carry data, and the items must be in
+// strictly ascending order of their type.
+func ValidateRequests(requests [][]byte) error {
+	for i, item := range requests {
+		if len(item) == 0 {
+			return errEmptyRequest
+		}
+		if len(item) == 1 {
+			return fmt.Errorf("%w: type %d", errEmptyRequestData, item[0])
+		}
+		if i > 0 && item[0] <= requests[i-1][0] {
+			return errRequestOrder
+		}
+	}
+	return nil
+}
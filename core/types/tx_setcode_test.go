@@ -0,0 +1,177 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append(common.CopyBytes(DelegationPrefix[:2]), addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+		if tt.want != nil && got != *tt.want {
+			t.Fatalf("wrong delegation address: have %s, want %s", got.Hex(), tt.want.Hex())
+		}
+	}
+}
+
+func TestSetCodeAuthority(t *testing.T) {
+	key, addr := defaultTestKey()
+
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: common.Address{0x42},
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatalf("could not sign authorization: %v", err)
+	}
+	if have, err := auth.Authority(); err != nil {
+		t.Fatalf("could not recover authority: %v", err)
+	} else if have != addr {
+		t.Fatalf("authority mismatch: have %s, want %s", have.Hex(), addr.Hex())
+	}
+	// Tampering with any of the signed fields must change the authority.
+	tampered := auth
+	tampered.Nonce++
+	if have, err := tampered.Authority(); err == nil && have == addr {
+		t.Fatal("authority recovered from tampered authorization")
+	}
+	// Signature values out of range must be rejected.
+	invalid := auth
+	invalid.V = 2
+	if _, err := invalid.Authority(); err == nil {
+		t.Fatal("authority recovered from invalid signature")
+	}
+}
+
+func TestSetCodeTxCoding(t *testing.T) {
+	key, addr := defaultTestKey()
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		Address: common.Address{0x42},
+		Nonce:   1,
+	})
+	if err != nil {
+		t.Fatalf("could not sign authorization: %v", err)
+	}
+	var (
+		signer    = NewPragueSigner(common.Big1)
+		recipient = common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+		accesses  = AccessList{{Address: addr, StorageKeys: []common.Hash{{0}}}}
+	)
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      1,
+		To:         recipient,
+		Gas:        123457,
+		GasTipCap:  uint256.NewInt(1),
+		GasFeeCap:  uint256.NewInt(10),
+		Value:      uint256.NewInt(5),
+		Data:       []byte("abcdef"),
+		AccessList: accesses,
+		AuthList:   []SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if sender, err := Sender(signer, tx); err != nil {
+		t.Fatalf("could not recover sender: %v", err)
+	} else if sender != addr {
+		t.Fatalf("sender mismatch: have %s, want %s", sender.Hex(), addr.Hex())
+	}
+	// Older signers must not accept the transaction.
+	if _, err := Sender(NewCancunSigner(common.Big1), tx); err == nil {
+		t.Fatal("cancun signer accepted set code transaction")
+	}
+	// RLP
+	parsedTx, err := encodeDecodeBinary(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := assertEqual(parsedTx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedTx.SetCodeAuthorizations(), tx.SetCodeAuthorizations()) {
+		t.Fatalf("authorization list mismatch after RLP roundtrip")
+	}
+	// JSON
+	parsedTx, err = encodeDecodeJSON(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := assertEqual(parsedTx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsedTx.SetCodeAuthorizations(), tx.SetCodeAuthorizations()) {
+		t.Fatalf("authorization list mismatch after JSON roundtrip")
+	}
+	// The authorization list is mandatory in the JSON encoding.
+	var dec map[string]interface{}
+	enc, _ := json.Marshal(tx)
+	json.Unmarshal(enc, &dec)
+	delete(dec, "authorizationList")
+	enc, _ = json.Marshal(dec)
+	if err := new(Transaction).UnmarshalJSON(enc); err == nil {
+		t.Fatal("decoded set code transaction without authorization list")
+	}
+}
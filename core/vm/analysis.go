@@ -116,3 +116,69 @@ func codeBitmapInternal(code, bits bitvec) bitvec {
 	}
 	return bits
 }
+
+// eofCodeBitmap collects data locations in an EOF code section, that is the
+// immediate arguments of the PUSH and the EOF instructions.
+func eofCodeBitmap(code []byte) bitvec {
+	// The bitmap is 4 bytes longer than necessary, in case the code
+	// ends with a PUSH32, the algorithm will set bits on the
+	// bitvector outside the bounds of the actual code.
+	bits := make(bitvec, len(code)/8+1+4)
+	return eofCodeBitmapInternal(code, bits)
+}
+
+// eofCodeBitmapInternal is the internal implementation of eofCodeBitmap.
+func eofCodeBitmapInternal(code, bits bitvec) bitvec {
+	for pc := uint64(0); pc < uint64(len(code)); {
+		var (
+			op      = OpCode(code[pc])
+			numbits = uint16(immediates[op])
+		)
+		pc++
+		if op == RJUMPV && pc < uint64(len(code)) {
+			// RJUMPV is unique as it has a variable sized operand. The total size is
+			// determined by the count byte which immediately follows RJUMPV.
+			numbits = 1 + (uint16(code[pc])+1)*2
+		}
+		if numbits == 0 {
+			continue
+		}
+		// Truncated immediates are rejected by the validation, only make sure
+		// not to write beyond the bitmap.
+		if rest := uint64(len(code)) - pc; uint64(numbits) > rest {
+			numbits = uint16(rest)
+		}
+		for ; numbits >= 16; numbits -= 16 {
+			bits.set16(pc)
+			pc += 16
+		}
+		for ; numbits >= 8; numbits -= 8 {
+			bits.set8(pc)
+			pc += 8
+		}
+		switch numbits {
+		case 1:
+			bits.set1(pc)
+			pc += 1
+		case 2:
+			bits.setN(set2BitsMask, pc)
+			pc += 2
+		case 3:
+			bits.setN(set3BitsMask, pc)
+			pc += 3
+		case 4:
+			bits.setN(set4BitsMask, pc)
+			pc += 4
+		case 5:
+			bits.setN(set5BitsMask, pc)
+			pc += 5
+		case 6:
+			bits.setN(set6BitsMask, pc)
+			pc += 6
+		case 7:
+			bits.setN(set7BitsMask, pc)
+			pc += 7
+		}
+	}
+	return bits
+}
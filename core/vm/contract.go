@@ -51,10 +51,17 @@ type Contract struct {
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
 
-	Code     []byte
-	CodeHash common.Hash
-	CodeAddr *common.Address
-	Input    []byte
+	Code      []byte
+	CodeHash  common.Hash
+	CodeAddr  *common.Address
+	Input     []byte
+	container *Container // Parsed EOF container, nil for legacy code
+
+	// is the execution frame represented by this object a contract deployment
+	IsDeployment bool
+
+	codeSection uint64          // Currently executing EOF code section
+	returnStack []returnContext // EOF function return stack
 
 	Gas   uint64
 	value *uint256.Int
@@ -190,3 +197,16 @@ func (c *Contract) SetCodeOptionalHash(addr *common.Address, codeAndHash *codeAn
 	c.CodeHash = codeAndHash.hash
 	c.CodeAddr = addr
 }
+
+// returnContext is the location execution resumes at after an EOF function
+// returns with RETF.
+type returnContext struct {
+	section uint64
+	pc      uint64
+}
+
+// enterSection switches the execution to the given EOF code section.
+func (c *Contract) enterSection(section uint64) {
+	c.codeSection = section
+	c.Code = c.container.codeSections[section]
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -59,10 +60,10 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x0a}): &kzgPointEvaluation{},
 
 	common.BytesToAddress([]byte{0x0f, 0x0a}): &bls12381G1Add{},
-	common.BytesToAddress([]byte{0x0f, 0x0b}): &bls12381G1Mul{},
+	common.BytesToAddress([]byte{0x0f, 0x0b}): &bls12381G1MultiExp{},
 	common.BytesToAddress([]byte{0x0f, 0x0c}): &bls12381G1MultiExp{},
 	common.BytesToAddress([]byte{0x0f, 0x0d}): &bls12381G2Add{},
-	common.BytesToAddress([]byte{0x0f, 0x0e}): &bls12381G2Mul{},
+	common.BytesToAddress([]byte{0x0f, 0x0e}): &bls12381G2MultiExp{},
 	common.BytesToAddress([]byte{0x0f, 0x0f}): &bls12381G2MultiExp{},
 	common.BytesToAddress([]byte{0x0f, 0x10}): &bls12381Pairing{},
 	common.BytesToAddress([]byte{0x0f, 0x11}): &bls12381MapG1{},
@@ -337,6 +338,24 @@ func TestPrecompiledBLS12381PairingFail(t *testing.T)    { testJsonFail("blsPair
 func TestPrecompiledBLS12381MapG1Fail(t *testing.T)      { testJsonFail("blsMapG1", "f11", t) }
 func TestPrecompiledBLS12381MapG2Fail(t *testing.T)      { testJsonFail("blsMapG2", "f12", t) }
 
+// Tests that the exported BLS precompile set contains exactly the EIP-2537
+// precompiles, and nothing else from the Prague set.
+func TestPrecompiledContractsBLS(t *testing.T) {
+	if len(PrecompiledContractsBLS) != 0x11-0x0b+1 {
+		t.Fatalf("precompile count mismatch: have %d, want %d", len(PrecompiledContractsBLS), 0x11-0x0b+1)
+	}
+	for id := byte(0x0b); id <= 0x11; id++ {
+		addr := common.BytesToAddress([]byte{id})
+		have, ok := PrecompiledContractsBLS[addr]
+		if !ok {
+			t.Fatalf("precompile %x missing", addr)
+		}
+		if want := PrecompiledContractsPrague[addr]; reflect.TypeOf(have) != reflect.TypeOf(want) {
+			t.Errorf("precompile %x mismatch: have %T, want %T", addr, have, want)
+		}
+	}
+}
+
 func loadJson(name string) ([]precompiledTest, error) {
 	data, err := os.ReadFile(fmt.Sprintf("testdata/precompiles/%v.json", name))
 	if err != nil {
@@ -372,7 +391,7 @@ func BenchmarkPrecompiledBLS12381G1MultiExpWorstCase(b *testing.B) {
 		Name:        "WorstCaseG1",
 		NoBenchmark: false,
 	}
-	benchmarkPrecompiled("0c", testcase, b)
+	benchmarkPrecompiled("f0c", testcase, b)
 }
 
 // BenchmarkPrecompiledBLS12381G2MultiExpWorstCase benchmarks the worst case we could find that still fits a gaslimit of 10MGas.
@@ -393,5 +412,5 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 		Name:        "WorstCaseG2",
 		NoBenchmark: false,
 	}
-	benchmarkPrecompiled("0f", testcase, b)
+	benchmarkPrecompiled("f0f", testcase, b)
 }
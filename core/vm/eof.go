@@ -0,0 +1,509 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes     = 1
+	kindCode      = 2
+	kindContainer = 3
+	kindData      = 0xff
+
+	eofFormatByte = 0xef
+	eof1Version   = 1
+
+	maxInputItems        = 127
+	maxOutputItems       = 128
+	maxStackHeight       = 1023
+	maxCodeSections      = 1024
+	maxContainerSections = 256
+	maxReturnStackHeight = 1024
+
+	nonReturningFunction = 0x80
+)
+
+var (
+	eofMagic = []byte{0xef, 0x00}
+
+	// eofMagicHash is the code hash legacy code observes for EOF contracts.
+	eofMagicHash = crypto.Keccak256Hash(eofMagic)
+)
+
+// HasEOFByte returns true if code starts with 0xEF byte
+func HasEOFByte(code []byte) bool {
+	return len(code) != 0 && code[0] == eofFormatByte
+}
+
+// isEOFVersion1 returns true if the code's version byte equals eof1Version. It
+// does not verify the EOF magic is valid.
+func isEOFVersion1(code []byte) bool {
+	return 2 < len(code) && code[2] == byte(eof1Version)
+}
+
+// hasEOFMagic returns true if code starts with magic defined by EIP-3540
+func hasEOFMagic(code []byte) bool {
+	return len(eofMagic) <= len(code) && bytes.Equal(eofMagic, code[0:len(eofMagic)])
+}
+
+// Container is an EOF container object.
+type Container struct {
+	types             []*functionMetadata
+	codeSections      [][]byte
+	subContainers     []*Container
+	subContainerCodes [][]byte
+	data              []byte
+	dataSize          int // might be more than len(data)
+}
+
+// functionMetadata is an EOF function signature.
+type functionMetadata struct {
+	inputs         uint8
+	outputs        uint8
+	maxStackHeight uint16
+}
+
+// stackDelta returns the #outputs - #inputs
+func (meta *functionMetadata) stackDelta() int {
+	return int(meta.outputs) - int(meta.inputs)
+}
+
+// checkInputs checks the current minimum stack (stackMin) against the required inputs
+// of the metadata, and returns an error if the stack is too shallow.
+func (meta *functionMetadata) checkInputs(stackMin int) error {
+	if int(meta.inputs) > stackMin {
+		return fmt.Errorf("%w: have %d, want %d", errEOFStackUnderflow, stackMin, meta.inputs)
+	}
+	return nil
+}
+
+// checkStackMax checks the if current maximum stack combined with the
+// function max stack will result in a stack overflow, and if so returns an error.
+func (meta *functionMetadata) checkStackMax(stackMax int) error {
+	newMaxStack := stackMax + int(meta.maxStackHeight) - int(meta.inputs)
+	if newMaxStack > int(params.StackLimit) {
+		return fmt.Errorf("%w: have %d, limit %d", errEOFStackOverflow, newMaxStack, params.StackLimit)
+	}
+	return nil
+}
+
+// isReturning reports whether the function returns to its caller.
+func (meta *functionMetadata) isReturning() bool {
+	return meta.outputs != nonReturningFunction
+}
+
+// MarshalBinary encodes an EOF container into binary format.
+func (c *Container) MarshalBinary() []byte {
+	// Build EOF prefix.
+	b := make([]byte, 2)
+	copy(b, eofMagic)
+	b = append(b, eof1Version)
+
+	// Write section headers.
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.codeSections)))
+	for _, codeSection := range c.codeSections {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(codeSection)))
+	}
+	if len(c.subContainers) != 0 {
+		b = append(b, kindContainer)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(c.subContainers)))
+		for _, section := range c.subContainerCodes {
+			b = binary.BigEndian.AppendUint32(b, uint32(len(section)))
+		}
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(c.dataSize))
+	b = append(b, 0) // terminator
+
+	// Write section contents.
+	for _, ty := range c.types {
+		b = append(b, []byte{ty.inputs, ty.outputs, byte(ty.maxStackHeight >> 8), byte(ty.maxStackHeight & 0x00ff)}...)
+	}
+	for _, code := range c.codeSections {
+		b = append(b, code...)
+	}
+	for _, section := range c.subContainerCodes {
+		b = append(b, section...)
+	}
+	b = append(b, c.data...)
+
+	return b
+}
+
+// UnmarshalBinary decodes an EOF container.
+func (c *Container) UnmarshalBinary(b []byte) error {
+	return c.unmarshalContainer(b, true)
+}
+
+// UnmarshalSubContainer decodes an EOF subcontainer, which is allowed to have a
+// truncated data section.
+func (c *Container) UnmarshalSubContainer(b []byte) error {
+	return c.unmarshalContainer(b, false)
+}
+
+// eofHeader holds the section sizes declared by an EOF container header.
+type eofHeader struct {
+	typesSize      int
+	codeSizes      []int
+	containerSizes []int
+	dataSize       int
+	headerSize     int
+	containerSize  int // size of the container excluding the data section
+}
+
+// parseHeader decodes the header of an EOF container.
+func parseHeader(b []byte) (*eofHeader, error) {
+	if !hasEOFMagic(b) {
+		return nil, fmt.Errorf("%w: want %x", errInvalidMagic, eofMagic)
+	}
+	if !isEOFVersion1(b) {
+		return nil, fmt.Errorf("%w: have %d, want %d", errInvalidVersion, b[2], eof1Version)
+	}
+	var (
+		h   = new(eofHeader)
+		err error
+	)
+	// Parse type section header.
+	var kind int
+	kind, h.typesSize, err = parseSection(b, offsetTypesKind)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindTypes {
+		return nil, fmt.Errorf("%w: found section kind %x instead", errMissingTypeHeader, kind)
+	}
+	if h.typesSize < 4 || h.typesSize%4 != 0 {
+		return nil, fmt.Errorf("%w: type section size must be divisible by 4, have %d", errInvalidTypeSize, h.typesSize)
+	}
+	if h.typesSize/4 > maxCodeSections {
+		return nil, fmt.Errorf("%w: type section must not exceed 4*1024, have %d", errInvalidTypeSize, h.typesSize)
+	}
+	// Parse code section header.
+	kind, h.codeSizes, err = parseSectionList(b, offsetCodeKind, 2)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindCode {
+		return nil, fmt.Errorf("%w: found section kind %x instead", errMissingCodeHeader, kind)
+	}
+	if len(h.codeSizes) != h.typesSize/4 {
+		return nil, fmt.Errorf("%w: mismatch of code sections found and type signatures, types %d, code %d", errInvalidCodeSize, h.typesSize/4, len(h.codeSizes))
+	}
+	// Parse the optional container sizes.
+	offset := offsetCodeKind + 2 + 2*len(h.codeSizes) + 1
+	if offset < len(b) && b[offset] == kindContainer {
+		kind, h.containerSizes, err = parseSectionList(b, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		if len(h.containerSizes) == 0 {
+			return nil, errInvalidContainerSectionSize
+		}
+		if len(h.containerSizes) > maxContainerSections {
+			return nil, fmt.Errorf("%w number of container section exceed: %v: have %v", errInvalidContainerSectionSize, maxContainerSections, len(h.containerSizes))
+		}
+		offset = offset + 2 + 4*len(h.containerSizes) + 1
+	}
+	// Parse data section header.
+	kind, h.dataSize, err = parseSection(b, offset)
+	if err != nil {
+		return nil, err
+	}
+	if kind != kindData {
+		return nil, fmt.Errorf("%w: found section %x instead", errMissingDataHeader, kind)
+	}
+	// Check for terminator.
+	offsetTerminator := offset + 3
+	if b[offsetTerminator] != 0 {
+		return nil, fmt.Errorf("%w: have %x", errMissingTerminator, b[offsetTerminator])
+	}
+	h.headerSize = offsetTerminator + 1
+
+	// Verify overall container size.
+	h.containerSize = h.headerSize + h.typesSize
+	for _, size := range h.codeSizes {
+		if size == 0 {
+			return nil, fmt.Errorf("%w: code section size must not be 0", errInvalidCodeSize)
+		}
+		h.containerSize += size
+	}
+	for _, size := range h.containerSizes {
+		if size == 0 {
+			return nil, fmt.Errorf("%w: container section size must not be 0", errInvalidContainerSectionSize)
+		}
+		h.containerSize += size
+	}
+	return h, nil
+}
+
+// unmarshalContainer decodes an EOF container. Containers at the top level must
+// contain their full data section, subcontainers may be truncated.
+func (c *Container) unmarshalContainer(b []byte, topLevel bool) error {
+	h, err := parseHeader(b)
+	if err != nil {
+		return err
+	}
+	if len(b) < h.containerSize {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), h.containerSize)
+	}
+	if topLevel && len(b) != h.containerSize+h.dataSize {
+		return fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), h.containerSize+h.dataSize)
+	}
+	if !topLevel && len(b) > h.containerSize+h.dataSize {
+		return fmt.Errorf("%w: have %d, want at most %d", errInvalidContainerSize, len(b), h.containerSize+h.dataSize)
+	}
+	// Parse types section.
+	idx := h.headerSize
+	var types = make([]*functionMetadata, 0, h.typesSize/4)
+	for i := 0; i < h.typesSize/4; i++ {
+		sig := &functionMetadata{
+			inputs:         b[idx+i*4],
+			outputs:        b[idx+i*4+1],
+			maxStackHeight: binary.BigEndian.Uint16(b[idx+i*4+2:]),
+		}
+		if sig.inputs > maxInputItems {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyInputs, i, sig.inputs)
+		}
+		if sig.outputs > maxOutputItems {
+			return fmt.Errorf("%w for section %d: have %d", errTooManyOutputs, i, sig.outputs)
+		}
+		if sig.maxStackHeight > maxStackHeight {
+			return fmt.Errorf("%w for section %d: have %d", errTooLargeMaxStackHeight, i, sig.maxStackHeight)
+		}
+		types = append(types, sig)
+	}
+	if types[0].inputs != 0 || types[0].outputs != nonReturningFunction {
+		return fmt.Errorf("%w: have %d, %d", errInvalidSection0Type, types[0].inputs, types[0].outputs)
+	}
+	c.types = types
+
+	// Parse code sections.
+	idx += h.typesSize
+	codeSections := make([][]byte, len(h.codeSizes))
+	for i, size := range h.codeSizes {
+		codeSections[i] = b[idx : idx+size]
+		idx += size
+	}
+	c.codeSections = codeSections
+
+	// Parse the optional container sections.
+	if len(h.containerSizes) != 0 {
+		subContainerCodes := make([][]byte, 0, len(h.containerSizes))
+		subContainers := make([]*Container, 0, len(h.containerSizes))
+		for i, size := range h.containerSizes {
+			if idx+size > len(b) {
+				return fmt.Errorf("%w: container section %d exceeds container", errInvalidContainerSectionSize, i)
+			}
+			subC := new(Container)
+			end := idx + size
+			if err := subC.unmarshalContainer(b[idx:end], false); err != nil {
+				if topLevel {
+					return fmt.Errorf("%w in sub container %d", err, i)
+				}
+				return err
+			}
+			subContainers = append(subContainers, subC)
+			subContainerCodes = append(subContainerCodes, b[idx:end])
+			idx += size
+		}
+		c.subContainers = subContainers
+		c.subContainerCodes = subContainerCodes
+	}
+	// Parse data section.
+	c.data = b[idx:]
+	c.dataSize = h.dataSize
+	return nil
+}
+
+// CodeSections returns the code sections of the container.
+func (c *Container) CodeSections() [][]byte {
+	return c.codeSections
+}
+
+// ValidateCode validates each code section of the container against the EOF v1
+// rule set, and recursively validates the subcontainers.
+func (c *Container) ValidateCode(jt *JumpTable, isInitCode bool) error {
+	refBy := refByReturnContract
+	if isInitCode {
+		refBy = refByEOFCreate
+	}
+	return c.validateSubContainer(jt, refBy)
+}
+
+// validateSubContainer validates the container, given the kind of instruction
+// referencing it: EOFCREATE for initcode and RETURNCONTRACT for runtime code.
+func (c *Container) validateSubContainer(jt *JumpTable, refBy int) error {
+	var (
+		visitedCode          = map[int]struct{}{0: {}}
+		visitedSubContainers = make(map[int]int)
+		toVisit              = []int{0}
+	)
+	// Validate the code sections reachable from the first one. Sections are
+	// only queued when first seen, so each one is validated at most once.
+	for len(toVisit) > 0 {
+		section := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+
+		res, err := validateCode(c.codeSections[section], section, c, jt)
+		if err != nil {
+			return err
+		}
+		if res.isInitCode && refBy == refByReturnContract {
+			return errIncompatibleContainerKind
+		}
+		if res.isRuntime && refBy == refByEOFCreate {
+			return errIncompatibleContainerKind
+		}
+		for idx := range res.visitedCode {
+			if _, ok := visitedCode[idx]; !ok {
+				visitedCode[idx] = struct{}{}
+				toVisit = append(toVisit, idx)
+			}
+		}
+		// A subcontainer must only be referenced by one kind of instruction.
+		for idx, reference := range res.visitedSubContainers {
+			if prev, ok := visitedSubContainers[idx]; ok && prev != reference {
+				return errIncompatibleContainerKind
+			}
+			visitedSubContainers[idx] = reference
+		}
+	}
+	// Make sure every code section is visited at least once.
+	if len(c.codeSections) != len(visitedCode) {
+		return errUnreachableCode
+	}
+	for idx, container := range c.subContainers {
+		reference, ok := visitedSubContainers[idx]
+		// Make sure every subcontainer is referenced.
+		if !ok {
+			return errOrphanedSubcontainer
+		}
+		if err := container.validateSubContainer(jt, reference); err != nil {
+			return err
+		}
+		// Only subcontainers deployed via RETURNCONTRACT may have a truncated
+		// data section, the rest of it is supplied as aux data.
+		if reference == refByEOFCreate && len(container.data) != container.dataSize {
+			return fmt.Errorf("%w: subcontainer %d has truncated data section", errInvalidContainerSize, idx)
+		}
+	}
+	return nil
+}
+
+// parseSection decodes a (kind, size) pair from an EOF header.
+func parseSection(b []byte, idx int) (kind, size int, err error) {
+	if idx+3 >= len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	size = int(binary.BigEndian.Uint16(b[idx+1:]))
+	return kind, size, nil
+}
+
+// parseSectionList decodes a (kind, len, []size) section list from an EOF
+// header, where each size is encoded in sizeBytes bytes.
+func parseSectionList(b []byte, idx int, sizeBytes int) (kind int, list []int, err error) {
+	if idx >= len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	list, err = decodeList(b, idx+1, sizeBytes)
+	if err != nil {
+		return 0, nil, err
+	}
+	return kind, list, nil
+}
+
+// decodeList decodes a list of sizes from an EOF header.
+func decodeList(b []byte, idx int, sizeBytes int) ([]int, error) {
+	if len(b) < idx+2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	count := binary.BigEndian.Uint16(b[idx:])
+	if count == 0 {
+		return nil, fmt.Errorf("%w: section list must not be empty", errInvalidSectionCount)
+	}
+	if count > maxCodeSections {
+		return nil, fmt.Errorf("%w: have %d, want at most %d", errInvalidSectionCount, count, maxCodeSections)
+	}
+	if len(b) <= idx+2+int(count)*sizeBytes {
+		return nil, io.ErrUnexpectedEOF
+	}
+	list := make([]int, count)
+	for i := 0; i < int(count); i++ {
+		offset := idx + 2 + sizeBytes*i
+		if sizeBytes == 2 {
+			list[i] = int(binary.BigEndian.Uint16(b[offset:]))
+		} else {
+			list[i] = int(binary.BigEndian.Uint32(b[offset:]))
+		}
+	}
+	return list, nil
+}
+
+// String returns a human readable string representation of the container.
+func (c *Container) String() string {
+	var output = []string{
+		"Header",
+		fmt.Sprintf("  - EOFMagic: %02x", eofMagic),
+		fmt.Sprintf("  - EOFVersion: %02x", eof1Version),
+		fmt.Sprintf("  - KindType: %02x", kindTypes),
+		fmt.Sprintf("  - TypesSize: %04x", len(c.types)*4),
+		fmt.Sprintf("  - KindCode: %02x", kindCode),
+		fmt.Sprintf("  - KindData: %02x", kindData),
+		fmt.Sprintf("  - DataSize: %04x", len(c.data)),
+		fmt.Sprintf("  - Number of code sections: %d", len(c.codeSections)),
+	}
+	for i, code := range c.codeSections {
+		output = append(output, fmt.Sprintf("    - Code section %d length: %04x", i, len(code)))
+	}
+	output = append(output, fmt.Sprintf("  - Number of subcontainers: %d", len(c.subContainers)))
+	if len(c.subContainers) > 0 {
+		for i, section := range c.subContainerCodes {
+			output = append(output, fmt.Sprintf("    - Subcontainer %d length: %04x", i, len(section)))
+		}
+	}
+	output = append(output, "Body")
+	for i, typ := range c.types {
+		output = append(output, fmt.Sprintf("  - Type %v: %x", i,
+			[]byte{typ.inputs, typ.outputs, byte(typ.maxStackHeight >> 8), byte(typ.maxStackHeight & 0x00ff)}))
+	}
+	for i, code := range c.codeSections {
+		output = append(output, fmt.Sprintf("  - Code section %d: %#x", i, code))
+	}
+	for i, section := range c.subContainerCodes {
+		output = append(output, fmt.Sprintf("  - Subcontainer %d: %x", i, section))
+	}
+	output = append(output, fmt.Sprintf("  - Data: %#x", c.data))
+	return strings.Join(output, "\n")
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// validateControlFlow runs the EIP-5450 stack validation over a code section,
+// and returns the number of instructions visited. All forward jumps widen the
+// stack height bounds of their destination, backward jumps are required to
+// arrive with the exact bounds the destination was first reached with.
+func validateControlFlow(code []byte, section int, metadata []*functionMetadata, jt *JumpTable) (int, error) {
+	var (
+		maxStackHeight = int(metadata[section].inputs)
+		visitCount     = 0
+		stackBoundsMin = make([]int, len(code))
+		stackBoundsMax = make([]int, len(code))
+	)
+	for i := range stackBoundsMax {
+		stackBoundsMax[i] = -1 // unvisited
+	}
+	stackBoundsMin[0], stackBoundsMax[0] = int(metadata[section].inputs), int(metadata[section].inputs)
+
+	// visit records the stack bounds when reaching instruction next from pos.
+	visit := func(pos, next, min, max int) error {
+		if next >= len(code) {
+			return fmt.Errorf("%w: pos %d", errInvalidCodeTermination, pos)
+		}
+		if next <= pos {
+			// Backward jump, the bounds must match exactly.
+			if stackBoundsMin[next] != min || stackBoundsMax[next] != max {
+				return fmt.Errorf("%w: have [%d, %d], want [%d, %d], pos %d", errInvalidBackwardJump, min, max, stackBoundsMin[next], stackBoundsMax[next], pos)
+			}
+			return nil
+		}
+		if stackBoundsMax[next] == -1 {
+			stackBoundsMin[next], stackBoundsMax[next] = min, max
+			return nil
+		}
+		if min < stackBoundsMin[next] {
+			stackBoundsMin[next] = min
+		}
+		if max > stackBoundsMax[next] {
+			stackBoundsMax[next] = max
+		}
+		return nil
+	}
+	for pos := 0; pos < len(code); {
+		op := OpCode(code[pos])
+		size := int(immediates[op])
+		if op == RJUMPV {
+			size = 1 + 2*(int(code[pos+1])+1)
+		}
+		currentStackMin, currentStackMax := stackBoundsMin[pos], stackBoundsMax[pos]
+		if currentStackMax == -1 {
+			// The instruction is never reached in a forward pass.
+			return 0, fmt.Errorf("%w: pos %d", errUnreachableCode, pos)
+		}
+		visitCount++
+
+		switch op {
+		case CALLF:
+			arg := binary.BigEndian.Uint16(code[pos+1:])
+			if err := metadata[arg].checkInputs(currentStackMin); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			if err := metadata[arg].checkStackMax(currentStackMax); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			currentStackMin += metadata[arg].stackDelta()
+			currentStackMax += metadata[arg].stackDelta()
+		case RETF:
+			if currentStackMin != currentStackMax || currentStackMin != int(metadata[section].outputs) {
+				return 0, fmt.Errorf("%w: have [%d, %d], want %d, pos %d", errInvalidOutputs, currentStackMin, currentStackMax, metadata[section].outputs, pos)
+			}
+		case JUMPF:
+			arg := binary.BigEndian.Uint16(code[pos+1:])
+			if err := metadata[arg].checkStackMax(currentStackMax); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+			if metadata[arg].isReturning() {
+				want := int(metadata[section].outputs) + int(metadata[arg].inputs) - int(metadata[arg].outputs)
+				if currentStackMin != currentStackMax || currentStackMin != want {
+					return 0, fmt.Errorf("%w: have [%d, %d], want %d, pos %d", errInvalidOutputs, currentStackMin, currentStackMax, want, pos)
+				}
+			} else if err := metadata[arg].checkInputs(currentStackMin); err != nil {
+				return 0, fmt.Errorf("%w: pos %d", err, pos)
+			}
+		case DUPN:
+			if arg := int(code[pos+1]) + 1; arg > currentStackMin {
+				return 0, fmt.Errorf("%w: have %d, want %d, pos %d", errEOFStackUnderflow, currentStackMin, arg, pos)
+			}
+			currentStackMin++
+			currentStackMax++
+		case SWAPN:
+			if arg := int(code[pos+1]) + 2; arg > currentStackMin {
+				return 0, fmt.Errorf("%w: have %d, want %d, pos %d", errEOFStackUnderflow, currentStackMin, arg, pos)
+			}
+		case EXCHANGE:
+			n, m := int(code[pos+1]>>4)+1, int(code[pos+1]&0x0f)+1
+			if n+m+1 > currentStackMin {
+				return 0, fmt.Errorf("%w: have %d, want %d, pos %d", errEOFStackUnderflow, currentStackMin, n+m+1, pos)
+			}
+		default:
+			pop := jt[op].minStack
+			push := int(params.StackLimit) + pop - jt[op].maxStack
+			if pop > currentStackMin {
+				return 0, fmt.Errorf("%w: have %d, want %d, pos %d", errEOFStackUnderflow, currentStackMin, pop, pos)
+			}
+			currentStackMin += push - pop
+			currentStackMax += push - pop
+		}
+		if currentStackMax > int(params.StackLimit) {
+			return 0, fmt.Errorf("%w: have %d, pos %d", errEOFStackOverflow, currentStackMax, pos)
+		}
+		if currentStackMax > maxStackHeight {
+			maxStackHeight = currentStackMax
+		}
+		// Propagate the stack bounds to the successors.
+		next := pos + 1 + size
+		switch op {
+		case RJUMP:
+			if err := visit(pos, next+parseInt16(code[pos+1:]), currentStackMin, currentStackMax); err != nil {
+				return 0, err
+			}
+		case RJUMPI:
+			if err := visit(pos, next+parseInt16(code[pos+1:]), currentStackMin, currentStackMax); err != nil {
+				return 0, err
+			}
+			if err := visit(pos, next, currentStackMin, currentStackMax); err != nil {
+				return 0, err
+			}
+		case RJUMPV:
+			for i := 0; i < int(code[pos+1])+1; i++ {
+				if err := visit(pos, next+parseInt16(code[pos+2+2*i:]), currentStackMin, currentStackMax); err != nil {
+					return 0, err
+				}
+			}
+			if err := visit(pos, next, currentStackMin, currentStackMax); err != nil {
+				return 0, err
+			}
+		default:
+			if !terminals[op] {
+				if err := visit(pos, next, currentStackMin, currentStackMax); err != nil {
+					return 0, err
+				}
+			}
+		}
+		pos = next
+	}
+	if maxStackHeight != int(metadata[section].maxStackHeight) {
+		return 0, fmt.Errorf("%w in code section %d: have %d, want %d", errInvalidMaxStackHeight, section, maxStackHeight, metadata[section].maxStackHeight)
+	}
+	return visitCount, nil
+}
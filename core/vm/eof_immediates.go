@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+// immediates denotes how many immediate bytes an operation uses. This information
+// is not required during runtime, only during EOF-validation, so is not
+// places into the op-struct in the instruction table.
+// Note: the immediates is fork-agnostic, and assumes that validity of opcodes at
+// the given time is performed elsewhere.
+var immediates [256]uint8
+
+// terminals denotes whether instructions can be the final opcode in a code section.
+// Note: the terminals is fork-agnostic, and assumes that validity of opcodes at
+// the given time is performed elsewhere.
+var terminals [256]bool
+
+func init() {
+	// The legacy pushes
+	for i := uint8(1); i < 33; i++ {
+		immediates[int(PUSH0)+int(i)] = i
+	}
+	// And new eof opcodes.
+	immediates[DATALOADN] = 2
+	immediates[RJUMP] = 2
+	immediates[RJUMPI] = 2
+	immediates[RJUMPV] = 3
+	immediates[CALLF] = 2
+	immediates[JUMPF] = 2
+	immediates[DUPN] = 1
+	immediates[SWAPN] = 1
+	immediates[EXCHANGE] = 1
+	immediates[EOFCREATE] = 1
+	immediates[RETURNCONTRACT] = 1
+
+	// Define the terminals.
+	terminals[STOP] = true
+	terminals[RETF] = true
+	terminals[JUMPF] = true
+	terminals[RETURNCONTRACT] = true
+	terminals[RETURN] = true
+	terminals[REVERT] = true
+	terminals[INVALID] = true
+}
+
+// EOFImmediateSize returns the number of immediate bytes following op in EOF
+// code. The jump table of RJUMPV is variably sized, so only the byte encoding
+// its length is accounted for.
+func EOFImmediateSize(op OpCode) int {
+	if op == RJUMPV {
+		return 1
+	}
+	return int(immediates[op])
+}
@@ -0,0 +1,372 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// All the instructions below only ever run on validated EOF code, so the
+// immediate arguments are known to be in bounds and the stack heights to be
+// consistent.
+
+// opRjump implements the RJUMP opcode.
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	offset := parseInt16(scope.Contract.Code[*pc+1:])
+	// Move past the opcode and its immediate, then apply the relative offset.
+	*pc = uint64(int64(*pc+3)+int64(offset)) - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opRjumpi implements the RJUMPI opcode.
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	condition := scope.Stack.pop()
+	if condition.IsZero() {
+		*pc += 2
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+// opRjumpv implements the RJUMPV opcode.
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code  = scope.Contract.Code
+		count = uint64(code[*pc+1]) + 1
+		idx   = scope.Stack.pop()
+	)
+	if !idx.LtUint64(count) {
+		// Out-of-bounds index, continue with the next instruction.
+		*pc += 1 + count*2
+		return nil, nil
+	}
+	offset := parseInt16(code[*pc+2+2*idx.Uint64():])
+	*pc = uint64(int64(*pc+2+count*2)+int64(offset)) - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opCallf implements the CALLF opcode.
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		container = scope.Contract.container
+		idx       = binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:])
+		typ       = container.types[idx]
+	)
+	if height := scope.Stack.len() + int(typ.maxStackHeight) - int(typ.inputs); height > int(params.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: height, limit: int(params.StackLimit)}
+	}
+	if len(scope.Contract.returnStack) >= maxReturnStackHeight {
+		return nil, ErrReturnStackExceeded
+	}
+	scope.Contract.returnStack = append(scope.Contract.returnStack, returnContext{section: scope.Contract.codeSection, pc: *pc + 3})
+	scope.Contract.enterSection(uint64(idx))
+	*pc = math.MaxUint64 // pc will be increased to 0 by the interpreter loop
+	return nil, nil
+}
+
+// opRetf implements the RETF opcode.
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	ctx := scope.Contract.returnStack[len(scope.Contract.returnStack)-1]
+	scope.Contract.returnStack = scope.Contract.returnStack[:len(scope.Contract.returnStack)-1]
+	scope.Contract.enterSection(ctx.section)
+	*pc = ctx.pc - 1 // pc will be increased by the interpreter loop
+	return nil, nil
+}
+
+// opJumpf implements the JUMPF opcode.
+func opJumpf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		container = scope.Contract.container
+		idx       = binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:])
+		typ       = container.types[idx]
+	)
+	if height := scope.Stack.len() + int(typ.maxStackHeight) - int(typ.inputs); height > int(params.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: height, limit: int(params.StackLimit)}
+	}
+	scope.Contract.enterSection(uint64(idx))
+	*pc = math.MaxUint64 // pc will be increased to 0 by the interpreter loop
+	return nil, nil
+}
+
+// opDupN implements the DUPN opcode.
+func opDupN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 1
+	scope.Stack.dup(n)
+	*pc += 1
+	return nil, nil
+}
+
+// opSwapN implements the SWAPN opcode.
+func opSwapN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	n := int(scope.Contract.Code[*pc+1]) + 2
+	scope.Stack.swap(n)
+	*pc += 1
+	return nil, nil
+}
+
+// opExchange implements the EXCHANGE opcode.
+func opExchange(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		imm = scope.Contract.Code[*pc+1]
+		n   = int(imm>>4) + 1
+		m   = int(imm&0x0f) + 1
+	)
+	scope.Stack.exchange(n, m)
+	*pc += 1
+	return nil, nil
+}
+
+// opDataLoad implements the DATALOAD opcode.
+func opDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		index = scope.Stack.peek()
+		data  = scope.Contract.container.data
+	)
+	offset, overflow := index.Uint64WithOverflow()
+	if overflow {
+		offset = math.MaxUint64
+	}
+	index.SetBytes32(getData(data, offset, 32))
+	return nil, nil
+}
+
+// opDataLoadN implements the DATALOADN opcode.
+func opDataLoadN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		offset = uint64(binary.BigEndian.Uint16(scope.Contract.Code[*pc+1:]))
+		data   = scope.Contract.container.data
+	)
+	scope.Stack.push(new(uint256.Int).SetBytes32(getData(data, offset, 32)))
+	*pc += 2
+	return nil, nil
+}
+
+// opDataSize implements the DATASIZE opcode.
+func opDataSize(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	length := len(scope.Contract.container.data)
+	scope.Stack.push(new(uint256.Int).SetUint64(uint64(length)))
+	return nil, nil
+}
+
+// opDataCopy implements the DATACOPY opcode.
+func opDataCopy(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset  = scope.Stack.pop()
+		dataOffset = scope.Stack.pop()
+		length     = scope.Stack.pop()
+	)
+	offset, overflow := dataOffset.Uint64WithOverflow()
+	if overflow {
+		offset = math.MaxUint64
+	}
+	// These values are checked for overflow during gas cost calculation
+	data := getData(scope.Contract.container.data, offset, length.Uint64())
+	scope.Memory.Set(memOffset.Uint64(), length.Uint64(), data)
+	return nil, nil
+}
+
+// opReturnDataLoad implements the RETURNDATALOAD opcode.
+func opReturnDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	index := scope.Stack.peek()
+	offset, overflow := index.Uint64WithOverflow()
+	if overflow {
+		offset = math.MaxUint64
+	}
+	index.SetBytes32(getData(interpreter.returnData, offset, 32))
+	return nil, nil
+}
+
+// opReturnDataCopyEOF implements the RETURNDATACOPY opcode for EOF code, which
+// pads out-of-bounds reads with zeroes instead of halting.
+func opReturnDataCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset  = scope.Stack.pop()
+		dataOffset = scope.Stack.pop()
+		length     = scope.Stack.pop()
+	)
+	offset, overflow := dataOffset.Uint64WithOverflow()
+	if overflow {
+		offset = math.MaxUint64
+	}
+	// These values are checked for overflow during gas cost calculation
+	data := getData(interpreter.returnData, offset, length.Uint64())
+	scope.Memory.Set(memOffset.Uint64(), length.Uint64(), data)
+	return nil, nil
+}
+
+// extCallStatus converts the result of an EOF call instruction into the status
+// code pushed onto the stack.
+func extCallStatus(err error) uint64 {
+	switch err {
+	case nil:
+		return 0 // success
+	case ErrExecutionReverted, ErrDepth, ErrInsufficientBalance:
+		return 1 // revert, or the call was not attempted
+	default:
+		return 2 // failure
+	}
+}
+
+// opExtCall implements the EXTCALL opcode.
+func opExtCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	// The callee gas was computed by the gas function.
+	gas := interpreter.evm.callGasTemp
+	// Pop the call parameters.
+	addr, inOffset, inSize, value := stack.pop(), stack.pop(), stack.pop(), stack.pop()
+	toAddr := common.Address(addr.Bytes20())
+	// Get the arguments from the memory.
+	args := scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+
+	if interpreter.readOnly && !value.IsZero() {
+		return nil, ErrWriteProtection
+	}
+	if gas == 0 {
+		// Not enough gas left for the callee, fail without calling.
+		interpreter.returnData = nil
+		stack.push(uint256.NewInt(1))
+		return nil, nil
+	}
+	ret, returnGas, err := interpreter.evm.Call(scope.Contract, toAddr, args, gas, &value)
+
+	stack.push(uint256.NewInt(extCallStatus(err)))
+	scope.Contract.Gas += returnGas
+
+	interpreter.returnData = ret
+	return ret, nil
+}
+
+// opExtDelegateCall implements the EXTDELEGATECALL opcode.
+func opExtDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	// The callee gas was computed by the gas function.
+	gas := interpreter.evm.callGasTemp
+	// Pop the call parameters.
+	addr, inOffset, inSize := stack.pop(), stack.pop(), stack.pop()
+	toAddr := common.Address(addr.Bytes20())
+	// Get the arguments from the memory.
+	args := scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+
+	// Only EOF contracts may be delegated to, calls to legacy contracts fail
+	// without calling.
+	if gas == 0 || !hasEOFMagic(interpreter.evm.StateDB.GetCode(toAddr)) {
+		interpreter.returnData = nil
+		stack.push(uint256.NewInt(1))
+		scope.Contract.Gas += gas
+		return nil, nil
+	}
+	ret, returnGas, err := interpreter.evm.DelegateCall(scope.Contract, toAddr, args, gas)
+
+	stack.push(uint256.NewInt(extCallStatus(err)))
+	scope.Contract.Gas += returnGas
+
+	interpreter.returnData = ret
+	return ret, nil
+}
+
+// opExtStaticCall implements the EXTSTATICCALL opcode.
+func opExtStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	stack := scope.Stack
+	// The callee gas was computed by the gas function.
+	gas := interpreter.evm.callGasTemp
+	// Pop the call parameters.
+	addr, inOffset, inSize := stack.pop(), stack.pop(), stack.pop()
+	toAddr := common.Address(addr.Bytes20())
+	// Get the arguments from the memory.
+	args := scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+
+	if gas == 0 {
+		// Not enough gas left for the callee, fail without calling.
+		interpreter.returnData = nil
+		stack.push(uint256.NewInt(1))
+		return nil, nil
+	}
+	ret, returnGas, err := interpreter.evm.StaticCall(scope.Contract, toAddr, args, gas)
+
+	stack.push(uint256.NewInt(extCallStatus(err)))
+	scope.Contract.Gas += returnGas
+
+	interpreter.returnData = ret
+	return ret, nil
+}
+
+// opEOFCreate implements the EOFCREATE opcode.
+func opEOFCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.readOnly {
+		return nil, ErrWriteProtection
+	}
+	var (
+		idx          = int(scope.Contract.Code[*pc+1])
+		value        = scope.Stack.pop()
+		salt         = scope.Stack.pop()
+		offset, size = scope.Stack.pop(), scope.Stack.pop()
+		input        = scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64()))
+		initcode     = scope.Contract.container.subContainerCodes[idx]
+	)
+	// Charge for hashing the initcontainer into the contract address.
+	if !scope.Contract.UseGas(params.Keccak256WordGas * toWordSize(uint64(len(initcode)))) {
+		return nil, ErrOutOfGas
+	}
+	// Apply EIP150
+	gas := scope.Contract.Gas
+	gas -= gas / 64
+	scope.Contract.UseGas(gas)
+
+	res, addr, returnGas, suberr := interpreter.evm.EOFCreate(scope.Contract, initcode, input, gas, &value, &salt)
+	// Push item on the stack based on the returned error.
+	stackvalue := size
+	if suberr != nil {
+		stackvalue.Clear()
+	} else {
+		stackvalue.SetBytes(addr.Bytes())
+	}
+	scope.Stack.push(&stackvalue)
+	scope.Contract.Gas += returnGas
+	*pc += 1
+
+	if suberr == ErrExecutionReverted {
+		interpreter.returnData = res // set REVERT data to return data buffer
+		return res, nil
+	}
+	interpreter.returnData = nil // clear dirty return data buffer
+	return nil, nil
+}
+
+// opReturnContract implements the RETURNCONTRACT opcode.
+func opReturnContract(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		idx          = int(scope.Contract.Code[*pc+1])
+		offset, size = scope.Stack.pop(), scope.Stack.pop()
+		auxData      = scope.Memory.GetPtr(int64(offset.Uint64()), int64(size.Uint64()))
+		deploy       = *scope.Contract.container.subContainers[idx]
+	)
+	// Append the aux data to the data section of the deployed container, which
+	// must fill up the declared data size and fit into the header.
+	data := make([]byte, 0, len(deploy.data)+len(auxData))
+	data = append(append(data, deploy.data...), auxData...)
+	if len(data) < deploy.dataSize || len(data) > math.MaxUint16 {
+		return nil, ErrInvalidAuxData
+	}
+	deploy.data, deploy.dataSize = data, len(data)
+
+	return deploy.MarshalBinary(), errStopToken
+}
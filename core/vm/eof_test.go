@@ -0,0 +1,397 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+func TestEOFMarshaling(t *testing.T) {
+	for i, test := range []struct {
+		want Container
+	}{
+		{
+			want: Container{
+				types:        []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+				codeSections: [][]byte{common.Hex2Bytes("604200")},
+				data:         []byte{0x01, 0x02, 0x03},
+				dataSize:     3,
+			},
+		},
+		{
+			want: Container{
+				types: []*functionMetadata{
+					{inputs: 0, outputs: 0x80, maxStackHeight: 1},
+					{inputs: 2, outputs: 3, maxStackHeight: 4},
+					{inputs: 1, outputs: 1, maxStackHeight: 1},
+				},
+				codeSections: [][]byte{
+					common.Hex2Bytes("604200"),
+					common.Hex2Bytes("6042604200"),
+					common.Hex2Bytes("00"),
+				},
+				data:     []byte{},
+				dataSize: 0,
+			},
+		},
+	} {
+		var (
+			b   = test.want.MarshalBinary()
+			got Container
+		)
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Fatalf("test %d: failed to decode container: %v", i, err)
+		}
+		if !bytes.Equal(got.MarshalBinary(), b) {
+			t.Fatalf("test %d: roundtrip mismatch: have %x, want %x", i, got.MarshalBinary(), b)
+		}
+		if len(got.codeSections) != len(test.want.codeSections) {
+			t.Fatalf("test %d: code section count mismatch: have %d, want %d", i, len(got.codeSections), len(test.want.codeSections))
+		}
+		for j, code := range test.want.codeSections {
+			if !bytes.Equal(got.codeSections[j], code) {
+				t.Fatalf("test %d: code section %d mismatch: have %x, want %x", i, j, got.codeSections[j], code)
+			}
+		}
+	}
+}
+
+func TestEOFSubcontainer(t *testing.T) {
+	var subcontainer = new(Container)
+	if err := subcontainer.UnmarshalBinary(common.Hex2Bytes("ef00010100040200010001ff00000000800000fe")); err != nil {
+		t.Fatal(err)
+	}
+	container := Container{
+		types:             []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		codeSections:      [][]byte{common.Hex2Bytes("546000e000")},
+		subContainers:     []*Container{subcontainer},
+		subContainerCodes: [][]byte{common.Hex2Bytes("ef00010100040200010001ff00000000800000fe")},
+		data:              []byte{0x01, 0x02, 0x03},
+		dataSize:          3,
+	}
+	var (
+		b   = container.MarshalBinary()
+		got Container
+	)
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.MarshalBinary(), b) {
+		t.Fatalf("roundtrip mismatch: have %x, want %x", got.MarshalBinary(), b)
+	}
+	if len(got.subContainers) != 1 || !bytes.Equal(got.subContainers[0].MarshalBinary(), subcontainer.MarshalBinary()) {
+		t.Fatalf("subcontainer mismatch")
+	}
+}
+
+func TestEOFParseErrors(t *testing.T) {
+	for i, test := range []struct {
+		code string
+		want error
+	}{
+		{"ef0002", errInvalidVersion},
+		{"ef00010100040200010001ff000000008000", errInvalidContainerSize},       // truncated body
+		{"ef00010100040200010001ff00000000800000fe00", errInvalidContainerSize}, // trailing bytes
+		{"ef00010200040200010001ff00000000800000fe", errMissingTypeHeader},
+		{"ef00010100040200000001ff00000000800000fe", errInvalidSectionCount},
+		{"ef00010100040200010001ff00000001800000fe", errInvalidSection0Type},
+		{"ef00010100040200010001ff0000ff00800000fe", errMissingTerminator},
+	} {
+		var c Container
+		if err := c.UnmarshalBinary(common.Hex2Bytes(test.code)); !errors.Is(err, test.want) {
+			t.Errorf("test %d: have error %v, want %v", i, err, test.want)
+		}
+	}
+}
+
+func TestEOFValidation(t *testing.T) {
+	for i, test := range []struct {
+		code     []byte
+		metadata []*functionMetadata
+		data     []byte
+		want     error
+	}{
+		{
+			// PUSH1 1, POP, STOP
+			code:     []byte{byte(PUSH1), 0x01, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		},
+		{
+			// CALLER, POP, INVALID
+			code:     []byte{byte(CALLER), byte(POP), byte(INVALID)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		},
+		{
+			// PUSH0, RJUMPI +1, STOP, STOP
+			code:     []byte{byte(PUSH0), byte(RJUMPI), 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		},
+		{
+			// PUSH0, RJUMPV [+0, +1], STOP, STOP
+			code:     []byte{byte(PUSH0), byte(RJUMPV), 0x01, 0x00, 0x00, 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+		},
+		{
+			// RJUMP -3 (infinite loop)
+			code:     []byte{byte(RJUMP), 0xff, 0xfd},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 0}},
+		},
+		{
+			// DATALOADN 0, POP, STOP
+			code:     []byte{byte(DATALOADN), 0x00, 0x00, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			data:     make([]byte, 32),
+		},
+		{
+			// DATALOADN 1 (out of bounds), POP, STOP
+			code:     []byte{byte(DATALOADN), 0x00, 0x01, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			data:     make([]byte, 32),
+			want:     errInvalidDataloadNArgument,
+		},
+		{
+			// DUP1, STOP (stack underflow)
+			code:     []byte{byte(DUP1), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errEOFStackUnderflow,
+		},
+		{
+			// PUSH1 1, POP, STOP (wrong max stack height)
+			code:     []byte{byte(PUSH1), 0x01, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 2}},
+			want:     errInvalidMaxStackHeight,
+		},
+		{
+			// PUSH1 1 (missing termination)
+			code:     []byte{byte(PUSH1), 0x01},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errInvalidCodeTermination,
+		},
+		{
+			// PUSH2 (truncated immediate)
+			code:     []byte{byte(PUSH2), 0x01},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errTruncatedImmediate,
+		},
+		{
+			// JUMP (undefined in EOF)
+			code:     []byte{byte(PUSH0), byte(JUMP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errUndefinedInstruction,
+		},
+		{
+			// RJUMP +1, STOP, STOP (unreachable code)
+			code:     []byte{byte(RJUMP), 0x00, 0x01, byte(STOP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 0}},
+			want:     errUnreachableCode,
+		},
+		{
+			// RJUMP +1 into immediate
+			code:     []byte{byte(RJUMP), 0x00, 0x01, byte(PUSH1), 0x00, byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errInvalidJumpDest,
+		},
+		{
+			// PUSH0, RJUMP -4 (stack height mismatch on backward jump)
+			code:     []byte{byte(PUSH0), byte(RJUMP), 0xff, 0xfc},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}},
+			want:     errInvalidBackwardJump,
+		},
+		{
+			// CALLF 1 (section out of range)
+			code:     []byte{byte(CALLF), 0x00, 0x01, byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 0}},
+			want:     errInvalidSectionArgument,
+		},
+		{
+			// RETF in non-returning section
+			code:     []byte{byte(RETF)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 0}},
+			want:     errInvalidNonReturningFlag,
+		},
+	} {
+		container := &Container{
+			types:        test.metadata,
+			codeSections: [][]byte{test.code},
+			data:         test.data,
+			dataSize:     len(test.data),
+		}
+		err := container.ValidateCode(&eofInstructionSet, false)
+		if !errors.Is(err, test.want) {
+			t.Errorf("test %d (%s): unexpected error (have %v, want %v)", i, common.Bytes2Hex(test.code), err, test.want)
+		}
+	}
+}
+
+func TestEOFValidationFunctions(t *testing.T) {
+	// Section 0 calls section 1, which returns a value and jumps to section 2
+	// which is non-returning.
+	container := &Container{
+		types: []*functionMetadata{
+			{inputs: 0, outputs: 0x80, maxStackHeight: 1},
+			{inputs: 0, outputs: 1, maxStackHeight: 1},
+			{inputs: 0, outputs: 0x80, maxStackHeight: 0},
+		},
+		codeSections: [][]byte{
+			{byte(CALLF), 0x00, 0x01, byte(POP), byte(JUMPF), 0x00, 0x02},
+			{byte(PUSH0), byte(RETF)},
+			{byte(STOP)},
+		},
+	}
+	if err := container.ValidateCode(&eofInstructionSet, false); err != nil {
+		t.Fatalf("valid container rejected: %v", err)
+	}
+	// Section 2 is never referenced.
+	container.codeSections[0] = []byte{byte(CALLF), 0x00, 0x01, byte(POP), byte(STOP)}
+	if err := container.ValidateCode(&eofInstructionSet, false); !errors.Is(err, errUnreachableCode) {
+		t.Fatalf("unexpected error: have %v, want %v", err, errUnreachableCode)
+	}
+	// CALLF into a non-returning section.
+	container.codeSections[0] = []byte{byte(CALLF), 0x00, 0x02, byte(STOP)}
+	if err := container.ValidateCode(&eofInstructionSet, false); !errors.Is(err, errInvalidCallArgument) {
+		t.Fatalf("unexpected error: have %v, want %v", err, errInvalidCallArgument)
+	}
+}
+
+// eofTestConfig returns a chain config with EOF activated.
+func eofTestConfig() *params.ChainConfig {
+	config := *params.AllEthashProtocolChanges
+	zero := uint64(0)
+	config.ShanghaiTime = &zero
+	config.CancunTime = &zero
+	config.PragueTime = &zero
+	config.OsakaTime = &zero
+	return &config
+}
+
+func TestEOFExecution(t *testing.T) {
+	var (
+		data    = common.LeftPadBytes([]byte{0x2a}, 32)
+		runtime = &Container{
+			types: []*functionMetadata{
+				{inputs: 0, outputs: 0x80, maxStackHeight: 2},
+				{inputs: 0, outputs: 1, maxStackHeight: 1},
+			},
+			codeSections: [][]byte{
+				// CALLF 1, PUSH0, MSTORE, PUSH1 32, PUSH0, RETURN
+				{byte(CALLF), 0x00, 0x01, byte(PUSH0), byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH0), byte(RETURN)},
+				// DATALOADN 0, RETF
+				{byte(DATALOADN), 0x00, 0x00, byte(RETF)},
+			},
+			data:     data,
+			dataSize: len(data),
+		}
+		initcode = &Container{
+			types: []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 2}},
+			codeSections: [][]byte{
+				// PUSH0, PUSH0, RETURNCONTRACT 0
+				{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0x00},
+			},
+			subContainers:     []*Container{runtime},
+			subContainerCodes: [][]byte{runtime.MarshalBinary()},
+		}
+		sender     = common.HexToAddress("0x1000")
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		vmctx      = BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+			Random:      &common.Hash{},
+		}
+		evm = NewEVM(vmctx, TxContext{}, statedb, eofTestConfig(), Config{})
+	)
+	statedb.CreateAccount(sender)
+
+	// Deploy the container via a creation transaction, with trailing calldata.
+	input := append(initcode.MarshalBinary(), 0x01, 0x02)
+	_, address, _, err := evm.Create(AccountRef(sender), input, 1_000_000, new(uint256.Int))
+	if err != nil {
+		t.Fatalf("failed to create EOF contract: %v", err)
+	}
+	if have, want := statedb.GetCode(address), runtime.MarshalBinary(); !bytes.Equal(have, want) {
+		t.Fatalf("deployed code mismatch: have %x, want %x", have, want)
+	}
+	// Execute the deployed container.
+	ret, _, err := evm.Call(AccountRef(sender), address, nil, 1_000_000, new(uint256.Int))
+	if err != nil {
+		t.Fatalf("failed to call EOF contract: %v", err)
+	}
+	if !bytes.Equal(ret, data) {
+		t.Fatalf("return data mismatch: have %x, want %x", ret, data)
+	}
+	// Initcontainers must not deploy code with STOP or RETURN.
+	invalid := *initcode
+	invalid.types = []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 1}}
+	invalid.codeSections = [][]byte{{byte(PUSH0), byte(POP), byte(STOP)}}
+	if _, _, _, err := evm.Create(AccountRef(sender), invalid.MarshalBinary(), 1_000_000, new(uint256.Int)); !errors.Is(err, ErrInvalidEOFInitcode) {
+		t.Fatalf("unexpected error: have %v, want %v", err, ErrInvalidEOFInitcode)
+	}
+}
+
+// Tests that Prague chains without Osaka keep executing EOF-prefixed code with
+// the legacy rules.
+func TestEOFDisabledBeforeOsaka(t *testing.T) {
+	var (
+		container = &Container{
+			types:        []*functionMetadata{{inputs: 0, outputs: 0x80, maxStackHeight: 0}},
+			codeSections: [][]byte{{byte(STOP)}},
+		}
+		code       = container.MarshalBinary()
+		eofAddr    = common.HexToAddress("0xe0f")
+		callerAddr = common.HexToAddress("0xca11")
+		sender     = common.HexToAddress("0x1000")
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		vmctx      = BlockContext{
+			CanTransfer: func(StateDB, common.Address, *uint256.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *uint256.Int) {},
+			BlockNumber: big.NewInt(1),
+			Random:      &common.Hash{},
+		}
+		config = eofTestConfig()
+	)
+	config.OsakaTime = nil
+	evm := NewEVM(vmctx, TxContext{}, statedb, config, Config{})
+
+	statedb.CreateAccount(sender)
+	statedb.SetCode(eofAddr, code)
+	// PUSH20 eofAddr, EXTCODESIZE, PUSH0, MSTORE, PUSH1 32, PUSH0, RETURN
+	caller := append([]byte{byte(PUSH20)}, eofAddr.Bytes()...)
+	caller = append(caller, byte(EXTCODESIZE), byte(PUSH0), byte(MSTORE), byte(PUSH1), 0x20, byte(PUSH0), byte(RETURN))
+	statedb.SetCode(callerAddr, caller)
+
+	ret, _, err := evm.Call(AccountRef(sender), callerAddr, nil, 1_000_000, new(uint256.Int))
+	if err != nil {
+		t.Fatalf("failed to call legacy contract: %v", err)
+	}
+	if have, want := new(big.Int).SetBytes(ret).Uint64(), uint64(len(code)); have != want {
+		t.Fatalf("EXTCODESIZE mismatch: have %d, want %d", have, want)
+	}
+	// EOF initcode is not recognized and fails as legacy code.
+	if _, _, _, err := evm.Create(AccountRef(sender), code, 1_000_000, new(uint256.Int)); errors.Is(err, ErrInvalidEOFInitcode) || err == nil {
+		t.Fatalf("unexpected error: have %v, want legacy execution failure", err)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// EOF container format errors.
+var (
+	errInvalidMagic                = errors.New("invalid magic")
+	errInvalidVersion              = errors.New("invalid version")
+	errMissingTypeHeader           = errors.New("missing type header")
+	errInvalidTypeSize             = errors.New("invalid type section size")
+	errMissingCodeHeader           = errors.New("missing code header")
+	errInvalidCodeSize             = errors.New("invalid code size")
+	errInvalidContainerSectionSize = errors.New("invalid container section size")
+	errInvalidSectionCount         = errors.New("invalid section count")
+	errMissingDataHeader           = errors.New("missing data header")
+	errMissingTerminator           = errors.New("missing header terminator")
+	errTooManyInputs               = errors.New("invalid type content, too many inputs")
+	errTooManyOutputs              = errors.New("invalid type content, too many outputs")
+	errInvalidSection0Type         = errors.New("invalid section 0 type, input and output should be zero and non-returning (0x80)")
+	errTooLargeMaxStackHeight      = errors.New("invalid type content, max stack height exceeds limit")
+	errInvalidContainerSize        = errors.New("invalid container size")
+)
+
+// EOF code validation errors.
+var (
+	errUndefinedInstruction      = errors.New("undefined instruction")
+	errTruncatedImmediate        = errors.New("truncated immediate")
+	errInvalidSectionArgument    = errors.New("invalid section argument")
+	errInvalidCallArgument       = errors.New("callf into non-returning section")
+	errInvalidDataloadNArgument  = errors.New("invalid dataloadN argument")
+	errInvalidJumpDest           = errors.New("invalid jump destination")
+	errInvalidBackwardJump       = errors.New("invalid backward jump")
+	errInvalidOutputs            = errors.New("invalid number of outputs")
+	errInvalidMaxStackHeight     = errors.New("invalid max stack height")
+	errInvalidCodeTermination    = errors.New("invalid code termination")
+	errInvalidContainerArgument  = errors.New("invalid container argument")
+	errOrphanedSubcontainer      = errors.New("subcontainer not referenced at all")
+	errIncompatibleContainerKind = errors.New("incompatible container kind")
+	errUnreachableCode           = errors.New("unreachable code")
+	errInvalidNonReturningFlag   = errors.New("invalid non-returning flag, bad RETF")
+	errInvalidJumpf              = errors.New("invalid jumpf target, more target outputs than current section")
+	errEOFStackUnderflow         = errors.New("stack underflow")
+	errEOFStackOverflow          = errors.New("stack overflow")
+)
+
+// The kinds of instructions a subcontainer may be referenced by, which
+// determine whether it holds initcode or runtime code.
+const (
+	refByReturnContract = iota + 1
+	refByEOFCreate
+)
+
+// validationResult collects the references found while validating a code
+// section, used to validate the container as a whole.
+type validationResult struct {
+	visitedCode          map[int]struct{}
+	visitedSubContainers map[int]int
+	isInitCode           bool
+	isRuntime            bool
+}
+
+// validateCode validates the code parameter against the EOF v1 validity requirements.
+func validateCode(code []byte, section int, container *Container, jt *JumpTable) (*validationResult, error) {
+	var (
+		i = 0
+		// Tracks the number of actual instructions in the code (e.g.
+		// non-immediate values). This is used at the end to determine
+		// if each instruction is reachable.
+		count         = 0
+		op            OpCode
+		analysis      = eofCodeBitmap(code)
+		hasReturning  bool
+		res           = &validationResult{visitedCode: make(map[int]struct{}), visitedSubContainers: make(map[int]int)}
+		metadata      = container.types
+		thisSignature = metadata[section]
+	)
+	// This loop visits every single instruction and verifies:
+	// * if the instruction is valid for the given jump table.
+	// * if the instruction has an immediate value, it is not truncated.
+	// * if performing a relative jump, all jump destinations are valid.
+	// * if changing code sections, the new code section index is valid and
+	//   will not cause a stack overflow.
+	for i < len(code) {
+		count++
+		op = OpCode(code[i])
+		if jt[op].undefined {
+			return nil, fmt.Errorf("%w: op %s, pos %d", errUndefinedInstruction, op, i)
+		}
+		size := int(immediates[op])
+		if size != 0 && len(code) <= i+size {
+			return nil, fmt.Errorf("%w: op %s, pos %d", errTruncatedImmediate, op, i)
+		}
+		switch op {
+		case RJUMP, RJUMPI:
+			if err := checkDest(code, analysis, i+1, i+3, len(code)); err != nil {
+				return nil, err
+			}
+		case RJUMPV:
+			maxSize := int(code[i+1])
+			length := maxSize + 1
+			if len(code) <= i+2+length*2-1 {
+				return nil, fmt.Errorf("%w: jump table truncated, op %s, pos %d", errTruncatedImmediate, op, i)
+			}
+			offset := i + 2
+			for j := 0; j < length; j++ {
+				if err := checkDest(code, analysis, offset+j*2, offset+(length*2), len(code)); err != nil {
+					return nil, err
+				}
+			}
+			i += 2 * maxSize
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(metadata) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(metadata), i)
+			}
+			if !metadata[arg].isReturning() {
+				return nil, fmt.Errorf("%w: section %d, pos %d", errInvalidCallArgument, arg, i)
+			}
+			res.visitedCode[arg] = struct{}{}
+		case RETF:
+			if !thisSignature.isReturning() {
+				return nil, fmt.Errorf("%w: section %d, pos %d", errInvalidNonReturningFlag, section, i)
+			}
+			hasReturning = true
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(metadata) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(metadata), i)
+			}
+			if metadata[arg].isReturning() {
+				if !thisSignature.isReturning() {
+					return nil, fmt.Errorf("%w: section %d, pos %d", errInvalidNonReturningFlag, section, i)
+				}
+				if metadata[arg].outputs > thisSignature.outputs {
+					return nil, fmt.Errorf("%w: arg %d, pos %d", errInvalidJumpf, arg, i)
+				}
+				hasReturning = true
+			}
+			res.visitedCode[arg] = struct{}{}
+		case DATALOADN:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg+32 > container.dataSize {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidDataloadNArgument, arg, container.dataSize, i)
+			}
+		case RETURNCONTRACT:
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return nil, fmt.Errorf("%w: arg %d, pos %d", errInvalidContainerArgument, arg, i)
+			}
+			if ref, ok := res.visitedSubContainers[arg]; ok && ref != refByReturnContract {
+				return nil, fmt.Errorf("%w: container %d, pos %d", errIncompatibleContainerKind, arg, i)
+			}
+			res.visitedSubContainers[arg] = refByReturnContract
+			res.isInitCode = true
+		case EOFCREATE:
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return nil, fmt.Errorf("%w: arg %d, pos %d", errInvalidContainerArgument, arg, i)
+			}
+			if ref, ok := res.visitedSubContainers[arg]; ok && ref != refByEOFCreate {
+				return nil, fmt.Errorf("%w: container %d, pos %d", errIncompatibleContainerKind, arg, i)
+			}
+			res.visitedSubContainers[arg] = refByEOFCreate
+		case STOP, RETURN:
+			res.isRuntime = true
+		}
+		i += size + 1
+	}
+	// Code sections may not "fall through" and require proper termination.
+	// Therefore, the last instruction must be considered terminal or RJUMP.
+	if !terminals[op] && op != RJUMP {
+		return nil, fmt.Errorf("%w: end with %s, pos %d", errInvalidCodeTermination, op, i)
+	}
+	// Returning sections must actually return, either directly or by jumping
+	// to another returning section.
+	if thisSignature.isReturning() && !hasReturning {
+		return nil, fmt.Errorf("%w: section %d", errInvalidNonReturningFlag, section)
+	}
+	if paths, err := validateControlFlow(code, section, metadata, jt); err != nil {
+		return nil, err
+	} else if paths != count {
+		return nil, errUnreachableCode
+	}
+	return res, nil
+}
+
+// checkDest parses the relative offset at code[imm:imm+2] and checks if it is a
+// valid jump destination, relative to the instruction following the immediates.
+func checkDest(code []byte, analysis bitvec, imm, from, length int) error {
+	offset := parseInt16(code[imm:])
+	dest := from + offset
+	if dest < 0 || dest >= length {
+		return fmt.Errorf("%w: out-of-bounds offset: offset %d, dest %d, pos %d", errInvalidJumpDest, offset, dest, imm)
+	}
+	if !analysis.codeSegment(uint64(dest)) {
+		return fmt.Errorf("%w: offset into immediate: offset %d, dest %d, pos %d", errInvalidJumpDest, offset, dest, imm)
+	}
+	return nil
+}
+
+// parseInt16 returns the int16 located at b[0:2].
+func parseInt16(b []byte) int {
+	return int(int16(b[1]) | int16(b[0])<<8)
+}
@@ -37,6 +37,13 @@ var (
 	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
+	ErrInvalidEOFInitcode       = errors.New("invalid eof initcode")
+	ErrReturnStackExceeded      = errors.New("return stack limit reached")
+	ErrInvalidAuxData           = errors.New("invalid eof aux data")
+
+	// errInvalidCallTarget is returned by the EOF call instructions when the
+	// target is not a valid address.
+	errInvalidCallTarget = errors.New("invalid call target")
 
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
@@ -477,3 +477,64 @@ func gasSelfdestruct(evm *EVM, contract *Contract, stack *Stack, mem *Memory, me
 	}
 	return gas, nil
 }
+
+// makeExtCallGas creates the gas function of the EOF call instructions. They
+// charge for the account access, the memory expansion and (for EXTCALL) the
+// value transfer, and pass all but the retained gas on to the callee.
+func makeExtCallGas(transfersValue bool) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		target := stack.Back(0)
+		// The target must be a 20 byte address, anything else halts the execution.
+		if target.ByteLen() > common.AddressLength {
+			return 0, errInvalidCallTarget
+		}
+		var (
+			address = common.Address(target.Bytes20())
+			gas     uint64
+		)
+		// The WarmStorageReadCostEIP2929 (100) is already deducted in the form of
+		// a constant cost, so the cost to charge for cold access, if any, is Cold - Warm.
+		if !evm.StateDB.AddressInAccessList(address) {
+			evm.StateDB.AddAddressToAccessList(address)
+			gas = params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929
+		}
+		if transfersValue && !stack.Back(3).IsZero() {
+			gas += params.CallValueTransferGas
+			if evm.StateDB.Empty(address) {
+				gas += params.CallNewAccountGas
+			}
+		}
+		memoryGas, err := memoryGasCost(mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		var overflow bool
+		if gas, overflow = math.SafeAdd(gas, memoryGas); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		// Leave the callee gas at zero if it would fall below the minimum, the
+		// call is then skipped and fails without consuming the gas.
+		evm.callGasTemp = 0
+		if gas < contract.Gas {
+			available := contract.Gas - gas
+			retained := available / 64
+			if retained < params.ExtCallMinRetainedGas {
+				retained = params.ExtCallMinRetainedGas
+			}
+			if available >= retained+params.ExtCallMinCalleeGas {
+				evm.callGasTemp = available - retained
+			}
+		}
+		if gas, overflow = math.SafeAdd(gas, evm.callGasTemp); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasExtCall         = makeExtCallGas(true)
+	gasExtDelegateCall = makeExtCallGas(false)
+	gasExtStaticCall   = makeExtCallGas(false)
+	gasDataCopy        = memoryCopierGas(2)
+)
@@ -41,8 +41,9 @@ type ScopeContext struct {
 
 // EVMInterpreter represents an EVM interpreter
 type EVMInterpreter struct {
-	evm   *EVM
-	table *JumpTable
+	evm      *EVM
+	table    *JumpTable
+	eofTable *JumpTable // Instruction set for EOF containers, nil before Osaka
 
 	hasher    crypto.KeccakState // Keccak256 hasher instance shared across opcodes
 	hasherBuf common.Hash        // Keccak256 hasher result array shared across opcodes
@@ -56,6 +57,10 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	var table *JumpTable
 	switch {
+	case evm.chainRules.IsVerkle:
+		table = &verkleInstructionSet
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
@@ -95,7 +100,12 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
-	return &EVMInterpreter{evm: evm, table: table}
+
+	var eofTable *JumpTable
+	if evm.chainRules.IsOsaka {
+		eofTable = &eofInstructionSet
+	}
+	return &EVMInterpreter{evm: evm, table: table, eofTable: eofTable}
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -124,6 +134,20 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	if len(contract.Code) == 0 {
 		return nil, nil
 	}
+	// EOF containers are executed with their own instruction set, starting at
+	// the first code section. Deployed EOF code has been validated at creation
+	// time, so it is only parsed here.
+	table := in.table
+	if in.eofTable != nil && contract.container == nil && hasEOFMagic(contract.Code) {
+		var container Container
+		if err := container.UnmarshalBinary(contract.Code); err == nil {
+			contract.container = &container
+		}
+	}
+	if contract.container != nil {
+		table = in.eofTable
+		contract.Code = contract.container.codeSections[0]
+	}
 
 	var (
 		op          OpCode        // current opcode
@@ -177,7 +201,7 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
 		op = contract.GetOp(pc)
-		operation := in.table[op]
+		operation := table[op]
 		cost = operation.constantGas // For tracing
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
@@ -185,6 +209,15 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		} else if sLen > operation.maxStack {
 			return nil, &ErrStackOverflow{stackLen: sLen, limit: operation.maxStack}
 		}
+		// In verkle, the code chunks touched by the instruction, immediates
+		// included, are charged as well (EIP-4762).
+		if in.evm.chainRules.IsVerkle && !contract.IsDeployment {
+			size := uint64(1)
+			if op.IsPush() {
+				size += uint64(op - PUSH0)
+			}
+			cost += in.evm.AccessEvents.CodeChunksRangeGas(contract.Address(), pc, size, uint64(len(contract.Code)), false)
+		}
 		if !contract.UseGas(cost) {
 			return nil, ErrOutOfGas
 		}
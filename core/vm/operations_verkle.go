@@ -0,0 +1,190 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// warmOrWitness returns the witness gas if any location was accessed for the
+// first time, otherwise the cost of a warm read.
+func warmOrWitness(gas uint64) uint64 {
+	if gas == 0 {
+		return params.WarmStorageReadCostEIP2929
+	}
+	return gas
+}
+
+func gasSStore4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// If we fail the minimum gas availability invariant, fail (0)
+	if contract.Gas <= params.SstoreSentryGasEIP2200 {
+		return 0, errors.New("not enough gas for reentrancy sentry")
+	}
+	return warmOrWitness(evm.AccessEvents.SlotGas(contract.Address(), stack.peek().Bytes32(), true)), nil
+}
+
+func gasSLoad4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return warmOrWitness(evm.AccessEvents.SlotGas(contract.Address(), stack.peek().Bytes32(), false)), nil
+}
+
+func gasBalance4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	address := stack.peek().Bytes20()
+	return warmOrWitness(evm.AccessEvents.BalanceGas(address, false)), nil
+}
+
+func gasExtCodeSize4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	address := stack.peek().Bytes20()
+	if _, isPrecompile := evm.precompile(address); isPrecompile {
+		return 0, nil
+	}
+	gas := evm.AccessEvents.VersionGas(address, false)
+	gas += evm.AccessEvents.CodeSizeGas(address, false)
+	return warmOrWitness(gas), nil
+}
+
+func gasExtCodeHash4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	address := stack.peek().Bytes20()
+	if _, isPrecompile := evm.precompile(address); isPrecompile {
+		return 0, nil
+	}
+	return warmOrWitness(evm.AccessEvents.CodeHashGas(address, false)), nil
+}
+
+// makeCallVariantGasEIP4762 charges the witness costs of touching the callee,
+// and of moving the value if the call transfers any, on top of the
+// regular call costs.
+func makeCallVariantGasEIP4762(oldCalculator gasFunc, withTransfer bool) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		target := common.Address(stack.Back(1).Bytes20())
+		var witnessGas uint64
+		if _, isPrecompile := evm.precompile(target); !isPrecompile {
+			witnessGas = warmOrWitness(evm.AccessEvents.MessageCallGas(target))
+		}
+		if withTransfer && !stack.Back(2).IsZero() {
+			witnessGas += evm.AccessEvents.ValueTransferGas(contract.Address(), target)
+		}
+		// Charge the witness costs here already, to correctly calculate the
+		// available gas for the call.
+		if !contract.UseGas(witnessGas) {
+			return 0, ErrOutOfGas
+		}
+		gas, err := oldCalculator(evm, contract, stack, mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		// Add the witness costs back, they are charged as part of the dynamic
+		// gas to be correctly reported to tracers.
+		contract.Gas += witnessGas
+
+		var overflow bool
+		if gas, overflow = math.SafeAdd(gas, witnessGas); overflow {
+			return 0, ErrGasUintOverflow
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasCallEIP4762         = makeCallVariantGasEIP4762(gasCall, true)
+	gasCallCodeEIP4762     = makeCallVariantGasEIP4762(gasCallCode, true)
+	gasStaticCallEIP4762   = makeCallVariantGasEIP4762(gasStaticCall, false)
+	gasDelegateCallEIP4762 = makeCallVariantGasEIP4762(gasDelegateCall, false)
+)
+
+func gasSelfdestructEIP4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	beneficiaryAddr := common.Address(stack.peek().Bytes20())
+	if _, isPrecompile := evm.precompile(beneficiaryAddr); isPrecompile {
+		return 0, nil
+	}
+	contractAddr := contract.Address()
+	gas := evm.AccessEvents.BalanceGas(contractAddr, false)
+	if contractAddr != beneficiaryAddr {
+		gas += evm.AccessEvents.BalanceGas(beneficiaryAddr, false)
+	}
+	// Charge write costs if it transfers value
+	if !evm.StateDB.GetBalance(contractAddr).IsZero() {
+		gas += evm.AccessEvents.BalanceGas(contractAddr, true)
+		if contractAddr != beneficiaryAddr {
+			gas += evm.AccessEvents.BalanceGas(beneficiaryAddr, true)
+		}
+	}
+	return gas, nil
+}
+
+// codeRange returns the part of the copied range which is within the code.
+func codeRange(codeLen uint64, offset, length uint64) (uint64, uint64) {
+	start := offset
+	if start > codeLen {
+		start = codeLen
+	}
+	end := start + length
+	if end < start || end > codeLen {
+		end = codeLen
+	}
+	return start, end - start
+}
+
+func gasCodeCopyEIP4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := gasCodeCopy(evm, contract, stack, mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	if contract.IsDeployment {
+		return gas, nil
+	}
+	codeOffset, overflow := stack.Back(1).Uint64WithOverflow()
+	if overflow {
+		codeOffset = math.MaxUint64
+	}
+	codeLen := uint64(len(contract.Code))
+	start, size := codeRange(codeLen, codeOffset, stack.Back(2).Uint64())
+	if gas, overflow = math.SafeAdd(gas, evm.AccessEvents.CodeChunksRangeGas(contract.Address(), start, size, codeLen, false)); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
+func gasExtCodeCopyEIP4762(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// memory expansion first (dynamic part of pre-2929 implementation)
+	gas, err := gasExtCodeCopy(evm, contract, stack, mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	addr := common.Address(stack.peek().Bytes20())
+	if _, isPrecompile := evm.precompile(addr); isPrecompile {
+		return gas, nil
+	}
+	witnessGas := evm.AccessEvents.VersionGas(addr, false)
+	witnessGas += evm.AccessEvents.CodeSizeGas(addr, false)
+
+	codeOffset, overflow := stack.Back(2).Uint64WithOverflow()
+	if overflow {
+		codeOffset = math.MaxUint64
+	}
+	codeLen := uint64(evm.StateDB.GetCodeSize(addr))
+	start, size := codeRange(codeLen, codeOffset, stack.Back(3).Uint64())
+	witnessGas += evm.AccessEvents.CodeChunksRangeGas(addr, start, size, codeLen, false)
+
+	if gas, overflow = math.SafeAdd(gas, warmOrWitness(witnessGas)); overflow {
+		return 0, ErrGasUintOverflow
+	}
+	return gas, nil
+}
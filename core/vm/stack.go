@@ -68,6 +68,12 @@ func (st *Stack) swap(n int) {
 	st.data[st.len()-n], st.data[st.len()-1] = st.data[st.len()-1], st.data[st.len()-n]
 }
 
+// exchange swaps the (n+1)'th and the (n+m+1)'th item from the top of the stack.
+func (st *Stack) exchange(n, m int) {
+	top := st.len() - 1
+	st.data[top-n], st.data[top-n-m] = st.data[top-n-m], st.data[top-n]
+}
+
 func (st *Stack) dup(n int) {
 	st.push(&st.data[st.len()-n])
 }
@@ -27,7 +27,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -37,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -203,13 +203,28 @@ func (b *EthAPIBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.B
 	if header == nil {
 		return nil, nil, errors.New("header not found")
 	}
-	stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+	stateDb, err := b.stateAt(header.Root)
 	if err != nil {
 		return nil, nil, err
 	}
 	return stateDb, header, nil
 }
 
+// stateAt returns the state with the given root. In path-based scheme, the state
+// which is no longer available is resolved from the indexed state histories.
+func (b *EthAPIBackend) stateAt(root common.Hash) (*state.StateDB, error) {
+	stateDb, err := b.eth.BlockChain().StateAt(root)
+	if err == nil || b.eth.BlockChain().TrieDB().Scheme() != rawdb.PathScheme {
+		return stateDb, err
+	}
+	historic, herr := b.eth.BlockChain().HistoricState(root)
+	if herr != nil {
+		log.Debug("Historic state is not available", "root", root, "err", herr)
+		return nil, err
+	}
+	return historic, nil
+}
+
 func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.StateAndHeaderByNumber(ctx, blockNr)
@@ -225,7 +240,7 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 		if blockNrOrHash.RequireCanonical && b.eth.blockchain.GetCanonicalHash(header.Number.Uint64()) != hash {
 			return nil, nil, errors.New("hash is not currently canonical")
 		}
-		stateDb, err := b.eth.BlockChain().StateAt(header.Root)
+		stateDb, err := b.stateAt(header.Root)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -252,6 +267,12 @@ func (b *EthAPIBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
 func (b *EthAPIBackend) GetEVM(ctx context.Context, msg *core.Message, state *state.StateDB, header *types.Header, vmConfig *vm.Config, blockCtx *vm.BlockContext) *vm.EVM {
 	if vmConfig == nil {
 		vmConfig = b.eth.blockchain.GetVMConfig()
+		if _, ok := vmConfig.Tracer.(core.BlockchainLogger); ok {
+			// Live tracers only follow chain import, not RPC calls.
+			cfg := *vmConfig
+			cfg.Tracer = nil
+			vmConfig = &cfg
+		}
 	}
 	txContext := core.NewEVMTxContext(msg)
 	var context vm.BlockContext
@@ -402,15 +423,8 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 	return b.eth.config.RPCTxFeeCap
 }
 
-func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
-	sections, _, _ := b.eth.bloomIndexer.Sections()
-	return params.BloomBitsBlocks, sections
-}
-
-func (b *EthAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
-	for i := 0; i < bloomFilterThreads; i++ {
-		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eth.bloomRequests)
-	}
+func (b *EthAPIBackend) LogIndexHead() (uint64, bool) {
+	return core.LogIndexHead(b.eth.chainDb)
 }
 
 func (b *EthAPIBackend) Engine() consensus.Engine {
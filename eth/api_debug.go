@@ -24,9 +24,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
@@ -443,3 +446,92 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// ExecutionWitness re-executes the given block on top of its parent state and
+// returns the witness needed to execute it statelessly: the trie nodes and
+// codes of the pre-state touched during processing, and the ancestor headers
+// needed to serve the BLOCKHASH opcode.
+func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*stateless.ExtWitness, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	witness, err := generateWitness(api.eth.blockchain, block)
+	if err != nil {
+		return nil, err
+	}
+	return witness.ToExtWitness(), nil
+}
+
+// generateWitness processes the block on top of its parent state with witness
+// collection enabled, validating the produced post-state along the way.
+func generateWitness(bc *core.BlockChain, block *types.Block) (*stateless.Witness, error) {
+	// Witnesses consist of merkle-patricia trie nodes, collecting them from the
+	// verkle tree is not supported.
+	if bc.TrieDB().IsVerkle() || bc.Config().IsVerkle(block.Number(), block.Time()) {
+		return nil, errors.New("execution witness is not supported for verkle state")
+	}
+	witness, err := stateless.NewWitness(block.Header(), bc)
+	if err != nil {
+		return nil, err
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	statedb.StartWitness(witness)
+
+	res, err := bc.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := bc.Validator().ValidateState(block, statedb, res); err != nil {
+		return nil, err
+	}
+	return witness, nil
+}
+
+// GetBlockAccessList returns the accounts and storage slots accessed by each
+// transaction of the given block, along with the values they were modified to.
+// The access list is served from the database if it was stored during import,
+// otherwise it's recorded by re-executing the block on top of its parent state.
+func (api *DebugAPI) GetBlockAccessList(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (types.BlockAccessList, error) {
+	block, err := api.eth.APIBackend.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("block not found")
+	}
+	if list := rawdb.ReadAccessList(api.eth.ChainDb(), block.Hash(), block.NumberU64()); list != nil {
+		return list, nil
+	}
+	return generateAccessList(api.eth.blockchain, block)
+}
+
+// generateAccessList processes the block on top of its parent state with access
+// recording enabled, validating the produced post-state along the way.
+func generateAccessList(bc *core.BlockChain, block *types.Block) (types.BlockAccessList, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %#x not found", block.ParentHash())
+	}
+	statedb, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	statedb.StartAccessRecording()
+
+	res, err := bc.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := bc.Validator().ValidateState(block, statedb, res); err != nil {
+		return nil, err
+	}
+	return statedb.BlockAccessList(), nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package catalyst
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/beacon/blsync"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/internal/telemetry"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+)
+
+// LightSyncer is an auxiliary service driving the chain by the heads verified by
+// a beacon light client, allowing Geth to follow the chain without an attached
+// consensus client. The execution payloads of the verified heads are imported
+// and the forkchoice is updated through the engine API.
+type LightSyncer struct {
+	api    *ConsensusAPI
+	client *blsync.Client
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// RegisterLightSyncer registers the light syncer service into the node stack for
+// launching and stopping the service controlled by node.
+func RegisterLightSyncer(stack *node.Node, backend *eth.Ethereum, client *blsync.Client) *LightSyncer {
+	syncer := &LightSyncer{
+		api:    NewConsensusAPI(backend),
+		client: client,
+		closed: make(chan struct{}),
+	}
+	stack.RegisterLifecycle(syncer)
+	return syncer
+}
+
+// Start launches the beacon light client and starts following its heads.
+func (s *LightSyncer) Start() error {
+	var (
+		headCh = make(chan blsync.ChainHeadEvent, 1)
+		sub    = s.client.SubscribeChainHeadEvent(headCh)
+	)
+	s.client.Start()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-headCh:
+				if err := s.update(ev); err != nil {
+					log.Warn("Failed to update light client head", "number", ev.Block.Payload.Number, "hash", ev.Block.Payload.BlockHash, "err", err)
+				}
+			case <-s.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop terminates the light client and the head updates.
+func (s *LightSyncer) Stop() error {
+	close(s.closed)
+	s.wg.Wait()
+	s.client.Stop()
+	return nil
+}
+
+// update imports the execution payload of the new head and makes it the head
+// of the chain.
+func (s *LightSyncer) update(ev blsync.ChainHeadEvent) error {
+	var (
+		payload    = ev.Block.Payload
+		cancun     = ev.Block.BlobCommitments != nil
+		prague     = ev.Block.Requests != nil
+		status     engine.PayloadStatusV1
+		err        error
+		forkchoice = engine.ForkchoiceStateV1{
+			HeadBlockHash:      payload.BlockHash,
+			SafeBlockHash:      ev.Finalized,
+			FinalizedBlockHash: ev.Finalized,
+		}
+	)
+	ctx, span := telemetry.StartSpan(context.Background(), "catalyst.lightSyncUpdate",
+		telemetry.Uint64("block.number", payload.Number),
+		telemetry.String("block.hash", payload.BlockHash.Hex()),
+	)
+	defer span.End()
+
+	switch {
+	case prague:
+		requests := make([]hexutil.Bytes, len(ev.Block.Requests))
+		for i, request := range ev.Block.Requests {
+			requests[i] = request
+		}
+		status, err = s.api.NewPayloadV4(ctx, *payload, ev.Block.BlobHashes(), &ev.Block.ParentRoot, requests)
+	case cancun:
+		status, err = s.api.NewPayloadV3(ctx, *payload, ev.Block.BlobHashes(), &ev.Block.ParentRoot)
+	default:
+		status, err = s.api.NewPayloadV2(ctx, *payload)
+	}
+	if err != nil {
+		return err
+	}
+	if status.Status == engine.INVALID {
+		if status.ValidationError != nil {
+			return fmt.Errorf("invalid payload: %s", *status.ValidationError)
+		}
+		return errors.New("invalid payload")
+	}
+	var resp engine.ForkChoiceResponse
+	if cancun || prague {
+		resp, err = s.api.ForkchoiceUpdatedV3(ctx, forkchoice, nil)
+	} else {
+		resp, err = s.api.ForkchoiceUpdatedV2(ctx, forkchoice, nil)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.PayloadStatus.Status == engine.INVALID {
+		return errors.New("invalid forkchoice")
+	}
+	log.Debug("Updated light client head", "number", payload.Number, "hash", payload.BlockHash, "status", resp.PayloadStatus.Status)
+	return nil
+}
@@ -17,7 +17,9 @@
 package catalyst
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"errors"
 	"math/big"
 	"sync"
@@ -27,10 +29,12 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/forks"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -99,7 +103,7 @@ func NewSimulatedBeacon(period uint64, eth *eth.Ethereum) (*SimulatedBeacon, err
 
 	// if genesis block, send forkchoiceUpdated to trigger transition to PoS
 	if block.Number.Sign() == 0 {
-		if _, err := engineAPI.ForkchoiceUpdatedV2(current, nil); err != nil {
+		if _, err := engineAPI.ForkchoiceUpdatedV2(context.Background(), current, nil); err != nil {
 			return nil, err
 		}
 	}
@@ -154,14 +158,20 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 		c.setCurrentState(header.Hash(), *finalizedHash)
 	}
 
+	version := payloadVersion(c.eth.BlockChain().Config(), timestamp)
+
 	var random [32]byte
 	rand.Read(random[:])
-	fcResponse, err := c.engineAPI.forkchoiceUpdated(c.curForkchoiceState, &engine.PayloadAttributes{
+	attrs := &engine.PayloadAttributes{
 		Timestamp:             timestamp,
 		SuggestedFeeRecipient: feeRecipient,
 		Withdrawals:           withdrawals,
 		Random:                random,
-	}, engine.PayloadV2, true)
+	}
+	if version >= engine.PayloadV3 {
+		attrs.BeaconRoot = &common.Hash{}
+	}
+	fcResponse, err := c.engineAPI.forkchoiceUpdated(context.Background(), c.curForkchoiceState, attrs, version, true)
 	if err != nil {
 		return err
 	}
@@ -186,20 +196,61 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 		}
 	}
 
+	var (
+		blobHashes []common.Hash
+		requests   [][]byte
+	)
+	// Compute the post-cancun fields, the blob hashes are calculated
+	// independently from the sidecars.
+	if version >= engine.PayloadV3 {
+		blobHashes = make([]common.Hash, 0)
+		if envelope.BlobsBundle != nil {
+			hasher := sha256.New()
+			for _, commit := range envelope.BlobsBundle.Commitments {
+				var commitment kzg4844.Commitment
+				if len(commit) != len(commitment) {
+					return errors.New("invalid commitment length")
+				}
+				copy(commitment[:], commit)
+				blobHashes = append(blobHashes, kzg4844.CalcBlobHashV1(hasher, &commitment))
+			}
+		}
+	}
+	if version >= engine.PayloadV4 {
+		requests = make([][]byte, 0)
+		if envelope.Requests != nil {
+			for _, request := range *envelope.Requests {
+				requests = append(requests, request)
+			}
+		}
+	}
 	// Mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV2(*payload); err != nil {
+	if _, err = c.engineAPI.newPayload(context.Background(), *payload, blobHashes, attrs.BeaconRoot, requests); err != nil {
 		return err
 	}
 	c.setCurrentState(payload.BlockHash, finalizedHash)
 
 	// Mark the block containing the payload as canonical
-	if _, err = c.engineAPI.ForkchoiceUpdatedV2(c.curForkchoiceState, nil); err != nil {
+	if _, err = c.engineAPI.ForkchoiceUpdatedV2(context.Background(), c.curForkchoiceState, nil); err != nil {
 		return err
 	}
 	c.lastBlockTime = payload.Timestamp
 	return nil
 }
 
+// payloadVersion returns the payload version to build the block at the given
+// timestamp with.
+func payloadVersion(config *params.ChainConfig, time uint64) engine.PayloadVersion {
+	switch config.LatestFork(time) {
+	case forks.Prague:
+		return engine.PayloadV4
+	case forks.Cancun:
+		return engine.PayloadV3
+	default:
+		return engine.PayloadV2
+	}
+}
+
 // loop runs the block production loop for non-zero period configuration
 func (c *SimulatedBeacon) loop() {
 	timer := time.NewTimer(0)
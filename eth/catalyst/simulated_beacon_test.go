@@ -139,3 +139,41 @@ func TestSimulatedBeaconSendWithdrawals(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the dev mode chain runs with cancun and prague enabled, storing the
+// parent block hashes in the EIP-2935 history contract.
+func TestSimulatedBeaconPragueHistory(t *testing.T) {
+	genesis := core.DeveloperGenesisBlock(10_000_000, nil)
+	node, ethService, _ := startSimulatedBeaconEthService(t, genesis)
+	defer node.Close()
+
+	chainHeadCh := make(chan core.ChainHeadEvent, 10)
+	subscription := ethService.BlockChain().SubscribeChainHeadEvent(chainHeadCh)
+	defer subscription.Unsubscribe()
+
+	timer := time.NewTimer(12 * time.Second)
+	for {
+		select {
+		case evt := <-chainHeadCh:
+			if evt.Block.NumberU64() < 2 {
+				continue
+			}
+			header := evt.Block.Header()
+			if header.ParentBeaconRoot == nil || header.RequestsHash == nil {
+				t.Fatalf("block %d is missing the cancun/prague header fields", header.Number)
+			}
+			statedb, err := ethService.BlockChain().StateAt(header.Root)
+			if err != nil {
+				t.Fatal("can't open state:", err)
+			}
+			parent := ethService.BlockChain().GetHeaderByHash(header.ParentHash)
+			slot := common.BigToHash(new(big.Int).SetUint64(parent.Number.Uint64() % params.HistoryServeWindow))
+			if have := statedb.GetState(params.HistoryStorageAddress, slot); have != parent.Hash() {
+				t.Fatalf("history contract mismatch at block %d: have %x, want %x", parent.Number, have, parent.Hash())
+			}
+			return
+		case <-timer.C:
+			t.Fatal("timed out without producing blocks")
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 // timeoutGracePeriod is the amount of time to allow for a peer to deliver a
@@ -312,6 +313,7 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				continue
 			}
 			if fails > 2 {
+				peer.report(reputation.Timeout)
 				queue.updateCapacity(peer, 0, 0)
 			} else {
 				d.dropPeer(peer.id)
@@ -362,6 +364,12 @@ func (d *Downloader) concurrentFetch(queue typedQueue, beaconMode bool) error {
 				if errors.Is(err, errInvalidChain) {
 					return err
 				}
+				switch {
+				case accepted > 0:
+					peer.report(reputation.UsefulResponse)
+				case err == nil:
+					peer.report(reputation.UselessResponse)
+				}
 				// Unless a peer delivered something completely else than requested (usually
 				// caused by a timed out request which came through in the end), set it to
 				// idle. If the delivery's stale, the peer should have already been idled.
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 const (
@@ -102,6 +103,14 @@ func (p *peerConnection) UpdateReceiptRate(delivered int, elapsed time.Duration)
 	p.rates.Update(eth.ReceiptsMsg, elapsed, delivered)
 }
 
+// report forwards behaviour of the peer to the reputation system, if the peer
+// is connected through p2p.
+func (p *peerConnection) report(ev reputation.Event) {
+	if r, ok := p.peer.(interface{ Report(reputation.Event) }); ok {
+		r.Report(ev)
+	}
+}
+
 // HeaderCapacity retrieves the peer's header download allowance based on its
 // previously discovered throughput.
 func (p *peerConnection) HeaderCapacity(targetRTT time.Duration) int {
@@ -22,11 +22,16 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
 )
 
+// logIndexSection is the number of blocks whose log index entries are gathered
+// at once when filtering a block range, bounding the memory used for matching.
+const logIndexSection = 4096
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	sys *FilterSystem
@@ -36,37 +41,13 @@ type Filter struct {
 
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
-
-	matcher *bloombits.Matcher
 }
 
-// NewRangeFilter creates a new filter which uses a bloom filter on blocks to
-// figure out whether a particular block is interesting or not.
+// NewRangeFilter creates a new filter which uses the log index to figure out
+// which blocks contain the matching logs.
 func (sys *FilterSystem) NewRangeFilter(begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
-	// Flatten the address and topic filter clauses into a single bloombits filter
-	// system. Since the bloombits are not positional, nil topics are permitted,
-	// which get flattened into a nil byte slice.
-	var filters [][][]byte
-	if len(addresses) > 0 {
-		filter := make([][]byte, len(addresses))
-		for i, address := range addresses {
-			filter[i] = address.Bytes()
-		}
-		filters = append(filters, filter)
-	}
-	for _, topicList := range topics {
-		filter := make([][]byte, len(topicList))
-		for i, topic := range topicList {
-			filter[i] = topic.Bytes()
-		}
-		filters = append(filters, filter)
-	}
-	size, _ := sys.backend.BloomStatus()
-
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(sys, addresses, topics)
-
-	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
 	filter.end = end
 
@@ -193,16 +174,12 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 		}()
 
 		// Gather all indexed logs, and finish with non indexed ones
-		var (
-			end            = uint64(f.end)
-			size, sections = f.sys.backend.BloomStatus()
-			err            error
-		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
+		end := uint64(f.end)
+		if indexed, ok := f.sys.backend.LogIndexHead(); ok && indexed >= uint64(f.begin) && f.indexable() {
 			if indexed > end {
-				indexed = end + 1
+				indexed = end
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+			if err := f.indexedLogs(ctx, indexed, logChan); err != nil {
 				errChan <- err
 				return
 			}
@@ -219,50 +196,159 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 	return logChan, errChan
 }
 
-// indexedLogs returns the logs matching the filter criteria based on the bloom
-// bits indexed available locally or via the network.
-func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
-	// Create a matcher session and request servicing from the backend
-	matches := make(chan uint64, 64)
-
-	session, err := f.matcher.Start(ctx, uint64(f.begin), end, matches)
-	if err != nil {
-		return err
+// indexable returns whether the filter criteria can be served by the log index,
+// which is not the case if all the logs are requested.
+func (f *Filter) indexable() bool {
+	if len(f.addresses) > 0 {
+		return true
 	}
-	defer session.Close()
-
-	f.sys.backend.ServiceFilter(ctx, session)
-
-	for {
-		select {
-		case number, ok := <-matches:
-			// Abort if all matches have been fulfilled
-			if !ok {
-				err := session.Error()
-				if err == nil {
-					f.begin = int64(end) + 1
-				}
-				return err
-			}
-			f.begin = int64(number) + 1
+	for _, sub := range f.topics {
+		if len(sub) > 0 {
+			return true
+		}
+	}
+	return false
+}
 
+// indexedLogs returns the logs matching the filter criteria based on the log
+// index available locally.
+func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
+	for f.begin <= int64(end) {
+		to := uint64(f.begin) + logIndexSection - 1
+		if to > end {
+			to = end
+		}
+		numbers, matches, err := f.indexedMatches(uint64(f.begin), to)
+		if err != nil {
+			return err
+		}
+		for _, number := range numbers {
 			// Retrieve the suggested block and pull any truly matching logs
 			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
 			if header == nil || err != nil {
 				return err
 			}
-			found, err := f.checkMatches(ctx, header)
+			found, err := f.checkMatches(ctx, header, matches[number])
 			if err != nil {
 				return err
 			}
 			for _, log := range found {
-				logChan <- log
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			f.begin = int64(number) + 1
+		}
+		f.begin = int64(to) + 1
+	}
+	return nil
+}
+
+// indexedMatches looks up the log index for the logs matching the filter
+// criteria within the block range [from, to]. The numbers of the matching
+// blocks are returned in ascending order, along with the positions of the
+// potentially matching logs within each block.
+func (f *Filter) indexedMatches(from, to uint64) ([]uint64, map[uint64][]uint32, error) {
+	// Collect the index prefixes of each filter clause, a log must match
+	// at least one value of every clause.
+	var clauses [][][]byte
+	if len(f.addresses) > 0 {
+		clause := make([][]byte, len(f.addresses))
+		for i, address := range f.addresses {
+			clause[i] = rawdb.AddressLogIndexPrefix(address)
+		}
+		clauses = append(clauses, clause)
+	}
+	for i, sub := range f.topics {
+		if len(sub) == 0 {
+			continue // empty rule set == wildcard
+		}
+		clause := make([][]byte, len(sub))
+		for j, topic := range sub {
+			clause[j] = rawdb.TopicLogIndexPrefix(i, topic)
+		}
+		clauses = append(clauses, clause)
+	}
+	var (
+		db      = f.sys.backend.ChainDb()
+		matches map[uint64][]uint32
+	)
+	for _, clause := range clauses {
+		found := make(map[uint64][]uint32)
+		for _, prefix := range clause {
+			err := rawdb.IterateLogIndex(db, prefix, from, to, func(number uint64, positions []uint32) bool {
+				// Only the blocks matched by all the previous clauses are
+				// interesting.
+				if matches != nil {
+					if _, ok := matches[number]; !ok {
+						return true
+					}
+				}
+				found[number] = mergePositions(found[number], positions)
+				return true
+			})
+			if err != nil {
+				return nil, nil, err
 			}
+		}
+		if matches != nil {
+			for number, positions := range found {
+				if positions = intersectPositions(matches[number], positions); len(positions) == 0 {
+					delete(found, number)
+				} else {
+					found[number] = positions
+				}
+			}
+		}
+		matches = found
+		if len(matches) == 0 {
+			break
+		}
+	}
+	numbers := make([]uint64, 0, len(matches))
+	for number := range matches {
+		numbers = append(numbers, number)
+	}
+	slices.Sort(numbers)
+	return numbers, matches, nil
+}
+
+// mergePositions returns the union of two sorted log position lists.
+func mergePositions(a, b []uint32) []uint32 {
+	if len(a) == 0 {
+		return b
+	}
+	merged := make([]uint32, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
 
-		case <-ctx.Done():
-			return ctx.Err()
+// intersectPositions returns the intersection of two sorted log position lists.
+func intersectPositions(a, b []uint32) []uint32 {
+	var shared []uint32
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			shared, a, b = append(shared, a[0]), a[1:], b[1:]
 		}
 	}
+	return shared
 }
 
 // unindexedLogs returns the logs matching the filter criteria based on raw block
@@ -291,15 +377,16 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *ty
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header *types.Header) ([]*types.Log, error) {
 	if bloomFilter(header.Bloom, f.addresses, f.topics) {
-		return f.checkMatches(ctx, header)
+		return f.checkMatches(ctx, header, nil)
 	}
 	return nil, nil
 }
 
 // checkMatches checks if the receipts belonging to the given header contain any log events that
 // match the filter criteria. This function is called when the bloom filter signals a potential match.
-// skipFilter signals all logs of the given block are requested.
-func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*types.Log, error) {
+// If positions is non-nil, only the logs at the given positions within the
+// block are inspected.
+func (f *Filter) checkMatches(ctx context.Context, header *types.Header, positions []uint32) ([]*types.Log, error) {
 	hash := header.Hash()
 	// Logs in cache are partially filled with context data
 	// such as tx index, block hash, etc.
@@ -309,7 +396,16 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*typ
 	if err != nil {
 		return nil, err
 	}
-	logs := filterLogs(cached.logs, nil, nil, f.addresses, f.topics)
+	unfiltered := cached.logs
+	if positions != nil {
+		unfiltered = make([]*types.Log, 0, len(positions))
+		for _, pos := range positions {
+			if int(pos) < len(cached.logs) {
+				unfiltered = append(unfiltered, cached.logs[pos])
+			}
+		}
+	}
+	logs := filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
 	if len(logs) == 0 {
 		return nil, nil
 	}
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"context"
 	"errors"
 	"math"
 	"math/big"
@@ -41,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
 )
 
@@ -53,6 +55,13 @@ const (
 	// All transactions with a higher size will be announced and need to be fetched
 	// by the peer.
 	txMaxBroadcastSize = 4096
+
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
+	// blockRangeUpdateInterval is the number of blocks the chain head needs to
+	// advance before the served block range is announced to eth/69 peers again.
+	blockRangeUpdateInterval = 32
 )
 
 var syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
@@ -117,6 +126,8 @@ type handler struct {
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
+	chainHeadCh   chan core.ChainHeadEvent
+	chainHeadSub  event.Subscription
 
 	requiredBlocks map[uint64]common.Hash
 
@@ -261,7 +272,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 					log.Info("Filtered out non-terminal pow block", "number", block.NumberU64(), "hash", block.Hash())
 					return 0, nil
 				}
-				if err := h.chain.InsertBlockWithoutSetHead(block); err != nil {
+				if err := h.chain.InsertBlockWithoutSetHead(context.Background(), block); err != nil {
 					return i, err
 				}
 			}
@@ -269,7 +280,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return h.chain.InsertChain(blocks)
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, func(id string) {
+		h.dropPeer(id, reputation.InvalidBlock)
+	})
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -281,7 +294,9 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	addTxs := func(txs []*types.Transaction) []error {
 		return h.txpool.Add(txs, false, false)
 	}
-	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, h.removePeer)
+	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, func(id string) {
+		h.dropPeer(id, reputation.InvalidTransaction)
+	})
 	h.chainSync = newChainSyncer(h)
 	return h, nil
 }
@@ -347,7 +362,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		td      = h.chain.GetTd(hash, number)
 	)
 	forkID := forkid.NewID(h.chain.Config(), genesis, number, head.Time)
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter, h.blockRange(head)); err != nil {
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -440,7 +455,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 				res.Done <- nil
 			case <-timeout.C:
 				peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.dropPeer(peer.ID(), reputation.Timeout)
 			}
 		}(number, hash, req)
 	}
@@ -472,10 +487,17 @@ func (h *handler) runSnapExtension(peer *snap.Peer, handler snap.Handler) error
 	return handler(peer)
 }
 
-// removePeer requests disconnection of a peer.
+// removePeer requests disconnection of a peer which violated the protocol.
 func (h *handler) removePeer(id string) {
+	h.dropPeer(id, reputation.ProtocolViolation)
+}
+
+// dropPeer reports the misbehaviour of a peer to the reputation system and
+// requests its disconnection.
+func (h *handler) dropPeer(id string, ev reputation.Event) {
 	peer := h.peers.peer(id)
 	if peer != nil {
+		peer.Peer.Report(ev)
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
 	}
 }
@@ -525,6 +547,12 @@ func (h *handler) Start(maxPeers int) {
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
 	go h.minedBroadcastLoop()
 
+	// announce the served block range to eth/69 peers
+	h.wg.Add(1)
+	h.chainHeadCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	h.chainHeadSub = h.chain.SubscribeChainHeadEvent(h.chainHeadCh)
+	go h.blockRangeLoop()
+
 	// start sync handlers
 	h.wg.Add(1)
 	go h.chainSync.loop()
@@ -537,6 +565,7 @@ func (h *handler) Start(maxPeers int) {
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.chainHeadSub.Unsubscribe()  // quits blockRangeLoop
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -674,6 +703,47 @@ func (h *handler) txBroadcastLoop() {
 	}
 }
 
+// blockRange returns the range of blocks the local node is able to serve, given
+// the current head of the chain.
+func (h *handler) blockRange(head *types.Header) eth.BlockRangeUpdatePacket {
+	// The tail of the ancient store is the first block with retained history,
+	// databases without a freezer keep the entire history around.
+	earliest, err := h.database.Tail()
+	if err != nil {
+		earliest = 0
+	}
+	return eth.BlockRangeUpdatePacket{
+		EarliestBlock:   earliest,
+		LatestBlock:     head.Number.Uint64(),
+		LatestBlockHash: head.Hash(),
+	}
+}
+
+// blockRangeLoop announces the range of blocks served by the local node to the
+// connected peers whenever the chain head advanced sufficiently.
+func (h *handler) blockRangeLoop() {
+	defer h.wg.Done()
+
+	last := h.chain.CurrentHeader().Number.Uint64()
+	for {
+		select {
+		case ev := <-h.chainHeadCh:
+			head := ev.Block.Header()
+			if number := head.Number.Uint64(); number >= last && number-last < blockRangeUpdateInterval {
+				continue
+			}
+			last = head.Number.Uint64()
+
+			update := h.blockRange(head)
+			for _, peer := range h.peers.all() {
+				peer.AsyncSendBlockRangeUpdate(update)
+			}
+		case <-h.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
 // enableSyncedFeatures enables the post-sync functionalities when the initial
 // sync is finished.
 func (h *handler) enableSyncedFeatures() {
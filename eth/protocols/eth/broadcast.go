@@ -54,6 +54,12 @@ func (p *Peer) broadcastBlocks() {
 			}
 			p.Log().Trace("Announced block", "number", block.Number(), "hash", block.Hash())
 
+		case blockRange := <-p.queuedBlockRange:
+			if err := p.SendBlockRangeUpdate(*blockRange); err != nil {
+				return
+			}
+			p.Log().Trace("Announced block range", "earliest", blockRange.EarliestBlock, "latest", blockRange.LatestBlock)
+
 		case <-p.term:
 			return
 		}
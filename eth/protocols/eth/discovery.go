@@ -17,6 +17,8 @@
 package eth
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/p2p/enode"
@@ -64,3 +66,23 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis(), head.Number.Uint64(), head.Time),
 	}
 }
+
+// NewNodeFilter returns a filtering function that returns whether the provided
+// node advertises a fork ID compatible with the current chain.
+func NewNodeFilter(chain *core.BlockChain) func(*enode.Node) bool {
+	filter := forkid.NewFilter(chain)
+	return func(n *enode.Node) bool {
+		var entry enrEntry
+		if err := n.Load(&entry); err != nil {
+			return false
+		}
+		return filter(entry.ForkID) == nil
+	}
+}
+
+// discoveryTopic returns the discv5 topic under which `eth` nodes of the chain
+// are advertised. The topic is derived from the genesis hash, so only nodes on
+// the same network are found.
+func discoveryTopic(chain *core.BlockChain) string {
+	return fmt.Sprintf("%s/%x", ProtocolName, chain.Genesis().Hash())
+}
@@ -17,10 +17,12 @@
 package eth
 
 import (
+	"errors"
 	"math"
 	"math/big"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -151,6 +153,7 @@ func (b *testBackend) Handle(*Peer, Packet) error {
 
 // Tests that block headers can be retrieved from a remote chain based on user queries.
 func TestGetBlockHeaders68(t *testing.T) { testGetBlockHeaders(t, ETH68) }
+func TestGetBlockHeaders69(t *testing.T) { testGetBlockHeaders(t, ETH69) }
 
 func testGetBlockHeaders(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -336,6 +339,7 @@ func testGetBlockHeaders(t *testing.T, protocol uint) {
 
 // Tests that block contents can be retrieved from a remote chain based on their hashes.
 func TestGetBlockBodies68(t *testing.T) { testGetBlockBodies(t, ETH68) }
+func TestGetBlockBodies69(t *testing.T) { testGetBlockBodies(t, ETH69) }
 
 func testGetBlockBodies(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -430,6 +434,7 @@ func testGetBlockBodies(t *testing.T, protocol uint) {
 
 // Tests that the transaction receipts can be retrieved based on hashes.
 func TestGetBlockReceipts68(t *testing.T) { testGetBlockReceipts(t, ETH68) }
+func TestGetBlockReceipts69(t *testing.T) { testGetBlockReceipts(t, ETH69) }
 
 func testGetBlockReceipts(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -492,10 +497,106 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		RequestId:          123,
 		GetReceiptsRequest: hashes,
 	})
-	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket{
+	var want interface{} = &ReceiptsPacket{
 		RequestId:        123,
 		ReceiptsResponse: receipts,
-	}); err != nil {
+	}
+	if protocol >= ETH69 {
+		res := make(ReceiptsResponse69, len(receipts))
+		for i, block := range receipts {
+			res[i] = make([]*Receipt69, len(block))
+			for j, receipt := range block {
+				res[i][j] = newReceipt69(receipt)
+			}
+		}
+		want = &ReceiptsPacket69{
+			RequestId:          123,
+			ReceiptsResponse69: res,
+		}
+	}
+	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, want); err != nil {
 		t.Errorf("receipts mismatch: %v", err)
 	}
 }
+
+// Tests that block range announcements are tracked on eth/69, and malformed
+// ones cause the peer to be dropped.
+func TestBlockRangeUpdate69(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(4)
+	defer backend.close()
+
+	peer, errc := newTestPeer("peer", ETH69, backend)
+	defer peer.close()
+
+	head := backend.chain.CurrentBlock()
+	update := &BlockRangeUpdatePacket{
+		EarliestBlock:   1,
+		LatestBlock:     head.Number.Uint64(),
+		LatestBlockHash: head.Hash(),
+	}
+	p2p.Send(peer.app, BlockRangeUpdateMsg, update)
+
+	// Round trip a request to ensure the update was processed
+	p2p.Send(peer.app, GetBlockHeadersMsg, &GetBlockHeadersPacket{
+		RequestId:              1,
+		GetBlockHeadersRequest: &GetBlockHeadersRequest{Origin: HashOrNumber{Number: 0}, Amount: 1},
+	})
+	if err := p2p.ExpectMsg(peer.app, BlockHeadersMsg, &BlockHeadersPacket{
+		RequestId:           1,
+		BlockHeadersRequest: BlockHeadersRequest{backend.chain.Genesis().Header()},
+	}); err != nil {
+		t.Fatalf("headers mismatch: %v", err)
+	}
+	if have := peer.BlockRange(); have == nil || *have != *update {
+		t.Fatalf("block range mismatch: have %v, want %v", have, update)
+	}
+	// Send an inverted range and ensure the peer is dropped
+	p2p.Send(peer.app, BlockRangeUpdateMsg, &BlockRangeUpdatePacket{
+		EarliestBlock:   2,
+		LatestBlock:     1,
+		LatestBlockHash: head.Hash(),
+	})
+	select {
+	case err := <-errc:
+		if !errors.Is(err, errInvalidBlockRange) {
+			t.Fatalf("wrong error: have %v, want %v", err, errInvalidBlockRange)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("peer not dropped on invalid block range")
+	}
+}
+
+// Tests that queued block range announcements are delivered on eth/69, with
+// newer ranges replacing the ones still waiting in the queue.
+func TestAsyncBlockRangeUpdate69(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(4)
+	defer backend.close()
+
+	peer, _ := newTestPeer("peer", ETH69, backend)
+	defer peer.close()
+
+	head := backend.chain.CurrentBlock()
+	for i := uint64(0); i <= head.Number.Uint64(); i++ {
+		peer.AsyncSendBlockRangeUpdate(BlockRangeUpdatePacket{LatestBlock: i, LatestBlockHash: head.Hash()})
+	}
+	for {
+		msg, err := peer.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("failed to read block range: %v", err)
+		}
+		if msg.Code != BlockRangeUpdateMsg {
+			t.Fatalf("wrong message code %d", msg.Code)
+		}
+		var update BlockRangeUpdatePacket
+		if err := msg.Decode(&update); err != nil {
+			t.Fatalf("failed to decode block range: %v", err)
+		}
+		if update.LatestBlock == head.Number.Uint64() {
+			break
+		}
+	}
+}
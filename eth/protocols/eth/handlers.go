@@ -242,9 +242,40 @@ func handleGetReceipts(backend Backend, msg Decoder, peer *Peer) error {
 	return peer.ReplyReceiptsRLP(query.RequestId, response)
 }
 
+func handleGetReceipts69(backend Backend, msg Decoder, peer *Peer) error {
+	// Decode the block receipts retrieval message
+	var query GetReceiptsPacket
+	if err := msg.Decode(&query); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	response := ServiceGetReceiptsQuery69(backend.Chain(), query.GetReceiptsRequest)
+	return peer.ReplyReceiptsRLP(query.RequestId, response)
+}
+
 // ServiceGetReceiptsQuery assembles the response to a receipt query. It is
 // exposed to allow external packages to test protocol behavior.
 func ServiceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest) []rlp.RawValue {
+	return serviceGetReceiptsQuery(chain, query, func(receipts types.Receipts) interface{} {
+		return receipts
+	})
+}
+
+// ServiceGetReceiptsQuery69 assembles the response to a receipt query on eth/69
+// and newer, encoding the receipts without their log blooms. It is exposed to
+// allow external packages to test protocol behavior.
+func ServiceGetReceiptsQuery69(chain *core.BlockChain, query GetReceiptsRequest) []rlp.RawValue {
+	return serviceGetReceiptsQuery(chain, query, func(receipts types.Receipts) interface{} {
+		encoded := make([]*Receipt69, len(receipts))
+		for i, receipt := range receipts {
+			encoded[i] = newReceipt69(receipt)
+		}
+		return encoded
+	})
+}
+
+// serviceGetReceiptsQuery assembles the response to a receipt query, converting
+// the receipts of each block into their network encoding via the given function.
+func serviceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest, encode func(types.Receipts) interface{}) []rlp.RawValue {
 	// Gather state data until the fetch or network limits is reached
 	var (
 		bytes    int
@@ -263,7 +294,7 @@ func ServiceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest) [
 			}
 		}
 		// If known, encode and queue for response packet
-		if encoded, err := rlp.EncodeToBytes(results); err != nil {
+		if encoded, err := rlp.EncodeToBytes(encode(results)); err != nil {
 			log.Error("Failed to encode receipt", "err", err)
 		} else {
 			receipts = append(receipts, encoded)
@@ -368,21 +399,53 @@ func handleReceipts(backend Backend, msg Decoder, peer *Peer) error {
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	return dispatchReceipts(peer, res.RequestId, res.ReceiptsResponse)
+}
+
+func handleReceipts69(backend Backend, msg Decoder, peer *Peer) error {
+	// A batch of bloomless receipts arrived to one of our previous requests
+	res := new(ReceiptsPacket69)
+	if err := msg.Decode(res); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	receipts, err := res.ReceiptsResponse69.Unpack()
+	if err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	return dispatchReceipts(peer, res.RequestId, receipts)
+}
+
+// dispatchReceipts delivers a batch of consensus receipts to the request they
+// were sent in reply to.
+func dispatchReceipts(peer *Peer, id uint64, receipts ReceiptsResponse) error {
 	metadata := func() interface{} {
 		hasher := trie.NewStackTrie(nil)
-		hashes := make([]common.Hash, len(res.ReceiptsResponse))
-		for i, receipt := range res.ReceiptsResponse {
+		hashes := make([]common.Hash, len(receipts))
+		for i, receipt := range receipts {
 			hashes[i] = types.DeriveSha(types.Receipts(receipt), hasher)
 		}
 		return hashes
 	}
 	return peer.dispatchResponse(&Response{
-		id:   res.RequestId,
+		id:   id,
 		code: ReceiptsMsg,
-		Res:  &res.ReceiptsResponse,
+		Res:  &receipts,
 	}, metadata)
 }
 
+func handleBlockRangeUpdate(backend Backend, msg Decoder, peer *Peer) error {
+	// The remote peer announced a change in the range of blocks it serves
+	update := new(BlockRangeUpdatePacket)
+	if err := msg.Decode(update); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if err := update.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidBlockRange, err)
+	}
+	peer.setBlockRange(*update)
+	return nil
+}
+
 func handleNewPooledTransactionHashes(backend Backend, msg Decoder, peer *Peer) error {
 	// New transaction announcement arrived, make sure we have
 	// a valid and fresh chain to handle them
@@ -35,25 +35,72 @@ const (
 )
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
-	// Send out own handshake in a new thread
-	errc := make(chan error, 2)
-
-	var status StatusPacket // safe to read after two values have been received from errc
+// network IDs, heads and genesis blocks. The total difficulty is only exchanged
+// on eth/68, whereas the served block range is only exchanged on eth/69 and newer.
+func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, blockRange BlockRangeUpdatePacket) error {
+	if p.version >= ETH69 {
+		var status StatusPacket69 // safe to read after two values have been received from errc
 
-	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
+		err := p.exchangeStatus(&StatusPacket69{
 			ProtocolVersion: uint32(p.version),
 			NetworkID:       network,
-			TD:              td,
-			Head:            head,
 			Genesis:         genesis,
 			ForkID:          forkID,
+			EarliestBlock:   blockRange.EarliestBlock,
+			LatestBlock:     blockRange.LatestBlock,
+			LatestBlockHash: blockRange.LatestBlockHash,
+		}, func() error {
+			return p.readStatus69(network, &status, genesis, forkFilter)
 		})
+		if err != nil {
+			return err
+		}
+		// The remote total difficulty is not known on eth/69, track it as zero
+		// to never select the peer for the legacy total difficulty based sync.
+		p.head, p.td = status.LatestBlockHash, new(big.Int)
+		p.blockRange = &BlockRangeUpdatePacket{
+			EarliestBlock:   status.EarliestBlock,
+			LatestBlock:     status.LatestBlock,
+			LatestBlockHash: status.LatestBlockHash,
+		}
+		return nil
+	}
+	var status StatusPacket // safe to read after two values have been received from errc
+
+	err := p.exchangeStatus(&StatusPacket{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		TD:              td,
+		Head:            head,
+		Genesis:         genesis,
+		ForkID:          forkID,
+	}, func() error {
+		return p.readStatus(network, &status, genesis, forkFilter)
+	})
+	if err != nil {
+		return err
+	}
+	p.td, p.head = status.TD, status.Head
+
+	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
+	// larger, it will still fit within 100 bits
+	if tdlen := p.td.BitLen(); tdlen > 100 {
+		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	}
+	return nil
+}
+
+// exchangeStatus sends the local status message to the remote peer, while
+// concurrently reading and validating the remote one.
+func (p *Peer) exchangeStatus(status Packet, read func() error) error {
+	// Send out own handshake in a new thread
+	errc := make(chan error, 2)
+
+	go func() {
+		errc <- p2p.Send(p.rw, StatusMsg, status)
 	}()
 	go func() {
-		errc <- p.readStatus(network, &status, genesis, forkFilter)
+		errc <- read()
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
 	defer timeout.Stop()
@@ -69,18 +116,38 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 			return p2p.DiscReadTimeout
 		}
 	}
-	p.td, p.head = status.TD, status.Head
+	return nil
+}
 
-	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
-	// larger, it will still fit within 100 bits
-	if tdlen := p.td.BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+// readStatus reads the remote eth/68 handshake message.
+func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
+	if err := p.readStatusMsg(status); err != nil {
+		return err
+	}
+	return p.checkStatus(network, status.NetworkID, status.ProtocolVersion, genesis, status.Genesis, forkFilter, status.ForkID)
+}
+
+// readStatus69 reads the remote eth/69 handshake message.
+func (p *Peer) readStatus69(network uint64, status *StatusPacket69, genesis common.Hash, forkFilter forkid.Filter) error {
+	if err := p.readStatusMsg(status); err != nil {
+		return err
+	}
+	if err := p.checkStatus(network, status.NetworkID, status.ProtocolVersion, genesis, status.Genesis, forkFilter, status.ForkID); err != nil {
+		return err
+	}
+	blockRange := BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	}
+	if err := blockRange.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidBlockRange, err)
 	}
 	return nil
 }
 
-// readStatus reads the remote handshake message.
-func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
+// readStatusMsg reads the remote handshake message and decodes it into status.
+func (p *Peer) readStatusMsg(status interface{}) error {
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
@@ -92,19 +159,24 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
 	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
+	if err := msg.Decode(status); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
-	if status.NetworkID != network {
-		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
+	return nil
+}
+
+// checkStatus validates the fields common to all versions of the handshake.
+func (p *Peer) checkStatus(network uint64, remoteNetwork uint64, remoteVersion uint32, genesis common.Hash, remoteGenesis common.Hash, forkFilter forkid.Filter, remoteForkID forkid.ID) error {
+	if remoteNetwork != network {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, remoteNetwork, network)
 	}
-	if uint(status.ProtocolVersion) != p.version {
-		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, status.ProtocolVersion, p.version)
+	if uint(remoteVersion) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, remoteVersion, p.version)
 	}
-	if status.Genesis != genesis {
-		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
+	if remoteGenesis != genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, remoteGenesis, genesis)
 	}
-	if err := forkFilter(status.ForkID); err != nil {
+	if err := forkFilter(remoteForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
 	return nil
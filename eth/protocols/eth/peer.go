@@ -72,12 +72,14 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	head common.Hash // Latest advertised head block hash
-	td   *big.Int    // Latest advertised head block total difficulty
+	head       common.Hash             // Latest advertised head block hash
+	td         *big.Int                // Latest advertised head block total difficulty
+	blockRange *BlockRangeUpdatePacket // Latest advertised range of served blocks (eth/69+)
 
-	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
-	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
-	queuedBlockAnns chan *types.Block      // Queue of blocks to announce to the peer
+	knownBlocks      *knownCache                  // Set of block hashes known to be known by this peer
+	queuedBlocks     chan *blockPropagation       // Queue of blocks to broadcast to the peer
+	queuedBlockAnns  chan *types.Block            // Queue of blocks to announce to the peer
+	queuedBlockRange chan *BlockRangeUpdatePacket // Latest block range to announce to the peer
 
 	txpool      TxPool             // Transaction pool used by the broadcasters for liveness checks
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
@@ -96,21 +98,22 @@ type Peer struct {
 // version.
 func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter, txpool TxPool) *Peer {
 	peer := &Peer{
-		id:              p.ID().String(),
-		Peer:            p,
-		rw:              rw,
-		version:         version,
-		knownTxs:        newKnownCache(maxKnownTxs),
-		knownBlocks:     newKnownCache(maxKnownBlocks),
-		queuedBlocks:    make(chan *blockPropagation, maxQueuedBlocks),
-		queuedBlockAnns: make(chan *types.Block, maxQueuedBlockAnns),
-		txBroadcast:     make(chan []common.Hash),
-		txAnnounce:      make(chan []common.Hash),
-		reqDispatch:     make(chan *request),
-		reqCancel:       make(chan *cancel),
-		resDispatch:     make(chan *response),
-		txpool:          txpool,
-		term:            make(chan struct{}),
+		id:               p.ID().String(),
+		Peer:             p,
+		rw:               rw,
+		version:          version,
+		knownTxs:         newKnownCache(maxKnownTxs),
+		knownBlocks:      newKnownCache(maxKnownBlocks),
+		queuedBlocks:     make(chan *blockPropagation, maxQueuedBlocks),
+		queuedBlockAnns:  make(chan *types.Block, maxQueuedBlockAnns),
+		queuedBlockRange: make(chan *BlockRangeUpdatePacket, 1),
+		txBroadcast:      make(chan []common.Hash),
+		txAnnounce:       make(chan []common.Hash),
+		reqDispatch:      make(chan *request),
+		reqCancel:        make(chan *cancel),
+		resDispatch:      make(chan *response),
+		txpool:           txpool,
+		term:             make(chan struct{}),
 	}
 	// Start up all the broadcasters
 	go peer.broadcastBlocks()
@@ -156,6 +159,29 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// BlockRange retrieves the latest range of blocks the peer advertised to serve,
+// or nil if the peer doesn't announce it (eth/68).
+func (p *Peer) BlockRange() *BlockRangeUpdatePacket {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.blockRange == nil {
+		return nil
+	}
+	blockRange := *p.blockRange
+	return &blockRange
+}
+
+// setBlockRange updates the range of blocks served by the peer, along with its
+// head block hash.
+func (p *Peer) setBlockRange(blockRange BlockRangeUpdatePacket) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.blockRange = &blockRange
+	p.head = blockRange.LatestBlockHash
+}
+
 // KnownBlock returns whether peer is known to already have a block.
 func (p *Peer) KnownBlock(hash common.Hash) bool {
 	return p.knownBlocks.Contains(hash)
@@ -314,6 +340,36 @@ func (p *Peer) ReplyBlockBodiesRLP(id uint64, bodies []rlp.RawValue) error {
 	})
 }
 
+// SendBlockRangeUpdate announces the range of blocks served by the local node
+// to the remote peer. Peers on eth/68 don't support the message, so the update
+// is silently skipped for them.
+func (p *Peer) SendBlockRangeUpdate(blockRange BlockRangeUpdatePacket) error {
+	if p.version < ETH69 {
+		return nil
+	}
+	return p2p.Send(p.rw, BlockRangeUpdateMsg, &blockRange)
+}
+
+// AsyncSendBlockRangeUpdate queues the range of blocks served by the local node
+// for announcement to the remote peer. Only the latest range is worth sending,
+// so a range still waiting in the queue is replaced.
+func (p *Peer) AsyncSendBlockRangeUpdate(blockRange BlockRangeUpdatePacket) {
+	if p.version < ETH69 {
+		return
+	}
+	for {
+		select {
+		case p.queuedBlockRange <- &blockRange:
+			return
+		default:
+		}
+		select {
+		case <-p.queuedBlockRange:
+		default:
+		}
+	}
+}
+
 // ReplyReceiptsRLP is the response to GetReceipts.
 func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
 	return p2p.Send(p.rw, ReceiptsMsg, &ReceiptsRLPPacket{
@@ -246,3 +246,51 @@ func TestMessages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that receipts survive the conversion to and from the bloomless eth/69
+// network encoding without changing their consensus encoding.
+func TestReceipt69Conversion(t *testing.T) {
+	logs := []*types.Log{
+		{
+			Address: common.BytesToAddress([]byte{0x11}),
+			Topics:  []common.Hash{common.HexToHash("dead"), common.HexToHash("beef")},
+			Data:    []byte{0x01, 0x00, 0xff},
+		},
+	}
+	receipts := types.Receipts{
+		{Type: types.LegacyTxType, PostState: common.HexToHash("0x1234").Bytes(), CumulativeGasUsed: 21000, Logs: []*types.Log{}},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 42000, Logs: logs},
+		{Type: types.BlobTxType, Status: types.ReceiptStatusFailed, CumulativeGasUsed: 63000, Logs: []*types.Log{}},
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	encoded := make([]*Receipt69, len(receipts))
+	for i, receipt := range receipts {
+		encoded[i] = newReceipt69(receipt)
+	}
+	blob, err := rlp.EncodeToBytes(ReceiptsResponse69{encoded})
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	var res ReceiptsResponse69
+	if err := rlp.DecodeBytes(blob, &res); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	decoded, err := res.Unpack()
+	if err != nil {
+		t.Fatalf("failed to unpack receipts: %v", err)
+	}
+	for i, receipt := range receipts {
+		want, _ := receipt.MarshalBinary()
+		have, _ := decoded[0][i].MarshalBinary()
+		if !bytes.Equal(have, want) {
+			t.Errorf("receipt %d: consensus encoding mismatch: have %x, want %x", i, have, want)
+		}
+	}
+	// Ensure malformed statuses are rejected
+	bad := ReceiptsResponse69{{{TxType: types.LegacyTxType, PostStateOrStatus: []byte{0x02}}}}
+	if _, err := bad.Unpack(); err == nil {
+		t.Errorf("invalid receipt status accepted")
+	}
+}
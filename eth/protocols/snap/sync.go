@@ -39,6 +39,7 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/msgrate"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -396,6 +397,14 @@ type SyncPeer interface {
 	Log() log.Logger
 }
 
+// reportPeer forwards behaviour of a peer to the reputation system, if the peer
+// is connected through p2p.
+func reportPeer(peer SyncPeer, ev reputation.Event) {
+	if r, ok := peer.(interface{ Report(reputation.Event) }); ok {
+		r.Report(ev)
+	}
+}
+
 // Syncer is an Ethereum account and storage trie syncer based on snapshots and
 // the  snap protocol. It's purpose is to download all the accounts and storage
 // slots from remote peers and reassemble chunks of the state trie, on top of
@@ -1048,6 +1057,7 @@ func (s *Syncer) assignAccountTasks(success chan *accountResponse, fail chan *ac
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Account range request timed out", "reqid", reqid)
+			reportPeer(peer, reputation.Timeout)
 			s.rates.Update(idle, AccountRangeMsg, 0, 0)
 			s.scheduleRevertAccountRequest(req)
 		})
@@ -1159,6 +1169,7 @@ func (s *Syncer) assignBytecodeTasks(success chan *bytecodeResponse, fail chan *
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode request timed out", "reqid", reqid)
+			reportPeer(peer, reputation.Timeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeRequest(req)
 		})
@@ -1306,6 +1317,7 @@ func (s *Syncer) assignStorageTasks(success chan *storageResponse, fail chan *st
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Storage request timed out", "reqid", reqid)
+			reportPeer(peer, reputation.Timeout)
 			s.rates.Update(idle, StorageRangesMsg, 0, 0)
 			s.scheduleRevertStorageRequest(req)
 		})
@@ -1443,6 +1455,7 @@ func (s *Syncer) assignTrienodeHealTasks(success chan *trienodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Trienode heal request timed out", "reqid", reqid)
+			reportPeer(peer, reputation.Timeout)
 			s.rates.Update(idle, TrieNodesMsg, 0, 0)
 			s.scheduleRevertTrienodeHealRequest(req)
 		})
@@ -1559,6 +1572,7 @@ func (s *Syncer) assignBytecodeHealTasks(success chan *bytecodeHealResponse, fai
 		}
 		req.timeout = time.AfterFunc(s.rates.TargetTimeout(), func() {
 			peer.Log().Debug("Bytecode heal request timed out", "reqid", reqid)
+			reportPeer(peer, reputation.Timeout)
 			s.rates.Update(idle, ByteCodesMsg, 0, 0)
 			s.scheduleRevertBytecodeHealRequest(req)
 		})
@@ -2482,6 +2496,7 @@ func (s *Syncer) OnAccounts(peer SyncPeer, id uint64, hashes []common.Hash, acco
 		logger.Debug("Peer rejected account range request", "root", s.root)
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		reportPeer(peer, reputation.UselessResponse)
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertAccountRequest(req)
@@ -2592,6 +2607,7 @@ func (s *Syncer) onByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) error
 		logger.Debug("Peer rejected bytecode request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		reportPeer(peer, reputation.UselessResponse)
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeRequest(req)
@@ -2720,6 +2736,7 @@ func (s *Syncer) OnStorage(peer SyncPeer, id uint64, hashes [][]common.Hash, slo
 		logger.Debug("Peer rejected storage request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		reportPeer(peer, reputation.UselessResponse)
 		s.scheduleRevertStorageRequest(req) // reschedule request
 		return nil
 	}
@@ -2839,6 +2856,7 @@ func (s *Syncer) OnTrieNodes(peer SyncPeer, id uint64, trienodes [][]byte) error
 		logger.Debug("Peer rejected trienode heal request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		reportPeer(peer, reputation.UselessResponse)
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertTrienodeHealRequest(req)
@@ -2946,6 +2964,7 @@ func (s *Syncer) onHealByteCodes(peer SyncPeer, id uint64, bytecodes [][]byte) e
 		logger.Debug("Peer rejected bytecode heal request")
 		s.statelessPeers[peer.ID()] = struct{}{}
 		s.lock.Unlock()
+		reportPeer(peer, reputation.UselessResponse)
 
 		// Signal this request as failed, and ready for rescheduling
 		s.scheduleRevertBytecodeHealRequest(req)
@@ -375,14 +375,15 @@ func TestInternals(t *testing.T) {
 
 			evm := vm.NewEVM(context, txContext, state.StateDB, params.MainnetChainConfig, vm.Config{Tracer: tc.tracer})
 			msg := &core.Message{
-				To:                &to,
-				From:              origin,
-				Value:             big.NewInt(0),
-				GasLimit:          80000,
-				GasPrice:          big.NewInt(0),
-				GasFeeCap:         big.NewInt(0),
-				GasTipCap:         big.NewInt(0),
-				SkipAccountChecks: false,
+				To:               &to,
+				From:             origin,
+				Value:            big.NewInt(0),
+				GasLimit:         80000,
+				GasPrice:         big.NewInt(0),
+				GasFeeCap:        big.NewInt(0),
+				GasTipCap:        big.NewInt(0),
+				SkipNonceChecks:  false,
+				SkipFromEOACheck: false,
 			}
 			st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(msg.GasLimit))
 			if _, err := st.TransitionDb(); err != nil {
@@ -0,0 +1,103 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package live
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("noop", newNoopTracer)
+}
+
+// noop is a no-op live tracer. It's there to
+// catch changes in the tracing interface, as well as
+// for testing live tracing performance. Can be removed
+// as soon as we have a real live tracer.
+type noop struct{}
+
+func newNoopTracer(_ json.RawMessage) (core.BlockchainLogger, error) {
+	return &noop{}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *noop) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *noop) CaptureEnd(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *noop) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *noop) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *noop) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *noop) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+func (t *noop) CaptureTxStart(gasLimit uint64) {}
+
+func (t *noop) CaptureTxEnd(restGas uint64) {}
+
+func (t *noop) OnBlockchainInit(chainConfig *params.ChainConfig) {}
+
+func (t *noop) OnBlockStart(ev core.BlockEvent) {}
+
+func (t *noop) OnBlockEnd(err error) {}
+
+func (t *noop) OnSkippedBlock(ev core.BlockEvent) {}
+
+func (t *noop) OnGenesisBlock(b *types.Block, alloc types.GenesisAlloc) {}
+
+func (t *noop) OnBeaconBlockRootStart(root common.Hash) {}
+
+func (t *noop) OnBeaconBlockRootEnd() {}
+
+func (t *noop) OnTxStart(env *vm.EVM, tx *types.Transaction, from common.Address) {}
+
+func (t *noop) OnTxEnd(receipt *types.Receipt, err error) {}
+
+func (t *noop) OnBalanceChange(addr common.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+}
+
+func (t *noop) OnNonceChange(addr common.Address, prev, new uint64) {}
+
+func (t *noop) OnCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+}
+
+func (t *noop) OnStorageChange(addr common.Address, slot common.Hash, prev, new common.Hash) {}
+
+func (t *noop) OnLog(l *types.Log) {}
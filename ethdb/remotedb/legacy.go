@@ -0,0 +1,145 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// debugDatabase is a read-only key-value lookup for the database of a regular
+// geth node, accessed via the debug_dbGet family of methods. It is used if the
+// remote end does not serve the remotedb namespace.
+type debugDatabase struct {
+	remote *rpc.Client
+}
+
+func (db *debugDatabase) Has(key []byte) (bool, error) {
+	if _, err := db.Get(key); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (db *debugDatabase) Get(key []byte) ([]byte, error) {
+	var resp hexutil.Bytes
+	err := db.remote.Call(&resp, "debug_dbGet", hexutil.Bytes(key))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (db *debugDatabase) HasAncient(kind string, number uint64) (bool, error) {
+	if _, err := db.Ancient(kind, number); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (db *debugDatabase) Ancient(kind string, number uint64) ([]byte, error) {
+	var resp hexutil.Bytes
+	err := db.remote.Call(&resp, "debug_dbAncient", kind, number)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (db *debugDatabase) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	return nil, errNotSupported
+}
+
+func (db *debugDatabase) Ancients() (uint64, error) {
+	var resp uint64
+	err := db.remote.Call(&resp, "debug_dbAncients")
+	return resp, err
+}
+
+func (db *debugDatabase) Tail() (uint64, error) {
+	return 0, errNotSupported
+}
+
+func (db *debugDatabase) AncientSize(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
+func (db *debugDatabase) ReadAncients(fn func(op ethdb.AncientReaderOp) error) (err error) {
+	return fn(db)
+}
+
+func (db *debugDatabase) Put(key []byte, value []byte) error {
+	return errNotSupported
+}
+
+func (db *debugDatabase) Delete(key []byte) error {
+	return errNotSupported
+}
+
+func (db *debugDatabase) ModifyAncients(f func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (db *debugDatabase) TruncateHead(n uint64) (uint64, error) {
+	return 0, errNotSupported
+}
+
+func (db *debugDatabase) TruncateTail(n uint64) (uint64, error) {
+	return 0, errNotSupported
+}
+
+func (db *debugDatabase) Sync() error {
+	return nil
+}
+
+func (db *debugDatabase) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
+	return errNotSupported
+}
+
+func (db *debugDatabase) NewBatch() ethdb.Batch {
+	panic("not supported")
+}
+
+func (db *debugDatabase) NewBatchWithSize(size int) ethdb.Batch {
+	panic("not supported")
+}
+
+func (db *debugDatabase) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	return &iterator{err: errNotSupported, done: true}
+}
+
+func (db *debugDatabase) Stat(property string) (string, error) {
+	return "", errNotSupported
+}
+
+func (db *debugDatabase) AncientDatadir() (string, error) {
+	return "", errNotSupported
+}
+
+func (db *debugDatabase) Compact(start []byte, limit []byte) error {
+	return nil
+}
+
+func (db *debugDatabase) NewSnapshot() (ethdb.Snapshot, error) {
+	return nil, errNotSupported
+}
+
+func (db *debugDatabase) Close() error {
+	db.remote.Close()
+	return nil
+}
@@ -14,141 +14,418 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package remotedb implements the key-value database layer based on a remote geth
-// node. Under the hood, it utilises the `debug_dbGet` method to implement a
-// read-only database.
-// There really are no guarantees in this database, since the local geth does not
-// exclusive access, but it can be used for basic diagnostics of a remote node.
+// Package remotedb implements the database layer based on a database served by
+// another process, e.g. by 'geth db serve'. Under the hood, it utilises the RPC
+// methods of the remotedb namespace over a persistent connection (websocket or
+// IPC preferably) to implement a full ethdb.Database, including batches,
+// iterators, snapshots and the ancient store.
+//
+// If the remote end is a regular geth node not serving the remotedb namespace,
+// the database falls back to a read-only key-value lookup via `debug_dbGet`,
+// which can be used for basic diagnostics of a remote node.
+//
+// There really are no guarantees of exclusive access in this database, the
+// served database might be modified by other clients at the same time.
 package remotedb
 
 import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// Database is a key-value lookup for a remote database via debug_dbGet.
+// errNotSupported is returned for the operations which can't be run remotely.
+var errNotSupported = errors.New("not supported by remote database")
+
+// Database is a key-value and ancient store backed by a remote database.
 type Database struct {
 	remote *rpc.Client
 }
 
-func (db *Database) Has(key []byte) (bool, error) {
-	if _, err := db.Get(key); err != nil {
-		return false, nil
+// New creates a database accessing the remote database served by the other end
+// of the given RPC client. If the remote end does not serve the remotedb
+// namespace but the debug one, a read-only database is returned.
+func New(client *rpc.Client) ethdb.Database {
+	if modules, err := client.SupportedModules(); err == nil {
+		if _, ok := modules[Namespace]; !ok {
+			if _, ok := modules["debug"]; ok {
+				return &debugDatabase{remote: client}
+			}
+		}
 	}
-	return true, nil
+	return &Database{
+		remote: client,
+	}
+}
+
+// call invokes a method of the remotedb namespace.
+func (db *Database) call(result interface{}, method string, args ...interface{}) error {
+	return db.remote.Call(result, Namespace+"_"+method, args...)
+}
+
+// Has retrieves if a key is present in the remote database.
+func (db *Database) Has(key []byte) (bool, error) {
+	var resp bool
+	err := db.call(&resp, "has", hexutil.Bytes(key))
+	return resp, err
 }
 
+// Get retrieves the given key if it's present in the remote database.
 func (db *Database) Get(key []byte) ([]byte, error) {
 	var resp hexutil.Bytes
-	err := db.remote.Call(&resp, "debug_dbGet", hexutil.Bytes(key))
-	if err != nil {
+	if err := db.call(&resp, "get", hexutil.Bytes(key)); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (db *Database) HasAncient(kind string, number uint64) (bool, error) {
-	if _, err := db.Ancient(kind, number); err != nil {
-		return false, nil
+// Put inserts the given value into the remote database.
+func (db *Database) Put(key []byte, value []byte) error {
+	return db.call(nil, "put", hexutil.Bytes(key), hexutil.Bytes(value))
+}
+
+// Delete removes the key from the remote database.
+func (db *Database) Delete(key []byte) error {
+	return db.call(nil, "delete", hexutil.Bytes(key))
+}
+
+// Stat returns a particular internal stat of the remote database.
+func (db *Database) Stat(property string) (string, error) {
+	var resp string
+	err := db.call(&resp, "stat", property)
+	return resp, err
+}
+
+// Compact flattens the remote database for the given key range.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	var from, to *hexutil.Bytes
+	if start != nil {
+		from = (*hexutil.Bytes)(&start)
+	}
+	if limit != nil {
+		to = (*hexutil.Bytes)(&limit)
+	}
+	return db.call(nil, "compact", from, to)
+}
+
+// NewBatch creates a write-only batch buffering the changes locally until a
+// final write is called, which applies them atomically to the remote database.
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewBatchWithSize creates a write-only database batch with pre-allocated buffer.
+func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
+	return &batch{db: db, ops: make([]KeyValue, 0, size)}
+}
+
+// NewIterator creates a binary-alphabetical iterator over a subset of the remote
+// database content with a particular key prefix, starting at a particular
+// initial key (or after, if it does not exist). The entries are streamed from
+// the remote database in chunks.
+func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	var id hexutil.Uint64
+	if err := db.call(&id, "newIterator", hexutil.Bytes(prefix), hexutil.Bytes(start)); err != nil {
+		return &iterator{err: err, done: true}
 	}
-	return true, nil
+	return &iterator{db: db, id: id}
+}
+
+// NewSnapshot creates a snapshot of the current state of the remote database.
+// The snapshot must be released to free up the resources held by the server.
+func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
+	var id hexutil.Uint64
+	if err := db.call(&id, "newSnapshot"); err != nil {
+		return nil, err
+	}
+	return &snapshot{db: db, id: id}, nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists in
+// the remote ancient store.
+func (db *Database) HasAncient(kind string, number uint64) (bool, error) {
+	var resp bool
+	err := db.call(&resp, "hasAncient", kind, hexutil.Uint64(number))
+	return resp, err
 }
 
+// Ancient retrieves an ancient binary blob from the remote ancient store.
 func (db *Database) Ancient(kind string, number uint64) ([]byte, error) {
 	var resp hexutil.Bytes
-	err := db.remote.Call(&resp, "debug_dbAncient", kind, number)
-	if err != nil {
+	if err := db.call(&resp, "ancient", kind, hexutil.Uint64(number)); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+// AncientRange retrieves multiple items in sequence from the remote ancient
+// store, starting from the index 'start'.
 func (db *Database) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
-	panic("not supported")
+	var resp []hexutil.Bytes
+	if err := db.call(&resp, "ancientRange", kind, hexutil.Uint64(start), hexutil.Uint64(count), hexutil.Uint64(maxBytes)); err != nil {
+		return nil, err
+	}
+	items := make([][]byte, len(resp))
+	for i, item := range resp {
+		items[i] = item
+	}
+	return items, nil
 }
 
+// Ancients returns the number of items in the remote ancient store.
 func (db *Database) Ancients() (uint64, error) {
-	var resp uint64
-	err := db.remote.Call(&resp, "debug_dbAncients")
-	return resp, err
+	var resp hexutil.Uint64
+	err := db.call(&resp, "ancients")
+	return uint64(resp), err
 }
 
+// Tail returns the number of the first stored item in the remote ancient store.
 func (db *Database) Tail() (uint64, error) {
-	panic("not supported")
+	var resp hexutil.Uint64
+	err := db.call(&resp, "tail")
+	return uint64(resp), err
 }
 
+// AncientSize returns the size of the specified category in the remote ancient
+// store.
 func (db *Database) AncientSize(kind string) (uint64, error) {
-	panic("not supported")
+	var resp hexutil.Uint64
+	err := db.call(&resp, "ancientSize", kind)
+	return uint64(resp), err
 }
 
+// ReadAncients runs the given read operation on the remote ancient store. Note,
+// the reads are not guaranteed to be atomic as opposed to a local freezer.
 func (db *Database) ReadAncients(fn func(op ethdb.AncientReaderOp) error) (err error) {
 	return fn(db)
 }
 
-func (db *Database) Put(key []byte, value []byte) error {
-	panic("not supported")
+// ModifyAncients runs a write operation on the remote ancient store. The items
+// are collected locally and appended atomically once the operation succeeds.
+func (db *Database) ModifyAncients(fn func(ethdb.AncientWriteOp) error) (int64, error) {
+	op := new(ancientWriteOp)
+	if err := fn(op); err != nil {
+		return 0, err
+	}
+	var size hexutil.Uint64
+	if err := db.call(&size, "modifyAncients", op.items); err != nil {
+		return 0, err
+	}
+	return int64(size), nil
 }
 
-func (db *Database) Delete(key []byte) error {
-	panic("not supported")
+// TruncateHead discards all but the first n ancient data from the remote
+// ancient store.
+func (db *Database) TruncateHead(n uint64) (uint64, error) {
+	var resp hexutil.Uint64
+	err := db.call(&resp, "truncateHead", hexutil.Uint64(n))
+	return uint64(resp), err
 }
 
-func (db *Database) ModifyAncients(f func(ethdb.AncientWriteOp) error) (int64, error) {
-	panic("not supported")
+// TruncateTail discards the first n ancient data from the remote ancient store.
+func (db *Database) TruncateTail(n uint64) (uint64, error) {
+	var resp hexutil.Uint64
+	err := db.call(&resp, "truncateTail", hexutil.Uint64(n))
+	return uint64(resp), err
 }
 
-func (db *Database) TruncateHead(n uint64) (uint64, error) {
-	panic("not supported")
+// Sync flushes the remote ancient store to disk.
+func (db *Database) Sync() error {
+	return db.call(nil, "sync")
 }
 
-func (db *Database) TruncateTail(n uint64) (uint64, error) {
-	panic("not supported")
+// MigrateTable is not supported remotely, the migration needs to be done on the
+// serving side.
+func (db *Database) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
+	return errNotSupported
 }
 
-func (db *Database) Sync() error {
+// AncientDatadir returns the path of the ancient store on the serving side.
+func (db *Database) AncientDatadir() (string, error) {
+	var resp string
+	err := db.call(&resp, "ancientDatadir")
+	return resp, err
+}
+
+// Close closes the connection to the remote database. The remote database is
+// not closed.
+func (db *Database) Close() error {
+	db.remote.Close()
 	return nil
 }
 
-func (db *Database) MigrateTable(s string, f func([]byte) ([]byte, error)) error {
-	panic("not supported")
+// batch is a write-only batch that commits changes to the remote database when
+// Write is called. A batch cannot be used concurrently.
+type batch struct {
+	db   *Database
+	ops  []KeyValue
+	size int
 }
 
-func (db *Database) NewBatch() ethdb.Batch {
-	panic("not supported")
+// Put inserts the given value into the batch for later committing.
+func (b *batch) Put(key, value []byte) error {
+	b.ops = append(b.ops, KeyValue{Key: common.CopyBytes(key), Value: common.CopyBytes(value)})
+	b.size += len(key) + len(value)
+	return nil
 }
 
-func (db *Database) NewBatchWithSize(size int) ethdb.Batch {
-	panic("not supported")
+// Delete inserts a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.ops = append(b.ops, KeyValue{Key: common.CopyBytes(key), Delete: true})
+	b.size += len(key)
+	return nil
 }
 
-func (db *Database) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
-	panic("not supported")
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
 }
 
-func (db *Database) Stat(property string) (string, error) {
-	panic("not supported")
+// Write flushes any accumulated data to the remote database.
+func (b *batch) Write() error {
+	return b.db.call(nil, "writeBatch", b.ops)
 }
 
-func (db *Database) AncientDatadir() (string, error) {
-	panic("not supported")
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.ops = b.ops[:0]
+	b.size = 0
 }
 
-func (db *Database) Compact(start []byte, limit []byte) error {
+// Replay replays the batch contents.
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, op := range b.ops {
+		if op.Delete {
+			if err := w.Delete(op.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (db *Database) NewSnapshot() (ethdb.Snapshot, error) {
-	panic("not supported")
+// iterator streams the entries of a remote iterator, retrieving them in chunks.
+type iterator struct {
+	db      *Database
+	id      hexutil.Uint64
+	entries []KeyValue
+	index   int
+	done    bool // Set if the remote iterator is exhausted and released
+	err     error
 }
 
-func (db *Database) Close() error {
-	db.remote.Close()
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.index < len(it.entries) {
+		it.index++
+	}
+	for it.index >= len(it.entries) {
+		if it.done {
+			return false
+		}
+		var chunk IteratorChunk
+		if err := it.db.call(&chunk, "iteratorNext", it.id); err != nil {
+			it.err, it.done = err, true
+			it.entries = nil
+			return false
+		}
+		it.entries, it.index, it.done = chunk.Entries, 0, chunk.Done
+	}
+	return true
+}
+
+// Error returns any accumulated error. Exhausting all the key/value pairs
+// is not considered to be an error.
+func (it *iterator) Error() error {
+	return it.err
+}
+
+// Key returns the key of the current key/value pair, or nil if done. The caller
+// should not modify the contents of the returned slice, and its contents may
+// change on the next call to Next.
+func (it *iterator) Key() []byte {
+	if it.index < len(it.entries) {
+		return it.entries[it.index].Key
+	}
 	return nil
 }
 
-func New(client *rpc.Client) ethdb.Database {
-	return &Database{
-		remote: client,
+// Value returns the value of the current key/value pair, or nil if done. The
+// caller should not modify the contents of the returned slice, and its contents
+// may change on the next call to Next.
+func (it *iterator) Value() []byte {
+	if it.index < len(it.entries) {
+		return it.entries[it.index].Value
+	}
+	return nil
+}
+
+// Release releases associated resources. Release should always succeed and can
+// be called multiple times without causing error.
+func (it *iterator) Release() {
+	if !it.done {
+		it.db.call(nil, "iteratorRelease", it.id)
+		it.done = true
+	}
+	it.entries, it.index = nil, 0
+}
+
+// snapshot is a read-only view of the remote database at a point in time.
+type snapshot struct {
+	db       *Database
+	id       hexutil.Uint64
+	released bool
+}
+
+// Has retrieves if a key is present in the snapshot.
+func (snap *snapshot) Has(key []byte) (bool, error) {
+	var resp bool
+	err := snap.db.call(&resp, "snapshotHas", snap.id, hexutil.Bytes(key))
+	return resp, err
+}
+
+// Get retrieves the given key if it's present in the snapshot.
+func (snap *snapshot) Get(key []byte) ([]byte, error) {
+	var resp hexutil.Bytes
+	if err := snap.db.call(&resp, "snapshotGet", snap.id, hexutil.Bytes(key)); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Release releases the snapshot on the serving side.
+func (snap *snapshot) Release() {
+	if !snap.released {
+		snap.db.call(nil, "snapshotRelease", snap.id)
+		snap.released = true
 	}
 }
+
+// ancientWriteOp collects the items appended in a remote ancient write operation.
+type ancientWriteOp struct {
+	items []AncientItem
+}
+
+// Append adds an RLP-encoded item.
+func (op *ancientWriteOp) Append(kind string, number uint64, item interface{}) error {
+	data, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		return err
+	}
+	return op.AppendRaw(kind, number, data)
+}
+
+// AppendRaw adds an item without RLP-encoding it.
+func (op *ancientWriteOp) AppendRaw(kind string, number uint64, item []byte) error {
+	op.items = append(op.items, AncientItem{Kind: kind, Number: hexutil.Uint64(number), Data: common.CopyBytes(item)})
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/dbtest"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestDatabase serves the given database in-process and returns a remote
+// database connected to it.
+func newTestDatabase(t *testing.T, db ethdb.Database) ethdb.Database {
+	server, service, err := NewServer(db)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	t.Cleanup(func() {
+		service.Close()
+		server.Stop()
+	})
+	return New(rpc.DialInProc(server))
+}
+
+func TestRemoteDB(t *testing.T) {
+	t.Run("DatabaseSuite", func(t *testing.T) {
+		dbtest.TestDatabaseSuite(t, func() ethdb.KeyValueStore {
+			return newTestDatabase(t, rawdb.NewMemoryDatabase())
+		})
+	})
+}
+
+// Tests that the iterators stream through many chunks of entries.
+func TestRemoteIteratorChunks(t *testing.T) {
+	db := newTestDatabase(t, rawdb.NewMemoryDatabase())
+
+	batch := db.NewBatch()
+	for i := 0; i < 3*iteratorChunkItems+5; i++ {
+		batch.Put([]byte(fmt.Sprintf("key-%06d", i)), []byte{byte(i)})
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatalf("failed to write batch: %v", err)
+	}
+	it := db.NewIterator([]byte("key-"), []byte("000100"))
+	defer it.Release()
+
+	var n int
+	for ; it.Next(); n++ {
+		if want := fmt.Sprintf("key-%06d", n+100); string(it.Key()) != want {
+			t.Fatalf("entry %d: key mismatch: have %s, want %s", n, it.Key(), want)
+		}
+		if !bytes.Equal(it.Value(), []byte{byte(n + 100)}) {
+			t.Fatalf("entry %d: value mismatch: have %x, want %x", n, it.Value(), []byte{byte(n + 100)})
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatalf("iteration failed: %v", err)
+	}
+	if want := 3*iteratorChunkItems + 5 - 100; n != want {
+		t.Fatalf("iteration terminated prematurely: have %d, want %d", n, want)
+	}
+}
+
+// Tests the remote access to the ancient store.
+func TestRemoteAncients(t *testing.T) {
+	local, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer local.Close()
+	db := newTestDatabase(t, local)
+
+	// Append a few blocks worth of data to the chain freezer.
+	_, err = db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			for _, kind := range []string{rawdb.ChainFreezerHashTable, rawdb.ChainFreezerHeaderTable, rawdb.ChainFreezerBodiesTable, rawdb.ChainFreezerReceiptTable} {
+				if err := op.AppendRaw(kind, i, []byte(fmt.Sprintf("%s-%d", kind, i))); err != nil {
+					return err
+				}
+			}
+			if err := op.Append(rawdb.ChainFreezerDifficultyTable, i, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to append ancients: %v", err)
+	}
+	// A failing operation should not write anything.
+	failure := errors.New("failure")
+	_, err = db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		op.AppendRaw(rawdb.ChainFreezerHashTable, 10, []byte("foo"))
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("unexpected error: have %v, want %v", err, failure)
+	}
+	if n, err := db.Ancients(); err != nil || n != 10 {
+		t.Fatalf("unexpected ancient count: have %d (%v), want 10", n, err)
+	}
+	if blob, err := db.Ancient(rawdb.ChainFreezerBodiesTable, 3); err != nil || string(blob) != "bodies-3" {
+		t.Fatalf("unexpected ancient item: have %s (%v), want bodies-3", blob, err)
+	}
+	items, err := db.AncientRange(rawdb.ChainFreezerHeaderTable, 2, 5, 0)
+	if err != nil {
+		t.Fatalf("failed to read ancient range: %v", err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("unexpected ancient range length: have %d, want 5", len(items))
+	}
+	for i, item := range items {
+		if want := fmt.Sprintf("headers-%d", i+2); string(item) != want {
+			t.Fatalf("ancient range item %d mismatch: have %s, want %s", i, item, want)
+		}
+	}
+	// Truncate the ancient store from both ends.
+	if _, err := db.TruncateHead(8); err != nil {
+		t.Fatalf("failed to truncate head: %v", err)
+	}
+	if _, err := db.TruncateTail(2); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	if err := db.Sync(); err != nil {
+		t.Fatalf("failed to sync ancients: %v", err)
+	}
+	if n, _ := db.Ancients(); n != 8 {
+		t.Fatalf("unexpected ancient count: have %d, want 8", n)
+	}
+	if tail, _ := db.Tail(); tail != 2 {
+		t.Fatalf("unexpected ancient tail: have %d, want 2", tail)
+	}
+	if ok, _ := db.HasAncient(rawdb.ChainFreezerHashTable, 8); ok {
+		t.Fatal("truncated ancient item still present")
+	}
+	if ok, _ := db.HasAncient(rawdb.ChainFreezerHashTable, 7); !ok {
+		t.Fatal("ancient item missing")
+	}
+}
+
+// testDebugAPI mimics the database access methods of the debug namespace of a
+// regular node.
+type testDebugAPI struct {
+	db ethdb.Database
+}
+
+func (api *testDebugAPI) DbGet(key hexutil.Bytes) (hexutil.Bytes, error) {
+	return api.db.Get(key)
+}
+
+// Tests that databases served by regular nodes are accessed read-only via the
+// debug namespace.
+func TestRemoteDebugFallback(t *testing.T) {
+	local := rawdb.NewMemoryDatabase()
+	local.Put([]byte("key"), []byte("value"))
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("debug", &testDebugAPI{db: local}); err != nil {
+		t.Fatalf("failed to register debug API: %v", err)
+	}
+	db := New(rpc.DialInProc(server))
+	defer db.Close()
+
+	if _, ok := db.(*debugDatabase); !ok {
+		t.Fatalf("unexpected database type: %T", db)
+	}
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Fatalf("unexpected value: have %s (%v), want value", value, err)
+	}
+	if ok, _ := db.Has([]byte("missing")); ok {
+		t.Fatal("missing key reported present")
+	}
+	if err := db.Put([]byte("key"), []byte("other")); err != errNotSupported {
+		t.Fatalf("unexpected write error: have %v, want %v", err, errNotSupported)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package remotedb
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// Namespace is the RPC namespace the database is served under.
+	Namespace = "remotedb"
+
+	// maxHandles is the maximum number of iterators and snapshots held open
+	// by the server at the same time.
+	maxHandles = 1024
+
+	// iteratorChunkItems and iteratorChunkSize limit the number of entries and
+	// the accumulated size of the entries returned by a single iterator step.
+	iteratorChunkItems = 1024
+	iteratorChunkSize  = 1024 * 1024
+
+	// maxHTTPBodySize is the maximum size of a request served over HTTP,
+	// sufficient for sizeable write batches.
+	maxHTTPBodySize = 128 * 1024 * 1024
+)
+
+var (
+	errTooManyHandles = errors.New("too many open iterators and snapshots")
+	errUnknownHandle  = errors.New("unknown iterator or snapshot")
+)
+
+// KeyValue is a database entry exchanged between the client and the server.
+type KeyValue struct {
+	Key    hexutil.Bytes `json:"key"`
+	Value  hexutil.Bytes `json:"value,omitempty"`
+	Delete bool          `json:"delete,omitempty"`
+}
+
+// AncientItem is a raw item appended to an ancient table.
+type AncientItem struct {
+	Kind   string         `json:"kind"`
+	Number hexutil.Uint64 `json:"number"`
+	Data   hexutil.Bytes  `json:"data"`
+}
+
+// IteratorChunk is a batch of consecutive entries read by a remote iterator.
+type IteratorChunk struct {
+	Entries []KeyValue `json:"entries"`
+	Done    bool       `json:"done"` // Set if the iterator is exhausted and released
+}
+
+// Service exposes a local database to remote clients through the RPC methods
+// of the remotedb namespace. Iterators and snapshots are held open on the server
+// side and referenced by the clients with numeric handles.
+type Service struct {
+	db ethdb.Database
+
+	lock      sync.Mutex
+	iterators map[uint64]ethdb.Iterator
+	snapshots map[uint64]ethdb.Snapshot
+	nextID    uint64
+}
+
+// NewService creates the RPC service exposing the given database.
+func NewService(db ethdb.Database) *Service {
+	return &Service{
+		db:        db,
+		iterators: make(map[uint64]ethdb.Iterator),
+		snapshots: make(map[uint64]ethdb.Snapshot),
+	}
+}
+
+// NewServer creates an RPC server serving the given database in the remotedb
+// namespace. The returned server can be exposed over any transport supported
+// by the rpc package, or dialed in-process.
+func NewServer(db ethdb.Database) (*rpc.Server, *Service, error) {
+	service := NewService(db)
+
+	server := rpc.NewServer()
+	server.SetHTTPBodyLimit(maxHTTPBodySize)
+	if err := server.RegisterName(Namespace, service); err != nil {
+		return nil, nil, err
+	}
+	return server, service, nil
+}
+
+// Close releases all the iterators and snapshots held open by the service. The
+// underlying database is not closed.
+func (s *Service) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, it := range s.iterators {
+		it.Release()
+		delete(s.iterators, id)
+	}
+	for id, snap := range s.snapshots {
+		snap.Release()
+		delete(s.snapshots, id)
+	}
+}
+
+// Has retrieves if a key is present in the database.
+func (s *Service) Has(key hexutil.Bytes) (bool, error) {
+	return s.db.Has(key)
+}
+
+// Get retrieves the given key if it's present in the database.
+func (s *Service) Get(key hexutil.Bytes) (hexutil.Bytes, error) {
+	return s.db.Get(key)
+}
+
+// Put inserts the given value into the database.
+func (s *Service) Put(key hexutil.Bytes, value hexutil.Bytes) error {
+	return s.db.Put(key, value)
+}
+
+// Delete removes the key from the database.
+func (s *Service) Delete(key hexutil.Bytes) error {
+	return s.db.Delete(key)
+}
+
+// WriteBatch atomically applies the given insertions and deletions.
+func (s *Service) WriteBatch(ops []KeyValue) error {
+	batch := s.db.NewBatch()
+	for _, op := range ops {
+		var err error
+		if op.Delete {
+			err = batch.Delete(op.Key)
+		} else {
+			err = batch.Put(op.Key, op.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// NewIterator creates an iterator over the subset of the database content with
+// the given prefix, starting at the given key. It returns the handle the iterator
+// can be stepped with.
+func (s *Service) NewIterator(prefix hexutil.Bytes, start hexutil.Bytes) (hexutil.Uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.iterators)+len(s.snapshots) >= maxHandles {
+		return 0, errTooManyHandles
+	}
+	s.nextID++
+	s.iterators[s.nextID] = s.db.NewIterator(prefix, start)
+	return hexutil.Uint64(s.nextID), nil
+}
+
+// IteratorNext reads the next chunk of entries of an iterator. The iterator is
+// released once it's exhausted or failed.
+//
+// The lock is held for the entire step, as iterators are not safe for concurrent
+// use and may otherwise be released while being stepped.
+func (s *Service) IteratorNext(id hexutil.Uint64) (*IteratorChunk, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	it := s.iterators[uint64(id)]
+	if it == nil {
+		return nil, errUnknownHandle
+	}
+	var (
+		chunk = new(IteratorChunk)
+		size  int
+	)
+	for len(chunk.Entries) < iteratorChunkItems && size < iteratorChunkSize {
+		if !it.Next() {
+			chunk.Done = true
+			break
+		}
+		// The returned slices are only valid until the next step, copy them.
+		key, value := common.CopyBytes(it.Key()), common.CopyBytes(it.Value())
+		chunk.Entries = append(chunk.Entries, KeyValue{Key: key, Value: value})
+		size += len(key) + len(value)
+	}
+	if chunk.Done {
+		err := it.Error()
+		it.Release()
+		delete(s.iterators, uint64(id))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chunk, nil
+}
+
+// IteratorRelease releases an iterator.
+func (s *Service) IteratorRelease(id hexutil.Uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if it := s.iterators[uint64(id)]; it != nil {
+		it.Release()
+		delete(s.iterators, uint64(id))
+	}
+}
+
+// NewSnapshot creates a snapshot of the current database content, returning the
+// handle it can be accessed with.
+func (s *Service) NewSnapshot() (hexutil.Uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.iterators)+len(s.snapshots) >= maxHandles {
+		return 0, errTooManyHandles
+	}
+	snap, err := s.db.NewSnapshot()
+	if err != nil {
+		return 0, err
+	}
+	s.nextID++
+	s.snapshots[s.nextID] = snap
+	return hexutil.Uint64(s.nextID), nil
+}
+
+// snapshot returns the snapshot with the given handle.
+func (s *Service) snapshot(id hexutil.Uint64) (ethdb.Snapshot, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	snap := s.snapshots[uint64(id)]
+	if snap == nil {
+		return nil, errUnknownHandle
+	}
+	return snap, nil
+}
+
+// SnapshotHas retrieves if a key is present in a snapshot.
+func (s *Service) SnapshotHas(id hexutil.Uint64, key hexutil.Bytes) (bool, error) {
+	snap, err := s.snapshot(id)
+	if err != nil {
+		return false, err
+	}
+	return snap.Has(key)
+}
+
+// SnapshotGet retrieves the given key from a snapshot.
+func (s *Service) SnapshotGet(id hexutil.Uint64, key hexutil.Bytes) (hexutil.Bytes, error) {
+	snap, err := s.snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Get(key)
+}
+
+// SnapshotRelease releases a snapshot.
+func (s *Service) SnapshotRelease(id hexutil.Uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if snap := s.snapshots[uint64(id)]; snap != nil {
+		snap.Release()
+		delete(s.snapshots, uint64(id))
+	}
+}
+
+// Stat returns a particular internal stat of the database.
+func (s *Service) Stat(property string) (string, error) {
+	return s.db.Stat(property)
+}
+
+// Compact flattens the database for the given key range, a missing boundary
+// denotes the respective end of the key space.
+func (s *Service) Compact(start *hexutil.Bytes, limit *hexutil.Bytes) error {
+	var from, to []byte
+	if start != nil {
+		from = *start
+	}
+	if limit != nil {
+		to = *limit
+	}
+	return s.db.Compact(from, to)
+}
+
+// HasAncient returns whether the specified ancient item exists.
+func (s *Service) HasAncient(kind string, number hexutil.Uint64) (bool, error) {
+	return s.db.HasAncient(kind, uint64(number))
+}
+
+// Ancient retrieves an ancient item.
+func (s *Service) Ancient(kind string, number hexutil.Uint64) (hexutil.Bytes, error) {
+	return s.db.Ancient(kind, uint64(number))
+}
+
+// AncientRange retrieves multiple ancient items in sequence.
+func (s *Service) AncientRange(kind string, start, count, maxBytes hexutil.Uint64) ([]hexutil.Bytes, error) {
+	items, err := s.db.AncientRange(kind, uint64(start), uint64(count), uint64(maxBytes))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]hexutil.Bytes, len(items))
+	for i, item := range items {
+		res[i] = item
+	}
+	return res, nil
+}
+
+// Ancients returns the number of items in the ancient store.
+func (s *Service) Ancients() (hexutil.Uint64, error) {
+	n, err := s.db.Ancients()
+	return hexutil.Uint64(n), err
+}
+
+// Tail returns the number of the first stored item in the ancient store.
+func (s *Service) Tail() (hexutil.Uint64, error) {
+	n, err := s.db.Tail()
+	return hexutil.Uint64(n), err
+}
+
+// AncientSize returns the size of the specified ancient table.
+func (s *Service) AncientSize(kind string) (hexutil.Uint64, error) {
+	n, err := s.db.AncientSize(kind)
+	return hexutil.Uint64(n), err
+}
+
+// ModifyAncients atomically appends the given raw items to the ancient store,
+// returning the total size of the written data.
+func (s *Service) ModifyAncients(items []AncientItem) (hexutil.Uint64, error) {
+	size, err := s.db.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for _, item := range items {
+			if err := op.AppendRaw(item.Kind, uint64(item.Number), item.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return hexutil.Uint64(size), err
+}
+
+// TruncateHead discards all but the first n ancient items.
+func (s *Service) TruncateHead(n hexutil.Uint64) (hexutil.Uint64, error) {
+	old, err := s.db.TruncateHead(uint64(n))
+	return hexutil.Uint64(old), err
+}
+
+// TruncateTail discards the first n ancient items.
+func (s *Service) TruncateTail(n hexutil.Uint64) (hexutil.Uint64, error) {
+	old, err := s.db.TruncateTail(uint64(n))
+	return hexutil.Uint64(old), err
+}
+
+// Sync flushes the ancient store to disk.
+func (s *Service) Sync() error {
+	return s.db.Sync()
+}
+
+// AncientDatadir returns the path of the ancient store on the server.
+func (s *Service) AncientDatadir() (string, error) {
+	return s.db.AncientDatadir()
+}
@@ -40,7 +40,6 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -618,10 +617,7 @@ func (b testBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscripti
 func (b testBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	panic("implement me")
 }
-func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
-func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
-	panic("implement me")
-}
+func (b testBackend) LogIndexHead() (uint64, bool) { panic("implement me") }
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -976,6 +972,299 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	// Initialize test accounts
+	var (
+		accounts = newAccounts(3)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		genBlocks = 10
+		signer    = types.HomesteadSigner{}
+	)
+	api := NewBlockChainAPI(newTestBackend(t, genBlocks, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[1]
+		//    value: 1000 wei
+		//    fee:   0 wei
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: uint64(i), To: &accounts[1].addr, Value: big.NewInt(1000), Gas: params.TxGas, GasPrice: b.BaseFee(), Data: nil}), signer, accounts[0].key)
+		b.AddTx(tx)
+		b.SetPoS()
+	}))
+	var (
+		randomAccounts = newAccounts(3)
+		logger         = randomAccounts[1].addr
+		reverter       = randomAccounts[2].addr
+		latest         = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	// The logger contract emits a LOG0 with the calldata, the reverter contract
+	// reverts unconditionally.
+	overrides := &StateOverride{
+		logger:   OverrideAccount{Code: hex2Bytes("366000600037366000a0")},
+		reverter: OverrideAccount{Code: hex2Bytes("60006000fd")},
+	}
+	num := func(n int64) *hexutil.Big { return (*hexutil.Big)(big.NewInt(n)) }
+
+	type callRes struct {
+		status  uint64
+		gasUsed uint64
+		logs    int
+		errCode int
+	}
+	var testSuite = []struct {
+		name     string
+		opts     simOpts
+		wantErr  int
+		blocks   []uint64    // expected block numbers
+		wantRes  [][]callRes // expected call results per block
+		validate func(t *testing.T, res []map[string]interface{})
+	}{
+		{
+			name: "transfers-across-blocks",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				Calls: []TransactionArgs{{
+					From:  &accounts[0].addr,
+					To:    &randomAccounts[0].addr,
+					Value: num(1000),
+				}},
+			}, {
+				Calls: []TransactionArgs{{
+					From:  &randomAccounts[0].addr,
+					To:    &accounts[1].addr,
+					Value: num(1000),
+				}},
+			}}},
+			blocks:  []uint64{11, 12},
+			wantRes: [][]callRes{{{status: 1, gasUsed: params.TxGas}}, {{status: 1, gasUsed: params.TxGas}}},
+		},
+		{
+			name: "logs-and-reverts",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				StateOverrides: overrides,
+				Calls: []TransactionArgs{{
+					From:  &accounts[0].addr,
+					To:    &logger,
+					Input: hex2Bytes("deadbeef"),
+				}, {
+					From: &accounts[0].addr,
+					To:   &reverter,
+				}},
+			}}},
+			blocks:  []uint64{11},
+			wantRes: [][]callRes{{{status: 1, gasUsed: 21493, logs: 1}, {status: 0, gasUsed: 21006, errCode: errCodeReverted}}},
+			validate: func(t *testing.T, res []map[string]interface{}) {
+				log := res[0]["calls"].([]simCallResult)[0].Logs[0]
+				if log.BlockHash != res[0]["hash"].(common.Hash) {
+					t.Errorf("log block hash mismatch: have %x, want %x", log.BlockHash, res[0]["hash"])
+				}
+				if !bytes.Equal(log.Data, common.FromHex("deadbeef")) {
+					t.Errorf("log data mismatch: have %x", log.Data)
+				}
+			},
+		},
+		{
+			name: "fill-gaps",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				BlockOverrides: &BlockOverrides{Number: num(12)},
+			}, {
+				BlockOverrides: &BlockOverrides{Number: num(15)},
+			}}},
+			blocks:  []uint64{11, 12, 13, 14, 15},
+			wantRes: [][]callRes{{}, {}, {}, {}, {}},
+		},
+		{
+			name: "blockhash-of-simulated-parent",
+			opts: simOpts{BlockStateCalls: []simBlock{{}, {
+				Calls: []TransactionArgs{{
+					From: &accounts[0].addr,
+					// BLOCKHASH(11), MSTORE, RETURN
+					Input: hex2Bytes("600b4060005260206000f3"),
+				}},
+			}}},
+			blocks: []uint64{11, 12},
+			validate: func(t *testing.T, res []map[string]interface{}) {
+				ret := res[1]["calls"].([]simCallResult)[0].ReturnValue
+				if common.BytesToHash(ret) != res[0]["hash"].(common.Hash) {
+					t.Errorf("blockhash mismatch: have %x, want %x", ret, res[0]["hash"])
+				}
+				if res[1]["parentHash"].(common.Hash) != res[0]["hash"].(common.Hash) {
+					t.Errorf("parent hash mismatch")
+				}
+			},
+		},
+		{
+			name: "invalid-block-order",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				BlockOverrides: &BlockOverrides{Number: num(12)},
+			}, {
+				BlockOverrides: &BlockOverrides{Number: num(12)},
+			}}},
+			wantErr: errCodeBlockNumberInvalid,
+		},
+		{
+			name: "invalid-timestamp-order",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				BlockOverrides: &BlockOverrides{Time: (*hexutil.Uint64)(new(uint64))},
+			}}},
+			wantErr: errCodeBlockTimestampInvalid,
+		},
+		{
+			name: "validation-nonce-too-high",
+			opts: simOpts{Validation: true, BlockStateCalls: []simBlock{{
+				Calls: []TransactionArgs{{
+					From:         &accounts[1].addr,
+					To:           &accounts[0].addr,
+					Nonce:        (*hexutil.Uint64)(new(uint64)),
+					MaxFeePerGas: (*hexutil.Big)(big.NewInt(params.GWei)),
+				}, {
+					From:         &accounts[1].addr,
+					To:           &accounts[0].addr,
+					Nonce:        (*hexutil.Uint64)(&[]uint64{5}[0]),
+					MaxFeePerGas: (*hexutil.Big)(big.NewInt(params.GWei)),
+				}},
+			}}},
+			wantErr: errCodeNonceTooHigh,
+		},
+		{
+			name: "validation-base-fee",
+			opts: simOpts{Validation: true, BlockStateCalls: []simBlock{{
+				Calls: []TransactionArgs{{
+					From: &accounts[1].addr,
+					To:   &accounts[0].addr,
+				}},
+			}}},
+			wantErr: errCodeInvalidParams,
+		},
+		{
+			name: "insufficient-funds",
+			opts: simOpts{BlockStateCalls: []simBlock{{
+				Calls: []TransactionArgs{{
+					From:  &randomAccounts[0].addr,
+					To:    &accounts[1].addr,
+					Value: num(1000),
+				}},
+			}}},
+			wantErr: errCodeInsufficientFunds,
+		},
+	}
+	for _, tc := range testSuite {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := api.SimulateV1(context.Background(), tc.opts, &latest)
+			if tc.wantErr != 0 {
+				if err == nil {
+					t.Fatalf("want error code %d, have nothing", tc.wantErr)
+				}
+				var coded rpc.Error
+				if !errors.As(err, &coded) || coded.ErrorCode() != tc.wantErr {
+					t.Fatalf("error mismatch: want code %d, have %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("want no error, have %v", err)
+			}
+			if len(result) != len(tc.blocks) {
+				t.Fatalf("block count mismatch: have %d, want %d", len(result), len(tc.blocks))
+			}
+			for i, block := range result {
+				if have := block["number"].(*hexutil.Big).ToInt().Uint64(); have != tc.blocks[i] {
+					t.Errorf("block %d: number mismatch: have %d, want %d", i, have, tc.blocks[i])
+				}
+				if tc.wantRes == nil {
+					continue
+				}
+				calls := block["calls"].([]simCallResult)
+				if len(calls) != len(tc.wantRes[i]) {
+					t.Fatalf("block %d: call count mismatch: have %d, want %d", i, len(calls), len(tc.wantRes[i]))
+				}
+				for j, call := range calls {
+					want := tc.wantRes[i][j]
+					if uint64(call.Status) != want.status {
+						t.Errorf("block %d call %d: status mismatch: have %d, want %d", i, j, call.Status, want.status)
+					}
+					if uint64(call.GasUsed) != want.gasUsed {
+						t.Errorf("block %d call %d: gas mismatch: have %d, want %d", i, j, call.GasUsed, want.gasUsed)
+					}
+					if len(call.Logs) != want.logs {
+						t.Errorf("block %d call %d: log count mismatch: have %d, want %d", i, j, len(call.Logs), want.logs)
+					}
+					if (call.Error == nil && want.errCode != 0) || (call.Error != nil && call.Error.Code != want.errCode) {
+						t.Errorf("block %d call %d: error mismatch: have %v, want code %d", i, j, call.Error, want.errCode)
+					}
+				}
+			}
+			if tc.validate != nil {
+				tc.validate(t, result)
+			}
+		})
+	}
+}
+
+// Tests that the system calls are applied before the calls of each simulated
+// block, making the parent beacon root and parent block hash accessible.
+func TestSimulateV1SystemCalls(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(1)
+		config   = *params.MergedTestChainConfig
+	)
+	config.PragueTime = new(uint64)
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr:             {Balance: big.NewInt(params.Ether)},
+			params.HistoryStorageAddress: {Nonce: 1, Code: params.HistoryStorageCode},
+		},
+	}
+	api := NewBlockChainAPI(newTestBackend(t, 10, genesis, beacon.NewFaker(), nil))
+	var (
+		latest = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		time   = uint64(1000)
+	)
+	opts := simOpts{BlockStateCalls: []simBlock{{
+		BlockOverrides: &BlockOverrides{Time: (*hexutil.Uint64)(&time)},
+		StateOverrides: &StateOverride{
+			params.BeaconRootsStorageAddress: OverrideAccount{Code: (*hexutil.Bytes)(&params.BeaconRootsCode)},
+		},
+	}, {
+		Calls: []TransactionArgs{{
+			// The beacon roots contract reverts for unknown timestamps
+			From:  &accounts[0].addr,
+			To:    &params.BeaconRootsStorageAddress,
+			Input: hex2Bytes(fmt.Sprintf("%064x", time+timestampIncrement)),
+		}, {
+			From:  &accounts[0].addr,
+			To:    &params.HistoryStorageAddress,
+			Input: hex2Bytes(fmt.Sprintf("%064x", 11)),
+		}},
+	}}}
+	result, err := api.SimulateV1(context.Background(), opts, &latest)
+	if err != nil {
+		t.Fatalf("want no error, have %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("block count mismatch: have %d, want 2", len(result))
+	}
+	calls := result[1]["calls"].([]simCallResult)
+	if calls[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+		t.Errorf("beacon root lookup failed: %v", calls[0].Error)
+	}
+	if have := common.BytesToHash(calls[0].ReturnValue); have != (common.Hash{}) {
+		t.Errorf("beacon root mismatch: have %x, want %x", have, common.Hash{})
+	}
+	if calls[1].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+		t.Errorf("block hash lookup failed: %v", calls[1].Error)
+	}
+	if have := common.BytesToHash(calls[1].ReturnValue); have != result[0]["hash"].(common.Hash) {
+		t.Errorf("parent block hash mismatch: have %x, want %x", have, result[0]["hash"])
+	}
+}
+
 func TestSignTransaction(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -0,0 +1,476 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	// maxSimulateBlocks is the maximum number of blocks that can be simulated
+	// in a single request.
+	maxSimulateBlocks = 256
+
+	// timestampIncrement is the default increment between block timestamps.
+	timestampIncrement = 1
+)
+
+// simBlock is a batch of calls to be simulated sequentially.
+type simBlock struct {
+	BlockOverrides *BlockOverrides
+	StateOverrides *StateOverride
+	Calls          []TransactionArgs
+}
+
+// simCallResult is the result of a simulated call.
+type simCallResult struct {
+	ReturnValue hexutil.Bytes  `json:"returnData"`
+	Logs        []*types.Log   `json:"logs"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	Error       *callError     `json:"error,omitempty"`
+}
+
+func (r *simCallResult) MarshalJSON() ([]byte, error) {
+	type callResultAlias simCallResult
+	// Marshal logs to be an empty array instead of nil when empty
+	if r.Logs == nil {
+		r.Logs = []*types.Log{}
+	}
+	return json.Marshal((*callResultAlias)(r))
+}
+
+// simOpts are the inputs to eth_simulateV1.
+type simOpts struct {
+	BlockStateCalls        []simBlock
+	Validation             bool
+	ReturnFullTransactions bool
+}
+
+// simulator is a stateful object that simulates a series of blocks.
+// It is not safe for concurrent use.
+type simulator struct {
+	b           Backend
+	state       *state.StateDB
+	base        *types.Header
+	chainConfig *params.ChainConfig
+	gp          *core.GasPool
+	validate    bool
+	fullTx      bool
+}
+
+// execute runs the simulation of a series of blocks.
+func (sim *simulator) execute(ctx context.Context, blocks []simBlock) ([]map[string]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var (
+		cancel  context.CancelFunc
+		timeout = sim.b.RPCEVMTimeout()
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	// Make sure the context is cancelled when the call has completed
+	// this makes sure resources are cleaned up.
+	defer cancel()
+
+	var err error
+	blocks, err = sim.sanitizeChain(blocks)
+	if err != nil {
+		return nil, err
+	}
+	// Prepare block headers with preliminary fields for the response.
+	headers, err := sim.makeHeaders(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results = make([]map[string]interface{}, len(blocks))
+		parent  = sim.base
+		// Assume same total difficulty for all simulated blocks.
+		td = sim.b.GetTd(ctx, sim.base.Hash())
+	)
+	for bi, block := range blocks {
+		result, senders, callResults, err := sim.processBlock(ctx, &block, headers[bi], parent, headers[:bi], timeout)
+		if err != nil {
+			return nil, err
+		}
+		enc := RPCMarshalBlock(result, true, sim.fullTx, sim.chainConfig)
+		if sim.fullTx {
+			// The simulated transactions are unsigned, patch in the real senders.
+			for i, tx := range enc["transactions"].([]interface{}) {
+				tx.(*RPCTransaction).From = senders[i]
+			}
+		}
+		enc["totalDifficulty"] = (*hexutil.Big)(td)
+		enc["calls"] = callResults
+		results[bi] = enc
+
+		// Replace the preliminary header with the sealed one, so subsequent
+		// blocks can resolve its hash via BLOCKHASH.
+		headers[bi] = result.Header()
+		parent = headers[bi]
+	}
+	return results, nil
+}
+
+func (sim *simulator) processBlock(ctx context.Context, block *simBlock, header, parent *types.Header, headers []*types.Header, timeout time.Duration) (*types.Block, []common.Address, []simCallResult, error) {
+	// Set header fields that depend only on parent block.
+	// Parent hash is needed for evm.GetHashFn to work.
+	header.ParentHash = parent.Hash()
+	if sim.chainConfig.IsLondon(header.Number) {
+		// In non-validation mode base fee is set to 0 if it is not overridden.
+		// This is because it creates an edge case in EVM where gasPrice < baseFee.
+		// Base fee could have been overridden.
+		if header.BaseFee == nil {
+			if sim.validate {
+				header.BaseFee = eip1559.CalcBaseFee(sim.chainConfig, parent)
+			} else {
+				header.BaseFee = big.NewInt(0)
+			}
+		}
+	}
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		var excess uint64
+		if sim.chainConfig.IsCancun(parent.Number, parent.Time) {
+			excess = eip4844.CalcExcessBlobGas(*parent.ExcessBlobGas, *parent.BlobGasUsed)
+		} else {
+			excess = eip4844.CalcExcessBlobGas(0, 0)
+		}
+		header.ExcessBlobGas = &excess
+	}
+	blockContext := core.NewEVMBlockContext(header, sim.newSimulatedChainContext(ctx, headers), nil)
+	if block.BlockOverrides.BlobBaseFee != nil {
+		blockContext.BlobBaseFee = block.BlockOverrides.BlobBaseFee.ToInt()
+	}
+	// State overrides are applied prior to execution of a block
+	if err := block.StateOverrides.Apply(sim.state); err != nil {
+		return nil, nil, nil, err
+	}
+	var (
+		gasUsed, blobGasUsed uint64
+		txes                 = make([]*types.Transaction, len(block.Calls))
+		senders              = make([]common.Address, len(block.Calls))
+		callResults          = make([]simCallResult, len(block.Calls))
+		receipts             = make([]*types.Receipt, len(block.Calls))
+		vmConfig             = &vm.Config{NoBaseFee: !sim.validate}
+		evm                  = vm.NewEVM(blockContext, vm.TxContext{GasPrice: new(big.Int)}, sim.state, sim.chainConfig, *vmConfig)
+	)
+	// Wait for the context to be done and cancel the evm. Even if the
+	// EVM has finished, cancelling may be done (repeatedly)
+	go func() {
+		<-ctx.Done()
+		evm.Cancel()
+	}()
+	// Apply the system calls preceding the transactions of the block.
+	if header.ParentBeaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, evm, sim.state)
+	}
+	if sim.chainConfig.IsPrague(header.Number, header.Time) {
+		core.ProcessParentBlockHash(header.ParentHash, evm, sim.state)
+	}
+	for i, call := range block.Calls {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, err
+		}
+		if err := sim.sanitizeCall(&call, sim.state, &blockContext, gasUsed); err != nil {
+			return nil, nil, nil, err
+		}
+		tx := call.toTransaction()
+		txes[i], senders[i] = tx, call.from()
+
+		msg, err := call.ToMessage(sim.gp.Gas(), header.BaseFee)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		msg.Nonce = uint64(*call.Nonce)
+		// The EOA check is always skipped, even in validation mode.
+		msg.SkipNonceChecks = !sim.validate
+		msg.SkipFromEOACheck = true
+
+		sim.state.SetTxContext(tx.Hash(), i)
+		evm.Reset(core.NewEVMTxContext(msg), sim.state)
+		result, err := core.ApplyMessage(evm, msg, sim.gp)
+		if err != nil {
+			return nil, nil, nil, txValidationError(err)
+		}
+		if err := sim.state.Error(); err != nil {
+			return nil, nil, nil, err
+		}
+		// If the timer caused an abort, return an appropriate error message
+		if evm.Cancelled() {
+			return nil, nil, nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		}
+		// Update the state with pending changes.
+		var root []byte
+		if sim.chainConfig.IsByzantium(blockContext.BlockNumber) {
+			sim.state.Finalise(true)
+		} else {
+			root = sim.state.IntermediateRoot(sim.chainConfig.IsEIP158(blockContext.BlockNumber)).Bytes()
+		}
+		gasUsed += result.UsedGas
+		// Block hash will be repaired after execution.
+		receipts[i] = core.MakeReceipt(evm, result, sim.state, blockContext.BlockNumber, common.Hash{}, tx, gasUsed, root)
+		blobGasUsed += receipts[i].BlobGasUsed
+
+		callRes := simCallResult{ReturnValue: result.Return(), Logs: receipts[i].Logs, GasUsed: hexutil.Uint64(result.UsedGas)}
+		if result.Failed() {
+			callRes.Status = hexutil.Uint64(types.ReceiptStatusFailed)
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				// If the result contains a revert reason, try to unpack it.
+				revertErr := newRevertError(result.Revert())
+				callRes.Error = &callError{Message: revertErr.Error(), Code: errCodeReverted, Data: revertErr.ErrorData().(string)}
+			} else {
+				callRes.Error = &callError{Message: result.Err.Error(), Code: errCodeVMError}
+			}
+		} else {
+			callRes.Status = hexutil.Uint64(types.ReceiptStatusSuccessful)
+		}
+		callResults[i] = callRes
+	}
+	header.Root = sim.state.IntermediateRoot(true)
+	header.GasUsed = gasUsed
+	if sim.chainConfig.IsCancun(header.Number, header.Time) {
+		header.BlobGasUsed = &blobGasUsed
+	}
+	var b *types.Block
+	if sim.chainConfig.IsShanghai(header.Number, header.Time) {
+		b = types.NewBlockWithWithdrawals(header, txes, nil, receipts, make([]*types.Withdrawal, 0), trie.NewStackTrie(nil))
+	} else {
+		b = types.NewBlock(header, txes, nil, receipts, trie.NewStackTrie(nil))
+	}
+	repairLogs(callResults, b.Hash())
+	return b, senders, callResults, nil
+}
+
+// repairLogs updates the block hash in the logs present in the result of
+// a simulated block. This is needed as during execution when logs are collected
+// the block hash is not known.
+func repairLogs(calls []simCallResult, hash common.Hash) {
+	for i := range calls {
+		for j := range calls[i].Logs {
+			calls[i].Logs[j].BlockHash = hash
+		}
+	}
+}
+
+// sanitizeCall fills in the defaults of a simulated call which depend on the
+// state of the simulated block.
+func (sim *simulator) sanitizeCall(call *TransactionArgs, state *state.StateDB, blockContext *vm.BlockContext, gasUsed uint64) error {
+	if call.Nonce == nil {
+		nonce := state.GetNonce(call.from())
+		call.Nonce = (*hexutil.Uint64)(&nonce)
+	}
+	// Let the call run wild unless explicitly specified.
+	if call.Gas == nil {
+		remaining := blockContext.GasLimit - gasUsed
+		call.Gas = (*hexutil.Uint64)(&remaining)
+	}
+	if gasUsed+uint64(*call.Gas) > blockContext.GasLimit {
+		return &blockGasLimitReachedError{fmt.Sprintf("block gas limit reached: %d >= %d", gasUsed, blockContext.GasLimit)}
+	}
+	if call.Data != nil && call.Input != nil && !bytes.Equal(*call.Data, *call.Input) {
+		return &invalidParamsError{message: `both "data" and "input" are set and not equal. Please use "input" to pass transaction call data`}
+	}
+	if call.Value == nil {
+		call.Value = new(hexutil.Big)
+	}
+	if call.ChainID == nil {
+		call.ChainID = (*hexutil.Big)(sim.chainConfig.ChainID)
+	} else if have := call.ChainID.ToInt(); have.Cmp(sim.chainConfig.ChainID) != 0 {
+		return &invalidParamsError{message: fmt.Sprintf("chainId does not match node's (have=%v, want=%v)", have, sim.chainConfig.ChainID)}
+	}
+	// Simulated calls are always packed as 1559 transactions, except when the
+	// caller explicitly requested a legacy gas price.
+	if call.GasPrice == nil {
+		if call.MaxFeePerGas == nil {
+			call.MaxFeePerGas = new(hexutil.Big)
+		}
+		if call.MaxPriorityFeePerGas == nil {
+			call.MaxPriorityFeePerGas = new(hexutil.Big)
+		}
+	}
+	if call.BlobHashes != nil {
+		if call.To == nil {
+			return &invalidParamsError{message: `missing "to" in blob transaction`}
+		}
+		if call.GasPrice != nil {
+			return &invalidParamsError{message: "gasPrice is not supported for blob transactions"}
+		}
+	}
+	if call.BlobHashes != nil && call.BlobFeeCap == nil {
+		call.BlobFeeCap = new(hexutil.Big)
+	}
+	return nil
+}
+
+// sanitizeChain checks the chain integrity. Specifically it checks that
+// block numbers and timestamp are strictly increasing, setting default values
+// when necessary. Gaps in block numbers are filled with empty blocks.
+// Note: It modifies the block's override object.
+func (sim *simulator) sanitizeChain(blocks []simBlock) ([]simBlock, error) {
+	var (
+		res           = make([]simBlock, 0, len(blocks))
+		base          = sim.base
+		prevNumber    = base.Number
+		prevTimestamp = base.Time
+	)
+	for _, block := range blocks {
+		if block.BlockOverrides == nil {
+			block.BlockOverrides = new(BlockOverrides)
+		}
+		if block.BlockOverrides.Number == nil {
+			n := new(big.Int).Add(prevNumber, big.NewInt(1))
+			block.BlockOverrides.Number = (*hexutil.Big)(n)
+		}
+		diff := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), prevNumber)
+		if diff.Cmp(common.Big0) <= 0 {
+			return nil, &invalidBlockNumberError{fmt.Sprintf("block numbers must be in order: %d <= %d", block.BlockOverrides.Number.ToInt().Uint64(), prevNumber)}
+		}
+		if total := new(big.Int).Sub(block.BlockOverrides.Number.ToInt(), base.Number); total.Cmp(big.NewInt(maxSimulateBlocks)) > 0 {
+			return nil, &clientLimitExceededError{message: "too many blocks"}
+		}
+		if diff.Cmp(big.NewInt(1)) > 0 {
+			// Fill the gap with empty blocks.
+			gap := new(big.Int).Sub(diff, big.NewInt(1))
+			// Assign block number to the empty blocks.
+			for i := uint64(0); i < gap.Uint64(); i++ {
+				n := new(big.Int).Add(prevNumber, big.NewInt(int64(i+1)))
+				t := prevTimestamp + timestampIncrement
+				b := simBlock{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(n), Time: (*hexutil.Uint64)(&t)}}
+				prevTimestamp = t
+				res = append(res, b)
+			}
+		}
+		// Only append block after filling a potential gap.
+		prevNumber = block.BlockOverrides.Number.ToInt()
+		var t uint64
+		if block.BlockOverrides.Time == nil {
+			t = prevTimestamp + timestampIncrement
+			block.BlockOverrides.Time = (*hexutil.Uint64)(&t)
+		} else {
+			t = uint64(*block.BlockOverrides.Time)
+			if t <= prevTimestamp {
+				return nil, &invalidBlockTimestampError{fmt.Sprintf("block timestamps must be in order: %d <= %d", t, prevTimestamp)}
+			}
+		}
+		prevTimestamp = t
+		res = append(res, block)
+	}
+	return res, nil
+}
+
+// makeHeaders makes header object with preliminary fields based on a simulated block.
+// Some fields have to be filled post-execution.
+// It assumes blocks are in order and numbers have been validated.
+func (sim *simulator) makeHeaders(blocks []simBlock) ([]*types.Header, error) {
+	var (
+		res    = make([]*types.Header, len(blocks))
+		header = sim.base
+	)
+	for bi, block := range blocks {
+		if block.BlockOverrides == nil || block.BlockOverrides.Number == nil {
+			return nil, errors.New("empty block number")
+		}
+		overrides := block.BlockOverrides
+
+		var withdrawalsHash *common.Hash
+		if sim.chainConfig.IsShanghai(overrides.Number.ToInt(), (uint64)(*overrides.Time)) {
+			withdrawalsHash = &types.EmptyWithdrawalsHash
+		}
+		var parentBeaconRoot *common.Hash
+		if sim.chainConfig.IsCancun(overrides.Number.ToInt(), (uint64)(*overrides.Time)) {
+			parentBeaconRoot = &common.Hash{}
+		}
+		header = overrides.MakeHeader(&types.Header{
+			UncleHash:        types.EmptyUncleHash,
+			ReceiptHash:      types.EmptyReceiptsHash,
+			TxHash:           types.EmptyTxsHash,
+			Coinbase:         header.Coinbase,
+			Difficulty:       header.Difficulty,
+			GasLimit:         header.GasLimit,
+			WithdrawalsHash:  withdrawalsHash,
+			ParentBeaconRoot: parentBeaconRoot,
+		})
+		res[bi] = header
+	}
+	return res, nil
+}
+
+func (sim *simulator) newSimulatedChainContext(ctx context.Context, headers []*types.Header) *ChainContext {
+	return NewChainContext(ctx, &simBackend{base: sim.base, b: sim.b, headers: headers})
+}
+
+// simBackend is a ChainContextBackend which resolves the headers of the
+// simulated blocks on top of the canonical chain.
+type simBackend struct {
+	b       ChainContextBackend
+	base    *types.Header
+	headers []*types.Header
+}
+
+func (b *simBackend) Engine() consensus.Engine {
+	return b.b.Engine()
+}
+
+func (b *simBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if uint64(number) == b.base.Number.Uint64() {
+		return b.base, nil
+	}
+	if uint64(number) < b.base.Number.Uint64() {
+		// Resolve canonical header.
+		return b.b.HeaderByNumber(ctx, number)
+	}
+	// Simulated block.
+	for _, header := range b.headers {
+		if header.Number.Uint64() == uint64(number) {
+			return header, nil
+		}
+	}
+	return nil, errors.New("header not found")
+}
+
+// simGasCap returns the gas allowance shared by all calls of a simulation.
+func simGasCap(globalGasCap uint64) uint64 {
+	if globalGasCap == 0 {
+		return math.MaxUint64
+	}
+	return globalGasCap
+}
@@ -0,0 +1,278 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// scopeName is the instrumentation scope the spans are reported under.
+	scopeName = "github.com/ethereum/go-ethereum"
+
+	// maxQueuedSpans is the number of finished spans buffered for export, any
+	// further spans are dropped until the queue is drained.
+	maxQueuedSpans = 4096
+
+	// maxBatchSpans is the maximum number of spans exported in a single request.
+	maxBatchSpans = 512
+
+	// statusError is the STATUS_CODE_ERROR enum value of OTLP.
+	statusError = 2
+)
+
+var droppedSpansMeter = metrics.NewRegisteredMeter("telemetry/spans/dropped", nil)
+
+// exporter batches the finished spans and pushes them to an OTLP/HTTP endpoint.
+type exporter struct {
+	config Config
+	client *http.Client
+	attrs  []keyValue // Resource attributes
+
+	queue  chan *Span
+	closeC chan struct{}
+	doneC  chan struct{}
+}
+
+func newExporter(config Config) *exporter {
+	attrs := map[string]string{"service.name": "geth"}
+	for key, value := range config.Attributes {
+		attrs[key] = value
+	}
+	var resource []keyValue
+	for key, value := range attrs {
+		resource = append(resource, newKeyValue(key, value))
+	}
+	sort.Slice(resource, func(i, j int) bool { return resource[i].Key < resource[j].Key })
+
+	e := &exporter{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		attrs:  resource,
+		queue:  make(chan *Span, maxQueuedSpans),
+		closeC: make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+// add queues a finished span for export, dropping it if the queue is full.
+func (e *exporter) add(s *Span) {
+	select {
+	case e.queue <- s:
+	default:
+		droppedSpansMeter.Mark(1)
+	}
+}
+
+// close stops the exporter, pushing the queued spans a last time.
+func (e *exporter) close() {
+	close(e.closeC)
+	<-e.doneC
+}
+
+// loop collects the finished spans into batches, pushing them when either full
+// or the configured interval elapsed.
+func (e *exporter) loop() {
+	defer close(e.doneC)
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.push(batch); err != nil {
+			log.Warn("Unable to send spans to OTLP endpoint", "spans", len(batch), "err", err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case s := <-e.queue:
+			if batch = append(batch, s); len(batch) >= maxBatchSpans {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+
+		case <-e.closeC:
+			for {
+				select {
+				case s := <-e.queue:
+					if batch = append(batch, s); len(batch) >= maxBatchSpans {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// push sends a batch of spans to the OTLP endpoint.
+func (e *exporter) push(batch []*Span) error {
+	spans := make([]*span, len(batch))
+	for i, s := range batch {
+		spans[i] = encodeSpan(s)
+	}
+	body, err := json.Marshal(&exportRequest{
+		ResourceSpans: []*resourceSpans{{
+			Resource: resource{Attributes: e.attrs},
+			ScopeSpans: []*scopeSpans{{
+				Scope: scope{Name: scopeName},
+				Spans: spans,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// encodeSpan converts a finished span into its OTLP representation.
+func encodeSpan(s *Span) *span {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	enc := &span{
+		TraceID:           hex.EncodeToString(s.ctx.TraceID[:]),
+		SpanID:            hex.EncodeToString(s.ctx.SpanID[:]),
+		Name:              s.name,
+		Kind:              int(s.kind),
+		StartTimeUnixNano: formatUnixNano(s.start),
+		EndTimeUnixNano:   formatUnixNano(s.end),
+	}
+	if s.parent != (SpanID{}) {
+		enc.ParentSpanID = hex.EncodeToString(s.parent[:])
+	}
+	for _, attr := range s.attrs {
+		enc.Attributes = append(enc.Attributes, newKeyValue(attr.Key, attr.Value))
+	}
+	if s.err != nil {
+		enc.Status = &status{Code: statusError, Message: s.err.Error()}
+	}
+	return enc
+}
+
+// The subset of the OTLP trace data model needed by the exporter, following the
+// proto3 JSON mapping of the opentelemetry-proto definitions (64 bit integers are
+// encoded as strings, enums as numbers, identifiers as hex strings).
+
+type exportRequest struct {
+	ResourceSpans []*resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource      `json:"resource"`
+	ScopeSpans []*scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope scope   `json:"scope"`
+	Spans []*span `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            *status    `json:"status,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+func newKeyValue(key string, value interface{}) keyValue {
+	kv := keyValue{Key: key}
+	switch v := value.(type) {
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		kv.Value.IntValue = &s
+	case bool:
+		kv.Value.BoolValue = &v
+	case string:
+		kv.Value.StringValue = &v
+	default:
+		s := fmt.Sprint(v)
+		kv.Value.StringValue = &s
+	}
+	return kv
+}
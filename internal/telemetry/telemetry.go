@@ -0,0 +1,314 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package telemetry implements distributed tracing of the node internals. Spans
+// are propagated through contexts, joined to remote traces via the W3C Trace
+// Context headers and exported to an OpenTelemetry collector over OTLP/HTTP.
+//
+// Tracing is disabled by default, in which case starting a span is a no-op that
+// doesn't allocate.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TraceID is the identifier of a trace, shared by all of its spans.
+type TraceID [16]byte
+
+// SpanID is the identifier of a single span within a trace.
+type SpanID [8]byte
+
+// SpanContext is the part of a span propagated to its children, either within
+// the process or to remote services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool // Whether the spans of the trace are recorded
+}
+
+// IsValid returns whether the span context carries non-zero identifiers.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != (TraceID{}) && sc.SpanID != (SpanID{})
+}
+
+// Traceparent encodes the span context as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + flags
+}
+
+// ParseTraceparent decodes a W3C traceparent header value. Unknown future versions
+// are accepted as long as they start with the fields of version 00.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	var sc SpanContext
+
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	version, err := hex.DecodeString(parts[0])
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&0x01 != 0
+	return sc, sc.IsValid()
+}
+
+// SpanKind describes the relationship of a span with the other spans of its trace.
+type SpanKind int
+
+// The span kinds, numbered the same as in the OTLP protocol.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// Attribute is a key/value pair annotating a span.
+type Attribute struct {
+	Key   string
+	Value interface{} // string, int64 or bool
+}
+
+// String creates a string attribute.
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int64 creates an integer attribute.
+func Int64(key string, value int64) Attribute { return Attribute{Key: key, Value: value} }
+
+// Uint64 creates an integer attribute, clamped to the signed range of the
+// OTLP integers.
+func Uint64(key string, value uint64) Attribute {
+	if value > 1<<63-1 {
+		value = 1<<63 - 1
+	}
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Bool creates a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Span is a single timed operation of a trace. All the methods of a span are
+// safe to call on a nil span, which is returned if tracing is disabled.
+type Span struct {
+	tracer *tracer
+	ctx    SpanContext
+	parent SpanID
+	kind   SpanKind
+	name   string
+	start  time.Time
+
+	lock  sync.Mutex
+	end   time.Time
+	attrs []Attribute
+	err   error
+	ended bool
+}
+
+// Context returns the span context to be propagated to the children of the span.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.ctx
+}
+
+// SetAttributes adds annotations to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil || !s.ctx.Sampled {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span as failed with the given error. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil || !s.ctx.Sampled {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = err
+}
+
+// End finishes the span, queueing it for export if it's sampled. Calling End on
+// a finished span is a no-op.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended, s.end = true, time.Now()
+	s.lock.Unlock()
+
+	if s.ctx.Sampled {
+		s.tracer.export(s)
+	}
+}
+
+type spanKey struct{}
+
+// ContextWithRemoteSpan returns a context carrying a span context received from
+// a remote service (e.g. through a traceparent header), which the spans started
+// from the returned context will be children of.
+func ContextWithRemoteSpan(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, sc)
+}
+
+// SpanContextFromContext returns the context of the span active in the given
+// context, local or remote.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	switch v := ctx.Value(spanKey{}).(type) {
+	case *Span:
+		return v.ctx, true
+	case SpanContext:
+		return v, true
+	}
+	return SpanContext{}, false
+}
+
+// StartSpan starts an internal span as a child of the span active in the given
+// context, returning the context carrying the new span. The span must be ended
+// by the caller.
+func StartSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return StartSpanWithKind(ctx, KindInternal, name, attrs...)
+}
+
+// StartSpanWithKind starts a span of the given kind as a child of the span active
+// in the given context, returning the context carrying the new span.
+func StartSpanWithKind(ctx context.Context, kind SpanKind, name string, attrs ...Attribute) (context.Context, *Span) {
+	t := active.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer: t,
+		kind:   kind,
+		name:   name,
+		start:  time.Now(),
+	}
+	if parent, ok := SpanContextFromContext(ctx); ok {
+		span.ctx = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.ctx.TraceID[:])
+		span.ctx.Sampled = t.sample(span.ctx.TraceID)
+	}
+	rand.Read(span.ctx.SpanID[:])
+	if span.ctx.Sampled {
+		span.attrs = attrs
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Config contains the settings of the tracing.
+type Config struct {
+	Endpoint    string            // Full URL of the OTLP/HTTP traces endpoint (e.g. http://localhost:4318/v1/traces)
+	SampleRatio float64           // Ratio of the root spans sampled, the children follow their parent
+	Interval    time.Duration     // Maximum time the finished spans are held before being exported
+	Headers     map[string]string // Additional HTTP headers sent with each push (e.g. authorization)
+	Attributes  map[string]string // Resource attributes describing the reporting node
+}
+
+// DefaultConfig contains the default settings of the tracing.
+var DefaultConfig = Config{
+	Endpoint:    "http://localhost:4318/v1/traces",
+	SampleRatio: 1,
+	Interval:    5 * time.Second,
+}
+
+// active is the tracer spans are started with, nil if tracing is disabled.
+var active atomic.Pointer[tracer]
+
+// tracer samples the started spans and exports the finished ones.
+type tracer struct {
+	threshold uint64 // Root spans with a trace ID below the threshold are sampled
+	exporter  *exporter
+}
+
+// Enable starts recording spans and exporting them according to the given config.
+// The returned function disables the tracing, exporting the spans still pending.
+func Enable(config Config) func() {
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig.Interval
+	}
+	t := &tracer{exporter: newExporter(config)}
+	switch {
+	case config.SampleRatio >= 1:
+		t.threshold = 1<<63 - 1
+	case config.SampleRatio > 0:
+		t.threshold = uint64(config.SampleRatio * (1 << 63))
+	}
+	active.Store(t)
+
+	return func() {
+		active.CompareAndSwap(t, nil)
+		t.exporter.close()
+	}
+}
+
+// sample decides whether a new trace is recorded. Similarly to the trace ID ratio
+// sampler of OpenTelemetry, the decision is derived from the trace ID, so all the
+// nodes configured with the same ratio make the same decision for a trace.
+func (t *tracer) sample(id TraceID) bool {
+	if t.threshold == 1<<63-1 {
+		return true
+	}
+	return binary.BigEndian.Uint64(id[8:])>>1 < t.threshold
+}
+
+// export queues a finished span for export.
+func (t *tracer) export(s *Span) {
+	t.exporter.add(s)
+}
+
+// formatUnixNano formats a timestamp the way the OTLP JSON encoding expects it.
+func formatUnixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
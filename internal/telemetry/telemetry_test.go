@@ -0,0 +1,228 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		valid  bool
+		ctx    SpanContext
+	}{
+		{
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			valid:  true,
+			ctx: SpanContext{
+				TraceID: TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+				Sampled: true,
+			},
+		},
+		{
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			valid:  true,
+			ctx: SpanContext{
+				TraceID: TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+				SpanID:  SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			},
+		},
+		{header: "", valid: false},
+		{header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", valid: false},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", valid: false},
+		{header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: false},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", valid: false},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01", valid: false},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01", valid: false},
+	}
+	for i, test := range tests {
+		ctx, ok := ParseTraceparent(test.header)
+		if ok != test.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want %v", i, ok, test.valid)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if ctx != test.ctx {
+			t.Errorf("test %d: span context mismatch: have %+v, want %+v", i, ctx, test.ctx)
+		}
+		if header := ctx.Traceparent(); header != test.header {
+			t.Errorf("test %d: encoding mismatch: have %s, want %s", i, header, test.header)
+		}
+	}
+}
+
+// receivedSpan is the generic decoding of an exported span.
+type receivedSpan struct {
+	TraceID      string                   `json:"traceId"`
+	SpanID       string                   `json:"spanId"`
+	ParentSpanID string                   `json:"parentSpanId"`
+	Name         string                   `json:"name"`
+	Kind         int                      `json:"kind"`
+	Attributes   []map[string]interface{} `json:"attributes"`
+	Status       *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+// newReceiver starts an OTLP/HTTP receiver stub collecting the exported spans.
+func newReceiver(t *testing.T) (*httptest.Server, func() []receivedSpan) {
+	var (
+		lock  sync.Mutex
+		spans []receivedSpan
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []receivedSpan `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				spans = append(spans, ss.Spans...)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []receivedSpan {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]receivedSpan(nil), spans...)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	ctx, span := StartSpan(context.Background(), "foo")
+	if span != nil {
+		t.Fatal("span started with tracing disabled")
+	}
+	if _, ok := SpanContextFromContext(ctx); ok {
+		t.Fatal("span context set with tracing disabled")
+	}
+	// All span methods must be safe to call on the nil span.
+	span.SetAttributes(String("key", "value"))
+	span.SetError(errors.New("failure"))
+	span.End()
+}
+
+func TestExport(t *testing.T) {
+	server, received := newReceiver(t)
+	stop := Enable(Config{Endpoint: server.URL, SampleRatio: 1, Interval: time.Hour})
+
+	// Join a remote trace, and create a small tree of spans.
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteSpan(context.Background(), remote)
+
+	ctx, root := StartSpanWithKind(ctx, KindServer, "root", String("rpc.method", "eth_call"))
+	_, child := StartSpan(ctx, "child", Int64("number", 1), Bool("ok", false))
+	child.SetError(errors.New("failure"))
+	child.End()
+	root.End()
+	root.End() // no-op
+
+	stop()
+
+	spans := received()
+	if len(spans) != 2 {
+		t.Fatalf("exported span count mismatch: have %d, want 2", len(spans))
+	}
+	byName := make(map[string]receivedSpan)
+	for _, s := range spans {
+		if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %s: trace ID mismatch: have %s", s.Name, s.TraceID)
+		}
+		byName[s.Name] = s
+	}
+	if s := byName["root"]; s.ParentSpanID != "00f067aa0ba902b7" || s.Kind != int(KindServer) || len(s.Attributes) != 1 || s.Status != nil {
+		t.Errorf("root span mismatch: %+v", s)
+	}
+	if s := byName["child"]; s.ParentSpanID != byName["root"].SpanID || s.Kind != int(KindInternal) || len(s.Attributes) != 2 {
+		t.Errorf("child span mismatch: %+v", s)
+	} else if s.Status == nil || s.Status.Code != statusError || s.Status.Message != "failure" {
+		t.Errorf("child span status mismatch: %+v", s.Status)
+	}
+	// Once disabled, spans should not be started anymore.
+	if _, span := StartSpan(context.Background(), "foo"); span != nil {
+		t.Fatal("span started after disabling tracing")
+	}
+}
+
+func TestSampling(t *testing.T) {
+	server, received := newReceiver(t)
+	stop := Enable(Config{Endpoint: server.URL, SampleRatio: 0, Interval: time.Hour})
+
+	// Root spans should not be sampled, but still propagate their context.
+	ctx, root := StartSpan(context.Background(), "root")
+	if sc := root.Context(); !sc.IsValid() || sc.Sampled {
+		t.Fatalf("unexpected root span context: %+v", sc)
+	}
+	_, child := StartSpan(ctx, "child")
+	if child.Context().TraceID != root.Context().TraceID || child.Context().Sampled {
+		t.Fatalf("unexpected child span context: %+v", child.Context())
+	}
+	child.End()
+	root.End()
+
+	// Remote sampled parents should override the local decision.
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := StartSpan(ContextWithRemoteSpan(context.Background(), remote), "sampled")
+	span.End()
+
+	stop()
+
+	spans := received()
+	if len(spans) != 1 || spans[0].Name != "sampled" {
+		t.Fatalf("unexpected exported spans: %+v", spans)
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	tr := &tracer{threshold: uint64(0.25 * (1 << 63))}
+
+	var sampled int
+	for i := 0; i < 10000; i++ {
+		var id TraceID
+		rand.Read(id[:])
+		if tr.sample(id) {
+			sampled++
+		}
+	}
+	if sampled < 2000 || sampled > 3000 {
+		t.Fatalf("sampled trace count out of range: have %d, want ~2500", sampled)
+	}
+}
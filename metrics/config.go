@@ -16,6 +16,8 @@
 
 package metrics
 
+import "time"
+
 // Config contains the configuration for the metric collection.
 type Config struct {
 	Enabled          bool   `toml:",omitempty"`
@@ -33,6 +35,12 @@ type Config struct {
 	InfluxDBToken        string `toml:",omitempty"`
 	InfluxDBBucket       string `toml:",omitempty"`
 	InfluxDBOrganization string `toml:",omitempty"`
+
+	EnableOTLP     bool          `toml:",omitempty"`
+	OTLPEndpoint   string        `toml:",omitempty"`
+	OTLPInterval   time.Duration `toml:",omitempty"`
+	OTLPHeaders    string        `toml:",omitempty"`
+	OTLPAttributes string        `toml:",omitempty"`
 }
 
 // DefaultConfig is the default config for metrics used in go-ethereum.
@@ -53,4 +61,9 @@ var DefaultConfig = Config{
 	InfluxDBToken:        "test",
 	InfluxDBBucket:       "geth",
 	InfluxDBOrganization: "geth",
+
+	// OpenTelemetry-specific flags
+	EnableOTLP:   false,
+	OTLPEndpoint: "http://localhost:4318/v1/metrics",
+	OTLPInterval: 10 * time.Second,
 }
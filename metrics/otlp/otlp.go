@@ -0,0 +1,251 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package otlp pushes go-metrics to an OpenTelemetry collector using the OTLP/HTTP
+// protocol with JSON encoding.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// scopeName is the instrumentation scope the exported metrics are reported under.
+const scopeName = "github.com/ethereum/go-ethereum/metrics"
+
+// quantiles are the quantiles reported for histograms and timers.
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+// Config contains the settings of the OTLP exporter.
+type Config struct {
+	Endpoint   string            // Full URL of the OTLP/HTTP metrics endpoint (e.g. http://localhost:4318/v1/metrics)
+	Interval   time.Duration     // Time between two consecutive pushes
+	Namespace  string            // Prefix prepended to the name of all the metrics
+	Headers    map[string]string // Additional HTTP headers sent with each push (e.g. authorization)
+	Attributes map[string]string // Resource attributes describing the reporting node
+}
+
+// DefaultConfig contains the default settings of the OTLP exporter.
+var DefaultConfig = Config{
+	Endpoint: "http://localhost:4318/v1/metrics",
+	Interval: 10 * time.Second,
+}
+
+// Exporter periodically pushes the content of a metrics registry to an OTLP/HTTP
+// endpoint.
+type Exporter struct {
+	reg    metrics.Registry
+	config Config
+	client *http.Client
+	start  time.Time // Start time of the cumulative metrics
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewExporter creates an exporter pushing the given registry to an OTLP endpoint.
+func NewExporter(reg metrics.Registry, config Config) *Exporter {
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig.Interval
+	}
+	return &Exporter{
+		reg:    reg,
+		config: config,
+		client: &http.Client{Timeout: config.Interval},
+		start:  time.Now(),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Start launches the background pushing of the metrics.
+func (e *Exporter) Start() {
+	e.wg.Add(1)
+	go e.loop()
+}
+
+// Stop terminates the background pushing, exporting the metrics a last time.
+func (e *Exporter) Stop() {
+	close(e.quit)
+	e.wg.Wait()
+}
+
+// loop pushes the metrics at each interval until the exporter is stopped.
+func (e *Exporter) loop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Export(context.Background()); err != nil {
+				log.Warn("Unable to send metrics to OTLP endpoint", "err", err)
+			}
+		case <-e.quit:
+			if err := e.Export(context.Background()); err != nil {
+				log.Warn("Unable to send metrics to OTLP endpoint", "err", err)
+			}
+			return
+		}
+	}
+}
+
+// Export pushes the current content of the registry to the OTLP endpoint.
+func (e *Exporter) Export(ctx context.Context) error {
+	body, err := json.Marshal(e.collect(time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status %s: %s", res.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// collect converts the content of the registry into an OTLP export request.
+func (e *Exporter) collect(now time.Time) *exportRequest {
+	// Gather and pre-sort the metrics to avoid random listings
+	var names []string
+	e.reg.Each(func(name string, i interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	var (
+		start = unixNano(e.start)
+		stamp = unixNano(now)
+		list  []*metric
+	)
+	for _, name := range names {
+		i := e.reg.Get(name)
+		if i == nil {
+			continue // Unregistered in the meantime
+		}
+		list = append(list, convert(e.config.Namespace+strings.ReplaceAll(name, "/", "."), i, start, stamp)...)
+	}
+	return &exportRequest{
+		ResourceMetrics: []*resourceMetrics{{
+			Resource: resource{Attributes: e.resourceAttributes()},
+			ScopeMetrics: []*scopeMetrics{{
+				Scope:   scope{Name: scopeName},
+				Metrics: list,
+			}},
+		}},
+	}
+}
+
+// resourceAttributes returns the configured resource attributes, sorted by key
+// and defaulting the service name if not set explicitly.
+func (e *Exporter) resourceAttributes() []keyValue {
+	attrs := make(map[string]string, len(e.config.Attributes)+1)
+	attrs["service.name"] = "geth"
+	for key, value := range e.config.Attributes {
+		attrs[key] = value
+	}
+	return attributes(attrs)
+}
+
+// convert maps a single go-metrics metric into its OTLP representation. Metrics
+// without an OTLP equivalent (or without data to report) are skipped.
+func convert(name string, i interface{}, start, now string) []*metric {
+	switch m := i.(type) {
+	case metrics.Counter:
+		return []*metric{newSum(name, "", false, dataPoint{StartTimeUnixNano: start, TimeUnixNano: now, AsInt: newIntValue(m.Snapshot().Count())})}
+
+	case metrics.CounterFloat64:
+		return []*metric{newSum(name, "", false, dataPoint{StartTimeUnixNano: start, TimeUnixNano: now, AsDouble: newFloatValue(m.Snapshot().Count())})}
+
+	case metrics.Gauge:
+		return []*metric{newGauge(name, "", dataPoint{TimeUnixNano: now, AsInt: newIntValue(m.Snapshot().Value())})}
+
+	case metrics.GaugeFloat64:
+		return []*metric{newGauge(name, "", dataPoint{TimeUnixNano: now, AsDouble: newFloatValue(m.Snapshot().Value())})}
+
+	case metrics.GaugeInfo:
+		// Informational gauges are reported the same way as Prometheus does, as
+		// a constant gauge with the information carried in its attributes.
+		return []*metric{newGauge(name, "", dataPoint{TimeUnixNano: now, AsInt: newIntValue(1), Attributes: attributes(m.Snapshot().Value())})}
+
+	case metrics.Meter:
+		ms := m.Snapshot()
+		return append([]*metric{newSum(name, "", true, dataPoint{StartTimeUnixNano: start, TimeUnixNano: now, AsInt: newIntValue(ms.Count())})},
+			rates(name, now, ms)...)
+
+	case metrics.Timer:
+		ms := m.Snapshot()
+		return append([]*metric{newSummary(name, "ns", start, now, ms.Count(), float64(ms.Sum()), ms.Percentiles(quantiles))},
+			rates(name, now, ms)...)
+
+	case metrics.Histogram:
+		ms := m.Snapshot()
+		return []*metric{newSummary(name, "", start, now, ms.Count(), float64(ms.Sum()), ms.Percentiles(quantiles))}
+
+	case metrics.ResettingTimer:
+		// Resetting timers only track the values since the last snapshot, so
+		// their data points cover the time since the previous push only.
+		ms := m.Snapshot()
+		if ms.Count() == 0 {
+			return nil
+		}
+		return []*metric{newSummary(name, "ns", "", now, int64(ms.Count()), ms.Mean()*float64(ms.Count()), ms.Percentiles(quantiles))}
+	}
+	log.Debug("Unknown OTLP metric type", "name", name, "type", fmt.Sprintf("%T", i))
+	return nil
+}
+
+// rates returns the moving average rates of a meter as gauges.
+func rates(name, now string, ms metrics.MeterSnapshot) []*metric {
+	return []*metric{
+		newGauge(name+".rate1", "1/s", dataPoint{TimeUnixNano: now, AsDouble: newFloatValue(ms.Rate1())}),
+		newGauge(name+".rate5", "1/s", dataPoint{TimeUnixNano: now, AsDouble: newFloatValue(ms.Rate5())}),
+		newGauge(name+".rate15", "1/s", dataPoint{TimeUnixNano: now, AsDouble: newFloatValue(ms.Rate15())}),
+		newGauge(name+".rate_mean", "1/s", dataPoint{TimeUnixNano: now, AsDouble: newFloatValue(ms.RateMean())}),
+	}
+}
+
+// unixNano formats a timestamp the way the OTLP JSON encoding expects it.
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
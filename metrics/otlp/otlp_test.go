@@ -0,0 +1,223 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+// receivedMetric is the generic decoding of an exported metric.
+type receivedMetric struct {
+	Name  string `json:"name"`
+	Unit  string `json:"unit"`
+	Gauge *struct {
+		DataPoints []map[string]interface{} `json:"dataPoints"`
+	} `json:"gauge"`
+	Sum *struct {
+		DataPoints             []map[string]interface{} `json:"dataPoints"`
+		AggregationTemporality int                      `json:"aggregationTemporality"`
+		IsMonotonic            bool                     `json:"isMonotonic"`
+	} `json:"sum"`
+	Summary *struct {
+		DataPoints []map[string]interface{} `json:"dataPoints"`
+	} `json:"summary"`
+}
+
+// receivedRequest is the generic decoding of an export request.
+type receivedRequest struct {
+	ResourceMetrics []struct {
+		Resource struct {
+			Attributes []keyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeMetrics []struct {
+			Metrics []receivedMetric `json:"metrics"`
+		} `json:"scopeMetrics"`
+	} `json:"resourceMetrics"`
+}
+
+// newReceiver starts an OTLP/HTTP receiver stub, delivering the decoded export
+// requests on the returned channel.
+func newReceiver(t *testing.T) (*httptest.Server, chan *receivedRequest, chan http.Header) {
+	var (
+		requests = make(chan *receivedRequest, 1024)
+		headers  = make(chan http.Header, 1024)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/metrics" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			http.Error(w, "unsupported content type "+ct, http.StatusUnsupportedMediaType)
+			return
+		}
+		req := new(receivedRequest)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests <- req
+		headers <- r.Header
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests, headers
+}
+
+func TestExport(t *testing.T) {
+	server, requests, headers := newReceiver(t)
+
+	r := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("chain/inserts", r).Inc(3)
+	metrics.NewRegisteredGauge("chain/head", r).Update(100)
+	metrics.NewRegisteredGaugeFloat64("txpool/ratio", r).Update(0.5)
+	metrics.NewRegisteredGaugeInfo("geth/info", r).Update(metrics.GaugeInfoValue{"version": "1.0.0"})
+	metrics.NewRegisteredMeter("p2p/ingress", r).Mark(10)
+	metrics.NewRegisteredTimer("rpc/duration", r).Update(time.Second)
+	metrics.NewRegisteredHistogram("db/sizes", r, metrics.NewUniformSample(100)).Update(42)
+	metrics.NewRegisteredResettingTimer("chain/execution", r).Update(2 * time.Millisecond)
+	metrics.NewRegisteredResettingTimer("chain/idle", r)
+
+	exporter := NewExporter(r, Config{
+		Endpoint:   server.URL + "/v1/metrics",
+		Namespace:  "geth.",
+		Headers:    map[string]string{"Authorization": "Bearer secret"},
+		Attributes: map[string]string{"host.name": "localhost"},
+	})
+	if err := exporter.Export(context.Background()); err != nil {
+		t.Fatalf("failed to export metrics: %v", err)
+	}
+	req := <-requests
+	if auth := (<-headers).Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("authorization header mismatch: have %q, want %q", auth, "Bearer secret")
+	}
+	if len(req.ResourceMetrics) != 1 || len(req.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("unexpected request layout: %+v", req)
+	}
+	// Check the resource attributes, including the defaulted service name.
+	attrs := make(map[string]string)
+	for _, kv := range req.ResourceMetrics[0].Resource.Attributes {
+		attrs[kv.Key] = kv.Value.StringValue
+	}
+	if attrs["service.name"] != "geth" || attrs["host.name"] != "localhost" {
+		t.Errorf("resource attributes mismatch: have %v", attrs)
+	}
+	// Check the metrics got mapped to the correct OTLP types.
+	exported := make(map[string]receivedMetric)
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		exported[m.Name] = m
+	}
+	if m, ok := exported["geth.chain.inserts"]; !ok || m.Sum == nil || m.Sum.IsMonotonic || m.Sum.DataPoints[0]["asInt"] != "3" {
+		t.Errorf("counter mismatch: %+v", m)
+	}
+	if m, ok := exported["geth.chain.head"]; !ok || m.Gauge == nil || m.Gauge.DataPoints[0]["asInt"] != "100" {
+		t.Errorf("gauge mismatch: %+v", m)
+	}
+	if m, ok := exported["geth.txpool.ratio"]; !ok || m.Gauge == nil || m.Gauge.DataPoints[0]["asDouble"] != 0.5 {
+		t.Errorf("float gauge mismatch: %+v", m)
+	}
+	if m, ok := exported["geth.geth.info"]; !ok || m.Gauge == nil || m.Gauge.DataPoints[0]["asInt"] != "1" {
+		t.Errorf("info gauge mismatch: %+v", m)
+	} else if attrs := m.Gauge.DataPoints[0]["attributes"].([]interface{}); len(attrs) != 1 {
+		t.Errorf("info gauge attributes mismatch: %v", attrs)
+	}
+	if m, ok := exported["geth.p2p.ingress"]; !ok || m.Sum == nil || !m.Sum.IsMonotonic || m.Sum.AggregationTemporality != aggregationCumulative || m.Sum.DataPoints[0]["asInt"] != "10" {
+		t.Errorf("meter mismatch: %+v", m)
+	}
+	for _, name := range []string{"geth.p2p.ingress.rate1", "geth.rpc.duration.rate_mean"} {
+		if m, ok := exported[name]; !ok || m.Gauge == nil {
+			t.Errorf("rate %s mismatch: %+v", name, m)
+		}
+	}
+	if m, ok := exported["geth.rpc.duration"]; !ok || m.Summary == nil || m.Unit != "ns" || m.Summary.DataPoints[0]["count"] != "1" {
+		t.Errorf("timer mismatch: %+v", m)
+	} else if sum := m.Summary.DataPoints[0]["sum"]; sum != float64(time.Second) {
+		t.Errorf("timer sum mismatch: have %v, want %v", sum, float64(time.Second))
+	}
+	if m, ok := exported["geth.db.sizes"]; !ok || m.Summary == nil || len(m.Summary.DataPoints[0]["quantileValues"].([]interface{})) != len(quantiles) {
+		t.Errorf("histogram mismatch: %+v", m)
+	}
+	if m, ok := exported["geth.chain.execution"]; !ok || m.Summary == nil || m.Summary.DataPoints[0]["sum"] != float64(2*time.Millisecond) {
+		t.Errorf("resetting timer mismatch: %+v", m)
+	}
+	if _, ok := exported["geth.chain.idle"]; ok {
+		t.Errorf("empty resetting timer exported")
+	}
+	// Resetting timers should only report the values since the last push.
+	if err := exporter.Export(context.Background()); err != nil {
+		t.Fatalf("failed to export metrics: %v", err)
+	}
+	<-headers
+	for _, m := range (<-requests).ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == "geth.chain.execution" {
+			t.Errorf("resetting timer exported again without new values")
+		}
+	}
+}
+
+// Tests that the exporter pushes periodically, and a last time when stopped.
+func TestExporterLoop(t *testing.T) {
+	server, requests, _ := newReceiver(t)
+
+	r := metrics.NewRegistry()
+	counter := metrics.NewRegisteredCounter("foo", r)
+
+	exporter := NewExporter(r, Config{Endpoint: server.URL + "/v1/metrics", Interval: 10 * time.Millisecond})
+	exporter.Start()
+
+	counter.Inc(1)
+	select {
+	case <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no periodic export received")
+	}
+	exporter.Stop()
+
+	// Drain the requests, the last one should carry the final counter value.
+	var last *receivedRequest
+	for len(requests) > 0 {
+		last = <-requests
+	}
+	if last == nil {
+		t.Fatal("no final export received")
+	}
+	if m := last.ResourceMetrics[0].ScopeMetrics[0].Metrics[0]; m.Sum.DataPoints[0]["asInt"] != "1" {
+		t.Errorf("counter mismatch: %+v", m)
+	}
+}
+
+// Tests that rejected pushes are reported as errors.
+func TestExportRejected(t *testing.T) {
+	server, _, _ := newReceiver(t)
+
+	exporter := NewExporter(metrics.NewRegistry(), Config{Endpoint: server.URL + "/v1/traces"})
+	if err := exporter.Export(context.Background()); err == nil {
+		t.Fatal("rejected export succeeded")
+	}
+}
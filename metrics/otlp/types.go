@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package otlp
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// This file contains the subset of the OTLP metrics data model needed by the
+// exporter, following the proto3 JSON mapping of the opentelemetry-proto
+// definitions (64 bit integers are encoded as strings, enums as numbers).
+
+// aggregationCumulative is the AGGREGATION_TEMPORALITY_CUMULATIVE enum value.
+const aggregationCumulative = 2
+
+type exportRequest struct {
+	ResourceMetrics []*resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource        `json:"resource"`
+	ScopeMetrics []*scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeMetrics struct {
+	Scope   scope     `json:"scope"`
+	Metrics []*metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type metric struct {
+	Name    string   `json:"name"`
+	Unit    string   `json:"unit,omitempty"`
+	Gauge   *gauge   `json:"gauge,omitempty"`
+	Sum     *sum     `json:"sum,omitempty"`
+	Summary *summary `json:"summary,omitempty"`
+}
+
+type gauge struct {
+	DataPoints []dataPoint `json:"dataPoints"`
+}
+
+type sum struct {
+	DataPoints             []dataPoint `json:"dataPoints"`
+	AggregationTemporality int         `json:"aggregationTemporality"`
+	IsMonotonic            bool        `json:"isMonotonic"`
+}
+
+type dataPoint struct {
+	Attributes        []keyValue  `json:"attributes,omitempty"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string      `json:"timeUnixNano"`
+	AsInt             *intValue   `json:"asInt,omitempty"`
+	AsDouble          *floatValue `json:"asDouble,omitempty"`
+}
+
+type summary struct {
+	DataPoints []summaryDataPoint `json:"dataPoints"`
+}
+
+type summaryDataPoint struct {
+	StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               floatValue      `json:"sum"`
+	QuantileValues    []quantileValue `json:"quantileValues"`
+}
+
+type quantileValue struct {
+	Quantile floatValue `json:"quantile"`
+	Value    floatValue `json:"value"`
+}
+
+// intValue is a 64 bit integer, encoded as a decimal string.
+type intValue int64
+
+func newIntValue(v int64) *intValue {
+	iv := intValue(v)
+	return &iv
+}
+
+func (v intValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatInt(int64(v), 10))
+}
+
+// floatValue is a double, encoding the non-finite values as strings the way the
+// proto3 JSON mapping requires.
+type floatValue float64
+
+func newFloatValue(v float64) *floatValue {
+	fv := floatValue(v)
+	return &fv
+}
+
+func (v floatValue) MarshalJSON() ([]byte, error) {
+	f := float64(v)
+	switch {
+	case math.IsNaN(f):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(f, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+	return json.Marshal(f)
+}
+
+// attributes converts a set of string key/values into OTLP attributes, sorted
+// by key.
+func attributes(kvs map[string]string) []keyValue {
+	attrs := make([]keyValue, 0, len(kvs))
+	for key, value := range kvs {
+		attrs = append(attrs, keyValue{Key: key, Value: anyValue{StringValue: value}})
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+	return attrs
+}
+
+func newGauge(name, unit string, point dataPoint) *metric {
+	return &metric{Name: name, Unit: unit, Gauge: &gauge{DataPoints: []dataPoint{point}}}
+}
+
+func newSum(name, unit string, monotonic bool, point dataPoint) *metric {
+	return &metric{Name: name, Unit: unit, Sum: &sum{
+		DataPoints:             []dataPoint{point},
+		AggregationTemporality: aggregationCumulative,
+		IsMonotonic:            monotonic,
+	}}
+}
+
+func newSummary(name, unit string, start, now string, count int64, total float64, values []float64) *metric {
+	point := summaryDataPoint{
+		StartTimeUnixNano: start,
+		TimeUnixNano:      now,
+		Count:             strconv.FormatInt(count, 10),
+		Sum:               floatValue(total),
+	}
+	for i, q := range quantiles {
+		point.QuantileValues = append(point.QuantileValues, quantileValue{Quantile: floatValue(q), Value: floatValue(values[i])})
+	}
+	return &metric{Name: name, Unit: unit, Summary: &summary{DataPoints: []summaryDataPoint{point}}}
+}
@@ -31,8 +31,8 @@ type jwtHandler struct {
 	next    http.Handler
 }
 
-// newJWTHandler creates a http.Handler with jwt authentication support.
-func newJWTHandler(secret []byte, next http.Handler) http.Handler {
+// NewJWTHandler creates a http.Handler with jwt authentication support.
+func NewJWTHandler(secret []byte, next http.Handler) http.Handler {
 	return &jwtHandler{
 		keyFunc: func(token *jwt.Token) (interface{}, error) {
 			return secret, nil
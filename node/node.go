@@ -339,10 +339,10 @@ func (n *Node) closeDataDir() {
 	}
 }
 
-// obtainJWTSecret loads the jwt-secret, either from the provided config,
+// ObtainJWTSecret loads the jwt-secret, either from the provided config,
 // or from the default location. If neither of those are present, it generates
 // a new secret and stores to the default location.
-func (n *Node) obtainJWTSecret(cliParam string) ([]byte, error) {
+func (n *Node) ObtainJWTSecret(cliParam string) ([]byte, error) {
 	fileName := cliParam
 	if len(fileName) == 0 {
 		// no path provided, use default
@@ -500,7 +500,7 @@ func (n *Node) startRPC() error {
 	}
 	// Configure authenticated API
 	if len(openAPIs) != len(allAPIs) {
-		jwtSecret, err := n.obtainJWTSecret(n.config.JWTSecret)
+		jwtSecret, err := n.ObtainJWTSecret(n.config.JWTSecret)
 		if err != nil {
 			return err
 		}
@@ -398,7 +398,7 @@ func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSe
 	handler := newCorsHandler(srv, cors)
 	handler = newVHostHandler(vhosts, handler)
 	if len(jwtSecret) != 0 {
-		handler = newJWTHandler(jwtSecret, handler)
+		handler = NewJWTHandler(jwtSecret, handler)
 	}
 	return newGzipHandler(handler)
 }
@@ -406,7 +406,7 @@ func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string, jwtSe
 // NewWSHandlerStack returns a wrapped ws-related handler.
 func NewWSHandlerStack(srv http.Handler, jwtSecret []byte) http.Handler {
 	if len(jwtSecret) != 0 {
-		return newJWTHandler(jwtSecret, srv)
+		return NewJWTHandler(jwtSecret, srv)
 	}
 	return srv
 }
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 const (
@@ -64,7 +65,29 @@ type tcpDialer struct {
 }
 
 func (t tcpDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
-	return t.d.DialContext(ctx, "tcp", nodeAddr(dest).String())
+	return dialEndpoints(ctx, dest, func(ctx context.Context, addr net.Addr) (net.Conn, error) {
+		return t.d.DialContext(ctx, "tcp", addr.String())
+	})
+}
+
+// dialEndpoints tries the TCP endpoints of a dual-stack node one address family at
+// a time, starting with the preferred one, until a connection is established.
+func dialEndpoints(ctx context.Context, dest *enode.Node, dial func(context.Context, net.Addr) (net.Conn, error)) (net.Conn, error) {
+	endpoints := dest.TCPEndpoints()
+	if len(endpoints) == 0 {
+		return dial(ctx, nodeAddr(dest))
+	}
+	var err error
+	for _, addr := range endpoints {
+		var fd net.Conn
+		if fd, err = dial(ctx, addr); err == nil {
+			return fd, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
 }
 
 func nodeAddr(n *enode.Node) net.Addr {
@@ -79,6 +102,7 @@ var (
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNetRestrict      = errors.New("not contained in netrestrict list")
 	errNoPort           = errors.New("node does not provide TCP port")
+	errBanned           = errors.New("banned")
 )
 
 // dialer creates outbound connections and submits them into Server.
@@ -128,10 +152,11 @@ type dialScheduler struct {
 type dialSetupFunc func(net.Conn, connFlag, *enode.Node) error
 
 type dialConfig struct {
-	self           enode.ID         // our own ID
-	maxDialPeers   int              // maximum number of dialed peers
-	maxActiveDials int              // maximum number of active dials
-	netRestrict    *netutil.Netlist // IP netrestrict list, disabled if nil
+	self           enode.ID            // our own ID
+	maxDialPeers   int                 // maximum number of dialed peers
+	maxActiveDials int                 // maximum number of active dials
+	netRestrict    *netutil.Netlist    // IP netrestrict list, disabled if nil
+	reputation     *reputation.Tracker // peer scores, disabled if nil
 	resolver       nodeResolver
 	dialer         NodeDialer
 	log            log.Logger
@@ -241,7 +266,7 @@ loop:
 
 		select {
 		case node := <-nodesCh:
-			if err := d.checkDial(node); err != nil {
+			if err := d.checkDynDial(node); err != nil {
 				d.log.Trace("Discarding dial candidate", "id", node.ID(), "ip", node.IP(), "reason", err)
 			} else {
 				d.startDial(newDialTask(node, dynDialedConn))
@@ -396,6 +421,18 @@ func (d *dialScheduler) checkDial(n *enode.Node) error {
 	return nil
 }
 
+// checkDynDial returns an error if dial candidate n should not be dialed. Unlike
+// static nodes, dial candidates are rejected when they are banned.
+func (d *dialScheduler) checkDynDial(n *enode.Node) error {
+	if err := d.checkDial(n); err != nil {
+		return err
+	}
+	if d.reputation != nil && d.reputation.Banned(n.ID()) {
+		return errBanned
+	}
+	return nil
+}
+
 // startStaticDials starts n static dial tasks.
 func (d *dialScheduler) startStaticDials(n int) (started int) {
 	for started = 0; started < n && len(d.staticPool) > 0; started++ {
@@ -439,7 +476,7 @@ func (d *dialScheduler) removeFromStaticPool(idx int) {
 func (d *dialScheduler) startDial(task *dialTask) {
 	d.log.Trace("Starting p2p dial", "id", task.dest.ID(), "ip", task.dest.IP(), "flag", task.flags)
 	hkey := string(task.dest.ID().Bytes())
-	d.history.add(hkey, d.clock.Now().Add(dialHistoryExpiration))
+	d.history.add(hkey, d.clock.Now().Add(d.historyExpiration(task)))
 	d.dialing[task.dest.ID()] = task
 	go func() {
 		task.run(d)
@@ -447,6 +484,19 @@ func (d *dialScheduler) startDial(task *dialTask) {
 	}()
 }
 
+// historyExpiration returns the time after which the destination of a dial task
+// can be dialed again. Dial candidates with a negative score are deprioritized by
+// keeping them in the history for longer.
+func (d *dialScheduler) historyExpiration(task *dialTask) time.Duration {
+	exp := dialHistoryExpiration
+	if d.reputation != nil && task.flags&dynDialedConn != 0 {
+		if score := d.reputation.Score(task.dest.ID()); score < 0 {
+			exp += time.Duration(-score / 10 * float64(dialHistoryExpiration))
+		}
+	}
+	return exp
+}
+
 // A dialTask generated for each node that is dialed.
 type dialTask struct {
 	staticPoolIndex int
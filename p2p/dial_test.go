@@ -31,7 +31,9 @@ import (
 	"github.com/ethereum/go-ethereum/internal/testlog"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 // This test checks that dynamic dials are launched from discovery results.
@@ -152,6 +154,40 @@ func TestDialSchedNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that banned dial candidates are not dialed.
+func TestDialSchedBanned(t *testing.T) {
+	t.Parallel()
+
+	nodes := []*enode.Node{
+		newNode(uintID(0x01), "127.0.0.1:30303"),
+		newNode(uintID(0x02), "127.0.0.2:30303"),
+		newNode(uintID(0x03), "127.0.0.3:30303"),
+		newNode(uintID(0x04), "127.0.0.4:30303"),
+	}
+	config := dialConfig{
+		reputation:     reputation.NewTracker(nil, new(mclock.Simulated)),
+		maxActiveDials: 10,
+		maxDialPeers:   10,
+	}
+	for _, n := range nodes[:2] {
+		for !config.reputation.Banned(n.ID()) {
+			config.reputation.Report(n.ID(), reputation.InvalidBlock)
+		}
+	}
+	runDialTest(t, config, []dialTestRound{
+		{
+			discovered:   nodes,
+			wantNewDials: nodes[2:],
+		},
+		{
+			succeeded: []enode.ID{
+				nodes[2].ID(),
+				nodes[3].ID(),
+			},
+		},
+	})
+}
+
 // This test checks that static dials work and obey the limits.
 func TestDialSchedStaticDial(t *testing.T) {
 	t.Parallel()
@@ -394,6 +430,53 @@ func TestDialSchedResolve(t *testing.T) {
 	})
 }
 
+// This test checks that dialing falls back to the other address family of a
+// dual-stack node.
+func TestDialEndpointsFallback(t *testing.T) {
+	t.Parallel()
+
+	var r enr.Record
+	r.Set(enr.IPv4{10, 0, 0, 1})
+	r.Set(enr.IPv6(net.ParseIP("2001:4860::1")))
+	r.Set(enr.TCP(30303))
+	r.Set(enr.TCP6(30304))
+	node := enode.SignNull(&r, uintID(0x01))
+
+	var (
+		dialed  []string
+		pipe, _ = net.Pipe()
+	)
+	defer pipe.Close()
+	dial := func(ctx context.Context, addr net.Addr) (net.Conn, error) {
+		dialed = append(dialed, addr.String())
+		if addr.(*net.TCPAddr).IP.To4() == nil {
+			return nil, errors.New("network unreachable")
+		}
+		return pipe, nil
+	}
+	fd, err := dialEndpoints(context.Background(), node, dial)
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+	if fd != pipe {
+		t.Fatal("wrong connection returned")
+	}
+	want := []string{"[2001:4860::1]:30304", "10.0.0.1:30303"}
+	if !reflect.DeepEqual(dialed, want) {
+		t.Fatalf("wrong dial order: got %v, want %v", dialed, want)
+	}
+
+	// When all endpoints fail, the last error is returned.
+	dialed = nil
+	_, err = dialEndpoints(context.Background(), node, func(ctx context.Context, addr net.Addr) (net.Conn, error) {
+		dialed = append(dialed, addr.String())
+		return nil, errors.New("refused")
+	})
+	if err == nil || len(dialed) != 2 {
+		t.Fatalf("expected failure after two dials, got err %v after %v", err, dialed)
+	}
+}
+
 // -------
 // Code below here is the framework for the tests above.
 
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 // UDPConn is a network connection on which discovery can operate.
@@ -44,8 +45,9 @@ type Config struct {
 	// All remaining settings are optional.
 
 	// Packet handling configuration:
-	NetRestrict *netutil.Netlist  // list of allowed IP networks
-	Unhandled   chan<- ReadPacket // unhandled packets are sent on this channel
+	NetRestrict *netutil.Netlist    // list of allowed IP networks
+	Unhandled   chan<- ReadPacket   // unhandled packets are sent on this channel
+	Reputation  reputation.Reporter // receives reports about unresponsive nodes
 
 	// Node table configuration:
 	Bootnodes       []*enode.Node // list of bootstrap nodes
@@ -0,0 +1,167 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+// DualStackConn is a UDPConn which operates on separate IPv4 and IPv6 sockets.
+// Packets are sent through the socket of the destination address family, and
+// packets received on either socket are returned by ReadFromUDP.
+type DualStackConn struct {
+	conn4, conn6 UDPConn
+	packets      chan dualStackPacket
+	closing      chan struct{}
+	closeOnce    sync.Once
+	wg           sync.WaitGroup
+}
+
+type dualStackPacket struct {
+	data []byte
+	addr *net.UDPAddr
+	err  error
+}
+
+// NewDualStackConn creates a connection reading from and writing to both the
+// given IPv4 and IPv6 sockets. The sockets are closed when the connection is closed.
+func NewDualStackConn(conn4, conn6 UDPConn) *DualStackConn {
+	c := &DualStackConn{
+		conn4:   conn4,
+		conn6:   conn6,
+		packets: make(chan dualStackPacket),
+		closing: make(chan struct{}),
+	}
+	c.wg.Add(2)
+	go c.readLoop(conn4)
+	go c.readLoop(conn6)
+	return c
+}
+
+// readLoop forwards the packets received on conn to ReadFromUDP.
+func (c *DualStackConn) readLoop(conn UDPConn) {
+	defer c.wg.Done()
+
+	for {
+		buf := make([]byte, maxPacketSize)
+		n, from, err := conn.ReadFromUDP(buf)
+		select {
+		case c.packets <- dualStackPacket{buf[:n], from, err}:
+		case <-c.closing:
+			return
+		}
+		if err != nil && !netutil.IsTemporaryError(err) {
+			return
+		}
+	}
+}
+
+// ReadFromUDP implements UDPConn, returning the next packet received on any of
+// the sockets.
+func (c *DualStackConn) ReadFromUDP(b []byte) (n int, addr *net.UDPAddr, err error) {
+	select {
+	case p := <-c.packets:
+		return copy(b, p.data), p.addr, p.err
+	case <-c.closing:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+// WriteToUDP implements UDPConn, sending the packet through the socket of the
+// address family of addr.
+func (c *DualStackConn) WriteToUDP(b []byte, addr *net.UDPAddr) (n int, err error) {
+	if addr.IP.To4() != nil {
+		return c.conn4.WriteToUDP(b, addr)
+	}
+	return c.conn6.WriteToUDP(b, addr)
+}
+
+// Close implements UDPConn, closing both sockets.
+func (c *DualStackConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closing)
+		err = c.conn4.Close()
+		if err6 := c.conn6.Close(); err == nil {
+			err = err6
+		}
+		c.wg.Wait()
+	})
+	return err
+}
+
+// LocalAddr implements UDPConn, returning the addresses of both sockets.
+func (c *DualStackConn) LocalAddr() net.Addr {
+	return &DualStackAddr{
+		V4: udpAddr(c.conn4.LocalAddr()),
+		V6: udpAddr(c.conn6.LocalAddr()),
+	}
+}
+
+// DualStackAddr is the local address of a DualStackConn.
+type DualStackAddr struct {
+	V4, V6 *net.UDPAddr
+}
+
+// Network implements net.Addr.
+func (a *DualStackAddr) Network() string { return "udp" }
+
+// String implements net.Addr.
+func (a *DualStackAddr) String() string {
+	return a.V4.String() + "," + a.V6.String()
+}
+
+func udpAddr(addr net.Addr) *net.UDPAddr {
+	if a, ok := addr.(*net.UDPAddr); ok {
+		return a
+	}
+	return nil
+}
+
+// addrFamilies reports which address families can be reached from a socket bound
+// to the given local address. A socket bound to the unspecified IPv6 address
+// accepts both families.
+func addrFamilies(laddr net.Addr) (ip4, ip6 bool) {
+	addr, ok := laddr.(*net.UDPAddr)
+	if !ok || addr.IP == nil {
+		return true, true
+	}
+	if addr.IP.To4() != nil {
+		return true, false
+	}
+	return addr.IP.IsUnspecified(), true
+}
+
+// nodeEndpoint returns the UDP endpoint of n which can be reached through a socket
+// with the given address families. If there is none, the preferred endpoint of the
+// node is returned.
+func nodeEndpoint(n *enode.Node, ip4, ip6 bool) *net.UDPAddr {
+	endpoints := n.UDPEndpoints()
+	for _, addr := range endpoints {
+		if is4 := addr.IP.To4() != nil; (is4 && ip4) || (!is4 && ip6) {
+			return addr
+		}
+	}
+	if len(endpoints) > 0 {
+		return endpoints[0]
+	}
+	return &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+}
@@ -0,0 +1,236 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/discover/v4wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+var (
+	testLocal4 = &net.UDPAddr{IP: net.IP{10, 0, 0, 1}, Port: 30303}
+	testLocal6 = &net.UDPAddr{IP: net.ParseIP("2001:4860::1"), Port: 30304}
+)
+
+// socketPipe is a dgramPipe bound to a local address, which can also receive
+// packets.
+type socketPipe struct {
+	*dgramPipe
+	laddr *net.UDPAddr
+	in    chan dgram // received packets, 'to' holds the sender address
+}
+
+func newSocketPipe(laddr *net.UDPAddr) *socketPipe {
+	return &socketPipe{newpipe(), laddr, make(chan dgram)}
+}
+
+func (c *socketPipe) ReadFromUDP(b []byte) (n int, addr *net.UDPAddr, err error) {
+	select {
+	case p := <-c.in:
+		return copy(b, p.data), &p.to, nil
+	case <-c.closing:
+		return 0, nil, io.EOF
+	}
+}
+
+func (c *socketPipe) LocalAddr() net.Addr {
+	return c.laddr
+}
+
+// newDualStackNode creates a node with a private IPv4 and a global IPv6 endpoint.
+func newDualStackNode(key *ecdsa.PrivateKey) *enode.Node {
+	var r enr.Record
+	r.Set(enr.IPv4{192, 168, 0, 1})
+	r.Set(enr.IPv6(net.ParseIP("2001:4860::2")))
+	r.Set(enr.UDP(1000))
+	r.Set(enr.UDP6(2000))
+	r.Set(enr.TCP(3000))
+	r.Set(enr.TCP6(4000))
+	if err := enode.SignV4(&r, key); err != nil {
+		panic(err)
+	}
+	n, err := enode.New(enode.ValidSchemes, &r)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func TestDualStackConn(t *testing.T) {
+	var (
+		pipe4 = newSocketPipe(testLocal4)
+		pipe6 = newSocketPipe(testLocal6)
+		conn  = NewDualStackConn(pipe4, pipe6)
+		addr4 = &net.UDPAddr{IP: net.IP{10, 0, 0, 2}, Port: 1000}
+		addr6 = &net.UDPAddr{IP: net.ParseIP("2001:4860::2"), Port: 2000}
+	)
+	defer conn.Close()
+
+	// Writes are routed by address family.
+	conn.WriteToUDP([]byte("to4"), addr4)
+	conn.WriteToUDP([]byte("to6"), addr6)
+	if p, err := pipe4.receive(); err != nil || string(p.data) != "to4" || !p.to.IP.Equal(addr4.IP) {
+		t.Fatalf("wrong packet on IPv4 socket: %q to %v (err %v)", p.data, p.to.IP, err)
+	}
+	if p, err := pipe6.receive(); err != nil || string(p.data) != "to6" || !p.to.IP.Equal(addr6.IP) {
+		t.Fatalf("wrong packet on IPv6 socket: %q to %v (err %v)", p.data, p.to.IP, err)
+	}
+
+	// Reads are merged from both sockets.
+	go func() { pipe6.in <- dgram{*addr6, []byte("from6")} }()
+	buf := make([]byte, maxPacketSize)
+	n, from, err := conn.ReadFromUDP(buf)
+	if err != nil || !bytes.Equal(buf[:n], []byte("from6")) || !from.IP.Equal(addr6.IP) {
+		t.Fatalf("wrong read: %q from %v (err %v)", buf[:n], from, err)
+	}
+	go func() { pipe4.in <- dgram{*addr4, []byte("from4")} }()
+	n, from, err = conn.ReadFromUDP(buf)
+	if err != nil || !bytes.Equal(buf[:n], []byte("from4")) || !from.IP.Equal(addr4.IP) {
+		t.Fatalf("wrong read: %q from %v (err %v)", buf[:n], from, err)
+	}
+
+	// The local address reports both sockets.
+	laddr, ok := conn.LocalAddr().(*DualStackAddr)
+	if !ok || laddr.V4 != testLocal4 || laddr.V6 != testLocal6 {
+		t.Fatalf("wrong local address %v", conn.LocalAddr())
+	}
+
+	// Closing closes both sockets.
+	conn.Close()
+	if !pipe4.closed || !pipe6.closed {
+		t.Fatal("sockets not closed")
+	}
+	if _, _, err := conn.ReadFromUDP(buf); err == nil {
+		t.Fatal("read on closed connection succeeded")
+	}
+}
+
+// This test checks that pings are sent to the endpoint which can be reached through
+// the socket.
+func TestUDPv4_dualStackPing(t *testing.T) {
+	remote := newDualStackNode(newkey())
+
+	tests := []struct {
+		name   string
+		pipe   func() (UDPConn, *socketPipe)
+		wantTo *net.UDPAddr
+	}{
+		{
+			name: "dual-stack",
+			pipe: func() (UDPConn, *socketPipe) {
+				pipe6 := newSocketPipe(testLocal6)
+				return NewDualStackConn(newSocketPipe(testLocal4), pipe6), pipe6
+			},
+			wantTo: &net.UDPAddr{IP: net.ParseIP("2001:4860::2"), Port: 2000},
+		},
+		{
+			name: "ipv4-only",
+			pipe: func() (UDPConn, *socketPipe) {
+				pipe4 := newSocketPipe(testLocal4)
+				return pipe4, pipe4
+			},
+			wantTo: &net.UDPAddr{IP: net.IP{192, 168, 0, 1}, Port: 1000},
+		},
+		{
+			name: "ipv6-only",
+			pipe: func() (UDPConn, *socketPipe) {
+				pipe6 := newSocketPipe(testLocal6)
+				return pipe6, pipe6
+			},
+			wantTo: &net.UDPAddr{IP: net.ParseIP("2001:4860::2"), Port: 2000},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, out := test.pipe()
+			db, _ := enode.OpenDB("")
+			defer db.Close()
+			key := newkey()
+			udp, err := ListenV4(conn, enode.NewLocalNode(db, key), Config{
+				PrivateKey: key,
+				Log:        testlog.Logger(t, log.LvlTrace),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer udp.Close()
+
+			go udp.ping(remote)
+			for {
+				p, err := out.receive()
+				if err != nil {
+					t.Fatal("no ping sent:", err)
+				}
+				if packet, _, _, err := v4wire.Decode(p.data); err != nil || packet.Kind() != v4wire.PingPacket {
+					continue // skip table refresh traffic
+				}
+				if !p.to.IP.Equal(test.wantTo.IP) || p.to.Port != test.wantTo.Port {
+					t.Fatalf("ping sent to %v, want %v", &p.to, test.wantTo)
+				}
+				return
+			}
+		})
+	}
+}
+
+// This test checks that FINDNODE responses carry the endpoint in the address family
+// of the requester.
+func TestUDPv4_dualStackFindnode(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.close()
+
+	remote := wrapNode(newDualStackNode(newkey()))
+	fillTable(test.table, []*node{remote}, true)
+
+	tests := []struct {
+		from    *net.UDPAddr
+		wantIP  net.IP
+		wantUDP uint16
+		wantTCP uint16
+	}{
+		{&net.UDPAddr{IP: net.IP{192, 168, 0, 99}, Port: 30303}, net.IP{192, 168, 0, 1}, 1000, 3000},
+		{&net.UDPAddr{IP: net.ParseIP("2a00:1450::99"), Port: 30303}, net.ParseIP("2001:4860::2"), 2000, 4000},
+	}
+	for _, tt := range tests {
+		remoteID := v4wire.EncodePubkey(&test.remotekey.PublicKey).ID()
+		test.table.db.UpdateLastPongReceived(remoteID, tt.from.IP, time.Now())
+
+		test.packetInFrom(nil, test.remotekey, tt.from, &v4wire.Findnode{Target: testTarget, Expiration: futureExp})
+		test.waitPacketOut(func(p *v4wire.Neighbors, to *net.UDPAddr, hash []byte) {
+			if !to.IP.Equal(tt.from.IP) {
+				t.Errorf("response sent to %v, want %v", to, tt.from)
+			}
+			if len(p.Nodes) != 1 {
+				t.Fatalf("wrong number of results: got %d, want 1", len(p.Nodes))
+			}
+			n := p.Nodes[0]
+			if !n.IP.Equal(tt.wantIP) || n.UDP != tt.wantUDP || n.TCP != tt.wantTCP {
+				t.Errorf("wrong endpoint for %v: got %v:%d/%d, want %v:%d/%d", tt.from, n.IP, n.UDP, n.TCP, tt.wantIP, tt.wantUDP, tt.wantTCP)
+			}
+		})
+	}
+}
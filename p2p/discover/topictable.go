@@ -0,0 +1,309 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	topicAdLifetime    = 15 * time.Minute // how long an advertisement stays in the ad table
+	topicAdsPerTopic   = 100              // max number of advertisements per topic
+	topicAdsTotal      = 5000             // max number of advertisements in the ad table
+	topicTicketWindow  = 10 * time.Second // time after the wait time in which a ticket can be used
+	topicBucketSize    = 3                // max number of registrars per topic table bucket
+	topicQueryMaxNodes = 16               // max number of nodes in a TOPICQUERY response
+)
+
+var (
+	errTicketInvalid = errors.New("invalid ticket")
+	errTicketEarly   = errors.New("ticket used before wait time")
+	errTicketMatch   = errors.New("ticket does not match registration")
+)
+
+// TopicID is the identifier of a topic, which is the hash of the topic name.
+type TopicID [32]byte
+
+// NewTopic returns the ID of the named topic.
+func NewTopic(name string) TopicID {
+	return TopicID(crypto.Keccak256Hash([]byte(name)))
+}
+
+// String returns the hex encoding of the topic ID.
+func (t TopicID) String() string {
+	return fmt.Sprintf("%x", t[:])
+}
+
+// topicAdTable stores the advertisements placed at the local node by other nodes.
+// Admission is controlled by tickets, which make advertisers wait longer the more
+// the table is occupied.
+type topicAdTable struct {
+	clock       mclock.Clock
+	key         []byte // ticket MAC key
+	ads         map[TopicID][]*topicAd
+	total       int
+	maxPerTopic int
+	maxTotal    int
+}
+
+// topicAd is an advertisement in the ad table.
+type topicAd struct {
+	node    *enode.Node
+	expires mclock.AbsTime
+}
+
+// topicTicket is the content of a ticket. Tickets are opaque to the advertiser,
+// and authenticated by the registrar which issued them.
+type topicTicket struct {
+	Topic     TopicID
+	Node      enode.ID
+	IP        net.IP
+	Issued    uint64 // registrar clock time at which the ticket was issued
+	WaitTime  uint64 // time before the ticket can be used
+	TotalWait uint64 // accumulated wait time of the previous tickets
+}
+
+func newTopicAdTable(clock mclock.Clock) *topicAdTable {
+	key := make([]byte, 32)
+	crand.Read(key)
+	return &topicAdTable{
+		clock:       clock,
+		key:         key,
+		ads:         make(map[TopicID][]*topicAd),
+		maxPerTopic: topicAdsPerTopic,
+		maxTotal:    topicAdsTotal,
+	}
+}
+
+// register handles a registration attempt for n. If n can be admitted, it is
+// added to the table and a zero wait time is returned. Otherwise, a ticket is
+// issued along with the time after which the registration should be repeated.
+func (tab *topicAdTable) register(topic TopicID, n *enode.Node, ip net.IP, ticket []byte) ([]byte, time.Duration, error) {
+	now := tab.clock.Now()
+	tab.expire(now)
+
+	var waited time.Duration
+	if len(ticket) > 0 {
+		tk, err := tab.decodeTicket(ticket)
+		if err != nil {
+			return nil, 0, err
+		}
+		if tk.Topic != topic || tk.Node != n.ID() || !tk.IP.Equal(ip) {
+			return nil, 0, errTicketMatch
+		}
+		due := mclock.AbsTime(tk.Issued).Add(time.Duration(tk.WaitTime))
+		switch {
+		case now < due:
+			return nil, 0, errTicketEarly
+		case now <= due.Add(topicTicketWindow):
+			// The advertiser came back in time, its wait so far counts.
+			waited = time.Duration(tk.TotalWait + tk.WaitTime)
+		}
+	}
+	if tab.contains(topic, n.ID()) {
+		return nil, 0, nil
+	}
+	wait := tab.waitTime(topic, now, waited)
+	if wait == 0 {
+		tab.ads[topic] = append(tab.ads[topic], &topicAd{node: n, expires: now.Add(topicAdLifetime)})
+		tab.total++
+		return nil, 0, nil
+	}
+	tk := &topicTicket{
+		Topic:     topic,
+		Node:      n.ID(),
+		IP:        ip,
+		Issued:    uint64(now),
+		WaitTime:  uint64(wait),
+		TotalWait: uint64(waited),
+	}
+	return tab.encodeTicket(tk), wait, nil
+}
+
+// waitTime computes how long an advertiser which has already waited for the
+// given time has to wait before it is admitted. The wait time grows with the
+// occupancy of the topic, and even more so with the occupancy of the whole table.
+func (tab *topicAdTable) waitTime(topic TopicID, now mclock.AbsTime, waited time.Duration) time.Duration {
+	queue := tab.ads[topic]
+	if len(queue) >= tab.maxPerTopic {
+		// Ads are ordered by expiry, so the first one is removed next.
+		return time.Duration(queue[0].expires - now)
+	}
+	if tab.total >= tab.maxTotal {
+		return time.Duration(tab.nextExpiry() - now)
+	}
+	var (
+		topicOcc = float64(len(queue)) / float64(tab.maxPerTopic)
+		totalOcc = float64(tab.total) / float64(tab.maxTotal)
+		wait     = time.Duration(float64(topicAdLifetime) * topicOcc / (1 - totalOcc))
+	)
+	if wait > topicAdLifetime {
+		wait = topicAdLifetime
+	}
+	if wait <= waited {
+		return 0
+	}
+	return wait - waited
+}
+
+// query returns up to max random nodes advertising the topic.
+func (tab *topicAdTable) query(topic TopicID, max int) []*enode.Node {
+	tab.expire(tab.clock.Now())
+
+	queue := tab.ads[topic]
+	nodes := make([]*enode.Node, 0, min(len(queue), max))
+	for _, i := range rand.Perm(len(queue)) {
+		if len(nodes) == max {
+			break
+		}
+		nodes = append(nodes, queue[i].node)
+	}
+	return nodes
+}
+
+// contains reports whether the node has an advertisement for the topic.
+func (tab *topicAdTable) contains(topic TopicID, id enode.ID) bool {
+	for _, ad := range tab.ads[topic] {
+		if ad.node.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// expire removes expired advertisements.
+func (tab *topicAdTable) expire(now mclock.AbsTime) {
+	for topic, queue := range tab.ads {
+		i := 0
+		for i < len(queue) && queue[i].expires <= now {
+			i++
+		}
+		tab.total -= i
+		if i == len(queue) {
+			delete(tab.ads, topic)
+		} else if i > 0 {
+			tab.ads[topic] = queue[i:]
+		}
+	}
+}
+
+// nextExpiry returns the expiration time of the oldest advertisement.
+func (tab *topicAdTable) nextExpiry() mclock.AbsTime {
+	var next mclock.AbsTime
+	for _, queue := range tab.ads {
+		if next == 0 || queue[0].expires < next {
+			next = queue[0].expires
+		}
+	}
+	return next
+}
+
+func (tab *topicAdTable) encodeTicket(tk *topicTicket) []byte {
+	enc, _ := rlp.EncodeToBytes(tk)
+	mac := hmac.New(sha256.New, tab.key)
+	mac.Write(enc)
+	return mac.Sum(enc)
+}
+
+func (tab *topicAdTable) decodeTicket(ticket []byte) (*topicTicket, error) {
+	if len(ticket) <= sha256.Size {
+		return nil, errTicketInvalid
+	}
+	enc, sum := ticket[:len(ticket)-sha256.Size], ticket[len(ticket)-sha256.Size:]
+	mac := hmac.New(sha256.New, tab.key)
+	mac.Write(enc)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return nil, errTicketInvalid
+	}
+	tk := new(topicTicket)
+	if err := rlp.DecodeBytes(enc, tk); err != nil {
+		return nil, errTicketInvalid
+	}
+	return tk, nil
+}
+
+// topicTable holds the known registrars of a topic. Like the node table, it is
+// organized in buckets by log distance, but relative to the topic ID instead of
+// the local node, so that advertisements and searches are spread across the DHT.
+type topicTable struct {
+	topic   TopicID
+	buckets [len(enode.ID{})*8 + 1][]*enode.Node
+}
+
+func newTopicTable(topic TopicID) *topicTable {
+	return &topicTable{topic: topic}
+}
+
+// add inserts n into its bucket if there is space.
+func (tab *topicTable) add(n *enode.Node) bool {
+	b := &tab.buckets[enode.LogDist(enode.ID(tab.topic), n.ID())]
+	if len(*b) >= topicBucketSize {
+		return false
+	}
+	for _, e := range *b {
+		if e.ID() == n.ID() {
+			return false
+		}
+	}
+	*b = append(*b, n)
+	return true
+}
+
+// remove deletes the node with the given ID.
+func (tab *topicTable) remove(id enode.ID) {
+	b := &tab.buckets[enode.LogDist(enode.ID(tab.topic), id)]
+	for i, e := range *b {
+		if e.ID() == id {
+			*b = append((*b)[:i], (*b)[i+1:]...)
+			return
+		}
+	}
+}
+
+// nodes returns up to max registrars, closest to the topic first.
+func (tab *topicTable) nodes(max int) []*enode.Node {
+	var nodes []*enode.Node
+	for _, b := range tab.buckets {
+		for _, n := range b {
+			if len(nodes) == max {
+				return nodes
+			}
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// len returns the number of registrars in the table.
+func (tab *topicTable) len() (n int) {
+	for _, b := range tab.buckets {
+		n += len(b)
+	}
+	return n
+}
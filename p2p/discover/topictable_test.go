@@ -0,0 +1,160 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestTopicAdTableAdmission(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicAdTable(clock)
+		topic = NewTopic("foo")
+		ip    = net.IP{10, 0, 0, 1}
+		n1    = newTopicTestNode(ip)
+		n2    = newTopicTestNode(ip)
+	)
+	tab.maxPerTopic = 4
+
+	// The first node is admitted immediately into the empty table.
+	ticket, wait, err := tab.register(topic, n1, ip, nil)
+	if err != nil || wait != 0 || ticket != nil {
+		t.Fatalf("first registration not admitted: wait %v, err %v", wait, err)
+	}
+	// Registering again doesn't add another ad.
+	if _, wait, _ := tab.register(topic, n1, ip, nil); wait != 0 || tab.total != 1 {
+		t.Fatalf("repeated registration: wait %v, total %d", wait, tab.total)
+	}
+
+	// The second node has to wait.
+	ticket, wait, err = tab.register(topic, n2, ip, nil)
+	if err != nil || wait == 0 || ticket == nil {
+		t.Fatalf("second registration should get ticket: wait %v, err %v", wait, err)
+	}
+	if _, _, err := tab.register(topic, n2, ip, ticket); err != errTicketEarly {
+		t.Fatalf("early ticket: got err %v, want %v", err, errTicketEarly)
+	}
+	if _, _, err := tab.register(topic, n1, ip, ticket); err != errTicketMatch {
+		t.Fatalf("ticket of other node: got err %v, want %v", err, errTicketMatch)
+	}
+	bad := append([]byte{}, ticket...)
+	bad[0]++
+	if _, _, err := tab.register(topic, n2, ip, bad); err != errTicketInvalid {
+		t.Fatalf("modified ticket: got err %v, want %v", err, errTicketInvalid)
+	}
+
+	// After waiting, the ticket gets the node in.
+	clock.Run(wait)
+	if _, wait, err := tab.register(topic, n2, ip, ticket); err != nil || wait != 0 {
+		t.Fatalf("ticket not accepted after wait time: wait %v, err %v", wait, err)
+	}
+	if nodes := tab.query(topic, 10); len(nodes) != 2 {
+		t.Fatalf("wrong number of nodes in query result: %d", len(nodes))
+	}
+
+	// Ads are removed when they expire.
+	clock.Run(topicAdLifetime)
+	if nodes := tab.query(topic, 10); len(nodes) != 0 || tab.total != 0 {
+		t.Fatalf("ads not expired: %d nodes, total %d", len(nodes), tab.total)
+	}
+}
+
+func TestTopicAdTableLateTicket(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicAdTable(clock)
+		topic = NewTopic("foo")
+		ip    = net.IP{10, 0, 0, 1}
+	)
+	tab.maxPerTopic = 4
+	tab.register(topic, newTopicTestNode(ip), ip, nil)
+
+	n := newTopicTestNode(ip)
+	ticket, wait, _ := tab.register(topic, n, ip, nil)
+
+	// Coming back after the ticket window loses the wait time.
+	clock.Run(wait + topicTicketWindow + time.Second)
+	_, wait2, err := tab.register(topic, n, ip, ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wait2 != wait {
+		t.Fatalf("wrong wait time for late ticket: got %v, want %v", wait2, wait)
+	}
+}
+
+func TestTopicAdTableFull(t *testing.T) {
+	var (
+		clock = new(mclock.Simulated)
+		tab   = newTopicAdTable(clock)
+		topic = NewTopic("foo")
+		ip    = net.IP{10, 0, 0, 1}
+	)
+	tab.maxPerTopic = 2
+	for i := 0; i < 2; i++ {
+		n := newTopicTestNode(ip)
+		ticket, wait, _ := tab.register(topic, n, ip, nil)
+		clock.Run(wait)
+		tab.register(topic, n, ip, ticket)
+	}
+	if tab.total != 2 {
+		t.Fatalf("wrong total: %d", tab.total)
+	}
+	// When the topic is full, the wait lasts until the oldest ad expires.
+	expiry := tab.ads[topic][0].expires
+	_, wait, _ := tab.register(topic, newTopicTestNode(ip), ip, nil)
+	if want := time.Duration(expiry - clock.Now()); wait != want {
+		t.Fatalf("wrong wait time: got %v, want %v", wait, want)
+	}
+}
+
+func TestTopicTable(t *testing.T) {
+	var (
+		topic = NewTopic("foo")
+		tab   = newTopicTable(topic)
+		ld    = 250
+	)
+	for i := 0; i < topicBucketSize+1; i++ {
+		n := unwrapNode(nodeAtDistance(enode.ID(topic), ld, intIP(i)))
+		if added := tab.add(n); added != (i < topicBucketSize) {
+			t.Fatalf("node %d: added = %v", i, added)
+		}
+	}
+	closest := unwrapNode(nodeAtDistance(enode.ID(topic), 200, intIP(10)))
+	tab.add(closest)
+	if tab.len() != topicBucketSize+1 {
+		t.Fatalf("wrong table size %d", tab.len())
+	}
+	nodes := tab.nodes(2)
+	if len(nodes) != 2 || nodes[0].ID() != closest.ID() {
+		t.Fatalf("wrong nodes returned: %v", nodes)
+	}
+	tab.remove(closest.ID())
+	if tab.len() != topicBucketSize {
+		t.Fatalf("wrong table size %d after remove", tab.len())
+	}
+}
+
+func newTopicTestNode(ip net.IP) *enode.Node {
+	return unwrapNode(nodeAtDistance(enode.ID{}, 255, ip))
+}
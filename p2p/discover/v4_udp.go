@@ -72,6 +72,8 @@ type UDPv4 struct {
 	localNode   *enode.LocalNode
 	db          *enode.DB
 	tab         *Table
+	reach4      bool // IPv4 endpoints can be reached through conn
+	reach6      bool // IPv6 endpoints can be reached through conn
 	closeOnce   sync.Once
 	wg          sync.WaitGroup
 
@@ -141,6 +143,7 @@ func ListenV4(c UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv4, error) {
 		cancelCloseCtx:  cancel,
 		log:             cfg.Log,
 	}
+	t.reach4, t.reach6 = addrFamilies(c.LocalAddr())
 
 	tab, err := newMeteredTable(t, ln.Database(), cfg)
 	if err != nil {
@@ -200,6 +203,11 @@ func (t *UDPv4) Resolve(n *enode.Node) *enode.Node {
 	return n
 }
 
+// nodeAddr returns the UDP endpoint of n to send packets to.
+func (t *UDPv4) nodeAddr(n *enode.Node) *net.UDPAddr {
+	return nodeEndpoint(n, t.reach4, t.reach6)
+}
+
 func (t *UDPv4) ourEndpoint() v4wire.Endpoint {
 	n := t.Self()
 	a := &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
@@ -214,7 +222,7 @@ func (t *UDPv4) Ping(n *enode.Node) error {
 
 // ping sends a ping message to the given node and waits for a reply.
 func (t *UDPv4) ping(n *enode.Node) (seq uint64, err error) {
-	rm := t.sendPing(n.ID(), &net.UDPAddr{IP: n.IP(), Port: n.UDP()}, nil)
+	rm := t.sendPing(n.ID(), t.nodeAddr(n), nil)
 	if err = <-rm.errc; err == nil {
 		seq = rm.reply.(*v4wire.Pong).ENRSeq
 	}
@@ -291,7 +299,7 @@ func (t *UDPv4) newLookup(ctx context.Context, targetKey encPubkey) *lookup {
 	target := enode.ID(crypto.Keccak256Hash(targetKey[:]))
 	ekey := v4wire.Pubkey(targetKey)
 	it := newLookup(ctx, t.tab, target, func(n *node) ([]*node, error) {
-		return t.findnode(n.ID(), n.addr(), ekey)
+		return t.findnode(n.ID(), t.nodeAddr(&n.Node), ekey)
 	})
 	return it
 }
@@ -336,7 +344,7 @@ func (t *UDPv4) findnode(toid enode.ID, toaddr *net.UDPAddr, target v4wire.Pubke
 
 // RequestENR sends ENRRequest to the given node and waits for a response.
 func (t *UDPv4) RequestENR(n *enode.Node) (*enode.Node, error) {
-	addr := &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+	addr := t.nodeAddr(n)
 	t.ensureBond(n.ID(), addr)
 
 	req := &v4wire.ENRRequest{
@@ -603,6 +611,19 @@ func nodeToRPC(n *node) v4wire.Node {
 	return v4wire.Node{ID: ekey, IP: n.IP(), UDP: uint16(n.UDP()), TCP: uint16(n.TCP())}
 }
 
+// nodeToRPCAt is like nodeToRPC, but encodes the given UDP endpoint of a
+// dual-stack node along with the TCP port of the same address family.
+func nodeToRPCAt(n *node, addr *net.UDPAddr) v4wire.Node {
+	rn := nodeToRPC(n)
+	rn.IP, rn.UDP = addr.IP, uint16(addr.Port)
+	for _, tcp := range n.TCPEndpoints() {
+		if tcp.IP.Equal(addr.IP) {
+			rn.TCP = uint16(tcp.Port)
+		}
+	}
+	return rn
+}
+
 // wrapPacket returns the handler functions applicable to a packet.
 func (t *UDPv4) wrapPacket(p v4wire.Packet) *packetHandlerV4 {
 	var h packetHandlerV4
@@ -728,8 +749,12 @@ func (t *UDPv4) handleFindnode(h *packetHandlerV4, from *net.UDPAddr, fromID eno
 	p := v4wire.Neighbors{Expiration: uint64(time.Now().Add(expiration).Unix())}
 	var sent bool
 	for _, n := range closest {
-		if netutil.CheckRelayIP(from.IP, n.IP()) == nil {
-			p.Nodes = append(p.Nodes, nodeToRPC(n))
+		// Report the endpoint in the address family of the requester if the
+		// node has one.
+		is4 := from.IP.To4() != nil
+		addr := nodeEndpoint(&n.Node, is4, !is4)
+		if netutil.CheckRelayIP(from.IP, addr.IP) == nil {
+			p.Nodes = append(p.Nodes, nodeToRPCAt(n, addr))
 		}
 		if len(p.Nodes) == v4wire.MaxNeighbors {
 			t.send(from, fromID, &p)
@@ -0,0 +1,363 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+const (
+	topicRegistrarLimit  = 16               // max number of registrars used per topic
+	topicRefreshInterval = 5 * time.Minute  // how often advertisers look for new registrars
+	topicSearchInterval  = 30 * time.Second // delay between topic search rounds
+)
+
+// topicSystem implements topic advertisement and search. Advertisers place ads
+// for their topics at registrars close to the topic ID, and searchers query those
+// registrars for the nodes advertising the topic.
+type topicSystem struct {
+	transport *UDPv5
+
+	mutex sync.Mutex
+	ads   *topicAdTable                  // ads placed at the local node
+	regs  map[TopicID]context.CancelFunc // topics advertised by the local node
+	wg    sync.WaitGroup
+}
+
+func newTopicSystem(transport *UDPv5) *topicSystem {
+	return &topicSystem{
+		transport: transport,
+		ads:       newTopicAdTable(transport.clock),
+		regs:      make(map[TopicID]context.CancelFunc),
+	}
+}
+
+// RegisterTopic starts advertising the local node under the given topic. The node
+// keeps registering at the registrars of the topic until StopRegisterTopic is
+// called or the transport is closed.
+func (t *UDPv5) RegisterTopic(topic TopicID) {
+	t.topics.register(topic)
+}
+
+// StopRegisterTopic stops advertising the given topic. Placed advertisements
+// remain valid until they expire.
+func (t *UDPv5) StopRegisterTopic(topic TopicID) {
+	t.topics.stopRegister(topic)
+}
+
+// TopicQuery asks n for the nodes advertising the given topic.
+func (t *UDPv5) TopicQuery(n *enode.Node, topic TopicID) ([]*enode.Node, error) {
+	resp := t.callToNode(n, v5wire.NodesMsg, &v5wire.TopicQuery{Topic: topic})
+	return t.waitForNodes(resp, nil)
+}
+
+// TopicSearch returns an iterator over the nodes advertising the given topic.
+// The iterator repeatedly queries the registrars of the topic, so the same node
+// may be returned more than once.
+func (t *UDPv5) TopicSearch(topic TopicID) enode.Iterator {
+	ctx, cancel := context.WithCancel(t.closeCtx)
+	return &topicIterator{
+		sys:    t.topics,
+		topic:  topic,
+		table:  newTopicTable(topic),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// regtopic calls REGTOPIC on a node and waits for the TICKET response.
+func (t *UDPv5) regtopic(n *enode.Node, topic TopicID, ticket []byte) ([]byte, time.Duration, error) {
+	req := &v5wire.Regtopic{Topic: topic, ENR: t.Self().Record(), Ticket: ticket}
+	resp := t.callToNode(n, v5wire.TicketMsg, req)
+	defer t.callDone(resp)
+
+	select {
+	case p := <-resp.ch:
+		tk := p.(*v5wire.Ticket)
+		return tk.Ticket, time.Duration(tk.WaitTime) * time.Millisecond, nil
+	case err := <-resp.err:
+		return nil, 0, err
+	}
+}
+
+// register starts the advertisement of a topic.
+func (sys *topicSystem) register(topic TopicID) {
+	sys.mutex.Lock()
+	defer sys.mutex.Unlock()
+
+	if _, ok := sys.regs[topic]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(sys.transport.closeCtx)
+	sys.regs[topic] = cancel
+	sys.wg.Add(1)
+	go sys.runRegistration(ctx, topic)
+}
+
+// stopRegister stops the advertisement of a topic.
+func (sys *topicSystem) stopRegister(topic TopicID) {
+	sys.mutex.Lock()
+	defer sys.mutex.Unlock()
+
+	if cancel, ok := sys.regs[topic]; ok {
+		cancel()
+		delete(sys.regs, topic)
+	}
+}
+
+// wait blocks until all advertisement goroutines have exited.
+func (sys *topicSystem) wait() {
+	sys.wg.Wait()
+}
+
+// runRegistration keeps the local node registered at the registrars of a topic.
+// Registrars which fail to respond are dropped and replaced on the next refresh.
+func (sys *topicSystem) runRegistration(ctx context.Context, topic TopicID) {
+	defer sys.wg.Done()
+
+	var (
+		table   = newTopicTable(topic)
+		active  = make(map[enode.ID]bool)
+		failed  = make(chan enode.ID)
+		refresh = sys.transport.clock.NewTimer(0)
+	)
+	defer refresh.Stop()
+	for {
+		select {
+		case <-refresh.C():
+			sys.fillTopicTable(ctx, table)
+			for _, n := range table.nodes(topicRegistrarLimit) {
+				if !active[n.ID()] {
+					active[n.ID()] = true
+					sys.wg.Add(1)
+					go sys.registerAt(ctx, topic, n, failed)
+				}
+			}
+			refresh.Reset(topicRefreshInterval)
+		case id := <-failed:
+			delete(active, id)
+			table.remove(id)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// registerAt keeps the local node registered at a registrar, renewing the
+// advertisement whenever it expires.
+func (sys *topicSystem) registerAt(ctx context.Context, topic TopicID, n *enode.Node, failed chan<- enode.ID) {
+	defer sys.wg.Done()
+
+	var ticket []byte
+	for {
+		newTicket, wait, err := sys.transport.regtopic(n, topic, ticket)
+		if err != nil {
+			sys.transport.log.Debug("Topic registration failed", "topic", topic, "id", n.ID(), "err", err)
+			select {
+			case failed <- n.ID():
+			case <-ctx.Done():
+			}
+			return
+		}
+		ticket = newTicket
+		if wait == 0 {
+			sys.transport.log.Trace("Topic registration succeeded", "topic", topic, "id", n.ID())
+			ticket, wait = nil, topicAdLifetime
+		}
+		if !sys.sleep(ctx, wait) {
+			return
+		}
+	}
+}
+
+// fillTopicTable adds the nodes of the main table, and the nodes closest to the
+// topic ID found by a lookup, to a topic table.
+func (sys *topicSystem) fillTopicTable(ctx context.Context, table *topicTable) {
+	for _, n := range sys.transport.AllNodes() {
+		table.add(n)
+	}
+	for _, n := range sys.transport.newLookup(ctx, enode.ID(table.topic)).run() {
+		table.add(n)
+	}
+}
+
+// sleep waits for the given duration, returning false if the context is canceled.
+func (sys *topicSystem) sleep(ctx context.Context, d time.Duration) bool {
+	timer := sys.transport.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// handleRegtopic processes a registration attempt.
+func (sys *topicSystem) handleRegtopic(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) {
+	t := sys.transport
+	n, err := sys.verifyAdvertiser(p, fromID, fromAddr)
+	if err != nil {
+		t.log.Debug("Invalid "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	sys.mutex.Lock()
+	ticket, wait, err := sys.ads.register(p.Topic, n, fromAddr.IP, p.Ticket)
+	sys.mutex.Unlock()
+	if err != nil {
+		t.log.Debug("Rejected "+p.Name(), "id", fromID, "addr", fromAddr, "err", err)
+		return
+	}
+	resp := &v5wire.Ticket{
+		ReqID:    p.ReqID,
+		Ticket:   ticket,
+		WaitTime: uint64((wait + time.Millisecond - 1) / time.Millisecond),
+	}
+	t.sendResponse(fromID, fromAddr, resp)
+}
+
+// verifyAdvertiser checks the record sent in a registration attempt. Only records
+// of the sender with an endpoint matching the packet source can be advertised.
+func (sys *topicSystem) verifyAdvertiser(p *v5wire.Regtopic, fromID enode.ID, fromAddr *net.UDPAddr) (*enode.Node, error) {
+	if p.ENR == nil {
+		return nil, errors.New("missing record")
+	}
+	n, err := enode.New(sys.transport.validSchemes, p.ENR)
+	if err != nil {
+		return nil, err
+	}
+	if n.ID() != fromID {
+		return nil, errors.New("record of another node")
+	}
+	for _, addr := range n.UDPEndpoints() {
+		if addr.IP.Equal(fromAddr.IP) {
+			return n, nil
+		}
+	}
+	return nil, errors.New("record endpoint does not match sender")
+}
+
+// handleTopicQuery returns the nodes advertising a topic.
+func (sys *topicSystem) handleTopicQuery(p *v5wire.TopicQuery, fromID enode.ID, fromAddr *net.UDPAddr) {
+	var nodes []*enode.Node
+	for _, n := range sys.localNodes(TopicID(p.Topic)) {
+		is4 := fromAddr.IP.To4() != nil
+		if netutil.CheckRelayIP(fromAddr.IP, nodeEndpoint(n, is4, !is4).IP) == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	for _, resp := range packNodes(p.ReqID, nodes) {
+		sys.transport.sendResponse(fromID, fromAddr, resp)
+	}
+}
+
+// localNodes returns nodes advertising the topic at the local node.
+func (sys *topicSystem) localNodes(topic TopicID) []*enode.Node {
+	sys.mutex.Lock()
+	defer sys.mutex.Unlock()
+	return sys.ads.query(topic, topicQueryMaxNodes)
+}
+
+// topicIterator finds the nodes advertising a topic. Each round of the search
+// queries the registrars of the topic, including the local node.
+type topicIterator struct {
+	sys    *topicSystem
+	topic  TopicID
+	table  *topicTable
+	ctx    context.Context
+	cancel context.CancelFunc
+	buffer []*enode.Node
+	rounds int
+	cur    *enode.Node
+}
+
+// Next moves to the next node.
+func (it *topicIterator) Next() bool {
+	it.cur = nil
+	for len(it.buffer) == 0 {
+		if it.rounds > 0 && !it.sys.sleep(it.ctx, topicSearchInterval) {
+			return false
+		}
+		if it.ctx.Err() != nil {
+			return false
+		}
+		it.buffer = it.search()
+		it.rounds++
+	}
+	it.cur, it.buffer = it.buffer[0], it.buffer[1:]
+	return true
+}
+
+// Node returns the current node.
+func (it *topicIterator) Node() *enode.Node {
+	return it.cur
+}
+
+// Close ends the iterator.
+func (it *topicIterator) Close() {
+	it.cancel()
+}
+
+// search performs a search round, returning the nodes found.
+func (it *topicIterator) search() []*enode.Node {
+	it.sys.fillTopicTable(it.ctx, it.table)
+
+	var (
+		self   = it.sys.transport.Self().ID()
+		seen   = make(map[enode.ID]bool)
+		result []*enode.Node
+		mu     sync.Mutex
+		failed []enode.ID
+		wg     sync.WaitGroup
+	)
+	add := func(nodes []*enode.Node) {
+		for _, n := range nodes {
+			if n.ID() != self && !seen[n.ID()] {
+				seen[n.ID()] = true
+				result = append(result, n)
+			}
+		}
+	}
+	add(it.sys.localNodes(it.topic))
+	for _, n := range it.table.nodes(topicRegistrarLimit) {
+		wg.Add(1)
+		go func(n *enode.Node) {
+			defer wg.Done()
+			nodes, err := it.sys.transport.TopicQuery(n, it.topic)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, n.ID())
+				return
+			}
+			add(nodes)
+		}(n)
+	}
+	wg.Wait()
+	for _, id := range failed {
+		it.table.remove(id)
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/discover/v5wire"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// This test checks that incoming REGTOPIC and TOPICQUERY calls are handled correctly.
+func TestUDPv5_topicHandling(t *testing.T) {
+	t.Parallel()
+	test := newUDPV5Test(t)
+	defer test.close()
+
+	topic := NewTopic("foo")
+	remote := test.getNode(test.remotekey, test.remoteaddr).Node()
+
+	// The first registration is admitted immediately.
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte("reg"), Topic: topic, ENR: remote.Record()})
+	test.waitPacketOut(func(p *v5wire.Ticket, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte("reg")) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if p.WaitTime != 0 {
+			t.Errorf("registration not admitted, wait time %d", p.WaitTime)
+		}
+	})
+
+	// Registrations with a record of another node are ignored.
+	other := test.getNode(newkey(), &net.UDPAddr{IP: net.IP{10, 0, 1, 100}, Port: 30303}).Node()
+	test.packetIn(&v5wire.Regtopic{ReqID: []byte("bad"), Topic: topic, ENR: other.Record()})
+	test.packetIn(&v5wire.Ping{ReqID: []byte("ping")})
+	test.waitPacketOut(func(p *v5wire.Pong, addr *net.UDPAddr, _ v5wire.Nonce) {})
+
+	// The registered node is returned by TOPICQUERY.
+	test.packetIn(&v5wire.TopicQuery{ReqID: []byte("query"), Topic: topic})
+	test.waitPacketOut(func(p *v5wire.Nodes, addr *net.UDPAddr, _ v5wire.Nonce) {
+		if !bytes.Equal(p.ReqID, []byte("query")) {
+			t.Error("wrong request ID in response:", p.ReqID)
+		}
+		if len(p.Nodes) != 1 {
+			t.Fatalf("wrong number of nodes in response: %d", len(p.Nodes))
+		}
+		n, err := enode.New(enode.ValidSchemesForTesting, p.Nodes[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.ID() != remote.ID() {
+			t.Errorf("wrong node in response: %v", n.ID())
+		}
+	})
+}
+
+// This test checks that a node advertising a topic can be found by topic search.
+func TestUDPv5_topicSearchE2E(t *testing.T) {
+	t.Parallel()
+
+	const N = 4
+	var nodes []*UDPv5
+	for i := 0; i < N; i++ {
+		var cfg Config
+		if len(nodes) > 0 {
+			cfg.Bootnodes = []*enode.Node{nodes[0].Self()}
+		}
+		node := startLocalhostV5(t, cfg)
+		nodes = append(nodes, node)
+		defer node.Close()
+	}
+	var (
+		topic      = NewTopic("foo")
+		advertiser = nodes[1]
+		searcher   = nodes[N-1]
+	)
+	advertiser.RegisterTopic(topic)
+
+	// Wait for the ad to be placed at the bootnode, which is known to all nodes.
+	deadline := time.Now().Add(10 * time.Second)
+	for len(nodes[0].topics.localNodes(topic)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("topic not registered at bootnode")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	it := searcher.TopicSearch(topic)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatal("search iterator ended")
+	}
+	if it.Node().ID() != advertiser.Self().ID() {
+		t.Fatalf("wrong node found: %v", it.Node().ID())
+	}
+}
@@ -69,6 +69,8 @@ type UDPv5 struct {
 	log          log.Logger
 	clock        mclock.Clock
 	validSchemes enr.IdentityScheme
+	reach4       bool // IPv4 endpoints can be reached through conn
+	reach6       bool // IPv6 endpoints can be reached through conn
 
 	// misc buffers used during message handling
 	logcontext []interface{}
@@ -76,6 +78,9 @@ type UDPv5 struct {
 	// talkreq handler registry
 	talk *talkSystem
 
+	// topic advertisement and search
+	topics *topicSystem
+
 	// channels into dispatch
 	packetInCh    chan ReadPacket
 	readNextCh    chan struct{}
@@ -173,7 +178,9 @@ func newUDPv5(conn UDPConn, ln *enode.LocalNode, cfg Config) (*UDPv5, error) {
 		closeCtx:       closeCtx,
 		cancelCloseCtx: cancelCloseCtx,
 	}
+	t.reach4, t.reach6 = addrFamilies(conn.LocalAddr())
 	t.talk = newTalkSystem(t)
+	t.topics = newTopicSystem(t)
 	tab, err := newMeteredTable(t, t.db, cfg)
 	if err != nil {
 		return nil, err
@@ -193,6 +200,7 @@ func (t *UDPv5) Close() {
 		t.cancelCloseCtx()
 		t.conn.Close()
 		t.talk.wait()
+		t.topics.wait()
 		t.wg.Wait()
 		t.tab.close()
 	})
@@ -426,13 +434,14 @@ func (t *UDPv5) verifyResponseNode(c *callV5, r *enr.Record, distances []uint, s
 	if err != nil {
 		return nil, err
 	}
-	if err := netutil.CheckRelayIP(c.addr.IP, node.IP()); err != nil {
+	addr := t.nodeAddr(node)
+	if err := netutil.CheckRelayIP(c.addr.IP, addr.IP); err != nil {
 		return nil, err
 	}
-	if t.netrestrict != nil && !t.netrestrict.Contains(node.IP()) {
+	if t.netrestrict != nil && !t.netrestrict.Contains(addr.IP) {
 		return nil, errors.New("not contained in netrestrict list")
 	}
-	if node.UDP() <= 1024 {
+	if addr.Port <= 1024 {
 		return nil, errLowPort
 	}
 	if distances != nil {
@@ -457,10 +466,15 @@ func containsUint(x uint, xs []uint) bool {
 	return false
 }
 
+// nodeAddr returns the UDP endpoint of n to send packets to.
+func (t *UDPv5) nodeAddr(n *enode.Node) *net.UDPAddr {
+	return nodeEndpoint(n, t.reach4, t.reach6)
+}
+
 // callToNode sends the given call and sets up a handler for response packets (of message
 // type responseType). Responses are dispatched to the call's response channel.
 func (t *UDPv5) callToNode(n *enode.Node, responseType byte, req v5wire.Packet) *callV5 {
-	addr := &net.UDPAddr{IP: n.IP(), Port: n.UDP()}
+	addr := t.nodeAddr(n)
 	c := &callV5{id: n.ID(), addr: addr, node: n}
 	t.initCall(c, responseType, req)
 	return c
@@ -771,6 +785,12 @@ func (t *UDPv5) handle(p v5wire.Packet, fromID enode.ID, fromAddr *net.UDPAddr)
 		t.talk.handleRequest(fromID, fromAddr, p)
 	case *v5wire.TalkResponse:
 		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.Regtopic:
+		t.topics.handleRegtopic(p, fromID, fromAddr)
+	case *v5wire.Ticket:
+		t.handleCallResponse(fromID, fromAddr, p)
+	case *v5wire.TopicQuery:
+		t.topics.handleTopicQuery(p, fromID, fromAddr)
 	}
 }
 
@@ -865,7 +885,8 @@ func (t *UDPv5) collectTableNodes(rip net.IP, distances []uint, limit int) []*en
 		for _, n := range t.tab.appendLiveNodes(dist, bn[:0]) {
 			// Apply some pre-checks to avoid sending invalid nodes.
 			// Note liveness is checked by appendLiveNodes.
-			if netutil.CheckRelayIP(rip, n.IP()) != nil {
+			is4 := rip.To4() != nil
+			if netutil.CheckRelayIP(rip, nodeEndpoint(n, is4, !is4).IP) != nil {
 				continue
 			}
 			nodes = append(nodes, n)
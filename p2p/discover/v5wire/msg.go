@@ -47,8 +47,12 @@ const (
 	NodesMsg
 	TalkRequestMsg
 	TalkResponseMsg
-	RequestTicketMsg
+	RegtopicMsg
 	TicketMsg
+	TopicQueryMsg
+
+	// RequestTicketMsg is the former name of RegtopicMsg.
+	RequestTicketMsg = RegtopicMsg
 
 	UnknownPacket   = byte(255) // any non-decryptable packet
 	WhoareyouPacket = byte(254) // the WHOAREYOU packet
@@ -118,6 +122,30 @@ type (
 		ReqID   []byte
 		Message []byte
 	}
+
+	// REGTOPIC requests the recipient to advertise the sender under a topic.
+	// The ticket is empty on the first attempt.
+	Regtopic struct {
+		ReqID  []byte
+		Topic  [32]byte
+		ENR    *enr.Record
+		Ticket []byte
+	}
+
+	// TICKET is the reply to REGTOPIC. The sender should repeat the registration
+	// with the ticket after the wait time (in milliseconds) has passed. A zero
+	// wait time confirms that the advertisement was placed.
+	Ticket struct {
+		ReqID    []byte
+		Ticket   []byte
+		WaitTime uint64
+	}
+
+	// TOPICQUERY is a query for nodes advertising a topic. The reply is NODES.
+	TopicQuery struct {
+		ReqID []byte
+		Topic [32]byte
+	}
 )
 
 // DecodeMessage decodes the message body of a packet.
@@ -136,6 +164,12 @@ func DecodeMessage(ptype byte, body []byte) (Packet, error) {
 		dec = new(TalkRequest)
 	case TalkResponseMsg:
 		dec = new(TalkResponse)
+	case RegtopicMsg:
+		dec = new(Regtopic)
+	case TicketMsg:
+		dec = new(Ticket)
+	case TopicQueryMsg:
+		dec = new(TopicQuery)
 	default:
 		return nil, fmt.Errorf("unknown packet type %d", ptype)
 	}
@@ -227,3 +261,30 @@ func (p *TalkResponse) SetRequestID(id []byte) { p.ReqID = id }
 func (p *TalkResponse) AppendLogInfo(ctx []interface{}) []interface{} {
 	return append(ctx, "req", hexutil.Bytes(p.ReqID), "len", len(p.Message))
 }
+
+func (*Regtopic) Name() string             { return "REGTOPIC/v5" }
+func (*Regtopic) Kind() byte               { return RegtopicMsg }
+func (p *Regtopic) RequestID() []byte      { return p.ReqID }
+func (p *Regtopic) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *Regtopic) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "topic", hexutil.Bytes(p.Topic[:]), "ticket", len(p.Ticket) > 0)
+}
+
+func (*Ticket) Name() string             { return "TICKET/v5" }
+func (*Ticket) Kind() byte               { return TicketMsg }
+func (p *Ticket) RequestID() []byte      { return p.ReqID }
+func (p *Ticket) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *Ticket) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "wait", p.WaitTime)
+}
+
+func (*TopicQuery) Name() string             { return "TOPICQUERY/v5" }
+func (*TopicQuery) Kind() byte               { return TopicQueryMsg }
+func (p *TopicQuery) RequestID() []byte      { return p.ReqID }
+func (p *TopicQuery) SetRequestID(id []byte) { p.ReqID = id }
+
+func (p *TopicQuery) AppendLogInfo(ctx []interface{}) []interface{} {
+	return append(ctx, "req", hexutil.Bytes(p.ReqID), "topic", hexutil.Bytes(p.Topic[:]))
+}
@@ -213,6 +213,17 @@ func (ln *LocalNode) SetFallbackUDP(port int) {
 	ln.updateEndpoints()
 }
 
+// SetFallbackUDP6 sets the last-resort UDP-on-IPv6 port, overriding the port set by
+// SetFallbackUDP. This is used when discovery listens on separate sockets for IPv4
+// and IPv6.
+func (ln *LocalNode) SetFallbackUDP6(port int) {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+
+	ln.endpoint6.fallbackUDP = uint16(port)
+	ln.updateEndpoints()
+}
+
 // UDPEndpointStatement should be called whenever a statement about the local node's
 // UDP endpoint is received. It feeds the local endpoint predictor.
 func (ln *LocalNode) UDPEndpointStatement(fromaddr, endpoint *net.UDPAddr) {
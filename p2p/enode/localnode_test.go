@@ -127,3 +127,39 @@ func TestLocalNodeEndpoint(t *testing.T) {
 	assert.Equal(t, fallback.Port, ln.Node().UDP())
 	assert.Equal(t, initialSeq+3, ln.Node().Seq())
 }
+
+// This test checks that both address families are advertised in the record.
+func TestLocalNodeDualStack(t *testing.T) {
+	var (
+		ip4 = net.IP{127, 0, 0, 1}
+		ip6 = net.ParseIP("2001:db8::1")
+	)
+	ln, db := newLocalNodeForTesting()
+	defer db.Close()
+
+	ln.SetFallbackIP(ip4)
+	ln.SetStaticIP(ip6)
+	ln.SetFallbackUDP(30303)
+	ln.SetFallbackUDP6(30304)
+
+	n := ln.Node()
+	var (
+		gotIP4  enr.IPv4
+		gotIP6  enr.IPv6
+		gotUDP  enr.UDP
+		gotUDP6 enr.UDP6
+	)
+	assert.NoError(t, n.Load(&gotIP4))
+	assert.NoError(t, n.Load(&gotIP6))
+	assert.NoError(t, n.Load(&gotUDP))
+	assert.NoError(t, n.Load(&gotUDP6))
+	assert.Equal(t, ip4, net.IP(gotIP4))
+	assert.Equal(t, ip6, net.IP(gotIP6))
+	assert.Equal(t, enr.UDP(30303), gotUDP)
+	assert.Equal(t, enr.UDP6(30304), gotUDP6)
+
+	// The global IPv6 address is preferred over loopback IPv4.
+	assert.Equal(t, ip6, n.IP())
+	assert.Equal(t, 30304, n.UDP())
+	assert.Equal(t, []*net.UDPAddr{{IP: ip6, Port: 30304}, {IP: ip4, Port: 30303}}, n.UDPEndpoints())
+}
@@ -99,35 +99,167 @@ func (n *Node) Load(k enr.Entry) error {
 	return n.r.Load(k)
 }
 
-// IP returns the IP address of the node. This prefers IPv4 addresses.
+// IP returns the IP address of the node. If the node has both an IPv4 and an IPv6
+// address, the one with the widest reach is returned, e.g. a global IPv6 address is
+// preferred over a private IPv4 address. IPv4 is preferred when both are equally
+// reachable.
 func (n *Node) IP() net.IP {
+	ip4, ip6 := n.ips()
+	if preferIP6(ip4, ip6) {
+		return ip6
+	}
+	return ip4
+}
+
+// UDP returns the UDP port of the node, for the address returned by IP.
+func (n *Node) UDP() int {
+	ip4, ip6 := n.ips()
+	return n.udpPort(preferIP6(ip4, ip6))
+}
+
+// TCP returns the TCP port of the node, for the address returned by IP.
+func (n *Node) TCP() int {
+	ip4, ip6 := n.ips()
+	return n.tcpPort(preferIP6(ip4, ip6))
+}
+
+// UDPEndpoints returns the UDP endpoints of the node, one per address family. The
+// endpoint matching IP and UDP comes first.
+func (n *Node) UDPEndpoints() []*net.UDPAddr {
+	var addrs []*net.UDPAddr
+	for _, ip := range n.orderedIPs() {
+		if port := n.udpPort(ip.To4() == nil); port != 0 {
+			addrs = append(addrs, &net.UDPAddr{IP: ip, Port: port})
+		}
+	}
+	return addrs
+}
+
+// TCPEndpoints returns the TCP endpoints of the node, one per address family. The
+// endpoint matching IP and TCP comes first.
+func (n *Node) TCPEndpoints() []*net.TCPAddr {
+	var addrs []*net.TCPAddr
+	for _, ip := range n.orderedIPs() {
+		if port := n.tcpPort(ip.To4() == nil); port != 0 {
+			addrs = append(addrs, &net.TCPAddr{IP: ip, Port: port})
+		}
+	}
+	return addrs
+}
+
+// QUICEndpoints returns the QUIC endpoints of the node, one per address family.
+// Nodes which do not advertise a QUIC port have no QUIC endpoints.
+func (n *Node) QUICEndpoints() []*net.UDPAddr {
+	var addrs []*net.UDPAddr
+	for _, ip := range n.orderedIPs() {
+		if port := n.quicPort(ip.To4() == nil); port != 0 {
+			addrs = append(addrs, &net.UDPAddr{IP: ip, Port: port})
+		}
+	}
+	return addrs
+}
+
+// ips returns the IPv4 and IPv6 addresses of the node. Either may be nil.
+func (n *Node) ips() (ip4, ip6 net.IP) {
 	var (
-		ip4 enr.IPv4
-		ip6 enr.IPv6
+		e4 enr.IPv4
+		e6 enr.IPv6
 	)
-	if n.Load(&ip4) == nil {
-		return net.IP(ip4)
+	if n.Load(&e4) == nil {
+		ip4 = net.IP(e4)
 	}
-	if n.Load(&ip6) == nil {
-		return net.IP(ip6)
+	if n.Load(&e6) == nil {
+		ip6 = net.IP(e6)
 	}
-	return nil
+	return ip4, ip6
 }
 
-// UDP returns the UDP port of the node.
-func (n *Node) UDP() int {
+// orderedIPs returns the addresses of the node, most preferred first.
+func (n *Node) orderedIPs() []net.IP {
+	ip4, ip6 := n.ips()
+	ips := make([]net.IP, 0, 2)
+	if ip4 != nil {
+		ips = append(ips, ip4)
+	}
+	if ip6 != nil {
+		if preferIP6(ip4, ip6) {
+			ips = append([]net.IP{ip6}, ips...)
+		} else {
+			ips = append(ips, ip6)
+		}
+	}
+	return ips
+}
+
+// udpPort returns the UDP port for the given address family. The "udp6" key is
+// optional and defaults to the "udp" port.
+func (n *Node) udpPort(ipv6 bool) int {
+	if ipv6 {
+		var port enr.UDP6
+		if n.Load(&port) == nil && port != 0 {
+			return int(port)
+		}
+	}
 	var port enr.UDP
 	n.Load(&port)
 	return int(port)
 }
 
-// TCP returns the TCP port of the node.
-func (n *Node) TCP() int {
+// tcpPort returns the TCP port for the given address family. The "tcp6" key is
+// optional and defaults to the "tcp" port.
+func (n *Node) tcpPort(ipv6 bool) int {
+	if ipv6 {
+		var port enr.TCP6
+		if n.Load(&port) == nil && port != 0 {
+			return int(port)
+		}
+	}
 	var port enr.TCP
 	n.Load(&port)
 	return int(port)
 }
 
+// quicPort returns the QUIC port for the given address family. The "quic6" key is
+// optional and defaults to the "quic" port.
+func (n *Node) quicPort(ipv6 bool) int {
+	if ipv6 {
+		var port enr.QUIC6
+		if n.Load(&port) == nil && port != 0 {
+			return int(port)
+		}
+	}
+	var port enr.QUIC
+	n.Load(&port)
+	return int(port)
+}
+
+// preferIP6 reports whether ip6 should be used over ip4.
+func preferIP6(ip4, ip6 net.IP) bool {
+	if ip6 == nil {
+		return false
+	}
+	if ip4 == nil {
+		return true
+	}
+	return ipScope(ip6) > ipScope(ip4)
+}
+
+// ipScope ranks an address by its reachability.
+func ipScope(ip net.IP) int {
+	switch {
+	case ip.IsUnspecified():
+		return 0
+	case ip.IsLoopback():
+		return 1
+	case ip.IsLinkLocalUnicast():
+		return 2
+	case ip.IsPrivate():
+		return 3
+	default:
+		return 4
+	}
+}
+
 // Pubkey returns the secp256k1 public key of the node, if present.
 func (n *Node) Pubkey() *ecdsa.PublicKey {
 	var key ecdsa.PublicKey
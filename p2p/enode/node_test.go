@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net"
 	"testing"
 	"testing/quick"
 
@@ -143,3 +144,85 @@ func TestID_logdistEqual(t *testing.T) {
 		t.Errorf("LogDist(x, x) != 0")
 	}
 }
+
+// This test checks address family selection of dual-stack nodes.
+func TestNodeEndpoints(t *testing.T) {
+	var (
+		global4  = net.IP{8, 8, 8, 8}
+		private4 = net.IP{192, 168, 0, 1}
+		global6  = net.ParseIP("2001:4860::1")
+		local6   = net.ParseIP("::1")
+	)
+	tests := []struct {
+		name    string
+		entries []enr.Entry
+		wantIP  net.IP
+		wantUDP int
+		wantTCP int
+		udp     []*net.UDPAddr
+		tcp     []*net.TCPAddr
+		quic    []*net.UDPAddr
+	}{
+		{
+			name:    "ipv4-only",
+			entries: []enr.Entry{enr.IPv4(global4), enr.UDP(30303), enr.TCP(30304)},
+			wantIP:  global4, wantUDP: 30303, wantTCP: 30304,
+			udp: []*net.UDPAddr{{IP: global4, Port: 30303}},
+			tcp: []*net.TCPAddr{{IP: global4, Port: 30304}},
+		},
+		{
+			name:    "ipv6-only",
+			entries: []enr.Entry{enr.IPv6(global6), enr.UDP(30303), enr.TCP(30304)},
+			wantIP:  global6, wantUDP: 30303, wantTCP: 30304,
+			udp: []*net.UDPAddr{{IP: global6, Port: 30303}},
+			tcp: []*net.TCPAddr{{IP: global6, Port: 30304}},
+		},
+		{
+			name:    "both-global",
+			entries: []enr.Entry{enr.IPv4(global4), enr.IPv6(global6), enr.UDP(1000), enr.UDP6(2000), enr.TCP(3000), enr.QUIC(5000)},
+			wantIP:  global4, wantUDP: 1000, wantTCP: 3000,
+			udp:  []*net.UDPAddr{{IP: global4, Port: 1000}, {IP: global6, Port: 2000}},
+			tcp:  []*net.TCPAddr{{IP: global4, Port: 3000}, {IP: global6, Port: 3000}},
+			quic: []*net.UDPAddr{{IP: global4, Port: 5000}, {IP: global6, Port: 5000}},
+		},
+		{
+			name:    "private-ipv4",
+			entries: []enr.Entry{enr.IPv4(private4), enr.IPv6(global6), enr.UDP(1000), enr.UDP6(2000), enr.TCP(3000), enr.TCP6(4000), enr.QUIC(5000), enr.QUIC6(6000)},
+			wantIP:  global6, wantUDP: 2000, wantTCP: 4000,
+			udp:  []*net.UDPAddr{{IP: global6, Port: 2000}, {IP: private4, Port: 1000}},
+			tcp:  []*net.TCPAddr{{IP: global6, Port: 4000}, {IP: private4, Port: 3000}},
+			quic: []*net.UDPAddr{{IP: global6, Port: 6000}, {IP: private4, Port: 5000}},
+		},
+		{
+			name:    "loopback-ipv6",
+			entries: []enr.Entry{enr.IPv4(private4), enr.IPv6(local6), enr.UDP(1000)},
+			wantIP:  private4, wantUDP: 1000, wantTCP: 0,
+			udp: []*net.UDPAddr{{IP: private4, Port: 1000}, {IP: local6, Port: 1000}},
+		},
+	}
+	for _, test := range tests {
+		var r enr.Record
+		for _, e := range test.entries {
+			r.Set(e)
+		}
+		n := SignNull(&r, ID{})
+		if !n.IP().Equal(test.wantIP) {
+			t.Errorf("%s: wrong IP %v, want %v", test.name, n.IP(), test.wantIP)
+		}
+		if n.UDP() != test.wantUDP {
+			t.Errorf("%s: wrong UDP port %d, want %d", test.name, n.UDP(), test.wantUDP)
+		}
+		if n.TCP() != test.wantTCP {
+			t.Errorf("%s: wrong TCP port %d, want %d", test.name, n.TCP(), test.wantTCP)
+		}
+		if udp := n.UDPEndpoints(); fmt.Sprint(udp) != fmt.Sprint(test.udp) {
+			t.Errorf("%s: wrong UDP endpoints %v, want %v", test.name, udp, test.udp)
+		}
+		if tcp := n.TCPEndpoints(); fmt.Sprint(tcp) != fmt.Sprint(test.tcp) {
+			t.Errorf("%s: wrong TCP endpoints %v, want %v", test.name, tcp, test.tcp)
+		}
+		if quic := n.QUICEndpoints(); fmt.Sprint(quic) != fmt.Sprint(test.quic) {
+			t.Errorf("%s: wrong QUIC endpoints %v, want %v", test.name, quic, test.quic)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"crypto/rand"
 	"encoding/binary"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"sync"
@@ -49,6 +50,8 @@ const (
 	dbNodePing      = "lastping"
 	dbNodePong      = "lastpong"
 	dbNodeSeq       = "seq"
+	dbNodeScore     = "score"
+	dbNodeScoreTime = "scoretime"
 
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
@@ -395,6 +398,24 @@ func (db *DB) UpdateLastPongReceived(id ID, ip net.IP, instance time.Time) error
 	return db.storeInt64(nodeItemKey(id, ip, dbNodePong), instance.Unix())
 }
 
+// NodeScore retrieves the reputation score of a node and the time it was stored.
+func (db *DB) NodeScore(id ID) (float64, time.Time) {
+	updated := db.fetchInt64(nodeItemKey(id, zeroIP, dbNodeScoreTime))
+	if updated == 0 {
+		return 0, time.Time{}
+	}
+	score := math.Float64frombits(db.fetchUint64(nodeItemKey(id, zeroIP, dbNodeScore)))
+	return score, time.Unix(updated, 0)
+}
+
+// UpdateNodeScore stores the reputation score of a node.
+func (db *DB) UpdateNodeScore(id ID, score float64, updated time.Time) error {
+	if err := db.storeUint64(nodeItemKey(id, zeroIP, dbNodeScore), math.Float64bits(score)); err != nil {
+		return err
+	}
+	return db.storeInt64(nodeItemKey(id, zeroIP, dbNodeScoreTime), updated.Unix())
+}
+
 // FindFails retrieves the number of findnode failures since bonding.
 func (db *DB) FindFails(id ID, ip net.IP) int {
 	if ip = ip.To16(); ip == nil {
@@ -112,6 +112,10 @@ func newMeteredConn(conn net.Conn) net.Conn {
 	if !metrics.Enabled {
 		return conn
 	}
+	if _, ok := conn.(*quicConn); ok {
+		// The traffic of QUIC connections is metered by the transport.
+		return conn
+	}
 	return &meteredConn{Conn: conn}
 }
 
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/rlp"
 	"golang.org/x/exp/slices"
 )
@@ -115,6 +116,9 @@ type Peer struct {
 	pingRecv chan struct{}
 	disc     chan DiscReason
 
+	// reputation receives reports about the peer if set
+	reputation *reputation.Tracker
+
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
@@ -244,10 +248,24 @@ func (p *Peer) Log() log.Logger {
 	return p.log
 }
 
+// Report records behaviour of the peer in the reputation system of the server.
+// Peers whose score drops below the ban threshold are disconnected, unless they
+// are trusted or static dialed.
+func (p *Peer) Report(ev reputation.Event) {
+	if p.reputation == nil {
+		return
+	}
+	p.reputation.Report(p.ID(), ev)
+	if !p.rw.is(trustedConn|staticDialedConn) && p.reputation.Banned(p.ID()) {
+		p.log.Debug("Disconnecting banned peer", "event", ev)
+		p.Disconnect(DiscUselessPeer)
+	}
+}
+
 func (p *Peer) run() (remoteRequested bool, err error) {
 	var (
-		writeStart = make(chan struct{}, 1)
-		writeErr   = make(chan error, 1)
+		writeStart = make(chan struct{}, p.maxWrites())
+		writeErr   = make(chan error, cap(writeStart))
 		readErr    = make(chan error, 1)
 		reason     DiscReason // sent to the peer
 	)
@@ -256,7 +274,9 @@ func (p *Peer) run() (remoteRequested bool, err error) {
 	go p.pingLoop()
 
 	// Start all protocol handlers.
-	writeStart <- struct{}{}
+	for i := 0; i < cap(writeStart); i++ {
+		writeStart <- struct{}{}
+	}
 	p.startProtocols(writeStart, writeErr)
 
 	// Wait for an error or disconnect.
@@ -409,6 +429,16 @@ outer:
 	return result
 }
 
+// maxWrites returns the number of protocol messages which may be written
+// concurrently. Writes are serialized unless the transport sends each sub-protocol
+// on its own stream.
+func (p *Peer) maxWrites() int {
+	if _, ok := p.rw.transport.(streamTransport); ok && len(p.running) > 1 {
+		return len(p.running)
+	}
+	return 1
+}
+
 func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error) {
 	p.wg.Add(len(p.running))
 	for _, proto := range p.running {
@@ -505,6 +535,7 @@ type PeerInfo struct {
 		Trusted       bool   `json:"trusted"`
 		Static        bool   `json:"static"`
 	} `json:"network"`
+	Score     float64                `json:"score"`     // Reputation score of the peer
 	Protocols map[string]interface{} `json:"protocols"` // Sub-protocol specific metadata fields
 }
 
@@ -531,6 +562,9 @@ func (p *Peer) Info() *PeerInfo {
 	info.Network.Inbound = p.rw.is(inboundConn)
 	info.Network.Trusted = p.rw.is(trustedConn)
 	info.Network.Static = p.rw.is(staticDialedConn)
+	if p.reputation != nil {
+		info.Score = p.reputation.Score(p.ID())
+	}
 
 	// Gather all the running protocol infos
 	for _, proto := range p.running {
@@ -28,9 +28,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 )
 
 var discard = Protocol{
@@ -198,6 +200,44 @@ func TestPeerDisconnect(t *testing.T) {
 	}
 }
 
+// This test checks that banned peers are disconnected when reported, unless
+// they are trusted or static dialed.
+func TestPeerReportBanned(t *testing.T) {
+	for _, test := range []struct {
+		flags      connFlag
+		disconnect bool
+	}{
+		{inboundConn, true},
+		{dynDialedConn, true},
+		{inboundConn | trustedConn, false},
+		{staticDialedConn, false},
+	} {
+		fd, _ := net.Pipe()
+		c := &conn{fd: fd, node: newNode(uintID(1), ""), flags: test.flags}
+		peer := newPeer(log.Root(), c, nil)
+		peer.reputation = reputation.NewTracker(nil, new(mclock.Simulated))
+		for !peer.reputation.Banned(peer.ID()) {
+			peer.reputation.Report(peer.ID(), reputation.ProtocolViolation)
+		}
+		go peer.Report(reputation.ProtocolViolation)
+
+		select {
+		case reason := <-peer.disc:
+			if !test.disconnect {
+				t.Errorf("flags %v: peer disconnected", test.flags)
+			} else if reason != DiscUselessPeer {
+				t.Errorf("flags %v: wrong disconnect reason %v", test.flags, reason)
+			}
+		case <-time.After(100 * time.Millisecond):
+			if test.disconnect {
+				t.Errorf("flags %v: peer not disconnected", test.flags)
+			}
+		}
+		close(peer.closed)
+		fd.Close()
+	}
+}
+
 // This test is supposed to verify that Peer can reliably handle
 // multiple causes of disconnection occurring at the same time.
 func TestPeerDisconnectRace(t *testing.T) {
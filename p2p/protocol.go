@@ -60,6 +60,15 @@ type Protocol struct {
 	// attempts to create connections to them.
 	DialCandidates enode.Iterator
 
+	// DiscoveryTopic, if non-empty, is the discv5 topic under which the node is
+	// advertised for this protocol. When discovery v5 topics are enabled, the server
+	// registers the topic and dials nodes found by searching for it.
+	DiscoveryTopic string
+
+	// NodeFilter, if set, filters the nodes found by searching the discovery
+	// topic before they are dialed.
+	NodeFilter func(*enode.Node) bool
+
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
 }
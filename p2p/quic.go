@@ -0,0 +1,477 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/golang/snappy"
+	"github.com/quic-go/quic-go"
+)
+
+const (
+	quicALPN         = "devp2p"
+	quicAuthLabel    = "EXPORTER-devp2p-quic-auth"
+	quicMaxFrameSize = 16 * 1024 * 1024
+	quicFlagSnappy   = 1 << 0 // frame payload is snappy-compressed
+
+	// quicDialTimeout is the time limit of connecting to a node over QUIC, after
+	// which the node is dialed over TCP instead.
+	quicDialTimeout = 3 * time.Second
+
+	// quicNoReason is the application error code of connections which are closed
+	// without a disconnect reason. Disconnect reasons use their own value as the
+	// error code.
+	quicNoReason = 0x100
+)
+
+var (
+	errQUICFrameTooLarge = errors.New("message too large")
+	errQUICReadTimeout   = errors.New("read timeout")
+	errQUICWrongNode     = errors.New("remote is not the dialed node")
+)
+
+// quicConfig is the QUIC configuration of devp2p connections. Peers only open the
+// control stream and one unidirectional stream per sub-protocol.
+var quicConfig = &quic.Config{
+	HandshakeIdleTimeout:  handshakeTimeout,
+	MaxIdleTimeout:        frameReadTimeout,
+	MaxIncomingStreams:    1,
+	MaxIncomingUniStreams: 32,
+}
+
+// newQUICTLSConfig creates the TLS configuration of QUIC connections. TLS is only
+// used for encryption: peers are authenticated by their node key in the encryption
+// handshake of the transport, so the certificate is ephemeral and not verified.
+func newQUICTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	cert, err := x509.CreateCertificate(crand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates:       []tls.Certificate{{Certificate: [][]byte{cert}, PrivateKey: key}},
+		NextProtos:         []string{quicALPN},
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS13,
+	}, nil
+}
+
+// quicConn is a QUIC connection along with its control stream. This is the
+// net.Conn passed to Server.SetupConn for QUIC connections.
+type quicConn struct {
+	quic.Stream
+	conn quic.Connection
+}
+
+// dialQUIC establishes a QUIC connection and opens its control stream.
+func dialQUIC(ctx context.Context, tr *quic.Transport, addr *net.UDPAddr, tlsConf *tls.Config) (*quicConn, error) {
+	conn, err := tr.Dial(ctx, addr, tlsConf, quicConfig)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+// acceptQUIC waits for the control stream of an inbound QUIC connection.
+func acceptQUIC(conn quic.Connection) (*quicConn, error) {
+	ctx, cancel := context.WithTimeout(conn.Context(), handshakeTimeout)
+	defer cancel()
+	stream, err := conn.AcceptStream(ctx)
+	if err != nil {
+		conn.CloseWithError(quicNoReason, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+func (c *quicConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *quicConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+func (c *quicConn) Close() error         { return c.conn.CloseWithError(quicNoReason, "") }
+
+// quicDialer dials nodes over QUIC if they advertise a QUIC port, using the fallback
+// dialer for all other nodes and for nodes which can't be reached over QUIC.
+type quicDialer struct {
+	transport *quic.Transport
+	tls       *tls.Config
+	fallback  NodeDialer
+	timeout   time.Duration // limit of the QUIC attempts, quicDialTimeout if zero
+}
+
+func (d *quicDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	if addrs := dest.QUICEndpoints(); len(addrs) > 0 {
+		if conn, err := d.dialQUIC(ctx, addrs); err == nil {
+			return conn, nil
+		}
+	}
+	return d.fallback.Dial(ctx, dest)
+}
+
+// dialQUIC tries the QUIC endpoints of a node within the QUIC dial timeout.
+func (d *quicDialer) dialQUIC(ctx context.Context, addrs []*net.UDPAddr) (conn net.Conn, err error) {
+	timeout := d.timeout
+	if timeout == 0 {
+		timeout = quicDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, addr := range addrs {
+		if conn, err = dialQUIC(ctx, d.transport, addr, d.tls); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// quicTransport is the transport used for QUIC connections. Messages of the base
+// protocol are sent on the control stream of the connection, while each
+// sub-protocol gets its own unidirectional stream, so a sub-protocol with a lot of
+// traffic doesn't hold up the others.
+//
+// Messages are sent as frames consisting of a flags byte, the message code and
+// payload size as uvarints, and the payload.
+type quicTransport struct {
+	conn     *quicConn
+	dialDest *ecdsa.PublicKey
+	snappy   atomic.Bool
+
+	mu      sync.Mutex
+	streams map[string]*quicSendStream // by protocol name, "" is the control stream
+
+	in       chan Msg
+	failOnce sync.Once
+	failed   chan struct{} // closed when reading fails
+	err      error         // the read error
+}
+
+// quicSendStream is the sending side of a stream.
+type quicSendStream struct {
+	mu     sync.Mutex
+	stream quic.SendStream
+	buf    bytes.Buffer
+}
+
+func newQUICTransport(conn *quicConn, dialDest *ecdsa.PublicKey) transport {
+	return &quicTransport{
+		conn:     conn,
+		dialDest: dialDest,
+		streams:  map[string]*quicSendStream{"": {stream: conn.Stream}},
+		in:       make(chan Msg),
+		failed:   make(chan struct{}),
+	}
+}
+
+// protocolStreams implements streamTransport.
+func (t *quicTransport) protocolStreams() {}
+
+func (t *quicTransport) doEncHandshake(prv *ecdsa.PrivateKey) (*ecdsa.PublicKey, error) {
+	t.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer t.conn.SetDeadline(time.Time{})
+
+	// Both sides sign keying material exported from the TLS session, which binds
+	// their node key to the connection. The initiator and the recipient sign
+	// different values, so signatures can't be reflected.
+	initiator := t.dialDest != nil
+	ours, err := t.authDigest(initiator)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := t.authDigest(!initiator)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(ours, prv)
+	if err != nil {
+		return nil, err
+	}
+	werr := make(chan error, 1)
+	go func() {
+		_, err := t.conn.Write(sig)
+		werr <- err
+	}()
+	remoteSig := make([]byte, crypto.SignatureLength)
+	if _, err := io.ReadFull(t.conn, remoteSig); err != nil {
+		<-werr // make sure the write terminates too
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	remote, err := crypto.SigToPub(theirs, remoteSig)
+	if err != nil {
+		return nil, err
+	}
+	if t.dialDest != nil && !bytes.Equal(crypto.FromECDSAPub(remote), crypto.FromECDSAPub(t.dialDest)) {
+		return nil, errQUICWrongNode
+	}
+
+	// Start reading messages.
+	go t.readStream(t.conn, true)
+	go t.acceptStreams()
+	return remote, nil
+}
+
+// authDigest returns the value signed by the initiator or the recipient of the
+// connection in the encryption handshake.
+func (t *quicTransport) authDigest(initiator bool) ([]byte, error) {
+	role := []byte("recipient")
+	if initiator {
+		role = []byte("initiator")
+	}
+	state := t.conn.conn.ConnectionState().TLS
+	return state.ExportKeyingMaterial(quicAuthLabel, role, 32)
+}
+
+func (t *quicTransport) doProtoHandshake(our *protoHandshake) (their *protoHandshake, err error) {
+	werr := make(chan error, 1)
+	go func() { werr <- Send(t, handshakeMsg, our) }()
+	if their, err = readProtocolHandshake(t); err != nil {
+		<-werr // make sure the write terminates too
+		return nil, err
+	}
+	if err := <-werr; err != nil {
+		return nil, fmt.Errorf("write error: %v", err)
+	}
+	// Frames carry a compression flag, so the remote end may start sending
+	// compressed messages before we get here.
+	t.snappy.Store(their.Version >= snappyProtocolVersion)
+
+	return their, nil
+}
+
+func (t *quicTransport) ReadMsg() (Msg, error) {
+	timeout := time.NewTimer(frameReadTimeout)
+	defer timeout.Stop()
+
+	select {
+	case msg := <-t.in:
+		return msg, nil
+	case <-t.failed:
+		// Deliver messages received before the failure first.
+		select {
+		case msg := <-t.in:
+			return msg, nil
+		default:
+			return Msg{}, t.err
+		}
+	case <-timeout.C:
+		return Msg{}, errQUICReadTimeout
+	}
+}
+
+// acceptStreams starts reading the streams opened by the remote end.
+func (t *quicTransport) acceptStreams() {
+	for {
+		stream, err := t.conn.conn.AcceptUniStream(t.conn.conn.Context())
+		if err != nil {
+			t.fail(err)
+			return
+		}
+		go t.readStream(stream, false)
+	}
+}
+
+// readStream reads the messages of a stream. The remote end never closes the
+// control stream, ending it fails the connection.
+func (t *quicTransport) readStream(stream io.Reader, control bool) {
+	r := bufio.NewReader(stream)
+	for {
+		msg, err := readQUICFrame(r)
+		if err != nil {
+			if control || err != io.EOF {
+				t.fail(err)
+			}
+			return
+		}
+		select {
+		case t.in <- msg:
+		case <-t.failed:
+			return
+		}
+	}
+}
+
+// fail records the read error of the connection. If the remote end closed the
+// connection with a disconnect reason, the reason becomes the error.
+func (t *quicTransport) fail(err error) {
+	t.failOnce.Do(func() {
+		var appErr *quic.ApplicationError
+		if errors.As(err, &appErr) && appErr.Remote && appErr.ErrorCode < quicNoReason {
+			err = DiscReason(appErr.ErrorCode)
+		}
+		t.err = err
+		close(t.failed)
+	})
+}
+
+func (t *quicTransport) WriteMsg(msg Msg) error {
+	s, err := t.sendStream(msg.meterCap.Name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Encode the frame.
+	payload := make([]byte, msg.Size)
+	if _, err := io.ReadFull(msg.Payload, payload); err != nil {
+		return err
+	}
+	var flags byte
+	if t.snappy.Load() {
+		payload = snappy.Encode(nil, payload)
+		flags |= quicFlagSnappy
+	}
+	if len(payload) > quicMaxFrameSize {
+		return errQUICFrameTooLarge
+	}
+	s.buf.Reset()
+	s.buf.WriteByte(flags)
+	s.buf.Write(binary.AppendUvarint(nil, msg.Code))
+	s.buf.Write(binary.AppendUvarint(nil, uint64(len(payload))))
+	s.buf.Write(payload)
+
+	// Write the frame.
+	s.stream.SetWriteDeadline(time.Now().Add(frameWriteTimeout))
+	if _, err := s.stream.Write(s.buf.Bytes()); err != nil {
+		return err
+	}
+
+	// Set metrics.
+	msg.meterSize = uint32(s.buf.Len())
+	egressTrafficMeter.Mark(int64(msg.meterSize))
+	if metrics.Enabled && msg.meterCap.Name != "" { // don't meter non-subprotocol messages
+		m := fmt.Sprintf("%s/%s/%d/%#02x", egressMeterName, msg.meterCap.Name, msg.meterCap.Version, msg.meterCode)
+		metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
+		metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
+	}
+	return nil
+}
+
+// sendStream returns the stream for messages of the given protocol, opening it
+// on first use.
+func (t *quicTransport) sendStream(proto string) (*quicSendStream, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s := t.streams[proto]; s != nil {
+		return s, nil
+	}
+	stream, err := t.conn.conn.OpenUniStream()
+	if err != nil {
+		return nil, err
+	}
+	s := &quicSendStream{stream: stream}
+	t.streams[proto] = s
+	return s, nil
+}
+
+func (t *quicTransport) close(err error) {
+	// The disconnect reason is sent as the error code of the connection close,
+	// which reaches the remote end even if the streams are congested.
+	code, msg := quic.ApplicationErrorCode(quicNoReason), ""
+	if r, ok := err.(DiscReason); ok && r != DiscNetworkError {
+		code, msg = quic.ApplicationErrorCode(r), r.String()
+	}
+	t.conn.conn.CloseWithError(code, msg)
+}
+
+// readQUICFrame reads a message frame.
+func readQUICFrame(r *bufio.Reader) (Msg, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return Msg{}, err
+	}
+	code, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, noEOF(err)
+	}
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return Msg{}, noEOF(err)
+	}
+	if size > quicMaxFrameSize {
+		return Msg{}, errQUICFrameTooLarge
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Msg{}, noEOF(err)
+	}
+	ingressTrafficMeter.Mark(int64(size))
+
+	if flags&quicFlagSnappy != 0 {
+		n, err := snappy.DecodedLen(data)
+		if err != nil {
+			return Msg{}, err
+		}
+		if n > quicMaxFrameSize {
+			return Msg{}, errQUICFrameTooLarge
+		}
+		if data, err = snappy.Decode(nil, data); err != nil {
+			return Msg{}, err
+		}
+	}
+	msg := Msg{
+		ReceivedAt: time.Now(),
+		Code:       code,
+		Size:       uint32(len(data)),
+		meterSize:  uint32(size),
+		Payload:    bytes.NewReader(data),
+	}
+	return msg, nil
+}
+
+// noEOF converts io.EOF into io.ErrUnexpectedEOF, for streams ending within a frame.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
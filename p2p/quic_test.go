@@ -0,0 +1,304 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/quic-go/quic-go"
+)
+
+type quicHandshakeResult struct {
+	t      *quicTransport
+	remote *ecdsa.PublicKey
+	err    error
+}
+
+// quicHandshake connects two QUIC transports over loopback and runs the
+// encryption handshake on both ends.
+func quicHandshake(t *testing.T, dialKey, listenKey *ecdsa.PrivateKey, dialDest *ecdsa.PublicKey) (dialer, listener quicHandshakeResult) {
+	tlsConf, err := newQUICTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trs [2]*quic.Transport
+	for i := range trs {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		trs[i] = &quic.Transport{Conn: conn}
+		t.Cleanup(func() { trs[i].Close(); conn.Close() })
+	}
+	ln, err := trs[1].Listen(tlsConf, quicConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	accepted := make(chan quicHandshakeResult, 1)
+	go func() {
+		conn, err := ln.Accept(context.Background())
+		if err != nil {
+			accepted <- quicHandshakeResult{err: err}
+			return
+		}
+		fd, err := acceptQUIC(conn)
+		if err != nil {
+			accepted <- quicHandshakeResult{err: err}
+			return
+		}
+		tr := newQUICTransport(fd, nil).(*quicTransport)
+		remote, err := tr.doEncHandshake(listenKey)
+		accepted <- quicHandshakeResult{tr, remote, err}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	fd, err := dialQUIC(ctx, trs[0], trs[1].Conn.LocalAddr().(*net.UDPAddr), tlsConf)
+	if err != nil {
+		t.Fatal("dial failed:", err)
+	}
+	tr := newQUICTransport(fd, dialDest).(*quicTransport)
+	remote, err := tr.doEncHandshake(dialKey)
+	dialer = quicHandshakeResult{tr, remote, err}
+	if err != nil {
+		tr.close(err)
+	}
+	return dialer, <-accepted
+}
+
+func TestQUICTransport(t *testing.T) {
+	var (
+		key0, key1 = newkey(), newkey()
+		hs0        = &protoHandshake{Version: baseProtocolVersion, ID: crypto.FromECDSAPub(&key0.PublicKey)[1:], Caps: []Cap{{"a", 1}, {"b", 1}}}
+		hs1        = &protoHandshake{Version: baseProtocolVersion, ID: crypto.FromECDSAPub(&key1.PublicKey)[1:], Caps: []Cap{{"a", 1}, {"b", 1}}}
+	)
+	dialer, listener := quicHandshake(t, key0, key1, &key1.PublicKey)
+	if dialer.err != nil || listener.err != nil {
+		t.Fatalf("handshake failed: dialer: %v, listener: %v", dialer.err, listener.err)
+	}
+	if !reflect.DeepEqual(dialer.remote, &key1.PublicKey) {
+		t.Errorf("dialer got wrong remote key %v", dialer.remote)
+	}
+	if !reflect.DeepEqual(listener.remote, &key0.PublicKey) {
+		t.Errorf("listener got wrong remote key %v", listener.remote)
+	}
+
+	// Run the protocol handshake.
+	phs := make(chan *protoHandshake, 1)
+	go func() {
+		hs, err := listener.t.doProtoHandshake(hs1)
+		if err != nil {
+			t.Error("listener protocol handshake failed:", err)
+		}
+		phs <- hs
+	}()
+	if hs, err := dialer.t.doProtoHandshake(hs0); err != nil {
+		t.Fatal("dialer protocol handshake failed:", err)
+	} else if hs.Rest = nil; !reflect.DeepEqual(hs, hs1) {
+		t.Errorf("dialer got wrong protocol handshake %+v", hs)
+	}
+	if hs := <-phs; hs == nil {
+		t.FailNow()
+	} else if hs.Rest = nil; !reflect.DeepEqual(hs, hs0) {
+		t.Errorf("listener got wrong protocol handshake %+v", hs)
+	}
+
+	// Send messages of the base protocol and two sub-protocols. Messages of the
+	// same protocol arrive in order.
+	sent := []Msg{
+		{Code: pingMsg},
+		{Code: 0x10, meterCap: Cap{"a", 1}},
+		{Code: 0x20, meterCap: Cap{"b", 1}},
+		{Code: 0x11, meterCap: Cap{"a", 1}},
+	}
+	for _, msg := range sent {
+		size, payload, _ := rlp.EncodeToReader([]uint64{msg.Code})
+		msg.Size, msg.Payload = uint32(size), payload
+		if err := dialer.t.WriteMsg(msg); err != nil {
+			t.Fatal("write failed:", err)
+		}
+	}
+	if n := len(dialer.t.streams); n != 3 {
+		t.Errorf("dialer uses %d streams, want 3", n)
+	}
+	received := make(map[uint64]int)
+	for i := range sent {
+		msg, err := listener.t.ReadMsg()
+		if err != nil {
+			t.Fatal("read failed:", err)
+		}
+		var content []uint64
+		if err := msg.Decode(&content); err != nil || len(content) != 1 || content[0] != msg.Code {
+			t.Errorf("message %d has wrong content %v (err %v)", msg.Code, content, err)
+		}
+		received[msg.Code] = i
+	}
+	if len(received) != len(sent) {
+		t.Fatalf("wrong messages received: %v", received)
+	}
+	if received[0x10] > received[0x11] {
+		t.Error("messages of protocol a were reordered")
+	}
+
+	// The disconnect reason reaches the remote end.
+	dialer.t.close(DiscQuitting)
+	if _, err := listener.t.ReadMsg(); err != DiscQuitting {
+		t.Errorf("wrong read error after close: %v", err)
+	}
+}
+
+func TestQUICTransportWrongNode(t *testing.T) {
+	dialer, _ := quicHandshake(t, newkey(), newkey(), &newkey().PublicKey)
+	if dialer.err != errQUICWrongNode {
+		t.Fatalf("wrong dialer error %v", dialer.err)
+	}
+}
+
+type quicFallbackDialer struct {
+	called chan struct{}
+}
+
+func (d *quicFallbackDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	close(d.called)
+	return nil, errors.New("fallback")
+}
+
+// This test checks that nodes which can't be reached over QUIC are dialed by the
+// fallback dialer, also when the dial context has expired.
+func TestQUICDialerFallback(t *testing.T) {
+	tlsConf, err := newQUICTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := &quic.Transport{Conn: conn}
+	defer tr.Close()
+
+	// The destination doesn't answer, its socket is never read.
+	silent, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	var r enr.Record
+	r.Set(enr.IPv4{127, 0, 0, 1})
+	r.Set(enr.QUIC(silent.LocalAddr().(*net.UDPAddr).Port))
+	dest := enode.SignNull(&r, enode.ID{1})
+
+	for _, expired := range []bool{false, true} {
+		var (
+			fallback = &quicFallbackDialer{called: make(chan struct{})}
+			dialer   = &quicDialer{transport: tr, tls: tlsConf, fallback: fallback, timeout: 200 * time.Millisecond}
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		if expired {
+			cancel()
+		}
+		start := time.Now()
+		if _, err := dialer.Dial(ctx, dest); err == nil || err.Error() != "fallback" {
+			t.Errorf("expired %t: wrong dial error %v", expired, err)
+		}
+		cancel()
+		select {
+		case <-fallback.called:
+		default:
+			t.Errorf("expired %t: fallback dialer not used", expired)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("expired %t: QUIC dial took %v", expired, elapsed)
+		}
+	}
+}
+
+func TestServerQUIC(t *testing.T) {
+	type result struct {
+		addr net.Addr
+		msg  string
+	}
+	results := make(chan result, 2)
+	proto := Protocol{
+		Name:    "test",
+		Version: 1,
+		Length:  1,
+		Run: func(p *Peer, rw MsgReadWriter) error {
+			if err := Send(rw, 0, "hello"); err != nil {
+				return err
+			}
+			msg, err := rw.ReadMsg()
+			if err != nil {
+				return err
+			}
+			var s string
+			msg.Decode(&s)
+			results <- result{p.RemoteAddr(), s}
+			_, err = rw.ReadMsg()
+			return err
+		},
+	}
+	var servers [2]*Server
+	for i := range servers {
+		servers[i] = &Server{Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			ListenAddr:  "127.0.0.1:0",
+			QUICAddr:    "127.0.0.1:0",
+			NoDiscovery: true,
+			Protocols:   []Protocol{proto},
+			Logger:      testlog.Logger(t, log.LvlTrace).New("server", i),
+		}}
+		if err := servers[i].Start(); err != nil {
+			t.Fatal("can't start server:", err)
+		}
+		defer servers[i].Stop()
+	}
+	var port enr.QUIC
+	if err := servers[1].Self().Load(&port); err != nil || port == 0 {
+		t.Fatalf("missing QUIC port in record: %v", err)
+	}
+
+	servers[0].AddPeer(servers[1].Self())
+	for i := 0; i < 2; i++ {
+		select {
+		case res := <-results:
+			if _, ok := res.addr.(*net.UDPAddr); !ok {
+				t.Errorf("peer connected via %v, want QUIC", res.addr)
+			}
+			if res.msg != "hello" {
+				t.Errorf("wrong message %q", res.msg)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("peers did not exchange messages")
+		}
+	}
+}
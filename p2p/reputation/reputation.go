@@ -0,0 +1,188 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package reputation keeps track of the behaviour of remote peers.
+//
+// Protocols report good and bad behaviour of peers as events, which add to or
+// subtract from the score of the peer. Scores decay towards zero over time, so
+// misbehaviour is eventually forgiven, and they are persisted in the node
+// database to survive restarts. Peers whose score drops below BanThreshold are
+// banned.
+package reputation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Event is a kind of peer behaviour.
+type Event int
+
+const (
+	ProtocolViolation  Event = iota // peer sent an invalid or unexpected message
+	Timeout                         // peer failed to respond to a request in time
+	UsefulResponse                  // peer delivered requested data
+	UselessResponse                 // peer responded without the requested data
+	InvalidBlock                    // peer sent a block which failed validation
+	InvalidTransaction              // peer sent an invalid transaction
+	numEvents
+)
+
+var (
+	eventWeights = [numEvents]float64{-20, -5, 1, -2, -50, -10}
+	eventNames   = [numEvents]string{"violation", "timeout", "useful", "useless", "invalid-block", "invalid-tx"}
+)
+
+func (ev Event) String() string {
+	if ev < 0 || ev >= numEvents {
+		return fmt.Sprintf("Event(%d)", int(ev))
+	}
+	return eventNames[ev]
+}
+
+const (
+	// BanThreshold is the score below which a peer is banned.
+	BanThreshold = -100
+
+	maxScore  = 100           // upper bound of scores, limiting the credit a peer can build up
+	minScore  = -200          // lower bound of scores, limiting how long a ban lasts
+	halfLife  = 6 * time.Hour // time after which a score has decayed to half its value
+	cacheSize = 1024          // number of scores kept in memory
+)
+
+var bannedMeter = metrics.NewRegisteredMeter("p2p/reputation/banned", nil)
+
+// Reporter is implemented by components which record peer behaviour.
+type Reporter interface {
+	Report(id enode.ID, ev Event)
+}
+
+// Tracker maintains the reputation scores of peers.
+type Tracker struct {
+	db    *enode.DB
+	clock mclock.Clock
+
+	mu    sync.Mutex
+	cache lru.BasicLRU[enode.ID, *score]
+}
+
+// score is the cached score of a peer.
+type score struct {
+	value   float64
+	updated mclock.AbsTime // time of the last update of value
+	dirty   bool           // value was changed since it was last stored
+}
+
+// NewTracker creates a tracker which persists scores in db. The database may be
+// nil, in which case scores are only kept in memory.
+func NewTracker(db *enode.DB, clock mclock.Clock) *Tracker {
+	return &Tracker{
+		db:    db,
+		clock: clock,
+		cache: lru.NewBasicLRU[enode.ID, *score](cacheSize),
+	}
+}
+
+// Report records an event for the peer. Penalties are stored immediately, while
+// rewards are stored lazily when the peer is flushed.
+func (t *Tracker) Report(id enode.ID, ev Event) {
+	if ev < 0 || ev >= numEvents {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.load(id)
+	wasBanned := s.value < BanThreshold
+	s.value = math.Max(minScore, math.Min(maxScore, s.value+eventWeights[ev]))
+	s.dirty = true
+	if eventWeights[ev] < 0 {
+		t.store(id, s)
+	}
+	if !wasBanned && s.value < BanThreshold {
+		bannedMeter.Mark(1)
+	}
+}
+
+// Score returns the current score of the peer.
+func (t *Tracker) Score(id enode.ID) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.load(id).value
+}
+
+// Banned reports whether the peer's score is below BanThreshold.
+func (t *Tracker) Banned(id enode.ID) bool {
+	return t.Score(id) < BanThreshold
+}
+
+// Flush stores the score of the peer if it has changed since it was last stored.
+// This should be called when the peer disconnects.
+func (t *Tracker) Flush(id enode.ID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s, ok := t.cache.Peek(id); ok && s.dirty {
+		t.store(id, s)
+	}
+}
+
+// load returns the score of the peer, decayed to the current time.
+func (t *Tracker) load(id enode.ID) *score {
+	now := t.clock.Now()
+	s, ok := t.cache.Get(id)
+	if !ok {
+		s = &score{updated: now}
+		if t.db != nil {
+			if value, stored := t.db.NodeScore(id); !stored.IsZero() {
+				s.value = decay(value, time.Since(stored))
+			}
+		}
+		if t.cache.Len() >= cacheSize {
+			if oid, old, _ := t.cache.RemoveOldest(); old.dirty {
+				t.store(oid, old)
+			}
+		}
+		t.cache.Add(id, s)
+	}
+	s.value = decay(s.value, time.Duration(now-s.updated))
+	s.updated = now
+	return s
+}
+
+// store writes the score to the database.
+func (t *Tracker) store(id enode.ID, s *score) {
+	if t.db != nil {
+		t.db.UpdateNodeScore(id, s.value, time.Now())
+	}
+	s.dirty = false
+}
+
+// decay returns the value of a score after the given time has passed.
+func decay(value float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return value
+	}
+	return value * math.Exp2(-float64(elapsed)/float64(halfLife))
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package reputation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestTrackerScore(t *testing.T) {
+	var (
+		clock   = new(mclock.Simulated)
+		tracker = NewTracker(nil, clock)
+		id      = enode.ID{1}
+	)
+	tracker.Report(id, UsefulResponse)
+	tracker.Report(id, Timeout)
+	if s := tracker.Score(id); s != -4 {
+		t.Fatalf("wrong score: got %v, want %v", s, -4)
+	}
+
+	// Scores decay towards zero.
+	clock.Run(halfLife)
+	if s := tracker.Score(id); math.Abs(s+2) > 1e-9 {
+		t.Fatalf("wrong score after decay: got %v, want %v", s, -2)
+	}
+
+	// Scores are bounded.
+	for i := 0; i < 20; i++ {
+		tracker.Report(id, InvalidBlock)
+	}
+	if s := tracker.Score(id); s != minScore {
+		t.Fatalf("score not bounded: got %v, want %v", s, minScore)
+	}
+}
+
+func TestTrackerBan(t *testing.T) {
+	var (
+		clock   = new(mclock.Simulated)
+		tracker = NewTracker(nil, clock)
+		id      = enode.ID{1}
+	)
+	tracker.Report(id, InvalidBlock)
+	tracker.Report(id, InvalidBlock)
+	if tracker.Banned(id) {
+		t.Fatal("peer banned at threshold")
+	}
+	tracker.Report(id, Timeout)
+	if !tracker.Banned(id) {
+		t.Fatal("peer not banned below threshold")
+	}
+	// The ban is lifted once the score has decayed.
+	clock.Run(halfLife / 4)
+	if tracker.Banned(id) {
+		t.Fatal("peer still banned after decay")
+	}
+}
+
+func TestTrackerPersistence(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	var (
+		clock = new(mclock.Simulated)
+		id1   = enode.ID{1}
+		id2   = enode.ID{2}
+	)
+	tracker := NewTracker(db, clock)
+	tracker.Report(id1, ProtocolViolation)
+	tracker.Report(id2, UsefulResponse)
+
+	// Penalties are stored immediately, rewards only when flushed.
+	tracker2 := NewTracker(db, clock)
+	if s := tracker2.Score(id1); math.Abs(s+20) > 1e-3 {
+		t.Fatalf("penalty not persisted: score %v", s)
+	}
+	if s := NewTracker(db, clock).Score(id2); s != 0 {
+		t.Fatalf("reward persisted before flush: score %v", s)
+	}
+	tracker.Flush(id2)
+	if s := NewTracker(db, clock).Score(id2); math.Abs(s-1) > 1e-3 {
+		t.Fatalf("reward not persisted after flush: score %v", s)
+	}
+}
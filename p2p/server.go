@@ -19,7 +19,9 @@ package p2p
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -38,6 +40,8 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
+	"github.com/quic-go/quic-go"
 	"golang.org/x/exp/slices"
 )
 
@@ -100,6 +104,11 @@ type Config struct {
 	// protocol should be started or not.
 	DiscoveryV5 bool `toml:",omitempty"`
 
+	// DiscoveryV5Topics specifies whether the discovery topics of the protocols
+	// should be advertised and searched via V5 discovery. It's experimental and
+	// disabled by default.
+	DiscoveryV5Topics bool `toml:",omitempty"`
+
 	// Name sets the node name of this server.
 	Name string `toml:"-"`
 
@@ -146,6 +155,18 @@ type Config struct {
 	// for TCP and DiscAddr for the UDP discovery protocol.
 	DiscAddr string
 
+	// If DiscAddr6 is set to a non-nil value, the UDP discovery protocol runs on
+	// separate IPv4 and IPv6 sockets, listening on DiscAddr (or ListenAddr) for
+	// IPv4 and on DiscAddr6 for IPv6. Both endpoints are advertised in the local
+	// node record.
+	DiscAddr6 string
+
+	// If QUICAddr is set to a non-nil value, the server also accepts QUIC
+	// connections on this UDP address and advertises the QUIC port in the local
+	// node record. Peers which advertise a QUIC port are dialed using QUIC
+	// instead of TCP.
+	QUICAddr string
+
 	// If set to a non-nil value, the given NAT port mapper
 	// is used to make the listening port available to the
 	// Internet.
@@ -183,6 +204,9 @@ type Server struct {
 	running bool
 
 	listener     net.Listener
+	quic         *quic.Transport
+	quicListener *quic.Listener
+	quicTLS      *tls.Config
 	ourHandshake *protoHandshake
 	loopWG       sync.WaitGroup // loop, listenLoop
 	peerFeed     event.Feed
@@ -195,6 +219,8 @@ type Server struct {
 	discmix   *enode.FairMix
 	dialsched *dialScheduler
 
+	reputation *reputation.Tracker
+
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
 
@@ -255,6 +281,14 @@ type transport interface {
 	close(err error)
 }
 
+// streamTransport is implemented by transports which send each sub-protocol on a
+// separate stream. Writes of different sub-protocols don't wait for each other on
+// such transports.
+type streamTransport interface {
+	transport
+	protocolStreams()
+}
+
 func (c *conn) String() string {
 	s := c.flags.String()
 	if (c.node.ID() != enode.ID{}) {
@@ -413,15 +447,22 @@ func (srv *Server) Stop() {
 		// this unblocks listener Accept
 		srv.listener.Close()
 	}
+	if srv.quicListener != nil {
+		srv.quicListener.Close()
+	}
 	close(srv.quit)
 	srv.lock.Unlock()
 	srv.loopWG.Wait()
+	if srv.quic != nil {
+		srv.quic.Close()
+		srv.quic.Conn.Close()
+	}
 }
 
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
 // messages that were found unprocessable and sent to the unhandled channel by the primary listener.
 type sharedUDPConn struct {
-	*net.UDPConn
+	discover.UDPConn
 	unhandled chan discover.ReadPacket
 }
 
@@ -493,6 +534,11 @@ func (srv *Server) Start() (err error) {
 			return err
 		}
 	}
+	if srv.QUICAddr != "" {
+		if err := srv.setupQUICListening(); err != nil {
+			return err
+		}
+	}
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
@@ -518,6 +564,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = reputation.NewTracker(db, srv.clock)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -560,6 +607,7 @@ func (srv *Server) setupDiscovery() error {
 			Bootnodes:   srv.BootstrapNodes,
 			Unhandled:   unhandled,
 			Log:         srv.log,
+			Reputation:  srv.reputation,
 		}
 		ntab, err := discover.ListenV4(conn, srv.localnode, cfg)
 		if err != nil {
@@ -574,6 +622,7 @@ func (srv *Server) setupDiscovery() error {
 			NetRestrict: srv.NetRestrict,
 			Bootnodes:   srv.BootstrapNodesV5,
 			Log:         srv.log,
+			Reputation:  srv.reputation,
 		}
 		srv.DiscV5, err = discover.ListenV5(sconn, srv.localnode, cfg)
 		if err != nil {
@@ -589,6 +638,23 @@ func (srv *Server) setupDiscovery() error {
 			added[proto.Name] = true
 		}
 	}
+	// Advertise and search protocol topics if requested.
+	if srv.DiscV5 != nil && srv.DiscoveryV5Topics {
+		topics := make(map[string]bool)
+		for _, proto := range srv.Protocols {
+			if proto.DiscoveryTopic != "" && !topics[proto.DiscoveryTopic] {
+				topic := discover.NewTopic(proto.DiscoveryTopic)
+				srv.DiscV5.RegisterTopic(topic)
+
+				var it enode.Iterator = srv.DiscV5.TopicSearch(topic)
+				if proto.NodeFilter != nil {
+					it = enode.Filter(it, proto.NodeFilter)
+				}
+				srv.discmix.AddSource(it)
+				topics[proto.DiscoveryTopic] = true
+			}
+		}
+	}
 	return nil
 }
 
@@ -599,6 +665,7 @@ func (srv *Server) setupDialScheduler() {
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
+		reputation:     srv.reputation,
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -608,6 +675,9 @@ func (srv *Server) setupDialScheduler() {
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
+	if srv.quic != nil {
+		config.dialer = &quicDialer{transport: srv.quic, tls: srv.quicTLS, fallback: config.dialer}
+	}
 	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
@@ -660,7 +730,32 @@ func (srv *Server) setupListening() error {
 	return nil
 }
 
-func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
+func (srv *Server) setupQUICListening() error {
+	conn, err := srv.listenUDP("udp", srv.QUICAddr, "ethereum p2p quic")
+	if err != nil {
+		return err
+	}
+	tlsConf, err := newQUICTLSConfig()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	tr := &quic.Transport{Conn: conn}
+	listener, err := tr.Listen(tlsConf, quicConfig)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	srv.quic, srv.quicListener, srv.quicTLS = tr, listener, tlsConf
+	srv.QUICAddr = conn.LocalAddr().String()
+	srv.localnode.Set(enr.QUIC(conn.LocalAddr().(*net.UDPAddr).Port))
+
+	srv.loopWG.Add(1)
+	go srv.quicListenLoop()
+	return nil
+}
+
+func (srv *Server) setupUDPListening() (discover.UDPConn, error) {
 	listenAddr := srv.ListenAddr
 
 	// Use an alternate listening address for UDP if
@@ -668,25 +763,56 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	if srv.DiscAddr != "" {
 		listenAddr = srv.DiscAddr
 	}
-	addr, err := net.ResolveUDPAddr("udp", listenAddr)
+	if srv.DiscAddr6 == "" {
+		conn, err := srv.listenUDP("udp", listenAddr, "ethereum peer discovery")
+		if err != nil {
+			return nil, err
+		}
+		srv.localnode.SetFallbackUDP(conn.LocalAddr().(*net.UDPAddr).Port)
+		return conn, nil
+	}
+
+	// Dual-stack discovery uses one socket per address family.
+	conn4, err := srv.listenUDP("udp4", listenAddr, "ethereum peer discovery")
+	if err != nil {
+		return nil, err
+	}
+	conn6, err := srv.listenUDP("udp6", srv.DiscAddr6, "ethereum peer discovery")
+	if err != nil {
+		conn4.Close()
+		return nil, err
+	}
+	srv.localnode.SetFallbackUDP(conn4.LocalAddr().(*net.UDPAddr).Port)
+	laddr6 := conn6.LocalAddr().(*net.UDPAddr)
+	srv.localnode.SetFallbackUDP6(laddr6.Port)
+	if !laddr6.IP.IsUnspecified() {
+		srv.localnode.SetFallbackIP(laddr6.IP)
+	}
+	return discover.NewDualStackConn(conn4, conn6), nil
+}
+
+// listenUDP opens a discovery socket on the given network and address.
+func (srv *Server) listenUDP(network, listenAddr, name string) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr(network, listenAddr)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := net.ListenUDP("udp", addr)
+	conn, err := net.ListenUDP(network, addr)
 	if err != nil {
 		return nil, err
 	}
 	laddr := conn.LocalAddr().(*net.UDPAddr)
-	srv.localnode.SetFallbackUDP(laddr.Port)
 	srv.log.Debug("UDP listener up", "addr", laddr)
-	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
+
+	// Port mappings are only requested for IPv4, as IPv6 endpoints are
+	// reachable without NAT.
+	if network != "udp6" && !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
 		srv.portMappingRegister <- &portMapping{
 			protocol: "UDP",
-			name:     "ethereum peer discovery",
+			name:     name,
 			port:     laddr.Port,
 		}
 	}
-
 	return conn, nil
 }
 
@@ -824,6 +950,8 @@ func (srv *Server) postHandshakeChecks(peers map[enode.ID]*Peer, inboundCount in
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case !c.is(trustedConn|staticDialedConn) && srv.reputation.Banned(c.node.ID()):
+		return DiscUselessPeer
 	default:
 		return nil
 	}
@@ -908,6 +1036,60 @@ func (srv *Server) listenLoop() {
 	}
 }
 
+// quicListenLoop runs in its own goroutine and accepts
+// inbound QUIC connections.
+func (srv *Server) quicListenLoop() {
+	srv.log.Debug("QUIC listener up", "addr", srv.quicListener.Addr())
+
+	// The slots channel limits accepts of new connections.
+	tokens := defaultMaxPendingPeers
+	if srv.MaxPendingPeers > 0 {
+		tokens = srv.MaxPendingPeers
+	}
+	slots := make(chan struct{}, tokens)
+	for i := 0; i < tokens; i++ {
+		slots <- struct{}{}
+	}
+
+	// Wait for slots to be returned on exit. This ensures all connection goroutines
+	// are down before quicListenLoop returns.
+	defer srv.loopWG.Done()
+	defer func() {
+		for i := 0; i < cap(slots); i++ {
+			<-slots
+		}
+	}()
+
+	for {
+		// Wait for a free slot before accepting.
+		<-slots
+
+		conn, err := srv.quicListener.Accept(context.Background())
+		if err != nil {
+			srv.log.Debug("QUIC accept error", "err", err)
+			slots <- struct{}{}
+			return
+		}
+		remoteIP := netutil.AddrIP(conn.RemoteAddr())
+		if err := srv.checkInboundConn(remoteIP); err != nil {
+			srv.log.Debug("Rejected inbound connection", "addr", conn.RemoteAddr(), "err", err)
+			conn.CloseWithError(quicNoReason, "")
+			slots <- struct{}{}
+			continue
+		}
+		serveMeter.Mark(1)
+		srv.log.Trace("Accepted QUIC connection", "addr", conn.RemoteAddr())
+		go func() {
+			if fd, err := acceptQUIC(conn); err != nil {
+				srv.log.Trace("Failed to accept control stream", "addr", conn.RemoteAddr(), "err", err)
+			} else {
+				srv.SetupConn(fd, inboundConn, nil)
+			}
+			slots <- struct{}{}
+		}()
+	}
+}
+
 func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 	if remoteIP == nil {
 		return nil
@@ -931,10 +1113,14 @@ func (srv *Server) checkInboundConn(remoteIP net.IP) error {
 // or the handshakes have failed.
 func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *enode.Node) error {
 	c := &conn{fd: fd, flags: flags, cont: make(chan error)}
-	if dialDest == nil {
-		c.transport = srv.newTransport(fd, nil)
+	var dialPubkey *ecdsa.PublicKey
+	if dialDest != nil {
+		dialPubkey = dialDest.Pubkey()
+	}
+	if qc, ok := fd.(*quicConn); ok {
+		c.transport = newQUICTransport(qc, dialPubkey)
 	} else {
-		c.transport = srv.newTransport(fd, dialDest.Pubkey())
+		c.transport = srv.newTransport(fd, dialPubkey)
 	}
 
 	err := srv.setupConn(c, flags, dialDest)
@@ -1007,9 +1193,13 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 func nodeFromConn(pubkey *ecdsa.PublicKey, conn net.Conn) *enode.Node {
 	var ip net.IP
 	var port int
-	if tcp, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
-		ip = tcp.IP
-		port = tcp.Port
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		ip = addr.IP
+		port = addr.Port
+	case *net.UDPAddr:
+		// The source port of QUIC connections is not a TCP port.
+		ip = addr.IP
 	}
 	return enode.NewV4(pubkey, ip, port, port)
 }
@@ -1027,6 +1217,7 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.reputation = srv.reputation
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
@@ -1050,6 +1241,7 @@ func (srv *Server) runPeer(p *Peer) {
 
 	// Run the per-peer main loop.
 	remoteRequested, err := p.run()
+	srv.reputation.Flush(p.ID())
 
 	// Announce disconnect on the main loop to update the peer set.
 	// The main loop waits for existing peers to be sent on srv.delpeer
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/reputation"
 	"github.com/ethereum/go-ethereum/p2p/rlpx"
 )
 
@@ -125,6 +126,48 @@ func TestServerListen(t *testing.T) {
 	}
 }
 
+// This test checks that discovery runs on both address families when DiscAddr6 is
+// set, and both endpoints are advertised.
+func TestServerDualStackDiscovery(t *testing.T) {
+	if conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: net.IPv6loopback}); err != nil {
+		t.Skip("IPv6 not available:", err)
+	} else {
+		conn.Close()
+	}
+	srv := &Server{Config: Config{
+		PrivateKey:  newkey(),
+		MaxPeers:    10,
+		ListenAddr:  "127.0.0.1:0",
+		DiscAddr6:   "[::1]:0",
+		DiscoveryV4: true,
+		DiscoveryV5: true,
+		Logger:      testlog.Logger(t, log.LvlTrace),
+	}}
+	if err := srv.Start(); err != nil {
+		t.Fatal("can't start server:", err)
+	}
+	defer srv.Stop()
+
+	var (
+		self = srv.Self()
+		ip6  enr.IPv6
+		udp  enr.UDP
+		udp6 enr.UDP6
+	)
+	if err := self.Load(&ip6); err != nil || !net.IP(ip6).Equal(net.IPv6loopback) {
+		t.Errorf("wrong IPv6 address in record: %v (err %v)", net.IP(ip6), err)
+	}
+	if err := self.Load(&udp); err != nil || udp == 0 {
+		t.Errorf("missing UDP port in record: %v", err)
+	}
+	if err := self.Load(&udp6); err != nil || udp6 == 0 {
+		t.Errorf("missing UDP6 port in record: %v", err)
+	}
+	if len(self.UDPEndpoints()) != 2 {
+		t.Errorf("wrong UDP endpoints %v", self.UDPEndpoints())
+	}
+}
+
 func TestServerDial(t *testing.T) {
 	// run a one-shot TCP server to handle the connection.
 	listener, err := net.Listen("tcp", "127.0.0.1:0")
@@ -310,6 +353,62 @@ func TestServerAtCap(t *testing.T) {
 	}
 }
 
+func TestServerBannedPeer(t *testing.T) {
+	trustedNode := newkey()
+	trustedID := enode.PubkeyToIDV4(&trustedNode.PublicKey)
+	srv := &Server{
+		Config: Config{
+			PrivateKey:   newkey(),
+			MaxPeers:     10,
+			NoDial:       true,
+			NoDiscovery:  true,
+			TrustedNodes: []*enode.Node{newNode(trustedID, "")},
+			Logger:       testlog.Logger(t, log.LvlTrace),
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start: %v", err)
+	}
+	defer srv.Stop()
+
+	newconn := func(id enode.ID) *conn {
+		fd, _ := net.Pipe()
+		tx := newTestTransport(&trustedNode.PublicKey, fd, nil)
+		node := enode.SignNull(new(enr.Record), id)
+		return &conn{fd: fd, transport: tx, flags: inboundConn, node: node, cont: make(chan error)}
+	}
+	ban := func(id enode.ID) {
+		for !srv.reputation.Banned(id) {
+			srv.reputation.Report(id, reputation.ProtocolViolation)
+		}
+	}
+
+	// Banned peers are rejected.
+	bannedID := randomID()
+	ban(bannedID)
+	if err := srv.checkpoint(newconn(bannedID), srv.checkpointPostHandshake); err != DiscUselessPeer {
+		t.Error("wrong error for banned peer:", err)
+	}
+	// Unless they are trusted.
+	ban(trustedID)
+	if err := srv.checkpoint(newconn(trustedID), srv.checkpointPostHandshake); err != nil {
+		t.Error("unexpected error for banned trusted peer:", err)
+	}
+	// The score is reported in the peer info.
+	c := newconn(randomID())
+	if err := srv.checkpoint(c, srv.checkpointAddPeer); err != nil {
+		t.Fatal("could not add conn:", err)
+	}
+	srv.reputation.Report(c.node.ID(), reputation.Timeout)
+	infos := srv.PeersInfo()
+	if len(infos) != 1 || infos[0].ID != c.node.ID().String() {
+		t.Fatalf("wrong peers: %v", infos)
+	}
+	if score := infos[0].Score; score > -4.9 || score < -5 {
+		t.Errorf("wrong score in peer info: %v", score)
+	}
+}
+
 func TestServerPeerLimits(t *testing.T) {
 	srvkey := newkey()
 	clientkey := newkey()
@@ -19,6 +19,8 @@ package rpc
 import (
 	"context"
 	"net/http"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 )
 
 type mdHeaderKey struct{}
@@ -41,9 +43,22 @@ func NewContextWithHeaders(ctx context.Context, h http.Header) context.Context {
 	return context.WithValue(ctx, mdHeaderKey{}, ctxh)
 }
 
-// headersFromContext is used to extract http.Header from context.
+// traceparentHeader is the W3C Trace Context header propagating the active span
+// between the client and the server.
+const traceparentHeader = "traceparent"
+
+// headersFromContext is used to extract http.Header from context. If a tracing
+// span is active in the context, it's propagated to the server too.
 func headersFromContext(ctx context.Context) http.Header {
 	source, _ := ctx.Value(mdHeaderKey{}).(http.Header)
+	if sc, ok := telemetry.SpanContextFromContext(ctx); ok {
+		if source == nil {
+			source = make(http.Header)
+		} else {
+			source = source.Clone()
+		}
+		source.Set(traceparentHeader, sc.Traceparent())
+	}
 	return source
 }
 
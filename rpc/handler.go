@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -509,7 +510,15 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, span := telemetry.StartSpanWithKind(cp.ctx, telemetry.KindServer, msg.Method,
+		telemetry.String("rpc.system", "jsonrpc"),
+		telemetry.String("rpc.method", msg.Method),
+	)
+	answer := h.runMethod(ctx, msg, callb, args)
+	if answer.Error != nil {
+		span.SetError(answer.Error)
+	}
+	span.End()
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -30,6 +30,8 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 )
 
 const (
@@ -326,6 +328,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
+	// Join the trace of the caller if it's propagated via the W3C Trace Context.
+	if sc, ok := telemetry.ParseTraceparent(r.Header.Get(traceparentHeader)); ok {
+		ctx = telemetry.ContextWithRemoteSpan(ctx, sc)
+	}
+
 	// All checks passed, create a codec that reads directly from the request body
 	// until EOF, writes the response to w, and orders the server to process a
 	// single request.
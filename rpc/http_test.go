@@ -23,6 +23,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/internal/telemetry"
 )
 
 func confirmStatusCode(t *testing.T, got, want int) {
@@ -243,3 +245,51 @@ func TestNewContextWithHeaders(t *testing.T) {
 		t.Error("call failed:", err)
 	}
 }
+
+type traceService struct{}
+
+func (traceService) SpanContext(ctx context.Context) string {
+	sc, _ := telemetry.SpanContextFromContext(ctx)
+	return sc.Traceparent()
+}
+
+// Tests that the trace context is propagated from the client to the server over
+// HTTP, and that the server traces the calls as children of the caller's span.
+func TestHTTPTraceContext(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer collector.Close()
+	stop := telemetry.Enable(telemetry.Config{Endpoint: collector.URL, SampleRatio: 1})
+	defer stop()
+
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("trace", traceService{}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	client, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	parent, _ := telemetry.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := telemetry.ContextWithRemoteSpan(context.Background(), parent)
+
+	var result string
+	if err := client.CallContext(ctx, &result, "trace_spanContext"); err != nil {
+		t.Fatal(err)
+	}
+	sc, ok := telemetry.ParseTraceparent(result)
+	if !ok {
+		t.Fatalf("invalid server span context: %q", result)
+	}
+	if sc.TraceID != parent.TraceID || !sc.Sampled {
+		t.Errorf("server span not joined to the caller trace: have %s, want trace %x", result, parent.TraceID)
+	}
+	if sc.SpanID == parent.SpanID {
+		t.Errorf("server call not traced in its own span")
+	}
+}
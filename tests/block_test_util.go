@@ -187,21 +187,27 @@ func (t *BlockTest) Run(snapshotter bool, scheme string, tracer vm.EVMLogger, po
 }
 
 func (t *BlockTest) genesis(config *params.ChainConfig) *core.Genesis {
+	return makeGenesis(config, &t.json.Genesis, t.json.Pre)
+}
+
+// makeGenesis assembles the genesis specification from the genesis header and
+// the pre-state of a test.
+func makeGenesis(config *params.ChainConfig, header *btHeader, alloc types.GenesisAlloc) *core.Genesis {
 	return &core.Genesis{
 		Config:        config,
-		Nonce:         t.json.Genesis.Nonce.Uint64(),
-		Timestamp:     t.json.Genesis.Timestamp,
-		ParentHash:    t.json.Genesis.ParentHash,
-		ExtraData:     t.json.Genesis.ExtraData,
-		GasLimit:      t.json.Genesis.GasLimit,
-		GasUsed:       t.json.Genesis.GasUsed,
-		Difficulty:    t.json.Genesis.Difficulty,
-		Mixhash:       t.json.Genesis.MixHash,
-		Coinbase:      t.json.Genesis.Coinbase,
-		Alloc:         t.json.Pre,
-		BaseFee:       t.json.Genesis.BaseFeePerGas,
-		BlobGasUsed:   t.json.Genesis.BlobGasUsed,
-		ExcessBlobGas: t.json.Genesis.ExcessBlobGas,
+		Nonce:         header.Nonce.Uint64(),
+		Timestamp:     header.Timestamp,
+		ParentHash:    header.ParentHash,
+		ExtraData:     header.ExtraData,
+		GasLimit:      header.GasLimit,
+		GasUsed:       header.GasUsed,
+		Difficulty:    header.Difficulty,
+		Mixhash:       header.MixHash,
+		Coinbase:      header.Coinbase,
+		Alloc:         alloc,
+		BaseFee:       header.BaseFeePerGas,
+		BlobGasUsed:   header.BlobGasUsed,
+		ExcessBlobGas: header.ExcessBlobGas,
 	}
 }
 
@@ -324,8 +330,13 @@ func validateHeader(h *btHeader, h2 *types.Header) error {
 }
 
 func (t *BlockTest) validatePostState(statedb *state.StateDB) error {
-	// validate post state accounts in test file against what we have in state db
-	for addr, acct := range t.json.Post {
+	return validatePostState(t.json.Post, statedb)
+}
+
+// validatePostState checks the accounts of the expected post state of a test
+// against the ones in the state database.
+func validatePostState(post types.GenesisAlloc, statedb *state.StateDB) error {
+	for addr, acct := range post {
 		// address is indirectly verified by the other fields, as it's the db key
 		code2 := statedb.GetCode(addr)
 		balance2 := statedb.GetBalance(addr).ToBig()
@@ -0,0 +1,192 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EngineClient delivers payloads to an execution client through the engine API.
+// The client is implemented by the caller, as the test package can't depend on
+// a full node.
+type EngineClient interface {
+	// NewPayload delivers a payload using the given version of engine_newPayload,
+	// returning the reported payload status or the error of the call.
+	NewPayload(version int, params []json.RawMessage) (string, error)
+
+	// ForkchoiceUpdated sets the head of the chain using the given version of
+	// engine_forkchoiceUpdated, returning the reported payload status.
+	ForkchoiceUpdated(version int, head common.Hash) (string, error)
+
+	// State returns the hash of the current head block and its state.
+	State() (common.Hash, *state.StateDB, error)
+
+	// Close terminates the execution client.
+	Close() error
+}
+
+// EngineTest checks the handling of blocks delivered through the engine API, as
+// in the blockchain_test_engine fixtures of the execution-spec-tests.
+type EngineTest struct {
+	json etJSON
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *EngineTest) UnmarshalJSON(in []byte) error {
+	return json.Unmarshal(in, &t.json)
+}
+
+type etJSON struct {
+	Genesis   btHeader              `json:"genesisBlockHeader"`
+	Pre       types.GenesisAlloc    `json:"pre"`
+	Post      types.GenesisAlloc    `json:"postState"`
+	BestBlock common.UnprefixedHash `json:"lastblockhash"`
+	Network   string                `json:"network"`
+	Payloads  []etPayload           `json:"engineNewPayloads"`
+}
+
+// etPayload is a payload delivered through the engine API, along with its
+// expected outcome.
+type etPayload struct {
+	Params                   []json.RawMessage   `json:"params"`
+	NewPayloadVersion        math.HexOrDecimal64 `json:"newPayloadVersion"`
+	ForkchoiceUpdatedVersion math.HexOrDecimal64 `json:"forkchoiceUpdatedVersion"`
+	ValidationError          string              `json:"validationError"`
+	ErrorCode                *etErrorCode        `json:"errorCode"`
+}
+
+// etErrorCode is the expected error code of an engine API call, encoded either
+// as a number or as a string.
+type etErrorCode int
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *etErrorCode) UnmarshalJSON(in []byte) error {
+	code, err := strconv.Atoi(strings.Trim(string(in), `"`))
+	if err != nil {
+		return fmt.Errorf("invalid error code %s: %v", in, err)
+	}
+	*c = etErrorCode(code)
+	return nil
+}
+
+// Network returns the fork the test is defined for.
+func (t *EngineTest) Network() string {
+	return t.json.Network
+}
+
+// Run executes the test against the execution client created by the connect
+// callback for the genesis of the test.
+func (t *EngineTest) Run(connect func(*core.Genesis) (EngineClient, error)) error {
+	config, ok := Forks[t.json.Network]
+	if !ok {
+		return UnsupportedForkError{t.json.Network}
+	}
+	client, err := connect(makeGenesis(config, &t.json.Genesis, t.json.Pre))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	head, _, err := client.State()
+	if err != nil {
+		return err
+	}
+	if head != t.json.Genesis.Hash {
+		return fmt.Errorf("genesis block hash doesn't match test: computed=%x, test=%x", head, t.json.Genesis.Hash)
+	}
+	for i, payload := range t.json.Payloads {
+		if err := t.deliver(client, &payload); err != nil {
+			return fmt.Errorf("payload %d: %w", i, err)
+		}
+	}
+	head, statedb, err := client.State()
+	if err != nil {
+		return err
+	}
+	if common.Hash(t.json.BestBlock) != head {
+		return fmt.Errorf("last block hash validation mismatch: want: %x, have: %x", t.json.BestBlock, head)
+	}
+	if err := validatePostState(t.json.Post, statedb); err != nil {
+		return fmt.Errorf("post state validation failed: %v", err)
+	}
+	return nil
+}
+
+// deliver sends a payload to the execution client and checks the outcome. Valid
+// payloads are made the head of the chain.
+func (t *EngineTest) deliver(client EngineClient, payload *etPayload) error {
+	version := int(payload.NewPayloadVersion)
+	status, err := client.NewPayload(version, payload.Params)
+	if payload.ErrorCode != nil {
+		var rpcErr interface{ ErrorCode() int }
+		if !errors.As(err, &rpcErr) {
+			return fmt.Errorf("expected error code %d, got %v (status %s)", *payload.ErrorCode, err, status)
+		}
+		if rpcErr.ErrorCode() != int(*payload.ErrorCode) {
+			return fmt.Errorf("error code mismatch: want %d, have %d (%v)", *payload.ErrorCode, rpcErr.ErrorCode(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("engine_newPayloadV%d failed: %v", version, err)
+	}
+	if payload.ValidationError != "" {
+		if status != engine.INVALID {
+			return fmt.Errorf("payload status %s, want %s due to %s", status, engine.INVALID, payload.ValidationError)
+		}
+		return nil
+	}
+	if status != engine.VALID {
+		return fmt.Errorf("payload status %s, want %s", status, engine.VALID)
+	}
+	var data struct {
+		BlockHash common.Hash `json:"blockHash"`
+	}
+	if len(payload.Params) == 0 {
+		return errors.New("missing execution payload")
+	}
+	if err := json.Unmarshal(payload.Params[0], &data); err != nil {
+		return fmt.Errorf("invalid execution payload: %v", err)
+	}
+	fcuVersion := int(payload.ForkchoiceUpdatedVersion)
+	if fcuVersion == 0 {
+		// Older fixtures don't specify the version, derive it from the payload
+		fcuVersion = version
+		if fcuVersion > 3 {
+			fcuVersion = 3
+		}
+	}
+	status, err = client.ForkchoiceUpdated(fcuVersion, data.BlockHash)
+	if err != nil {
+		return fmt.Errorf("engine_forkchoiceUpdatedV%d failed: %v", fcuVersion, err)
+	}
+	if status != engine.VALID {
+		return fmt.Errorf("forkchoice status %s, want %s", status, engine.VALID)
+	}
+	return nil
+}
@@ -51,12 +51,6 @@ func FuzzG1Add(f *testing.F) {
 	})
 }
 
-func FuzzG1Mul(f *testing.F) {
-	f.Fuzz(func(t *testing.T, data []byte) {
-		fuzz(blsG1Mul, data)
-	})
-}
-
 func FuzzG1MultiExp(f *testing.F) {
 	f.Fuzz(func(t *testing.T, data []byte) {
 		fuzz(blsG1MultiExp, data)
@@ -69,12 +63,6 @@ func FuzzG2Add(f *testing.F) {
 	})
 }
 
-func FuzzG2Mul(f *testing.F) {
-	f.Fuzz(func(t *testing.T, data []byte) {
-		fuzz(blsG2Mul, data)
-	})
-}
-
 func FuzzG2MultiExp(f *testing.F) {
 	f.Fuzz(func(t *testing.T, data []byte) {
 		fuzz(blsG2MultiExp, data)
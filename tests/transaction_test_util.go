@@ -17,12 +17,19 @@
 package tests
 
 import (
+	"errors"
 	"fmt"
+	gomath "math"
+	"math/big"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -55,7 +62,7 @@ func (tt *TransactionTest) Run(config *params.ChainConfig) error {
 			return nil, nil, err
 		}
 		// Intrinsic gas
-		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, isHomestead, isIstanbul, false)
+		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, isHomestead, isIstanbul, false)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -108,3 +115,172 @@ func (tt *TransactionTest) Run(config *params.ChainConfig) error {
 	}
 	return nil
 }
+
+// TxTest checks the validity, sender derivation and intrinsic gas of a
+// transaction across forks, as in the transaction fixtures of the
+// execution-spec-tests.
+type TxTest struct {
+	TxBytes hexutil.Bytes         `json:"txbytes"`
+	Result  map[string]*TxOutcome `json:"result"`
+}
+
+// TxOutcome is the expected outcome of validating a transaction in a fork.
+type TxOutcome struct {
+	Hash         *common.Hash        `json:"hash"`
+	Sender       *common.Address     `json:"sender"`
+	IntrinsicGas math.HexOrDecimal64 `json:"intrinsicGas"`
+	Exception    string              `json:"exception"`
+}
+
+// Run validates the transaction against the rules of the given fork, returning
+// an error if the outcome doesn't match the expected one.
+func (tt *TxTest) Run(fork string) error {
+	config, ok := Forks[fork]
+	if !ok {
+		return UnsupportedForkError{fork}
+	}
+	want, ok := tt.Result[fork]
+	if !ok {
+		return fmt.Errorf("no expected result for fork %s", fork)
+	}
+	tx, sender, err := validateTxBytes(config, tt.TxBytes)
+	if want.Exception != "" {
+		if err == nil {
+			return fmt.Errorf("expected error %s, got none (sender %v)", want.Exception, sender)
+		}
+		if !matchTxException(want.Exception, err) {
+			return fmt.Errorf("expected error %s, got %v", want.Exception, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unexpected error: %v", err)
+	}
+	if want.Sender != nil && *want.Sender != sender {
+		return fmt.Errorf("sender mismatch: got %x, want %x", sender, *want.Sender)
+	}
+	if want.Hash != nil && *want.Hash != tx.Hash() {
+		return fmt.Errorf("hash mismatch: got %x, want %x", tx.Hash(), *want.Hash)
+	}
+	if want.IntrinsicGas != 0 {
+		gas, err := txIntrinsicGas(config, tx)
+		if err != nil {
+			return err
+		}
+		if gas != uint64(want.IntrinsicGas) {
+			return fmt.Errorf("intrinsic gas mismatch: got %d, want %d", gas, uint64(want.IntrinsicGas))
+		}
+	}
+	return nil
+}
+
+// txExceptions maps the transaction exceptions of the execution-spec-tests to
+// the errors reported for them. Decoding failures aren't mapped to a specific
+// error, any error returned by the decoder satisfies them.
+var txExceptions = map[string][]error{
+	"INTRINSIC_GAS_TOO_LOW":                 {core.ErrIntrinsicGas},
+	"TYPE_NOT_SUPPORTED":                    {types.ErrTxTypeNotSupported},
+	"INITCODE_SIZE_EXCEEDED":                {core.ErrMaxInitCodeSizeExceeded},
+	"PRIORITY_GREATER_THAN_MAX_FEE_PER_GAS": {core.ErrTipAboveFeeCap},
+	"NONCE_IS_MAX":                          {core.ErrNonceMax},
+	"GAS_ALLOWANCE_EXCEEDED":                {core.ErrGasUintOverflow},
+	"INVALID_SIGNATURE_VRS":                 {types.ErrInvalidSig, types.ErrInvalidChainId},
+	"TYPE_3_TX_CONTRACT_CREATION":           {core.ErrBlobTxCreate},
+	"TYPE_3_TX_ZERO_BLOBS":                  {core.ErrMissingBlobHashes},
+	"TYPE_4_EMPTY_AUTHORIZATION_LIST":       {core.ErrEmptyAuthList},
+	"TYPE_4_TX_CONTRACT_CREATION":           {core.ErrSetCodeTxCreate},
+}
+
+// matchTxException reports whether err satisfies the expected exception, which
+// may list several alternatives separated by '|'. Exceptions without a known
+// error are accepted for any error.
+func matchTxException(exception string, err error) bool {
+	for _, name := range strings.Split(exception, "|") {
+		name = strings.TrimPrefix(strings.TrimSpace(name), "TransactionException.")
+		errs, ok := txExceptions[name]
+		if !ok {
+			return true
+		}
+		for _, want := range errs {
+			if errors.Is(err, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateTxBytes decodes a transaction and validates it against the rules of
+// the given chain configuration at genesis.
+func validateTxBytes(config *params.ChainConfig, txBytes []byte) (*types.Transaction, common.Address, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(txBytes); err != nil {
+		return nil, common.Address{}, err
+	}
+	head := &types.Header{Number: new(big.Int), GasLimit: gomath.MaxUint64}
+	signer := types.MakeSigner(config, head.Number, head.Time)
+
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	gas, err := txIntrinsicGas(config, tx)
+	if err != nil {
+		return nil, sender, err
+	}
+	if tx.Gas() < gas {
+		return nil, sender, fmt.Errorf("%w: needed %v, allowed %v", core.ErrIntrinsicGas, gas, tx.Gas())
+	}
+	// The pool validation assumes Homestead rules, which are also the earliest
+	// transaction fixtures are filled for. Blob sidecars are never part of the
+	// consensus encoding, so their validation is skipped.
+	if !config.IsHomestead(head.Number) {
+		return tx, sender, nil
+	}
+	if tx.Type() == types.BlobTxType && tx.BlobTxSidecar() == nil {
+		if err := validateBlobTx(tx); err != nil {
+			return nil, sender, err
+		}
+		return tx, sender, nil
+	}
+	opts := &txpool.ValidationOptions{
+		Config: config,
+		Accept: 1<<types.LegacyTxType | 1<<types.AccessListTxType | 1<<types.DynamicFeeTxType |
+			1<<types.BlobTxType | 1<<types.SetCodeTxType,
+		MaxSize: gomath.MaxUint64,
+		MinTip:  new(big.Int),
+	}
+	if err := txpool.ValidateTransaction(tx, head, signer, opts); err != nil {
+		return nil, sender, err
+	}
+	return tx, sender, nil
+}
+
+// txIntrinsicGas returns the intrinsic gas of a transaction under the rules of
+// the given chain configuration at genesis. As in the state tests, forks after
+// the merge are assumed to be post-merge.
+func txIntrinsicGas(config *params.ChainConfig, tx *types.Transaction) (uint64, error) {
+	rules := config.Rules(new(big.Int), true, 0)
+	return core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, rules.IsHomestead, rules.IsIstanbul, rules.IsShanghai)
+}
+
+// validateBlobTx checks the consensus rules of a blob transaction stripped of
+// its sidecar, which the pool validation can't be applied to.
+func validateBlobTx(tx *types.Transaction) error {
+	if tx.GasFeeCapIntCmp(tx.GasTipCap()) < 0 {
+		return core.ErrTipAboveFeeCap
+	}
+	hashes := tx.BlobHashes()
+	if len(hashes) == 0 {
+		return core.ErrMissingBlobHashes
+	}
+	if len(hashes) > params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob {
+		return fmt.Errorf("too many blobs in transaction: have %d, permitted %d", len(hashes), params.MaxBlobGasPerBlock/params.BlobTxBlobGasPerBlob)
+	}
+	for _, hash := range hashes {
+		if !kzg4844.IsValidVersionedHash(hash[:]) {
+			return errors.New("invalid blob versioned hash")
+		}
+	}
+	return nil
+}
@@ -241,6 +241,12 @@ func (t *StateTrie) Hash() common.Hash {
 	return t.trie.Hash()
 }
 
+// Witness returns a set containing all trie nodes that have been accessed
+// since the trie was opened or last committed.
+func (t *StateTrie) Witness() map[string]struct{} {
+	return t.trie.Witness()
+}
+
 // Copy returns a copy of StateTrie.
 func (t *StateTrie) Copy() *StateTrie {
 	return &StateTrie{
@@ -127,6 +127,12 @@ func (t *VerkleTrie) GetStorage(addr common.Address, key []byte) ([]byte, error)
 	return common.TrimLeftZeroes(val), nil
 }
 
+// GetLeaf retrieves the value of the leaf at the given tree key. If the leaf is
+// not in the verkle tree, nil will be returned.
+func (t *VerkleTrie) GetLeaf(key []byte) ([]byte, error) {
+	return t.root.Get(key, t.nodeResolver)
+}
+
 // UpdateAccount implements state.Trie, writing the provided account into the tree.
 // If the tree is corrupted, an error will be returned.
 func (t *VerkleTrie) UpdateAccount(addr common.Address, acc *types.StateAccount) error {
@@ -256,6 +262,13 @@ func (t *VerkleTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
 	panic("not implemented")
 }
 
+// Witness returns a set containing all trie nodes that have been accessed.
+//
+// TODO(gballet, rjl493456442) implement it.
+func (t *VerkleTrie) Witness() map[string]struct{} {
+	panic("not implemented")
+}
+
 // Copy returns a deep-copied verkle tree.
 func (t *VerkleTrie) Copy() *VerkleTrie {
 	return &VerkleTrie{
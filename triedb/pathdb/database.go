@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"github.com/gballet/go-verkle"
 )
 
 const (
@@ -86,10 +87,11 @@ type layer interface {
 
 // Config contains the settings for database.
 type Config struct {
-	StateHistory   uint64 // Number of recent blocks to maintain state history for
-	CleanCacheSize int    // Maximum memory allowance (in bytes) for caching clean nodes
-	DirtyCacheSize int    // Maximum memory allowance (in bytes) for caching dirty nodes
-	ReadOnly       bool   // Flag whether the database is opened in read only mode.
+	StateHistory        uint64 // Number of recent blocks to maintain state history for
+	CleanCacheSize      int    // Maximum memory allowance (in bytes) for caching clean nodes
+	DirtyCacheSize      int    // Maximum memory allowance (in bytes) for caching dirty nodes
+	ReadOnly            bool   // Flag whether the database is opened in read only mode.
+	EnableStateIndexing bool   // Flag whether the state histories are indexed for serving historical state
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -130,18 +132,20 @@ type Database struct {
 	// the shutdown to reject all following unexpected mutations.
 	readOnly   bool                     // Flag if database is opened in read only mode
 	waitSync   bool                     // Flag if database is deactivated due to initial state sync
+	isVerkle   bool                     // Flag if database is used for verkle tree
 	bufferSize int                      // Memory allowance (in bytes) for caching dirty nodes
 	config     *Config                  // Configuration for database
 	diskdb     ethdb.Database           // Persistent storage for matured trie nodes
 	tree       *layerTree               // The group for all known layers
 	freezer    *rawdb.ResettableFreezer // Freezer for storing trie histories, nil possible in tests
+	indexer    *historyIndexer          // Indexer of state histories, nil if indexing is disabled
 	lock       sync.RWMutex             // Lock to prevent mutations from happening at the same time
 }
 
 // New attempts to load an already existing layer from a persistent key-value
 // store (with a number of memory layers from a journal). If the journal is not
 // matched with the base persistent layer, all the recorded diff layers are discarded.
-func New(diskdb ethdb.Database, config *Config) *Database {
+func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	if config == nil {
 		config = Defaults
 	}
@@ -152,6 +156,15 @@ func New(diskdb ethdb.Database, config *Config) *Database {
 		bufferSize: config.DirtyCacheSize,
 		config:     config,
 		diskdb:     diskdb,
+		isVerkle:   isVerkle,
+	}
+	// Establish a dedicated database namespace tailored for verkle-specific
+	// data, ensuring the isolation of both verkle and merkle tree data. It's
+	// important to note that the introduction of a prefix won't lead to
+	// substantial storage overhead, as the underlying database will efficiently
+	// compress the shared key prefix.
+	if isVerkle {
+		db.diskdb = rawdb.NewTable(diskdb, string(rawdb.VerklePrefix))
 	}
 	// Construct the layer tree by resolving the in-disk singleton state
 	// and in-memory layer journal.
@@ -163,7 +176,11 @@ func New(diskdb ethdb.Database, config *Config) *Database {
 	// Because the freezer can only be opened once at the same time, this
 	// mechanism also ensures that at most one **non-readOnly** database
 	// is opened at the same time to prevent accidental mutation.
-	if ancient, err := diskdb.AncientDatadir(); err == nil && ancient != "" && !db.readOnly {
+	//
+	// State histories are not maintained for verkle yet, as the storage
+	// slots are keyed by hash in the history and can't be mapped back to
+	// the tree keys for reverting.
+	if ancient, err := diskdb.AncientDatadir(); err == nil && ancient != "" && !db.readOnly && !isVerkle {
 		freezer, err := rawdb.NewStateFreezer(ancient, false)
 		if err != nil {
 			log.Crit("Failed to open state history freezer", "err", err)
@@ -196,9 +213,14 @@ func New(diskdb ethdb.Database, config *Config) *Database {
 				log.Warn("Truncated extra state histories", "number", pruned)
 			}
 		}
+		// Index the state histories for serving historical state if required.
+		if config.EnableStateIndexing {
+			db.indexer = newHistoryIndexer(db.diskdb, freezer)
+			log.Info("Enabled state history indexing", "indexed", db.indexer.indexed())
+		}
 	}
 	// Disable database in case node is still in the initial state sync stage.
-	if rawdb.ReadSnapSyncStatusFlag(diskdb) == rawdb.StateSyncRunning && !db.readOnly {
+	if rawdb.ReadSnapSyncStatusFlag(db.diskdb) == rawdb.StateSyncRunning && !db.readOnly {
 		if err := db.Disable(); err != nil {
 			log.Crit("Failed to disable database", "err", err) // impossible to happen
 		}
@@ -297,7 +319,7 @@ func (db *Database) Enable(root common.Hash) error {
 	}
 	// Ensure the provided state root matches the stored one.
 	root = types.TrieRootHash(root)
-	_, stored := rawdb.ReadAccountTrieNode(db.diskdb, nil)
+	stored := types.TrieRootHash(db.diskRoot())
 	if stored != root {
 		return fmt.Errorf("state root mismatch: stored %x, synced %x", stored, root)
 	}
@@ -313,7 +335,11 @@ func (db *Database) Enable(root common.Hash) error {
 	// all root->id mappings should be removed as well. Since
 	// mappings can be huge and might take a while to clear
 	// them, just leave them in disk and wait for overwriting.
-	if db.freezer != nil {
+	if db.indexer != nil {
+		if err := db.indexer.reset(); err != nil {
+			return err
+		}
+	} else if db.freezer != nil {
 		if err := db.freezer.Reset(); err != nil {
 			return err
 		}
@@ -368,7 +394,7 @@ func (db *Database) Recover(root common.Hash, loader triestate.TrieLoader) error
 		db.tree.reset(dl)
 	}
 	rawdb.DeleteTrieJournal(db.diskdb)
-	_, err := truncateFromHead(db.diskdb, db.freezer, dl.stateID())
+	_, err := db.truncateHistoryHead(dl.stateID())
 	if err != nil {
 		return err
 	}
@@ -378,6 +404,10 @@ func (db *Database) Recover(root common.Hash, loader triestate.TrieLoader) error
 
 // Recoverable returns the indicator if the specified state is recoverable.
 func (db *Database) Recoverable(root common.Hash) bool {
+	// State histories are not available, e.g. in verkle mode.
+	if db.freezer == nil {
+		return false
+	}
 	// Ensure the requested state is a known state.
 	root = types.TrieRootHash(root)
 	id := rawdb.ReadStateID(db.diskdb, root)
@@ -418,6 +448,10 @@ func (db *Database) Close() error {
 	// Release the memory held by clean cache.
 	db.tree.bottom().resetCache()
 
+	// Terminate the background state history indexing.
+	if db.indexer != nil {
+		db.indexer.close()
+	}
 	// Close the attached state history freezer.
 	if db.freezer == nil {
 		return nil
@@ -425,6 +459,24 @@ func (db *Database) Close() error {
 	return db.freezer.Close()
 }
 
+// truncateHistoryHead removes the extra state histories from the head, along
+// with their index if indexing is enabled.
+func (db *Database) truncateHistoryHead(nhead uint64) (int, error) {
+	if db.indexer != nil {
+		return db.indexer.truncateHead(db.diskdb, nhead)
+	}
+	return truncateFromHead(db.diskdb, db.freezer, nhead)
+}
+
+// truncateHistoryTail removes the oldest state histories from the tail, along
+// with their index if indexing is enabled.
+func (db *Database) truncateHistoryTail(ntail uint64) (int, error) {
+	if db.indexer != nil {
+		return db.indexer.truncateTail(db.diskdb, ntail)
+	}
+	return truncateFromTail(db.diskdb, db.freezer, ntail)
+}
+
 // Size returns the current storage size of the memory cache in front of the
 // persistent database layer.
 func (db *Database) Size() (diffs common.StorageSize, nodes common.StorageSize) {
@@ -454,6 +506,31 @@ func (db *Database) Initialized(genesisRoot common.Hash) bool {
 	return inited
 }
 
+// diskRoot returns the root hash of the persistent state. It's the keccak256
+// hash of the root node in merkle mode and the root commitment in verkle mode.
+func (db *Database) diskRoot() common.Hash {
+	blob, root := rawdb.ReadAccountTrieNode(db.diskdb, nil)
+	if !db.isVerkle || len(blob) == 0 {
+		return root
+	}
+	node, err := verkle.ParseNode(blob, 0)
+	if err != nil {
+		log.Crit("Failed to parse verkle root node", "err", err)
+	}
+	return node.Commit().Bytes()
+}
+
+// nodeHash returns the hash of the provided trie node blob. Verkle nodes are
+// addressed by path only and are tracked with the zero hash instead.
+func (db *Database) nodeHash(blob []byte) common.Hash {
+	if db.isVerkle {
+		return common.Hash{}
+	}
+	h := newHasher()
+	defer h.release()
+	return h.hash(blob)
+}
+
 // SetBufferSize sets the node buffer size to the provided value(in bytes).
 func (db *Database) SetBufferSize(size int) error {
 	db.lock.Lock()
@@ -0,0 +1,194 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// The state history index records, for each account and storage slot, the ids
+// of the state histories in which the item was modified. It's used to locate
+// the state history containing the value of an item at a specific historical
+// state without scanning through all the histories.
+//
+// The index of a single item is a sorted list of history ids, split into a set
+// of index blocks. Each block contains at most indexBlockEntries ids and is
+// keyed by the item identifier along with the id of the last history it holds.
+// The most recent block is still being filled and is therefore keyed by the
+// maximum uint64 value, it gets sealed with its real key once it's full.
+//
+//   +----------------------+  +----------------------+       +----------------------+
+//   | ident + last id (#1) |  | ident + last id (#2) |  ...  | ident + MaxUint64    |
+//   +----------------------+  +----------------------+       +----------------------+
+//
+// With this layout, the first history after a given id can be located by one
+// iterator seek: the first block whose key is not lower than the id+1 is the
+// one containing the requested history.
+
+const (
+	// indexBlockEntries is the maximum number of history ids held by a single
+	// index block.
+	indexBlockEntries = 2048
+
+	// openBlockID is the key suffix of the index block which is still being
+	// filled.
+	openBlockID = math.MaxUint64
+)
+
+// encodeIndexBlock packs the given history ids into a byte stream.
+func encodeIndexBlock(ids []uint64) []byte {
+	buf := make([]byte, 8*len(ids))
+	for i, id := range ids {
+		binary.BigEndian.PutUint64(buf[8*i:], id)
+	}
+	return buf
+}
+
+// decodeIndexBlock unpacks the history ids from the given byte stream.
+func decodeIndexBlock(blob []byte) ([]uint64, error) {
+	if len(blob)%8 != 0 {
+		return nil, fmt.Errorf("corrupted index block, len: %d", len(blob))
+	}
+	ids := make([]uint64, len(blob)/8)
+	for i := range ids {
+		ids[i] = binary.BigEndian.Uint64(blob[8*i:])
+	}
+	return ids, nil
+}
+
+// appendIndex appends the history id to the index of the item with given
+// identifier. The history ids must be appended in ascending order.
+func appendIndex(db ethdb.KeyValueReader, batch ethdb.KeyValueWriter, ident []byte, id uint64) error {
+	ids, err := decodeIndexBlock(rawdb.ReadStateHistoryIndexBlock(db, ident, openBlockID))
+	if err != nil {
+		return err
+	}
+	if n := len(ids); n > 0 && ids[n-1] >= id {
+		return fmt.Errorf("history index out of order, last: %d, new: %d", ids[n-1], id)
+	}
+	ids = append(ids, id)
+	if len(ids) < indexBlockEntries {
+		rawdb.WriteStateHistoryIndexBlock(batch, ident, openBlockID, encodeIndexBlock(ids))
+		return nil
+	}
+	// The block is full, seal it with its real key
+	rawdb.DeleteStateHistoryIndexBlock(batch, ident, openBlockID)
+	rawdb.WriteStateHistoryIndexBlock(batch, ident, id, encodeIndexBlock(ids))
+	return nil
+}
+
+// removeLastIndex removes the history id from the index of the item with given
+// identifier. The history id must be the last one in the index.
+func removeLastIndex(db ethdb.KeyValueReader, batch ethdb.KeyValueWriter, ident []byte, id uint64) error {
+	ids, err := decodeIndexBlock(rawdb.ReadStateHistoryIndexBlock(db, ident, openBlockID))
+	if err != nil {
+		return err
+	}
+	if n := len(ids); n > 0 {
+		if ids[n-1] != id {
+			return fmt.Errorf("history index mismatch, last: %d, removed: %d", ids[n-1], id)
+		}
+		if n == 1 {
+			rawdb.DeleteStateHistoryIndexBlock(batch, ident, openBlockID)
+		} else {
+			rawdb.WriteStateHistoryIndexBlock(batch, ident, openBlockID, encodeIndexBlock(ids[:n-1]))
+		}
+		return nil
+	}
+	// The open block is not existent, the history id must be the last
+	// one of a sealed block. Reopen it with the remaining ids.
+	ids, err = decodeIndexBlock(rawdb.ReadStateHistoryIndexBlock(db, ident, id))
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("history index not found, removed: %d", id)
+	}
+	rawdb.DeleteStateHistoryIndexBlock(batch, ident, id)
+	if len(ids) > 1 {
+		rawdb.WriteStateHistoryIndexBlock(batch, ident, openBlockID, encodeIndexBlock(ids[:len(ids)-1]))
+	}
+	return nil
+}
+
+// removeIndexBelow removes all the history ids which are not greater than the
+// given one from the index of the item with given identifier.
+func removeIndexBelow(db ethdb.Iteratee, batch ethdb.KeyValueWriter, ident []byte, id uint64) error {
+	it := db.NewIterator(ident, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(ident)+8 {
+			continue
+		}
+		ids, err := decodeIndexBlock(it.Value())
+		if err != nil {
+			return err
+		}
+		last := binary.BigEndian.Uint64(key[len(ident):])
+		n := sort.Search(len(ids), func(i int) bool { return ids[i] > id })
+		switch {
+		case n == 0:
+			return nil // nothing to remove
+		case n == len(ids):
+			rawdb.DeleteStateHistoryIndexBlock(batch, ident, last)
+		default:
+			rawdb.WriteStateHistoryIndexBlock(batch, ident, last, encodeIndexBlock(ids[n:]))
+			return nil
+		}
+	}
+	return it.Error()
+}
+
+// findIndex returns the id of the first history after the given one in which
+// the item with given identifier was modified. The flag is false if there is
+// no such history.
+func findIndex(db ethdb.Iteratee, ident []byte, after uint64) (uint64, bool, error) {
+	if after == math.MaxUint64 {
+		return 0, false, nil
+	}
+	var start [8]byte
+	binary.BigEndian.PutUint64(start[:], after+1)
+
+	it := db.NewIterator(ident, start[:])
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(ident)+8 {
+			continue
+		}
+		ids, err := decodeIndexBlock(it.Value())
+		if err != nil {
+			return 0, false, err
+		}
+		n := sort.Search(len(ids), func(i int) bool { return ids[i] > after })
+		if n == len(ids) {
+			// Only the open block can be in this state, which means
+			// the item is not modified after the given history.
+			return 0, false, nil
+		}
+		return ids[n], true, nil
+	}
+	return 0, false, it.Error()
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/trie/testutil"
+)
+
+// checkIndex ensures the first history after each id is located correctly
+// within the given list of indexed history ids.
+func checkIndex(t *testing.T, db ethdb.Iteratee, ident []byte, ids []uint64) {
+	t.Helper()
+
+	var max uint64
+	if len(ids) > 0 {
+		max = ids[len(ids)-1]
+	}
+	pos := 0
+	for after := uint64(0); after <= max+1; after++ {
+		for pos < len(ids) && ids[pos] <= after {
+			pos++
+		}
+		id, found, err := findIndex(db, ident, after)
+		if err != nil {
+			t.Fatalf("Failed to find index, after: %d, err: %v", after, err)
+		}
+		if pos == len(ids) {
+			if found {
+				t.Fatalf("Unexpected index, after: %d, got: %d", after, id)
+			}
+			continue
+		}
+		if !found || id != ids[pos] {
+			t.Fatalf("Unexpected index, after: %d, want: %d, got: %d (%t)", after, ids[pos], id, found)
+		}
+	}
+}
+
+func TestHistoryIndex(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		ident = rawdb.AccountHistoryIndexPrefix(testutil.RandomAddress())
+		other = rawdb.AccountHistoryIndexPrefix(testutil.RandomAddress())
+		ids   []uint64
+	)
+	// Fill up a few index blocks, interleaved with an unrelated index.
+	for id := uint64(1); id <= 3*indexBlockEntries+10; id += 2 {
+		batch := db.NewBatch()
+		if err := appendIndex(db, batch, ident, id); err != nil {
+			t.Fatalf("Failed to append index: %v", err)
+		}
+		if err := appendIndex(db, batch, other, id+1); err != nil {
+			t.Fatalf("Failed to append index: %v", err)
+		}
+		batch.Write()
+		ids = append(ids, id)
+	}
+	checkIndex(t, db, ident, ids)
+
+	// Appending out of order must be rejected.
+	if err := appendIndex(db, db.NewBatch(), ident, ids[len(ids)-1]); err == nil {
+		t.Fatal("Out of order index is accepted")
+	}
+	// Remove the ids from the head across the block boundary.
+	for i := 0; i < indexBlockEntries/2+10; i++ {
+		batch := db.NewBatch()
+		if err := removeLastIndex(db, batch, ident, ids[len(ids)-1]); err != nil {
+			t.Fatalf("Failed to remove index: %v", err)
+		}
+		batch.Write()
+		ids = ids[:len(ids)-1]
+	}
+	checkIndex(t, db, ident, ids)
+
+	// Extend the index again, and prune the ids from the tail.
+	last := ids[len(ids)-1]
+	for id := last + 1; id < last+100; id++ {
+		batch := db.NewBatch()
+		if err := appendIndex(db, batch, ident, id); err != nil {
+			t.Fatalf("Failed to append index: %v", err)
+		}
+		batch.Write()
+		ids = append(ids, id)
+	}
+	for _, limit := range []int{10, indexBlockEntries / 2, 500} {
+		batch := db.NewBatch()
+		if err := removeIndexBelow(db, batch, ident, ids[limit]); err != nil {
+			t.Fatalf("Failed to prune index: %v", err)
+		}
+		batch.Write()
+		ids = ids[limit+1:]
+		checkIndex(t, db, ident, ids)
+	}
+}
+
+func TestHistoryIndexWipe(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		address = testutil.RandomAddress()
+		idents  = [][]byte{
+			rawdb.AccountHistoryIndexPrefix(address),
+			rawdb.StorageHistoryIndexPrefix(address, testutil.RandomHash()),
+			rawdb.IncompleteHistoryIndexPrefix(address),
+		}
+		// Unrelated data sharing the index prefixes must be retained.
+		unrelated = [][]byte{
+			[]byte("metadata"),
+			append(common.CopyBytes(rawdb.StateHistoryAccountIndexPrefix), 0x01),
+		}
+	)
+	batch := db.NewBatch()
+	for _, ident := range idents {
+		if err := appendIndex(db, batch, ident, 1); err != nil {
+			t.Fatalf("Failed to append index: %v", err)
+		}
+	}
+	storeIndexMetadata(batch, 1)
+	for _, key := range unrelated {
+		batch.Put(key, []byte{0x1})
+	}
+	batch.Write()
+
+	indexer := &historyIndexer{disk: db}
+	indexer.last.Store(1)
+	if err := indexer.wipe(); err != nil {
+		t.Fatalf("Failed to wipe index: %v", err)
+	}
+	for _, ident := range idents {
+		checkIndex(t, db, ident, nil)
+	}
+	if blob := rawdb.ReadStateHistoryIndexMetadata(db); len(blob) != 0 {
+		t.Fatalf("Index metadata is not deleted: %x", blob)
+	}
+	for _, key := range unrelated {
+		if ok, _ := db.Has(key); !ok {
+			t.Fatalf("Unrelated key %x is deleted", key)
+		}
+	}
+	if last := indexer.last.Load(); last != 0 {
+		t.Fatalf("Unexpected indexing progress: have %d, want 0", last)
+	}
+}
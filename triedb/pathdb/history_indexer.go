@@ -0,0 +1,392 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// stateIndexVersion is the version of the state history index format.
+const stateIndexVersion = uint8(0)
+
+// indexMetadata describes the indexing progress of state histories.
+type indexMetadata struct {
+	Version uint8
+	Last    uint64 // The id of the last indexed state history, zero if none
+}
+
+// loadIndexMetadata retrieves the indexing progress from the database. Nil is
+// returned if the index is not existent or not compatible.
+func loadIndexMetadata(db ethdb.KeyValueReader) *indexMetadata {
+	blob := rawdb.ReadStateHistoryIndexMetadata(db)
+	if len(blob) == 0 {
+		return nil
+	}
+	var m indexMetadata
+	if err := rlp.DecodeBytes(blob, &m); err != nil {
+		log.Error("Failed to decode state history index metadata", "err", err)
+		return nil
+	}
+	if m.Version != stateIndexVersion {
+		return nil
+	}
+	return &m
+}
+
+// storeIndexMetadata writes the indexing progress into the database.
+func storeIndexMetadata(db ethdb.KeyValueWriter, last uint64) {
+	blob, err := rlp.EncodeToBytes(&indexMetadata{Version: stateIndexVersion, Last: last})
+	if err != nil {
+		panic(err) // can't happen
+	}
+	rawdb.WriteStateHistoryIndexMetadata(db, blob)
+}
+
+// historyIdents returns the identifiers of all the items modified in the given
+// state history.
+func historyIdents(h *history) [][]byte {
+	var idents [][]byte
+	for _, addr := range h.accountList {
+		idents = append(idents, rawdb.AccountHistoryIndexPrefix(addr))
+		for _, slot := range h.storageList[addr] {
+			idents = append(idents, rawdb.StorageHistoryIndexPrefix(addr, slot))
+		}
+	}
+	for _, addr := range h.meta.incomplete {
+		idents = append(idents, rawdb.IncompleteHistoryIndexPrefix(addr))
+	}
+	return idents
+}
+
+// indexSingle adds the items modified in the specified state history into the
+// index, along with updating the indexing progress atomically.
+func indexSingle(id uint64, db ethdb.KeyValueStore, freezer *rawdb.ResettableFreezer) error {
+	h, err := readHistory(freezer, id)
+	if err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	for _, ident := range historyIdents(h) {
+		if err := appendIndex(db, batch, ident, id); err != nil {
+			return err
+		}
+	}
+	storeIndexMetadata(batch, id)
+	return batch.Write()
+}
+
+// unindexSingle removes the items modified in the specified state history from
+// the index, along with updating the indexing progress atomically. The state
+// history must be the last indexed one.
+func unindexSingle(id uint64, db ethdb.KeyValueStore, freezer *rawdb.ResettableFreezer) error {
+	h, err := readHistory(freezer, id)
+	if err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	for _, ident := range historyIdents(h) {
+		if err := removeLastIndex(db, batch, ident, id); err != nil {
+			return err
+		}
+	}
+	storeIndexMetadata(batch, id-1)
+	return batch.Write()
+}
+
+// pruneSingle removes the items modified in the specified state history from
+// the index, along with the ones from any older history which was left over.
+func pruneSingle(id uint64, db ethdb.KeyValueStore, freezer *rawdb.ResettableFreezer) error {
+	h, err := readHistory(freezer, id)
+	if err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	for _, ident := range historyIdents(h) {
+		if err := removeIndexBelow(db, batch, ident, id); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// historyIndexer maintains the index of state histories. The newly persisted
+// state histories are indexed in the background, while the truncation of the
+// histories is reflected in the index synchronously.
+type historyIndexer struct {
+	disk    ethdb.KeyValueStore
+	freezer *rawdb.ResettableFreezer
+	lock    sync.Mutex    // Lock for mutating the index
+	last    atomic.Uint64 // The id of the last indexed state history
+
+	notify chan struct{}
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newHistoryIndexer constructs the history indexer and starts indexing the
+// state histories which are not yet indexed in the background.
+func newHistoryIndexer(disk ethdb.KeyValueStore, freezer *rawdb.ResettableFreezer) *historyIndexer {
+	indexer := &historyIndexer{
+		disk:    disk,
+		freezer: freezer,
+		notify:  make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	if m := loadIndexMetadata(disk); m != nil {
+		indexer.last.Store(m.Last)
+	} else {
+		// The index is either not existent or not compatible,
+		// rebuild it from scratch.
+		if err := indexer.wipe(); err != nil {
+			log.Crit("Failed to wipe state history index", "err", err)
+		}
+	}
+	// Drop the index of state histories which are already truncated from
+	// the head, e.g. when the histories were repaired in the last startup.
+	head, err := freezer.Ancients()
+	if err != nil {
+		log.Crit("Failed to retrieve head of state history", "err", err)
+	}
+	if indexer.last.Load() > head {
+		log.Warn("Dropping dangling state history index", "indexed", indexer.last.Load(), "head", head)
+		if err := indexer.wipe(); err != nil {
+			log.Crit("Failed to wipe state history index", "err", err)
+		}
+	}
+	indexer.wg.Add(1)
+	go indexer.loop()
+	indexer.extend()
+	return indexer
+}
+
+// close terminates the background indexing.
+func (i *historyIndexer) close() {
+	select {
+	case <-i.closed:
+	default:
+		close(i.closed)
+	}
+	i.wg.Wait()
+}
+
+// extend notifies the indexer that new state histories are available.
+func (i *historyIndexer) extend() {
+	select {
+	case i.notify <- struct{}{}:
+	default:
+	}
+}
+
+// indexed returns the id of the last indexed state history.
+func (i *historyIndexer) indexed() uint64 {
+	return i.last.Load()
+}
+
+// loop indexes the newly added state histories whenever notified.
+func (i *historyIndexer) loop() {
+	defer i.wg.Done()
+
+	for {
+		select {
+		case <-i.notify:
+			if err := i.catchUp(); err != nil {
+				log.Error("Failed to index state history", "err", err)
+			}
+		case <-i.closed:
+			return
+		}
+	}
+}
+
+// catchUp indexes all the state histories which are not yet indexed.
+func (i *historyIndexer) catchUp() error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		indexed int
+	)
+	for {
+		select {
+		case <-i.closed:
+			return nil
+		default:
+		}
+		done, err := i.indexNext()
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+		indexed++
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing state history", "indexed", indexed, "last", i.indexed(), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if indexed > 0 {
+		log.Debug("Indexed state history", "items", indexed, "last", i.indexed(), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
+// indexNext indexes the next state history, returning the flag whether there
+// are no more histories to index.
+func (i *historyIndexer) indexNext() (bool, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	head, err := i.freezer.Ancients()
+	if err != nil {
+		return false, err
+	}
+	tail, err := i.freezer.Tail()
+	if err != nil {
+		return false, err
+	}
+	last := i.last.Load()
+	if last >= head {
+		return true, nil
+	}
+	// The histories between the last indexed one and the tail were pruned
+	// before being indexed, skip them.
+	if last < tail {
+		last = tail
+	}
+	if err := indexSingle(last+1, i.disk, i.freezer); err != nil {
+		return false, err
+	}
+	i.last.Store(last + 1)
+	return false, nil
+}
+
+// truncateHead removes the state histories above the given id from the head,
+// dropping their index beforehand.
+func (i *historyIndexer) truncateHead(db ethdb.Batcher, nhead uint64) (int, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	tail, err := i.freezer.Tail()
+	if err != nil {
+		return 0, err
+	}
+	for last := i.last.Load(); last > nhead; last-- {
+		if last <= tail {
+			// The remaining indexed histories were all pruned, wipe the
+			// leftovers of them.
+			if err := i.wipe(); err != nil {
+				return 0, err
+			}
+			break
+		}
+		if err := unindexSingle(last, i.disk, i.freezer); err != nil {
+			return 0, err
+		}
+		i.last.Store(last - 1)
+	}
+	return truncateFromHead(db, i.freezer, nhead)
+}
+
+// truncateTail removes the state histories up to the given id from the tail,
+// dropping their index beforehand.
+func (i *historyIndexer) truncateTail(db ethdb.Batcher, ntail uint64) (int, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	otail, err := i.freezer.Tail()
+	if err != nil {
+		return 0, err
+	}
+	for id := otail + 1; id <= ntail && id <= i.last.Load(); id++ {
+		if err := pruneSingle(id, i.disk, i.freezer); err != nil {
+			return 0, err
+		}
+	}
+	return truncateFromTail(db, i.freezer, ntail)
+}
+
+// reset drops the entire index along with all the state histories.
+func (i *historyIndexer) reset() error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if err := i.wipe(); err != nil {
+		return err
+	}
+	return i.freezer.Reset()
+}
+
+// wipe removes the entire index from the database. The caller must either hold
+// the lock or be the only user of the indexer.
+func (i *historyIndexer) wipe() error {
+	batch := i.disk.NewBatch()
+	for _, table := range []struct {
+		prefix []byte
+		length int
+	}{
+		{rawdb.StateHistoryAccountIndexPrefix, len(rawdb.StateHistoryAccountIndexPrefix) + common.AddressLength + 8},
+		{rawdb.StateHistoryStorageIndexPrefix, len(rawdb.StateHistoryStorageIndexPrefix) + common.AddressLength + common.HashLength + 8},
+		{rawdb.StateHistoryIncompleteIndexPrefix, len(rawdb.StateHistoryIncompleteIndexPrefix) + common.AddressLength + 8},
+	} {
+		if err := wipeIndexBlocks(i.disk, batch, table.prefix, table.length); err != nil {
+			return err
+		}
+	}
+	rawdb.DeleteStateHistoryIndexMetadata(batch)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	i.last.Store(0)
+	return nil
+}
+
+// wipeIndexBlocks deletes all the index blocks with the given prefix. Only the
+// keys of the expected length are removed, leaving any other data sharing the
+// prefix untouched.
+func wipeIndexBlocks(db ethdb.Iteratee, batch ethdb.Batch, prefix []byte, length int) error {
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != length {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return it.Error()
+}
+
+// errHistoryNotIndexed is returned if the requested state history is not yet
+// indexed.
+var errHistoryNotIndexed = errors.New("state history is not yet indexed")
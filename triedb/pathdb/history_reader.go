@@ -0,0 +1,266 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxUnindexedHistories is the maximum number of state histories which are
+// not yet indexed, but can be scanned through when serving historical state.
+const maxUnindexedHistories = 128
+
+var (
+	// errStateIndexDisabled is returned if the historical state is requested
+	// but the state history indexing is not enabled.
+	errStateIndexDisabled = errors.New("state history indexing is disabled")
+
+	// errIncompleteHistory is returned if the requested storage was wiped by
+	// a destruction too large to be recorded in the state history.
+	errIncompleteHistory = errors.New("incomplete state history")
+)
+
+// readHistoryAccount retrieves the account data from the specified state
+// history. The flag is false if the account was not modified in it.
+func readHistoryAccount(freezer *rawdb.ResettableFreezer, id uint64, address common.Address) (*accountIndex, []byte, bool, error) {
+	indexes := rawdb.ReadStateAccountIndex(freezer, id)
+	if len(indexes) == 0 || len(indexes)%accountIndexSize != 0 {
+		return nil, nil, false, fmt.Errorf("state history %d is corrupted or not found", id)
+	}
+	var (
+		n   = len(indexes) / accountIndexSize
+		pos = sort.Search(n, func(i int) bool {
+			return bytes.Compare(indexes[i*accountIndexSize:i*accountIndexSize+common.AddressLength], address.Bytes()) >= 0
+		})
+	)
+	if pos == n || !bytes.Equal(indexes[pos*accountIndexSize:pos*accountIndexSize+common.AddressLength], address.Bytes()) {
+		return nil, nil, false, nil
+	}
+	var index accountIndex
+	index.decode(indexes[pos*accountIndexSize : (pos+1)*accountIndexSize])
+
+	data := rawdb.ReadStateAccountHistory(freezer, id)
+	if uint32(len(data)) < index.offset+uint32(index.length) {
+		return nil, nil, false, fmt.Errorf("account data of state history %d is corrupted", id)
+	}
+	return &index, data[index.offset : index.offset+uint32(index.length)], true, nil
+}
+
+// readHistoryStorage retrieves the storage slot data from the specified state
+// history. The flag is false if the slot was not modified in it.
+func readHistoryStorage(freezer *rawdb.ResettableFreezer, id uint64, address common.Address, slotHash common.Hash) ([]byte, bool, error) {
+	accIndex, _, found, err := readHistoryAccount(freezer, id, address)
+	if err != nil || !found || accIndex.storageSlots == 0 {
+		return nil, false, err
+	}
+	indexes := rawdb.ReadStateStorageIndex(freezer, id)
+	if uint32(len(indexes)) < (accIndex.storageOffset+accIndex.storageSlots)*uint32(slotIndexSize) {
+		return nil, false, fmt.Errorf("storage index of state history %d is corrupted", id)
+	}
+	indexes = indexes[accIndex.storageOffset*uint32(slotIndexSize) : (accIndex.storageOffset+accIndex.storageSlots)*uint32(slotIndexSize)]
+
+	var (
+		n   = len(indexes) / slotIndexSize
+		pos = sort.Search(n, func(i int) bool {
+			return bytes.Compare(indexes[i*slotIndexSize:i*slotIndexSize+common.HashLength], slotHash.Bytes()) >= 0
+		})
+	)
+	if pos == n || !bytes.Equal(indexes[pos*slotIndexSize:pos*slotIndexSize+common.HashLength], slotHash.Bytes()) {
+		return nil, false, nil
+	}
+	var index slotIndex
+	index.decode(indexes[pos*slotIndexSize : (pos+1)*slotIndexSize])
+
+	data := rawdb.ReadStateStorageHistory(freezer, id)
+	if uint32(len(data)) < index.offset+uint32(index.length) {
+		return nil, false, fmt.Errorf("storage data of state history %d is corrupted", id)
+	}
+	return data[index.offset : index.offset+uint32(index.length)], true, nil
+}
+
+// readHistoryIncomplete reports whether the storage of the given account is
+// marked as incomplete in the specified state history.
+func readHistoryIncomplete(freezer *rawdb.ResettableFreezer, id uint64, address common.Address) (bool, error) {
+	blob := rawdb.ReadStateHistoryMeta(freezer, id)
+	if len(blob) == 0 {
+		return false, fmt.Errorf("state history not found %d", id)
+	}
+	var m meta
+	if err := m.decode(blob); err != nil {
+		return false, err
+	}
+	for _, addr := range m.incomplete {
+		if addr == address {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HistoricalStateReader provides access to the state at a historical point
+// which is no longer available in the layer tree. The state is reconstructed
+// from the state histories: the value of an item at the requested state is
+// recorded in the first state history after it which modifies the item. If
+// there is no such history, the item remains unchanged up to the state of
+// the disk layer and can be resolved from there.
+type HistoricalStateReader struct {
+	db       *Database
+	id       uint64      // The state id of the requested state
+	diskID   uint64      // The state id of the disk layer when the reader was created
+	diskRoot common.Hash // The state root of the disk layer when the reader was created
+}
+
+// HistoricReader constructs a reader for accessing the requested historical
+// state. The state must be reachable by the state histories still retained.
+func (db *Database) HistoricReader(root common.Hash) (*HistoricalStateReader, error) {
+	if db.indexer == nil {
+		return nil, errStateIndexDisabled
+	}
+	root = types.TrieRootHash(root)
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	dl := db.tree.bottom()
+	if *id > dl.stateID() {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	// The state histories in range [id+1, disklayer.ID] are required
+	// for resolving the state.
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return nil, err
+	}
+	if *id < tail {
+		return nil, fmt.Errorf("state %#x is pruned, id: %d, tail: %d", root, *id, tail)
+	}
+	if indexed := db.indexer.indexed(); dl.stateID() > indexed+maxUnindexedHistories {
+		return nil, fmt.Errorf("%w, indexed: %d, disk: %d", errHistoryNotIndexed, indexed, dl.stateID())
+	}
+	return &HistoricalStateReader{
+		db:       db,
+		id:       *id,
+		diskID:   dl.stateID(),
+		diskRoot: dl.rootHash(),
+	}, nil
+}
+
+// DiskRoot returns the state root of the disk layer where the items which are
+// not modified since the requested state have to be resolved from.
+func (r *HistoricalStateReader) DiskRoot() common.Hash {
+	return r.diskRoot
+}
+
+// find returns the id of the first state history after the requested state
+// which modifies the item, with the given function to inspect the state
+// histories which are not yet indexed.
+func (r *HistoricalStateReader) find(ident []byte, contains func(id uint64) (bool, error)) (uint64, bool, error) {
+	if r.id == r.diskID {
+		return 0, false, nil
+	}
+	indexed := r.db.indexer.indexed()
+	if indexed > r.id {
+		id, found, err := findIndex(r.db.diskdb, ident, r.id)
+		if err != nil {
+			return 0, false, err
+		}
+		if found {
+			if id > r.diskID {
+				return 0, false, nil
+			}
+			return id, true, nil
+		}
+	}
+	// Scan through the state histories which are not yet indexed.
+	start := r.id + 1
+	if indexed >= start {
+		start = indexed + 1
+	}
+	if start <= r.diskID && r.diskID-start >= maxUnindexedHistories {
+		return 0, false, fmt.Errorf("%w, indexed: %d, disk: %d", errHistoryNotIndexed, indexed, r.diskID)
+	}
+	for id := start; id <= r.diskID; id++ {
+		ok, err := contains(id)
+		if err != nil {
+			return 0, false, err
+		}
+		if ok {
+			return id, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Account returns the RLP-encoded slim account with the specified address at
+// the requested state. The returned data is empty if the account was not
+// existent. The flag is false if the account has not been modified since the
+// requested state, in which case it must be resolved from the disk state.
+func (r *HistoricalStateReader) Account(address common.Address) ([]byte, bool, error) {
+	id, found, err := r.find(rawdb.AccountHistoryIndexPrefix(address), func(id uint64) (bool, error) {
+		_, _, ok, err := readHistoryAccount(r.db.freezer, id, address)
+		return ok, err
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+	_, data, _, err := readHistoryAccount(r.db.freezer, id, address)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Storage returns the RLP-encoded storage slot with the specified account
+// address and slot hash at the requested state. The returned data is empty
+// if the slot was not existent. The flag is false if the slot has not been
+// modified since the requested state, in which case it must be resolved from
+// the disk state.
+func (r *HistoricalStateReader) Storage(address common.Address, slotHash common.Hash) ([]byte, bool, error) {
+	id, found, err := r.find(rawdb.StorageHistoryIndexPrefix(address, slotHash), func(id uint64) (bool, error) {
+		_, ok, err := readHistoryStorage(r.db.freezer, id, address, slotHash)
+		return ok, err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	// Reject the request if the storage was wiped without being recorded
+	// before the located state history.
+	wiped, wipedFound, err := r.find(rawdb.IncompleteHistoryIndexPrefix(address), func(id uint64) (bool, error) {
+		return readHistoryIncomplete(r.db.freezer, id, address)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if wipedFound && (!found || wiped < id) {
+		return nil, false, fmt.Errorf("%w, address: %x, id: %d", errIncompleteHistory, address, wiped)
+	}
+	if !found {
+		return nil, false, nil
+	}
+	data, _, err := readHistoryStorage(r.db.freezer, id, address, slotHash)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
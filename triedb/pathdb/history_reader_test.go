@@ -0,0 +1,106 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func waitIndexing(t *testing.T, db *Database) {
+	t.Helper()
+
+	for i := 0; i < 1000; i++ {
+		if db.indexer.indexed() == db.tree.bottom().stateID() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("State histories are not indexed, indexed: %d, disk: %d", db.indexer.indexed(), db.tree.bottom().stateID())
+}
+
+func TestHistoricReader(t *testing.T) {
+	tester := newTester(t, 0)
+	defer tester.release()
+
+	if err := tester.db.Commit(tester.lastHash(), false); err != nil {
+		t.Fatalf("Failed to cap database, err: %v", err)
+	}
+	if _, err := tester.db.HistoricReader(tester.roots[0]); err == nil {
+		t.Fatal("Historic reader is available without state indexing")
+	}
+	// Reopen the database with state indexing enabled, the existent state
+	// histories should be indexed in the background.
+	tester.db.Close()
+	tester.db = New(tester.db.diskdb, &Config{EnableStateIndexing: true}, false)
+	waitIndexing(t, tester.db)
+
+	for i := 0; i < len(tester.roots)-1; i += 128 {
+		root := tester.roots[i]
+		reader, err := tester.db.HistoricReader(root)
+		if err != nil {
+			t.Fatalf("Failed to open historic reader, err: %v", err)
+		}
+		if reader.DiskRoot() != tester.lastHash() {
+			t.Fatalf("Unexpected disk root, want: %x, got: %x", tester.lastHash(), reader.DiskRoot())
+		}
+		for addrHash, addr := range tester.preimages {
+			want := tester.snapAccounts[root][addrHash]
+			blob, found, err := reader.Account(addr)
+			if err != nil {
+				t.Fatalf("Failed to read account, err: %v", err)
+			}
+			if !found {
+				blob = tester.accounts[addrHash]
+			}
+			if !bytes.Equal(blob, want) {
+				t.Fatalf("Account is mismatched, root: %x, address: %x, want: %x, got: %x", root, addr, want, blob)
+			}
+			// Check a few slots of the account, both the ones at the
+			// historical state and the ones at the disk state.
+			slots := make(map[common.Hash]struct{})
+			for hash := range tester.snapStorages[root][addrHash] {
+				if len(slots) == 2 {
+					break
+				}
+				slots[hash] = struct{}{}
+			}
+			for hash := range tester.storages[addrHash] {
+				if len(slots) == 4 {
+					break
+				}
+				slots[hash] = struct{}{}
+			}
+			for hash := range slots {
+				want := tester.snapStorages[root][addrHash][hash]
+				blob, found, err := reader.Storage(addr, hash)
+				if err != nil {
+					t.Fatalf("Failed to read storage, err: %v", err)
+				}
+				if !found {
+					blob = tester.storages[addrHash][hash]
+				}
+				if !bytes.Equal(blob, want) {
+					t.Fatalf("Storage is mismatched, root: %x, address: %x, slot: %x, want: %x, got: %x", root, addr, hash, want, blob)
+				}
+			}
+		}
+	}
+}
@@ -26,7 +26,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -115,8 +114,7 @@ func (db *Database) loadJournal(diskRoot common.Hash) (layer, error) {
 // loadLayers loads a pre-existing state layer backed by a key-value store.
 func (db *Database) loadLayers() layer {
 	// Retrieve the root node of persistent state.
-	_, root := rawdb.ReadAccountTrieNode(db.diskdb, nil)
-	root = types.TrieRootHash(root)
+	root := types.TrieRootHash(db.diskRoot())
 
 	// Load the layers by resolving the journal
 	head, err := db.loadJournal(root)
@@ -162,7 +160,7 @@ func (db *Database) loadDiskLayer(r *rlp.Stream) (layer, error) {
 		subset := make(map[string]*trienode.Node)
 		for _, n := range entry.Nodes {
 			if len(n.Blob) > 0 {
-				subset[string(n.Path)] = trienode.New(crypto.Keccak256Hash(n.Blob), n.Blob)
+				subset[string(n.Path)] = trienode.New(db.nodeHash(n.Blob), n.Blob)
 			} else {
 				subset[string(n.Path)] = trienode.NewDeleted()
 			}
@@ -200,7 +198,7 @@ func (db *Database) loadDiffLayer(parent layer, r *rlp.Stream) (layer, error) {
 		subset := make(map[string]*trienode.Node)
 		for _, n := range entry.Nodes {
 			if len(n.Blob) > 0 {
-				subset[string(n.Path)] = trienode.New(crypto.Keccak256Hash(n.Blob), n.Blob)
+				subset[string(n.Path)] = trienode.New(db.nodeHash(n.Blob), n.Blob)
 			} else {
 				subset[string(n.Path)] = trienode.NewDeleted()
 			}
@@ -365,8 +363,7 @@ func (db *Database) Journal(root common.Hash) error {
 	}
 	// The stored state in disk might be empty, convert the
 	// root to emptyRoot in this case.
-	_, diskroot := rawdb.ReadAccountTrieNode(db.diskdb, nil)
-	diskroot = types.TrieRootHash(diskroot)
+	diskroot := types.TrieRootHash(db.diskRoot())
 
 	// Secondly write out the state root in disk, ensure all layers
 	// on top are continuous with disk.